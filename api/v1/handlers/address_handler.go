@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"backend/internal/auth"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AddressHandler maneja las peticiones HTTP de la libreta de direcciones
+type AddressHandler struct {
+	addressService *services.AddressService
+}
+
+// NewAddressHandler crea un nuevo handler de direcciones
+func NewAddressHandler(addressService *services.AddressService) *AddressHandler {
+	return &AddressHandler{
+		addressService: addressService,
+	}
+}
+
+// @Summary Listar mis direcciones
+// @Description Devuelve las direcciones guardadas del usuario autenticado (primero la predeterminada)
+// @Tags direcciones
+// @Produce json
+// @Success 200 {array} models.UserAddress
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /users/me/addresses [get]
+// ListAddresses lista las direcciones del usuario
+func (h *AddressHandler) ListAddresses(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	addresses, err := h.addressService.List(claims.UserID.String())
+	if err != nil {
+		return h.handleAddressError(c, err)
+	}
+
+	return c.JSON(addresses)
+}
+
+// @Summary Obtener una dirección
+// @Tags direcciones
+// @Produce json
+// @Param addressId path string true "ID de la dirección"
+// @Success 200 {object} models.UserAddress
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /users/me/addresses/{addressId} [get]
+// GetAddress obtiene una dirección del usuario
+func (h *AddressHandler) GetAddress(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	address, err := h.addressService.Get(c.Params("addressId"), claims.UserID.String())
+	if err != nil {
+		return h.handleAddressError(c, err)
+	}
+
+	return c.JSON(address)
+}
+
+// @Summary Guardar una dirección
+// @Description Guarda una dirección con etiqueta, coordenadas y referencia. La primera queda como predeterminada
+// @Tags direcciones
+// @Accept json
+// @Produce json
+// @Param address body models.CreateAddressRequest true "Datos de la dirección"
+// @Success 201 {object} models.UserAddress
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /users/me/addresses [post]
+// CreateAddress guarda una nueva dirección
+func (h *AddressHandler) CreateAddress(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	var req models.CreateAddressRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos de dirección inválidos",
+		})
+	}
+
+	address, err := h.addressService.Create(claims.UserID.String(), &req)
+	if err != nil {
+		return h.handleAddressError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(address)
+}
+
+// @Summary Actualizar una dirección
+// @Tags direcciones
+// @Accept json
+// @Produce json
+// @Param addressId path string true "ID de la dirección"
+// @Param address body models.UpdateAddressRequest true "Campos a actualizar"
+// @Success 200 {object} models.UserAddress
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /users/me/addresses/{addressId} [put]
+// UpdateAddress actualiza una dirección del usuario
+func (h *AddressHandler) UpdateAddress(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	var req models.UpdateAddressRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos de dirección inválidos",
+		})
+	}
+
+	address, err := h.addressService.Update(c.Params("addressId"), claims.UserID.String(), &req)
+	if err != nil {
+		return h.handleAddressError(c, err)
+	}
+
+	return c.JSON(address)
+}
+
+// @Summary Marcar dirección como predeterminada
+// @Tags direcciones
+// @Produce json
+// @Param addressId path string true "ID de la dirección"
+// @Success 200 {object} models.UserAddress
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /users/me/addresses/{addressId}/default [put]
+// SetDefaultAddress marca una dirección como predeterminada
+func (h *AddressHandler) SetDefaultAddress(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	address, err := h.addressService.SetDefault(c.Params("addressId"), claims.UserID.String())
+	if err != nil {
+		return h.handleAddressError(c, err)
+	}
+
+	return c.JSON(address)
+}
+
+// @Summary Eliminar una dirección
+// @Description Elimina una dirección guardada. Los pedidos que la usaron conservan su copia
+// @Tags direcciones
+// @Produce json
+// @Param addressId path string true "ID de la dirección"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /users/me/addresses/{addressId} [delete]
+// DeleteAddress elimina una dirección del usuario
+func (h *AddressHandler) DeleteAddress(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	if err := h.addressService.Delete(c.Params("addressId"), claims.UserID.String()); err != nil {
+		return h.handleAddressError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Dirección eliminada correctamente",
+	})
+}
+
+// handleAddressError traduce los errores del servicio de direcciones a respuestas HTTP
+func (h *AddressHandler) handleAddressError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidAddress):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrAddressNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Dirección no encontrada",
+		})
+	case errors.Is(err, services.ErrAddressLimitReached):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Alcanzaste el máximo de direcciones guardadas",
+		})
+	default:
+		log.Printf("Error en libreta de direcciones: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al procesar la dirección",
+		})
+	}
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *AddressHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler) {
+	addresses := router.Group("/users/me/addresses", authMiddleware)
+
+	addresses.Get("/", h.ListAddresses)                       // GET /users/me/addresses
+	addresses.Post("/", h.CreateAddress)                      // POST /users/me/addresses
+	addresses.Get("/:addressId", h.GetAddress)                // GET /users/me/addresses/:addressId
+	addresses.Put("/:addressId", h.UpdateAddress)             // PUT /users/me/addresses/:addressId
+	addresses.Put("/:addressId/default", h.SetDefaultAddress) // PUT /users/me/addresses/:addressId/default
+	addresses.Delete("/:addressId", h.DeleteAddress)          // DELETE /users/me/addresses/:addressId
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"log"
+
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AnalyticsHandler maneja los reportes de ventas para administradores
+type AnalyticsHandler struct {
+	analyticsService *services.AnalyticsService
+}
+
+// NewAnalyticsHandler crea un nuevo handler de analítica
+func NewAnalyticsHandler(analyticsService *services.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+// @Summary Resumen de ventas
+// @Description Ingresos, cantidad de pedidos, ticket promedio y tasa de cancelación de un rango de jornadas, con comparación opcional
+// @Tags analítica
+// @Produce json
+// @Param from query string false "Jornada inicial YYYY-MM-DD (por defecto, últimos 30 días)"
+// @Param to query string false "Jornada final YYYY-MM-DD"
+// @Param compare query string false "previous para comparar con el período anterior de igual duración"
+// @Param compare_from query string false "Jornada inicial del período de comparación"
+// @Param compare_to query string false "Jornada final del período de comparación"
+// @Success 200 {object} models.SalesAnalytics
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/analytics/summary [get]
+// GetSummary obtiene los indicadores totales de ventas
+func (h *AnalyticsHandler) GetSummary(c *fiber.Ctx) error {
+	analytics, err := h.analyticsService.GetSalesAnalytics(h.parseQuery(c, ""))
+	if err != nil {
+		return h.handleAnalyticsError(c, err)
+	}
+
+	return c.JSON(analytics)
+}
+
+// @Summary Ventas agrupadas
+// @Description Indicadores de ventas agrupados por día, semana, mes, producto, categoría, repartidor u hora del día (zona horaria del negocio)
+// @Tags analítica
+// @Produce json
+// @Param group_by query string false "day (por defecto), week, month, product, category, repartidor u hour"
+// @Param from query string false "Jornada inicial YYYY-MM-DD (por defecto, últimos 30 días)"
+// @Param to query string false "Jornada final YYYY-MM-DD"
+// @Param compare query string false "previous para comparar con el período anterior de igual duración"
+// @Param compare_from query string false "Jornada inicial del período de comparación"
+// @Param compare_to query string false "Jornada final del período de comparación"
+// @Success 200 {object} models.SalesAnalytics
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/analytics/sales [get]
+// GetSales obtiene los indicadores de ventas agrupados
+func (h *AnalyticsHandler) GetSales(c *fiber.Ctx) error {
+	analytics, err := h.analyticsService.GetSalesAnalytics(h.parseQuery(c, c.Query("group_by", "day")))
+	if err != nil {
+		return h.handleAnalyticsError(c, err)
+	}
+
+	return c.JSON(analytics)
+}
+
+// parseQuery lee el rango y la comparación de los parámetros de consulta
+func (h *AnalyticsHandler) parseQuery(c *fiber.Ctx, groupBy string) services.SalesAnalyticsQuery {
+	return services.SalesAnalyticsQuery{
+		GroupBy:         groupBy,
+		From:            c.Query("from"),
+		To:              c.Query("to"),
+		CompareFrom:     c.Query("compare_from"),
+		CompareTo:       c.Query("compare_to"),
+		ComparePrevious: c.Query("compare") == "previous",
+	}
+}
+
+// handleAnalyticsError traduce los errores del servicio de analítica a respuestas HTTP
+func (h *AnalyticsHandler) handleAnalyticsError(c *fiber.Ctx, err error) error {
+	switch err {
+	case services.ErrInvalidBusinessDate:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Rango de fechas inválido, use el formato YYYY-MM-DD",
+		})
+	case services.ErrInvalidAnalyticsGroup:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Agrupación inválida. Use day, week, month, product, category, repartidor u hour",
+		})
+	default:
+		log.Printf("Error al generar reporte de ventas: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al generar el reporte de ventas",
+		})
+	}
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *AnalyticsHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler) {
+	analytics := router.Group("/admin/analytics", authMiddleware, adminOnly)
+	analytics.Get("/summary", h.GetSummary) // GET /admin/analytics/summary
+	analytics.Get("/sales", h.GetSales)     // GET /admin/analytics/sales
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"backend/internal/auth"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BranchHandler maneja las sucursales, su stock y la asignación de personal
+type BranchHandler struct {
+	branchService *services.BranchService
+}
+
+// NewBranchHandler crea un nuevo handler de sucursales
+func NewBranchHandler(branchService *services.BranchService) *BranchHandler {
+	return &BranchHandler{
+		branchService: branchService,
+	}
+}
+
+// @Summary Listar sucursales activas
+// @Description Sucursales activas con su ubicación, zona de reparto y horario propio
+// @Tags sucursales
+// @Produce json
+// @Success 200 {array} models.Branch
+// @Failure 500 {object} map[string]interface{}
+// @Router /branches [get]
+// ListActiveBranches lista las sucursales activas
+func (h *BranchHandler) ListActiveBranches(c *fiber.Ctx) error {
+	branches, err := h.branchService.ListBranches(true)
+	if err != nil {
+		return h.handleBranchError(c, err)
+	}
+
+	return c.JSON(branches)
+}
+
+// @Summary Listar todas las sucursales
+// @Tags sucursales
+// @Produce json
+// @Success 200 {array} models.Branch
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/branches [get]
+// ListBranches lista todas las sucursales, incluidas las inactivas
+func (h *BranchHandler) ListBranches(c *fiber.Ctx) error {
+	branches, err := h.branchService.ListBranches(false)
+	if err != nil {
+		return h.handleBranchError(c, err)
+	}
+
+	return c.JSON(branches)
+}
+
+// @Summary Obtener una sucursal
+// @Tags sucursales
+// @Produce json
+// @Param id path string true "ID de la sucursal"
+// @Success 200 {object} models.Branch
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/branches/{id} [get]
+// GetBranch obtiene una sucursal
+func (h *BranchHandler) GetBranch(c *fiber.Ctx) error {
+	branch, err := h.branchService.GetBranch(c.Params("id"))
+	if err != nil {
+		return h.handleBranchError(c, err)
+	}
+
+	return c.JSON(branch)
+}
+
+// @Summary Crear sucursal
+// @Description Crea una sucursal con su ubicación y zona de reparto. La primera sucursal recibe el stock actual de los productos. Solo administradores sin sucursal asignada
+// @Tags sucursales
+// @Accept json
+// @Produce json
+// @Param branch body models.CreateBranchRequest true "Datos de la sucursal"
+// @Success 201 {object} models.Branch
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/branches [post]
+// CreateBranch crea una sucursal
+func (h *BranchHandler) CreateBranch(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	var req models.CreateBranchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos de sucursal inválidos",
+		})
+	}
+
+	branch, err := h.branchService.CreateBranch(claims.UserID.String(), &req)
+	if err != nil {
+		return h.handleBranchError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(branch)
+}
+
+// @Summary Actualizar sucursal
+// @Description Actualiza los datos, la zona de reparto, el horario propio o el estado de una sucursal
+// @Tags sucursales
+// @Accept json
+// @Produce json
+// @Param id path string true "ID de la sucursal"
+// @Param branch body models.UpdateBranchRequest true "Campos a actualizar"
+// @Success 200 {object} models.Branch
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/branches/{id} [put]
+// UpdateBranch actualiza una sucursal
+func (h *BranchHandler) UpdateBranch(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	var req models.UpdateBranchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos de sucursal inválidos",
+		})
+	}
+
+	branch, err := h.branchService.UpdateBranch(claims.UserID.String(), c.Params("id"), &req)
+	if err != nil {
+		return h.handleBranchError(c, err)
+	}
+
+	return c.JSON(branch)
+}
+
+// @Summary Stock de una sucursal
+// @Tags sucursales
+// @Produce json
+// @Param id path string true "ID de la sucursal"
+// @Success 200 {array} models.BranchStock
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/branches/{id}/stock [get]
+// GetBranchStock obtiene el stock por producto de una sucursal
+func (h *BranchHandler) GetBranchStock(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	stock, err := h.branchService.GetBranchStock(claims.UserID.String(), c.Params("id"))
+	if err != nil {
+		return h.handleBranchError(c, err)
+	}
+
+	return c.JSON(stock)
+}
+
+// @Summary Fijar stock en una sucursal
+// @Description Fija el stock de un producto en la sucursal. El stock total del producto pasa a ser la suma de todas las sucursales
+// @Tags sucursales
+// @Accept json
+// @Produce json
+// @Param id path string true "ID de la sucursal"
+// @Param productId path string true "ID del producto"
+// @Param stock body models.SetBranchStockRequest true "Cantidad disponible"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/branches/{id}/stock/{productId} [put]
+// SetBranchStock fija el stock de un producto en una sucursal
+func (h *BranchHandler) SetBranchStock(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	var req models.SetBranchStockRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos de stock inválidos",
+		})
+	}
+
+	if err := h.branchService.SetBranchStock(claims.UserID.String(), c.Params("id"), c.Params("productId"), req.Quantity); err != nil {
+		return h.handleBranchError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Stock actualizado correctamente",
+	})
+}
+
+// @Summary Asignar sucursal a un usuario
+// @Description Asigna un repartidor o administrador a una sucursal; branch_id null lo deja sin sucursal. Un administrador con sucursal solo gestiona la suya
+// @Tags sucursales
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del usuario"
+// @Param branch body models.AssignUserBranchRequest true "Sucursal a asignar"
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/users/{id}/branch [put]
+// AssignUserBranch asigna una sucursal a un repartidor o administrador
+func (h *BranchHandler) AssignUserBranch(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	var req models.AssignUserBranchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos inválidos",
+		})
+	}
+
+	user, err := h.branchService.AssignUserBranch(claims.UserID.String(), c.Params("id"), req.BranchID)
+	if err != nil {
+		return h.handleBranchError(c, err)
+	}
+
+	return c.JSON(user)
+}
+
+// handleBranchError traduce los errores del servicio de sucursales a respuestas HTTP
+func (h *BranchHandler) handleBranchError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidBranch):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidStockQuantity):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "La cantidad de stock no puede ser negativa",
+		})
+	case errors.Is(err, services.ErrInvalidRole):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Solo se pueden asignar sucursales a repartidores y administradores",
+		})
+	case errors.Is(err, services.ErrBranchNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Sucursal no encontrada",
+		})
+	case errors.Is(err, services.ErrProductNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Producto no encontrado",
+		})
+	case errors.Is(err, services.ErrUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuario no encontrado",
+		})
+	case errors.Is(err, services.ErrBranchAccessDenied):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "No tienes acceso a esta sucursal",
+		})
+	case errors.Is(err, services.ErrBranchNameTaken):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Ya existe una sucursal con ese nombre",
+		})
+	default:
+		log.Printf("Error en sucursales: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al procesar la sucursal",
+		})
+	}
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *BranchHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler) {
+	router.Get("/branches", h.ListActiveBranches) // GET /branches
+
+	branches := router.Group("/admin/branches", authMiddleware, adminOnly)
+	branches.Get("/", h.ListBranches)                       // GET /admin/branches
+	branches.Post("/", h.CreateBranch)                      // POST /admin/branches
+	branches.Get("/:id", h.GetBranch)                       // GET /admin/branches/:id
+	branches.Put("/:id", h.UpdateBranch)                    // PUT /admin/branches/:id
+	branches.Get("/:id/stock", h.GetBranchStock)            // GET /admin/branches/:id/stock
+	branches.Put("/:id/stock/:productId", h.SetBranchStock) // PUT /admin/branches/:id/stock/:productId
+
+	router.Put("/admin/users/:id/branch", authMiddleware, adminOnly, h.AssignUserBranch) // PUT /admin/users/:id/branch
+}
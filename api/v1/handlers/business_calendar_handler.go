@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BusinessCalendarHandler maneja el horario de atención público y su administración
+type BusinessCalendarHandler struct {
+	calendarService *services.BusinessCalendarService
+}
+
+// NewBusinessCalendarHandler crea un nuevo handler del calendario de atención
+func NewBusinessCalendarHandler(calendarService *services.BusinessCalendarService) *BusinessCalendarHandler {
+	return &BusinessCalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// @Summary Horario de atención
+// @Description Indica si el negocio está atendiendo ahora, la próxima apertura y el horario de hoy y los próximos 6 días (incluye feriados y horarios especiales)
+// @Tags horario
+// @Produce json
+// @Success 200 {object} models.StoreHours
+// @Router /store/hours [get]
+// GetStoreHours obtiene el horario de atención público
+func (h *BusinessCalendarHandler) GetStoreHours(c *fiber.Ctx) error {
+	return c.JSON(h.calendarService.GetStoreHours())
+}
+
+// @Summary Horario semanal
+// @Description Horario de atención por día de la semana (0 = domingo). Los días sin configurar muestran el horario por defecto
+// @Tags horario
+// @Produce json
+// @Success 200 {array} models.BusinessHours
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/store/hours [get]
+// GetWeeklyHours obtiene el horario semanal
+func (h *BusinessCalendarHandler) GetWeeklyHours(c *fiber.Ctx) error {
+	hours, err := h.calendarService.GetWeeklyHours()
+	if err != nil {
+		return h.handleCalendarError(c, err)
+	}
+
+	return c.JSON(hours)
+}
+
+// @Summary Actualizar horario semanal
+// @Description Reemplaza el horario de los días enviados; los demás se mantienen. Aplica de inmediato sin reiniciar el servidor
+// @Tags horario
+// @Accept json
+// @Produce json
+// @Param hours body []models.BusinessHours true "Horario por día de la semana"
+// @Success 200 {array} models.BusinessHours
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/store/hours [put]
+// UpdateWeeklyHours actualiza el horario semanal
+func (h *BusinessCalendarHandler) UpdateWeeklyHours(c *fiber.Ctx) error {
+	var req []models.BusinessHours
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos de horario inválidos",
+		})
+	}
+
+	hours, err := h.calendarService.UpdateWeeklyHours(req)
+	if err != nil {
+		return h.handleCalendarError(c, err)
+	}
+
+	return c.JSON(hours)
+}
+
+// @Summary Listar días especiales
+// @Description Feriados, cierres y horarios especiales entre dos fechas (por defecto, los próximos 60 días)
+// @Tags horario
+// @Produce json
+// @Param from query string false "Fecha inicial (YYYY-MM-DD)"
+// @Param to query string false "Fecha final (YYYY-MM-DD)"
+// @Success 200 {array} models.SpecialDay
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/store/special-days [get]
+// ListSpecialDays lista los días especiales
+func (h *BusinessCalendarHandler) ListSpecialDays(c *fiber.Ctx) error {
+	days, err := h.calendarService.ListSpecialDays(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return h.handleCalendarError(c, err)
+	}
+
+	return c.JSON(days)
+}
+
+// @Summary Crear día especial
+// @Description Registra un feriado o cierre (is_open = false) o un horario especial (is_open = true con opens_at y closes_at)
+// @Tags horario
+// @Accept json
+// @Produce json
+// @Param day body models.SpecialDayRequest true "Datos del día especial"
+// @Success 201 {object} models.SpecialDay
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/store/special-days [post]
+// CreateSpecialDay crea un día especial
+func (h *BusinessCalendarHandler) CreateSpecialDay(c *fiber.Ctx) error {
+	var req models.SpecialDayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos del día especial inválidos",
+		})
+	}
+
+	day, err := h.calendarService.CreateSpecialDay(&req)
+	if err != nil {
+		return h.handleCalendarError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(day)
+}
+
+// @Summary Actualizar día especial
+// @Tags horario
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del día especial"
+// @Param day body models.SpecialDayRequest true "Datos del día especial"
+// @Success 200 {object} models.SpecialDay
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/store/special-days/{id} [put]
+// UpdateSpecialDay actualiza un día especial
+func (h *BusinessCalendarHandler) UpdateSpecialDay(c *fiber.Ctx) error {
+	var req models.SpecialDayRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos del día especial inválidos",
+		})
+	}
+
+	day, err := h.calendarService.UpdateSpecialDay(c.Params("id"), &req)
+	if err != nil {
+		return h.handleCalendarError(c, err)
+	}
+
+	return c.JSON(day)
+}
+
+// @Summary Eliminar día especial
+// @Description La fecha vuelve a usar el horario semanal
+// @Tags horario
+// @Produce json
+// @Param id path string true "ID del día especial"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/store/special-days/{id} [delete]
+// DeleteSpecialDay elimina un día especial
+func (h *BusinessCalendarHandler) DeleteSpecialDay(c *fiber.Ctx) error {
+	if err := h.calendarService.DeleteSpecialDay(c.Params("id")); err != nil {
+		return h.handleCalendarError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Día especial eliminado correctamente",
+	})
+}
+
+// handleCalendarError traduce los errores del servicio de calendario a respuestas HTTP
+func (h *BusinessCalendarHandler) handleCalendarError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidBusinessHours), errors.Is(err, services.ErrInvalidSpecialDay):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidBusinessDate):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Rango de fechas inválido, use YYYY-MM-DD",
+		})
+	case errors.Is(err, services.ErrSpecialDayNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Día especial no encontrado",
+		})
+	case errors.Is(err, services.ErrSpecialDayExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Ya existe un día especial para esa fecha",
+		})
+	default:
+		log.Printf("Error en calendario de atención: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al procesar el horario de atención",
+		})
+	}
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *BusinessCalendarHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler) {
+	router.Get("/store/hours", h.GetStoreHours) // GET /store/hours
+
+	admin := router.Group("/admin/store", authMiddleware, adminOnly)
+	admin.Get("/hours", h.GetWeeklyHours)                 // GET /admin/store/hours
+	admin.Put("/hours", h.UpdateWeeklyHours)              // PUT /admin/store/hours
+	admin.Get("/special-days", h.ListSpecialDays)         // GET /admin/store/special-days
+	admin.Post("/special-days", h.CreateSpecialDay)       // POST /admin/store/special-days
+	admin.Put("/special-days/:id", h.UpdateSpecialDay)    // PUT /admin/store/special-days/:id
+	admin.Delete("/special-days/:id", h.DeleteSpecialDay) // DELETE /admin/store/special-days/:id
+}
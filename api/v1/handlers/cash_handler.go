@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"log"
+
+	"backend/internal/auth"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CashHandler maneja las peticiones HTTP relacionadas con la caja de los repartidores
+type CashHandler struct {
+	cashService *services.CashService
+}
+
+// NewCashHandler crea un nuevo handler de caja
+func NewCashHandler(cashService *services.CashService) *CashHandler {
+	return &CashHandler{
+		cashService: cashService,
+	}
+}
+
+// RecordHandInRequest estructura para registrar una entrega de efectivo
+type RecordHandInRequest struct {
+	Date   string  `json:"date"` // YYYY-MM-DD, vacío = hoy
+	Amount float64 `json:"amount" validate:"min=0"`
+	Notes  string  `json:"notes"`
+}
+
+// @Summary Obtener la liquidación diaria de caja
+// @Description Devuelve la caja de cada repartidor con entregas en efectivo o entregas registradas en la jornada
+// @Tags caja
+// @Produce json
+// @Param date query string false "Jornada en formato YYYY-MM-DD (por defecto hoy)"
+// @Success 200 {object} models.DailySettlementReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/cash/settlements [get]
+// GetDailySettlementReport obtiene la liquidación diaria de todos los repartidores
+func (h *CashHandler) GetDailySettlementReport(c *fiber.Ctx) error {
+	report, err := h.cashService.GetDailySettlementReport(c.Query("date"))
+	if err != nil {
+		return h.handleCashError(c, err)
+	}
+
+	return c.JSON(report)
+}
+
+// @Summary Obtener la caja de un repartidor
+// @Description Devuelve los pedidos cobrados en efectivo, lo entregado y la diferencia de un repartidor en una jornada
+// @Tags caja
+// @Produce json
+// @Param id path string true "ID del repartidor"
+// @Param date query string false "Jornada en formato YYYY-MM-DD (por defecto hoy)"
+// @Success 200 {object} models.CashLedger
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/cash/repartidores/{id}/ledger [get]
+// GetRepartidorLedger obtiene la caja de un repartidor
+func (h *CashHandler) GetRepartidorLedger(c *fiber.Ctx) error {
+	repartidorID := c.Params("id")
+	if _, err := uuid.Parse(repartidorID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de repartidor inválido",
+		})
+	}
+
+	ledger, err := h.cashService.GetRepartidorLedger(repartidorID, c.Query("date"))
+	if err != nil {
+		return h.handleCashError(c, err)
+	}
+
+	return c.JSON(ledger)
+}
+
+// @Summary Registrar entrega de efectivo
+// @Description Registra el monto que un repartidor entregó en caja y devuelve la caja actualizada
+// @Tags caja
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del repartidor"
+// @Param handIn body RecordHandInRequest true "Monto entregado"
+// @Success 201 {object} models.CashLedger
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/cash/repartidores/{id}/hand-ins [post]
+// RecordHandIn registra una entrega de efectivo de un repartidor
+func (h *CashHandler) RecordHandIn(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	repartidorID := c.Params("id")
+	if _, err := uuid.Parse(repartidorID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de repartidor inválido",
+		})
+	}
+
+	var req RecordHandInRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos de entrega inválidos",
+		})
+	}
+
+	ledger, err := h.cashService.RecordHandIn(repartidorID, req.Date, req.Amount, req.Notes, claims.UserID.String())
+	if err != nil {
+		return h.handleCashError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(ledger)
+}
+
+// @Summary Obtener mi caja
+// @Description Devuelve la caja del repartidor autenticado para una jornada
+// @Tags caja
+// @Produce json
+// @Param date query string false "Jornada en formato YYYY-MM-DD (por defecto hoy)"
+// @Success 200 {object} models.CashLedger
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /cash/me/ledger [get]
+// GetMyLedger obtiene la caja del repartidor autenticado
+func (h *CashHandler) GetMyLedger(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	ledger, err := h.cashService.GetRepartidorLedger(claims.UserID.String(), c.Query("date"))
+	if err != nil {
+		return h.handleCashError(c, err)
+	}
+
+	return c.JSON(ledger)
+}
+
+// handleCashError traduce los errores del servicio de caja a respuestas HTTP
+func (h *CashHandler) handleCashError(c *fiber.Ctx, err error) error {
+	switch err {
+	case services.ErrInvalidBusinessDate:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Fecha inválida, use el formato YYYY-MM-DD",
+		})
+	case services.ErrInvalidHandInAmount:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "El monto entregado no puede ser negativo",
+		})
+	case services.ErrNotARepartidor:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "El usuario no es un repartidor",
+		})
+	case services.ErrUserNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Repartidor no encontrado",
+		})
+	default:
+		log.Printf("Error en caja de repartidores: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al procesar la caja",
+		})
+	}
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *CashHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler, repartidorOrAdmin fiber.Handler) {
+	// Rutas administrativas de caja
+	adminCash := router.Group("/admin/cash", authMiddleware, adminOnly)
+	adminCash.Get("/settlements", h.GetDailySettlementReport)        // GET /admin/cash/settlements
+	adminCash.Get("/repartidores/:id/ledger", h.GetRepartidorLedger) // GET /admin/cash/repartidores/:id/ledger
+	adminCash.Post("/repartidores/:id/hand-ins", h.RecordHandIn)     // POST /admin/cash/repartidores/:id/hand-ins
+
+	// Caja propia del repartidor
+	cash := router.Group("/cash", authMiddleware, repartidorOrAdmin)
+	cash.Get("/me/ledger", h.GetMyLedger) // GET /cash/me/ledger
+}
@@ -34,6 +34,10 @@ func (h *CategoryHandler) RegisterRoutes(router fiber.Router, authMiddleware fib
 	adminCategories.Post("/", h.CreateCategory)
 	adminCategories.Put("/:id", h.UpdateCategory)
 	adminCategories.Delete("/:id", h.DeleteCategory)
+
+	// Categorías eliminadas (solo administradores)
+	router.Get("/admin/categories/deleted", authMiddleware, adminOnly, h.ListDeletedCategories)
+	router.Post("/admin/categories/:id/restore", authMiddleware, adminOnly, h.RestoreCategory)
 }
 
 // GetAllCategories obtiene todas las categorías
@@ -265,7 +269,7 @@ func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
 
 // DeleteCategory elimina una categoría
 // @Summary Eliminar categoría
-// @Description Elimina una categoría del sistema (solo administradores)
+// @Description Elimina una categoría del sistema (borrado lógico: puede restaurarse; sus productos conservan la referencia). Solo administradores
 // @Tags categorías
 // @Accept json
 // @Produce json
@@ -305,3 +309,56 @@ func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
 		"message": "Categoría eliminada exitosamente",
 	})
 }
+
+// ListDeletedCategories lista las categorías eliminadas
+// @Summary Listar categorías eliminadas
+// @Description Categorías eliminadas, las más recientes primero (solo administradores)
+// @Tags categorías
+// @Produce json
+// @Success 200 {array} models.Category
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/v1/admin/categories/deleted [get]
+func (h *CategoryHandler) ListDeletedCategories(c *fiber.Ctx) error {
+	categories, err := h.categoryService.ListDeleted()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "No se pudieron obtener las categorías eliminadas",
+		})
+	}
+
+	return c.JSON(categories)
+}
+
+// RestoreCategory restaura una categoría eliminada
+// @Summary Restaurar categoría
+// @Description Restaura una categoría eliminada si ninguna otra usa su nombre (solo administradores)
+// @Tags categorías
+// @Produce json
+// @Param id path string true "ID de la categoría"
+// @Success 200 {object} models.CategoryResponse
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /api/v1/admin/categories/{id}/restore [post]
+func (h *CategoryHandler) RestoreCategory(c *fiber.Ctx) error {
+	category, err := h.categoryService.Restore(c.Params("id"))
+	if err != nil {
+		switch err {
+		case services.ErrCategoryNotFoundService:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "No se encontró una categoría eliminada con el ID especificado",
+			})
+		case services.ErrCategoryNameExists:
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "No se pudo restaurar la categoría",
+			})
+		}
+	}
+
+	return c.JSON(category.ToResponse())
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"log"
+
+	"backend/internal/auth"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ChatHandler maneja las peticiones HTTP del chat de pedidos
+type ChatHandler struct {
+	chatService *services.ChatService
+}
+
+// NewChatHandler crea un nuevo handler de chat
+func NewChatHandler(chatService *services.ChatService) *ChatHandler {
+	return &ChatHandler{
+		chatService: chatService,
+	}
+}
+
+// @Summary Historial del chat de un pedido
+// @Description Devuelve los mensajes del chat del pedido en orden cronológico (cliente, repartidor asignado o admin)
+// @Tags chat
+// @Produce json
+// @Param id path string true "ID del pedido"
+// @Success 200 {array} models.OrderMessage
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /orders/{id}/messages [get]
+// GetMessages obtiene el historial del chat de un pedido
+func (h *ChatHandler) GetMessages(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	messages, err := h.chatService.GetMessages(c.Params("id"), claims.UserID.String(), claims.UserRole)
+	if err != nil {
+		return h.handleChatError(c, err)
+	}
+
+	return c.JSON(messages)
+}
+
+// @Summary Enviar mensaje al chat de un pedido
+// @Description Alternativa REST al WebSocket; solo mientras el pedido está ASSIGNED o IN_TRANSIT
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del pedido"
+// @Param message body models.SendOrderMessageRequest true "Contenido del mensaje"
+// @Success 201 {object} models.OrderMessage
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /orders/{id}/messages [post]
+// SendMessage envía un mensaje al chat de un pedido
+func (h *ChatHandler) SendMessage(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	var req models.SendOrderMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos de mensaje inválidos",
+		})
+	}
+
+	message, err := h.chatService.SendMessage(c.Params("id"), claims.UserID.String(), claims.UserRole, req.Content)
+	if err != nil {
+		return h.handleChatError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(message)
+}
+
+// @Summary Marcar mensajes como leídos
+// @Description Marca como leídos los mensajes recibidos en el chat del pedido y notifica a los demás participantes
+// @Tags chat
+// @Produce json
+// @Param id path string true "ID del pedido"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /orders/{id}/messages/read [put]
+// MarkAsRead marca como leídos los mensajes del chat
+func (h *ChatHandler) MarkAsRead(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	count, err := h.chatService.MarkAsRead(c.Params("id"), claims.UserID.String(), claims.UserRole)
+	if err != nil {
+		return h.handleChatError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"marked_as_read": count,
+	})
+}
+
+// handleChatError traduce los errores del servicio de chat a respuestas HTTP
+func (h *ChatHandler) handleChatError(c *fiber.Ctx, err error) error {
+	switch err {
+	case services.ErrOrderNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Pedido no encontrado",
+		})
+	case services.ErrChatAccessDenied:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "No participas en el chat de este pedido",
+		})
+	case services.ErrChatClosed:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "El chat solo está disponible mientras el pedido está asignado o en camino",
+		})
+	case services.ErrInvalidChatMessage:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "El mensaje no puede estar vacío ni superar los 1000 caracteres",
+		})
+	default:
+		log.Printf("Error en chat de pedido: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al procesar el chat",
+		})
+	}
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *ChatHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler) {
+	router.Get("/orders/:id/messages", authMiddleware, h.GetMessages)     // GET /orders/:id/messages
+	router.Post("/orders/:id/messages", authMiddleware, h.SendMessage)    // POST /orders/:id/messages
+	router.Put("/orders/:id/messages/read", authMiddleware, h.MarkAsRead) // PUT /orders/:id/messages/read
+}
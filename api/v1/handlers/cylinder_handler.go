@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"log"
+
+	"backend/internal/auth"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CylinderHandler maneja las peticiones HTTP de conciliación de balones
+type CylinderHandler struct {
+	cylinderService *services.CylinderService
+}
+
+// NewCylinderHandler crea un nuevo handler de balones
+func NewCylinderHandler(cylinderService *services.CylinderService) *CylinderHandler {
+	return &CylinderHandler{
+		cylinderService: cylinderService,
+	}
+}
+
+// @Summary Balones en poder de los clientes
+// @Description Devuelve por cliente y producto los balones entregados sin vacío a cambio (con garantía)
+// @Tags balones
+// @Produce json
+// @Param client_id query string false "Filtrar por cliente"
+// @Success 200 {array} models.CylinderHolding
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/cylinders/holdings [get]
+// GetHoldings obtiene los balones en poder de los clientes
+func (h *CylinderHandler) GetHoldings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID != "" {
+		if _, err := uuid.Parse(clientID); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "ID de cliente inválido",
+			})
+		}
+	}
+
+	holdings, err := h.cylinderService.GetHoldings(clientID)
+	if err != nil {
+		log.Printf("Error al obtener balones de clientes: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener los balones",
+		})
+	}
+
+	return c.JSON(holdings)
+}
+
+// @Summary Movimiento diario de balones por repartidor
+// @Description Balones llenos entregados, vacíos recogidos y garantías cobradas por repartidor en una jornada
+// @Tags balones
+// @Produce json
+// @Param date query string false "Jornada en formato YYYY-MM-DD (por defecto hoy)"
+// @Success 200 {object} models.CylinderDailyReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/cylinders/collections [get]
+// GetDailyReport obtiene el movimiento de balones de una jornada
+func (h *CylinderHandler) GetDailyReport(c *fiber.Ctx) error {
+	report, err := h.cylinderService.GetDailyReport(c.Query("date"))
+	if err != nil {
+		if err == services.ErrInvalidBusinessDate {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Fecha inválida, use el formato YYYY-MM-DD",
+			})
+		}
+		log.Printf("Error al obtener movimiento de balones: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener el movimiento de balones",
+		})
+	}
+
+	return c.JSON(report)
+}
+
+// @Summary Mis balones
+// @Description Devuelve los balones de la empresa en poder del usuario autenticado
+// @Tags balones
+// @Produce json
+// @Success 200 {array} models.CylinderHolding
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /users/me/cylinders [get]
+// GetMyHoldings obtiene los balones del usuario autenticado
+func (h *CylinderHandler) GetMyHoldings(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	holdings, err := h.cylinderService.GetHoldings(claims.UserID.String())
+	if err != nil {
+		log.Printf("Error al obtener balones del cliente: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener los balones",
+		})
+	}
+
+	return c.JSON(holdings)
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *CylinderHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler) {
+	router.Get("/users/me/cylinders", authMiddleware, h.GetMyHoldings) // GET /users/me/cylinders
+
+	adminCylinders := router.Group("/admin/cylinders", authMiddleware, adminOnly)
+	adminCylinders.Get("/holdings", h.GetHoldings)       // GET /admin/cylinders/holdings
+	adminCylinders.Get("/collections", h.GetDailyReport) // GET /admin/cylinders/collections
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"log"
+
+	"backend/internal/auth"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// DeliveryRatingHandler maneja las peticiones HTTP relacionadas con la calificación de entregas
+type DeliveryRatingHandler struct {
+	deliveryRatingService *services.DeliveryRatingService
+}
+
+// NewDeliveryRatingHandler crea un nuevo handler de calificaciones de entrega
+func NewDeliveryRatingHandler(deliveryRatingService *services.DeliveryRatingService) *DeliveryRatingHandler {
+	return &DeliveryRatingHandler{
+		deliveryRatingService: deliveryRatingService,
+	}
+}
+
+// @Summary Calificar la entrega de un pedido
+// @Description Permite al cliente calificar una sola vez la entrega de un pedido DELIVERED (1-5 estrellas, etiquetas y comentario)
+// @Tags calificaciones de entrega
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del pedido"
+// @Param rating body models.CreateDeliveryRatingRequest true "Calificación de la entrega"
+// @Success 201 {object} models.DeliveryRating
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /orders/{id}/delivery-rating [post]
+// CreateDeliveryRating registra la calificación de la entrega
+func (h *DeliveryRatingHandler) CreateDeliveryRating(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	if claims.UserRole != models.UserRoleClient {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Solo los clientes pueden calificar entregas",
+		})
+	}
+
+	orderID := c.Params("id")
+	if _, err := uuid.Parse(orderID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de pedido inválido",
+		})
+	}
+
+	var req models.CreateDeliveryRatingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos de calificación inválidos",
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	rating, err := h.deliveryRatingService.Create(orderID, claims.UserID.String(), &req)
+	if err != nil {
+		switch err {
+		case services.ErrOrderNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Pedido no encontrado",
+			})
+		case services.ErrNotOrderOwner:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "No tienes permiso para calificar este pedido",
+			})
+		case services.ErrOrderNotDelivered:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Solo se pueden calificar pedidos entregados",
+			})
+		case services.ErrDeliveryRatingExists:
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "La entrega de este pedido ya fue calificada",
+			})
+		case services.ErrInvalidDeliveryRating:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Calificación de entrega inválida",
+			})
+		default:
+			log.Printf("Error al calificar entrega: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error al registrar la calificación",
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(rating)
+}
+
+// @Summary Obtener la calificación de entrega de un pedido
+// @Description Disponible para el cliente del pedido, el repartidor asignado y administradores
+// @Tags calificaciones de entrega
+// @Produce json
+// @Param id path string true "ID del pedido"
+// @Success 200 {object} models.DeliveryRating
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /orders/{id}/delivery-rating [get]
+// GetDeliveryRating obtiene la calificación de la entrega de un pedido
+func (h *DeliveryRatingHandler) GetDeliveryRating(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	rating, err := h.deliveryRatingService.GetByOrderID(c.Params("id"), claims.UserID.String(), claims.UserRole)
+	if err != nil {
+		switch err {
+		case services.ErrOrderNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Pedido no encontrado",
+			})
+		case services.ErrOrderAccessDenied:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "No tienes permiso para ver este pedido",
+			})
+		case services.ErrDeliveryRatingNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "La entrega de este pedido aún no fue calificada",
+			})
+		default:
+			log.Printf("Error al obtener calificación de entrega: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error al obtener la calificación",
+			})
+		}
+	}
+
+	return c.JSON(rating)
+}
+
+// @Summary Puntaje de entrega de los repartidores
+// @Description Devuelve el puntaje agregado de cada repartidor calificado, ordenado de mayor a menor
+// @Tags calificaciones de entrega
+// @Produce json
+// @Success 200 {array} models.RepartidorRatingSummary
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/repartidores/ratings [get]
+// GetRepartidorRatings obtiene el puntaje de todos los repartidores
+func (h *DeliveryRatingHandler) GetRepartidorRatings(c *fiber.Ctx) error {
+	summaries, err := h.deliveryRatingService.GetRepartidorRatings()
+	if err != nil {
+		log.Printf("Error al obtener puntajes de repartidores: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener los puntajes",
+		})
+	}
+
+	return c.JSON(summaries)
+}
+
+// @Summary Puntaje de entrega de un repartidor
+// @Description Devuelve el puntaje agregado de un repartidor con el conteo de etiquetas
+// @Tags calificaciones de entrega
+// @Produce json
+// @Param id path string true "ID del repartidor"
+// @Success 200 {object} models.RepartidorRatingSummary
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/repartidores/{id}/rating [get]
+// GetRepartidorRating obtiene el puntaje de un repartidor
+func (h *DeliveryRatingHandler) GetRepartidorRating(c *fiber.Ctx) error {
+	repartidorID := c.Params("id")
+	if _, err := uuid.Parse(repartidorID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de repartidor inválido",
+		})
+	}
+
+	summary, err := h.deliveryRatingService.GetRepartidorRating(repartidorID)
+	if err != nil {
+		if err == services.ErrUserNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Repartidor no encontrado",
+			})
+		}
+		log.Printf("Error al obtener puntaje del repartidor: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener el puntaje",
+		})
+	}
+
+	return c.JSON(summary)
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *DeliveryRatingHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler) {
+	// Calificación de la entrega de un pedido
+	router.Post("/orders/:id/delivery-rating", authMiddleware, h.CreateDeliveryRating) // POST /orders/:id/delivery-rating
+	router.Get("/orders/:id/delivery-rating", authMiddleware, h.GetDeliveryRating)     // GET /orders/:id/delivery-rating
+
+	// Puntajes agregados para administradores
+	adminRatings := router.Group("/admin/repartidores", authMiddleware, adminOnly)
+	adminRatings.Get("/ratings", h.GetRepartidorRatings)   // GET /admin/repartidores/ratings
+	adminRatings.Get("/:id/rating", h.GetRepartidorRating) // GET /admin/repartidores/:id/rating
+}
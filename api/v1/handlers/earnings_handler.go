@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"log"
+
+	"backend/internal/auth"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EarningsHandler maneja las peticiones HTTP relacionadas con propinas y ganancias de repartidores
+type EarningsHandler struct {
+	earningsService *services.EarningsService
+}
+
+// NewEarningsHandler crea un nuevo handler de ganancias
+func NewEarningsHandler(earningsService *services.EarningsService) *EarningsHandler {
+	return &EarningsHandler{
+		earningsService: earningsService,
+	}
+}
+
+// @Summary Obtener mis ganancias
+// @Description Devuelve las entregas y propinas del repartidor autenticado en un rango de jornadas
+// @Tags ganancias
+// @Produce json
+// @Param from query string false "Jornada inicial YYYY-MM-DD (por defecto hoy)"
+// @Param to query string false "Jornada final YYYY-MM-DD (por defecto igual a from)"
+// @Success 200 {object} models.RepartidorEarnings
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /repartidores/me/earnings [get]
+// GetMyEarnings obtiene las ganancias del repartidor autenticado
+func (h *EarningsHandler) GetMyEarnings(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	earnings, err := h.earningsService.GetRepartidorEarnings(claims.UserID.String(), c.Query("from"), c.Query("to"))
+	if err != nil {
+		return h.handleEarningsError(c, err)
+	}
+
+	return c.JSON(earnings)
+}
+
+// @Summary Reporte de propinas
+// @Description Devuelve las propinas por repartidor en un rango de jornadas
+// @Tags ganancias
+// @Produce json
+// @Param from query string false "Jornada inicial YYYY-MM-DD (por defecto hoy)"
+// @Param to query string false "Jornada final YYYY-MM-DD (por defecto igual a from)"
+// @Success 200 {object} models.TipsReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/reports/tips [get]
+// GetTipsReport obtiene el reporte de propinas de todos los repartidores
+func (h *EarningsHandler) GetTipsReport(c *fiber.Ctx) error {
+	report, err := h.earningsService.GetTipsReport(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return h.handleEarningsError(c, err)
+	}
+
+	return c.JSON(report)
+}
+
+// handleEarningsError traduce los errores del servicio de ganancias a respuestas HTTP
+func (h *EarningsHandler) handleEarningsError(c *fiber.Ctx, err error) error {
+	switch err {
+	case services.ErrInvalidBusinessDate:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Rango de fechas inválido, use el formato YYYY-MM-DD",
+		})
+	case services.ErrUserNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Repartidor no encontrado",
+		})
+	default:
+		log.Printf("Error al obtener ganancias: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener las ganancias",
+		})
+	}
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *EarningsHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler, repartidorOrAdmin fiber.Handler) {
+	// Ganancias propias del repartidor
+	repartidores := router.Group("/repartidores", authMiddleware, repartidorOrAdmin)
+	repartidores.Get("/me/earnings", h.GetMyEarnings) // GET /repartidores/me/earnings
+
+	// Reportes administrativos
+	reports := router.Group("/admin/reports", authMiddleware, adminOnly)
+	reports.Get("/tips", h.GetTipsReport) // GET /admin/reports/tips
+}
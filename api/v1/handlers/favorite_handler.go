@@ -199,6 +199,7 @@ func (h *FavoriteHandler) GetFavoriteStatus(c *fiber.Ctx) error {
 // @Produce json
 // @Param page query int false "Número de página (default: 1)"
 // @Param limit query int false "Límite por página (default: 20, max: 100)"
+// @Param cursor query string false "Cursor opaco (vacío para la primera página); activa la paginación por cursor"
 // @Success 200 {object} models.FavoritesListResponse "Favoritos obtenidos exitosamente"
 // @Failure 400 {object} map[string]string "Petición inválida"
 // @Failure 401 {object} map[string]string "No autorizado"
@@ -210,6 +211,20 @@ func (h *FavoriteHandler) GetUserFavorites(c *fiber.Ctx) error {
 	claims := c.Locals("user").(*auth.Claims)
 	userID := claims.UserID
 
+	// Paginación por cursor: devuelve models.CursorPage
+	if cursor, limit, ok := cursorQuery(c); ok {
+		response, err := h.favoriteService.GetUserFavoritesWithCursor(userID, cursor, limit)
+		if err != nil {
+			if err == services.ErrInvalidCursor {
+				return invalidCursorResponse(c)
+			}
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(http.StatusOK).JSON(response)
+	}
+
 	// Obtener parámetros de paginación
 	page := c.QueryInt("page", 1)
 	limit := c.QueryInt("limit", 20)
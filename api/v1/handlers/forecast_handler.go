@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"log"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ForecastHandler maneja los pronósticos de demanda y sugerencias de stock
+type ForecastHandler struct {
+	forecastService *services.ForecastService
+}
+
+// NewForecastHandler crea un nuevo handler de pronósticos
+func NewForecastHandler(forecastService *services.ForecastService) *ForecastHandler {
+	return &ForecastHandler{
+		forecastService: forecastService,
+	}
+}
+
+// @Summary Pronóstico de demanda
+// @Description Demanda estimada por producto y jornada a partir de las últimas 8 semanas (día de la semana, inicio de mes y hora del día). Los totales incluyen el detalle por hora para planificar repartidores
+// @Tags pronósticos
+// @Produce json
+// @Param product_id query string false "ID del producto (por defecto, todos los activos)"
+// @Param days query int false "Jornadas a pronosticar desde hoy (por defecto: 7, máximo: 30)"
+// @Param hourly query boolean false "Incluir el detalle por hora de cada producto"
+// @Success 200 {object} models.DemandForecastReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/forecast/demand [get]
+// GetDemandForecast obtiene el pronóstico de demanda
+func (h *ForecastHandler) GetDemandForecast(c *fiber.Ctx) error {
+	report, err := h.forecastService.GetDemandForecast(
+		c.Query("product_id"),
+		c.QueryInt("days", models.ForecastDefaultHorizonDays),
+		c.QueryBool("hourly", false),
+	)
+	if err != nil {
+		return h.handleForecastError(c, err)
+	}
+
+	return c.JSON(report)
+}
+
+// @Summary Stock sugerido
+// @Description Stock necesario por producto para cubrir la demanda pronosticada más un stock de seguridad (≈ 95%), y cuánto reponer
+// @Tags pronósticos
+// @Produce json
+// @Param days query int false "Jornadas a cubrir desde hoy (por defecto: 7, máximo: 30)"
+// @Success 200 {object} models.StockSuggestionReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/forecast/stock [get]
+// GetStockSuggestions obtiene el stock sugerido por producto
+func (h *ForecastHandler) GetStockSuggestions(c *fiber.Ctx) error {
+	report, err := h.forecastService.GetStockSuggestions(c.QueryInt("days", models.ForecastDefaultHorizonDays))
+	if err != nil {
+		return h.handleForecastError(c, err)
+	}
+
+	return c.JSON(report)
+}
+
+// handleForecastError traduce los errores del servicio de pronósticos a respuestas HTTP
+func (h *ForecastHandler) handleForecastError(c *fiber.Ctx, err error) error {
+	switch err {
+	case services.ErrInvalidForecastHorizon:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "El parámetro days debe estar entre 1 y 30",
+		})
+	case services.ErrProductNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Producto no encontrado o inactivo",
+		})
+	default:
+		log.Printf("Error al generar pronóstico: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al generar el pronóstico",
+		})
+	}
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *ForecastHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler) {
+	forecasts := router.Group("/admin/forecast", authMiddleware, adminOnly)
+	forecasts.Get("/demand", h.GetDemandForecast)  // GET /admin/forecast/demand
+	forecasts.Get("/stock", h.GetStockSuggestions) // GET /admin/forecast/stock
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"backend/internal/auth"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// InventoryHandler maneja el libro de movimientos de stock y los reportes de inventario
+type InventoryHandler struct {
+	inventoryService *services.InventoryService
+}
+
+// NewInventoryHandler crea un nuevo handler de inventario
+func NewInventoryHandler(inventoryService *services.InventoryService) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+	}
+}
+
+// @Summary Listar movimientos de stock de un producto
+// @Description Libro de movimientos del producto (compras, ventas, cancelaciones, ajustes y bajas), del más reciente al más antiguo
+// @Tags inventario
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param cursor query string false "Cursor devuelto en next_cursor (vacío = primera página)"
+// @Param limit query int false "Movimientos por página (máximo 100)"
+// @Success 200 {object} models.CursorPage[models.StockMovement]
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/{id}/stock-movements [get]
+// ListStockMovements lista el libro de movimientos de un producto
+func (h *InventoryHandler) ListStockMovements(c *fiber.Ctx) error {
+	page, err := h.inventoryService.ListMovements(c.Params("id"), c.Query("cursor"), c.QueryInt("limit", models.DefaultCursorLimit))
+	if err != nil {
+		return h.handleInventoryError(c, err)
+	}
+
+	return c.JSON(page)
+}
+
+// @Summary Registrar movimiento de stock
+// @Description Registra un ingreso por compra (PURCHASE_RECEIPT), un ajuste con motivo (ADJUSTMENT, cantidad con signo) o una baja por daño (DAMAGE) y actualiza el stock. Con sucursales, branch_id es obligatorio. Las ventas y cancelaciones se registran solas con el pedido
+// @Tags inventario
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param movement body models.CreateStockMovementRequest true "Movimiento"
+// @Success 201 {object} models.StockMovement
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/{id}/stock-movements [post]
+// CreateStockMovement registra un movimiento manual de stock
+func (h *InventoryHandler) CreateStockMovement(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	var req models.CreateStockMovementRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Formato de solicitud inválido",
+		})
+	}
+
+	movement, err := h.inventoryService.RecordMovement(c.Params("id"), claims.UserID.String(), &req)
+	if err != nil {
+		return h.handleInventoryError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(movement)
+}
+
+// @Summary Conciliar stock con el libro de movimientos
+// @Description Si el stock del producto no coincide con la suma de su libro (cambios hechos fuera de la aplicación), registra un ajuste por la diferencia sin cambiar el stock
+// @Tags inventario
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/{id}/stock-movements/reconcile [post]
+// ReconcileStock concilia el stock de un producto con su libro de movimientos
+func (h *InventoryHandler) ReconcileStock(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	movement, err := h.inventoryService.Reconcile(c.Params("id"), claims.UserID.String())
+	if err != nil {
+		return h.handleInventoryError(c, err)
+	}
+	if movement == nil {
+		return c.JSON(fiber.Map{
+			"message": "El stock ya coincide con el libro de movimientos",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Stock conciliado",
+		"movement": movement,
+	})
+}
+
+// @Summary Productos con stock bajo
+// @Description Productos activos con stock por debajo de su umbral de reposición, los más críticos primero
+// @Tags inventario
+// @Produce json
+// @Success 200 {array} models.LowStockProduct
+// @Security BearerAuth
+// @Router /admin/inventory/low-stock [get]
+// GetLowStock lista los productos con stock bajo
+func (h *InventoryHandler) GetLowStock(c *fiber.Ctx) error {
+	products, err := h.inventoryService.GetLowStock()
+	if err != nil {
+		return h.handleInventoryError(c, err)
+	}
+
+	return c.JSON(products)
+}
+
+// @Summary Diferencias entre stock y libro de movimientos
+// @Description Productos cuyo stock no coincide con la suma de su libro de movimientos
+// @Tags inventario
+// @Produce json
+// @Success 200 {array} models.StockReconciliation
+// @Security BearerAuth
+// @Router /admin/inventory/reconciliation [get]
+// GetReconciliation lista los productos con stock sin conciliar
+func (h *InventoryHandler) GetReconciliation(c *fiber.Ctx) error {
+	rows, err := h.inventoryService.GetReconciliation()
+	if err != nil {
+		return h.handleInventoryError(c, err)
+	}
+
+	return c.JSON(rows)
+}
+
+// handleInventoryError traduce los errores del servicio de inventario a respuestas HTTP
+func (h *InventoryHandler) handleInventoryError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidCursor):
+		return invalidCursorResponse(c)
+	case errors.Is(err, services.ErrInvalidStockMovement):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrProductNotFoundService):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Producto no encontrado",
+		})
+	case errors.Is(err, services.ErrVariantNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Variante no encontrada",
+		})
+	case errors.Is(err, services.ErrBranchNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Sucursal no encontrada",
+		})
+	case errors.Is(err, services.ErrStockBelowZero):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrBranchStockRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Indique la sucursal (branch_id) del movimiento",
+		})
+	default:
+		log.Printf("Error de inventario: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al procesar el inventario",
+		})
+	}
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *InventoryHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler) {
+	admin := router.Group("/admin", authMiddleware, adminOnly)
+	admin.Get("/products/:id/stock-movements", h.ListStockMovements)        // GET /admin/products/:id/stock-movements
+	admin.Post("/products/:id/stock-movements", h.CreateStockMovement)      // POST /admin/products/:id/stock-movements
+	admin.Post("/products/:id/stock-movements/reconcile", h.ReconcileStock) // POST /admin/products/:id/stock-movements/reconcile
+	admin.Get("/inventory/low-stock", h.GetLowStock)                        // GET /admin/inventory/low-stock
+	admin.Get("/inventory/reconciliation", h.GetReconciliation)             // GET /admin/inventory/reconciliation
+}
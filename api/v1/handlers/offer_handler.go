@@ -27,6 +27,7 @@ func NewOfferHandler(offerService services.OfferService) *OfferHandler {
 // CreateOfferRequest estructura para crear ofertas
 type CreateOfferRequest struct {
 	ProductID     string                   `json:"product_id" validate:"required,uuid"`
+	VariantID     string                   `json:"variant_id,omitempty" validate:"omitempty,uuid"` // Vacío = todas las variantes
 	DiscountType  models.OfferDiscountType `json:"discount_type" validate:"required,oneof=percentage fixed_amount fixed_price"`
 	DiscountValue float64                  `json:"discount_value" validate:"required,gt=0"`
 	StartDate     string                   `json:"start_date" validate:"required"`
@@ -88,6 +89,17 @@ func (h *OfferHandler) CreateOffer(c *fiber.Ctx) error {
 		EndDate:       endDate,
 	}
 
+	// Oferta para una sola variante del producto
+	if req.VariantID != "" {
+		variantUUID, err := uuid.Parse(req.VariantID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "variant_id inválido",
+			})
+		}
+		offer.VariantID = &variantUUID
+	}
+
 	if err := h.offerService.CreateOffer(userID, offer); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"bufio"
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"backend/internal/auth"
+	"backend/internal/export"
 	"backend/internal/models"
 	"backend/internal/services"
 
@@ -16,32 +19,45 @@ import (
 
 // OrderHandler maneja las peticiones HTTP relacionadas con pedidos
 type OrderHandler struct {
-	orderService *services.OrderService
-	authService  auth.Service
+	orderService   *services.OrderService
+	authService    auth.Service
+	addressService *services.AddressService
 }
 
 // NewOrderHandler crea un nuevo handler de pedidos
-func NewOrderHandler(orderService *services.OrderService, authService auth.Service) *OrderHandler {
+func NewOrderHandler(orderService *services.OrderService, authService auth.Service, addressService *services.AddressService) *OrderHandler {
 	return &OrderHandler{
-		orderService: orderService,
-		authService:  authService,
+		orderService:   orderService,
+		authService:    authService,
+		addressService: addressService,
 	}
 }
 
 // CreateOrderRequest estructura para la creación de un pedido
 type CreateOrderRequest struct {
 	Items               []OrderItemRequest `json:"items" validate:"required,dive"`
-	Latitude            float64            `json:"latitude" validate:"required"`
-	Longitude           float64            `json:"longitude" validate:"required"`
-	DeliveryAddressText string             `json:"delivery_address_text" validate:"required"`
+	AddressID           string             `json:"address_id" validate:"omitempty,uuid"` // Dirección guardada; reemplaza latitud, longitud y texto
+	Latitude            float64            `json:"latitude" validate:"required_without=AddressID"`
+	Longitude           float64            `json:"longitude" validate:"required_without=AddressID"`
+	DeliveryAddressText string             `json:"delivery_address_text" validate:"required_without=AddressID"`
+	DeliveryReference   string             `json:"delivery_reference"`
 	PaymentNote         string             `json:"payment_note"`
+	PaymentMethod       string             `json:"payment_method"` // CASH (por defecto), YAPE, PLIN o CARD
+	TipAmount           float64            `json:"tip_amount"`     // Propina opcional para el repartidor
 }
 
 // OrderItemRequest estructura para los ítems de un pedido
 type OrderItemRequest struct {
-	ProductID string  `json:"product_id" validate:"required,uuid"`
-	Quantity  int     `json:"quantity" validate:"required,min=1"`
-	UnitPrice float64 `json:"unit_price" validate:"required,min=0"`
+	ProductID         string  `json:"product_id" validate:"required,uuid"`
+	VariantID         string  `json:"variant_id,omitempty" validate:"omitempty,uuid"` // Vacío = variante predeterminada
+	Quantity          int     `json:"quantity" validate:"required,min=1"`
+	UnitPrice         float64 `json:"unit_price" validate:"required,min=0"`
+	CylindersReturned *int    `json:"cylinders_returned,omitempty" validate:"omitempty,min=0"` // Vacíos que entrega el cliente (por defecto, uno por balón)
+}
+
+// ConfirmCylindersRequest estructura para confirmar los balones vacíos recibidos en la entrega
+type ConfirmCylindersRequest struct {
+	Items []models.CylinderReturnRequest `json:"items" validate:"required,dive"`
 }
 
 // UpdateOrderStatusRequest estructura para actualizar el estado de un pedido
@@ -54,13 +70,18 @@ type AssignRepartidorRequest struct {
 	RepartidorID string `json:"repartidor_id" validate:"omitempty,uuid"`
 }
 
+// SetTipRequest estructura para dejar o cambiar la propina de un pedido
+type SetTipRequest struct {
+	TipAmount float64 `json:"tip_amount" validate:"min=0"`
+}
+
 // SetETARequest estructura para establecer el tiempo estimado de llegada
 type SetETARequest struct {
 	EstimatedArrivalTime string `json:"estimated_arrival_time" validate:"required"`
 }
 
 // @Summary Crear un nuevo pedido
-// @Description Crea un nuevo pedido para un cliente
+// @Description Crea un nuevo pedido para un cliente. Si hay sucursales, se asigna a la más cercana que cubra la dirección y tenga stock
 // @Tags pedidos
 // @Accept json
 // @Produce json
@@ -69,6 +90,7 @@ type SetETARequest struct {
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Security BearerAuth
 // @Router /orders [post]
@@ -99,6 +121,17 @@ func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 		})
 	}
 
+	// Método de pago: efectivo por defecto
+	paymentMethod := models.PaymentMethodCash
+	if req.PaymentMethod != "" {
+		paymentMethod = models.PaymentMethod(req.PaymentMethod)
+		if !models.IsValidPaymentMethod(paymentMethod) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Método de pago inválido",
+			})
+		}
+	}
+
 	// Crear el pedido en el modelo
 	clientID, err := uuid.Parse(claims.UserID.String())
 	if err != nil {
@@ -112,10 +145,45 @@ func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 		Latitude:            req.Latitude,
 		Longitude:           req.Longitude,
 		DeliveryAddressText: req.DeliveryAddressText,
+		DeliveryReference:   req.DeliveryReference,
 		PaymentNote:         req.PaymentNote,
+		PaymentMethod:       paymentMethod,
+		TipAmount:           req.TipAmount,
 		OrderTime:           time.Now(),
 	}
 
+	// Sin dirección guardada, la ubicación y el texto de la dirección son obligatorios
+	if req.AddressID == "" && (req.Latitude == 0 || req.Longitude == 0 || strings.TrimSpace(req.DeliveryAddressText) == "") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Indique una dirección guardada o la ubicación y la dirección de entrega",
+		})
+	}
+
+	// Usar una dirección guardada: se copia al pedido
+	if req.AddressID != "" {
+		if h.addressService == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Las direcciones guardadas no están disponibles",
+			})
+		}
+		if _, err := uuid.Parse(req.AddressID); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "ID de dirección inválido",
+			})
+		}
+		if err := h.addressService.ApplyToOrder(req.AddressID, claims.UserID.String(), order); err != nil {
+			if err == services.ErrAddressNotFound {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "La dirección seleccionada no existe",
+				})
+			}
+			log.Printf("Error al usar dirección guardada: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error al crear el pedido",
+			})
+		}
+	}
+
 	// Convertir los items de la petición al modelo
 	var orderItems []models.OrderItem
 	for _, item := range req.Items {
@@ -125,10 +193,27 @@ func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 				"error": fmt.Sprintf("El ID de producto '%s' no es un UUID válido", item.ProductID),
 			})
 		}
+		var variantID *uuid.UUID
+		if item.VariantID != "" {
+			parsed, err := uuid.Parse(item.VariantID)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": fmt.Sprintf("El ID de variante '%s' no es un UUID válido", item.VariantID),
+				})
+			}
+			variantID = &parsed
+		}
+		// Por defecto el cliente entrega un vacío por cada balón
+		cylindersReturned := item.Quantity
+		if item.CylindersReturned != nil {
+			cylindersReturned = *item.CylindersReturned
+		}
 		orderItems = append(orderItems, models.OrderItem{
-			ProductID: productID,
-			Quantity:  item.Quantity,
-			UnitPrice: item.UnitPrice,
+			ProductID:         productID,
+			VariantID:         variantID,
+			Quantity:          item.Quantity,
+			UnitPrice:         item.UnitPrice,
+			CylindersReturned: cylindersReturned,
 		})
 	}
 
@@ -151,10 +236,34 @@ func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "Uno o más productos no están disponibles",
 			})
+		case services.ErrVariantNotFound:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Una o más variantes no existen o no están disponibles",
+			})
+		case services.ErrInsufficientStock:
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "No hay stock suficiente de una o más variantes",
+			})
 		case services.ErrInvalidUnitPrice:
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "Precio unitario inválido",
 			})
+		case services.ErrInvalidTipAmount:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("La propina debe estar entre S/ 0.00 y S/ %.2f", models.MaxTipAmount),
+			})
+		case services.ErrInvalidCylindersReturned:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "La cantidad de balones vacíos no puede ser negativa ni mayor a la cantidad pedida",
+			})
+		case services.ErrOutsideServiceZone:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "La dirección de entrega está fuera de la zona de reparto de nuestras sucursales",
+			})
+		case services.ErrNoBranchStock:
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Ninguna sucursal cercana tiene stock suficiente para este pedido",
+			})
 		default:
 			// Loggear el error para debugging
 			log.Printf("Error al crear pedido: %v", err)
@@ -214,7 +323,7 @@ func (h *OrderHandler) GetOrders(c *fiber.Ctx) error {
 				})
 			}
 
-			pendingOrders, err := h.orderService.GetPendingOrders()
+			pendingOrders, err := h.orderService.GetPendingOrdersForRepartidor(claims.UserID.String())
 			if err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 					"error": "Error al obtener los pedidos",
@@ -265,6 +374,21 @@ func (h *OrderHandler) GetOrdersPaginated(c *fiber.Ctx) error {
 	// Obtener filtro de búsqueda opcional
 	searchQuery := c.Query("search")
 
+	// Paginación por cursor: estable aunque lleguen pedidos nuevos durante el scroll
+	if cursor, limit, ok := cursorQuery(c); ok {
+		result, err := h.orderService.GetOrdersWithCursor(cursor, limit, status, searchQuery, claims.UserRole, claims.UserID.String())
+		if err != nil {
+			if err == services.ErrInvalidCursor {
+				return invalidCursorResponse(c)
+			}
+			log.Printf("Error al obtener órdenes por cursor: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error al obtener los pedidos",
+			})
+		}
+		return c.JSON(result)
+	}
+
 	// Obtener órdenes paginadas
 	result, err := h.orderService.GetOrdersWithPagination(page, pageSize, status, searchQuery, claims.UserRole, claims.UserID.String())
 	if err != nil {
@@ -277,6 +401,66 @@ func (h *OrderHandler) GetOrdersPaginated(c *fiber.Ctx) error {
 	return c.JSON(result)
 }
 
+// @Summary Exportar pedidos
+// @Description Descarga los pedidos con sus ítems (una fila por ítem) en CSV o XLSX. Acepta los mismos filtros que /orders/paginated más un rango de jornadas
+// @Tags pedidos
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (por defecto) o xlsx"
+// @Param status query string false "Estado del pedido"
+// @Param search query string false "Búsqueda por ID, dirección o nota de pago"
+// @Param from query string false "Jornada inicial YYYY-MM-DD (por defecto hoy)"
+// @Param to query string false "Jornada final YYYY-MM-DD (por defecto igual a from)"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/orders/export [get]
+// ExportOrders transmite la exportación de pedidos sin cargarla completa en memoria
+func (h *OrderHandler) ExportOrders(c *fiber.Ctx) error {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Formato inválido, use csv o xlsx",
+		})
+	}
+
+	var status *models.OrderStatus
+	if statusQuery := c.Query("status"); statusQuery != "" {
+		orderStatus := models.OrderStatus(statusQuery)
+		status = &orderStatus
+	}
+
+	// Un admin de sucursal solo exporta los pedidos de su sucursal
+	claims := c.Locals("user").(*auth.Claims)
+	filter, err := h.orderService.NewOrderExportFilter(status, c.Query("search"), c.Query("from"), c.Query("to"), claims.UserID.String())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Rango de fechas inválido, use el formato YYYY-MM-DD",
+		})
+	}
+
+	filename := fmt.Sprintf("pedidos_%s_%s%s",
+		filter.From.Format(models.BusinessDateLayout),
+		filter.To.AddDate(0, 0, -1).Format(models.BusinessDateLayout),
+		format.Extension(),
+	)
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// Las filas se escriben a medida que se leen; un error a mitad de la descarga
+	// ya no puede cambiar el código de estado, solo se registra
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.orderService.ExportOrders(w, format, filter); err != nil {
+			log.Printf("Error al exportar pedidos: %v", err)
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("Error al enviar la exportación de pedidos: %v", err)
+		}
+	})
+
+	return nil
+}
+
 // @Summary Obtener un pedido por su ID
 // @Description Obtiene los detalles de un pedido específico por su ID
 // @Tags pedidos
@@ -305,7 +489,7 @@ func (h *OrderHandler) GetOrderByID(c *fiber.Ctx) error {
 	}
 
 	// Obtener el pedido
-	order, err := h.orderService.GetOrderByID(orderID)
+	order, err := h.orderService.GetOrderForUser(orderID, claims.UserID.String(), claims.UserRole)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Pedido no encontrado",
@@ -332,7 +516,7 @@ func (h *OrderHandler) GetOrderByID(c *fiber.Ctx) error {
 			})
 		}
 	case models.UserRoleAdmin:
-		// Los administradores pueden ver cualquier pedido
+		// Los administradores ven cualquier pedido de su sucursal (validado en el servicio)
 	}
 
 	return c.JSON(order)
@@ -366,7 +550,7 @@ func (h *OrderHandler) GetOrderRepartidor(c *fiber.Ctx) error {
 	}
 
 	// Obtener el pedido
-	order, err := h.orderService.GetOrderByID(orderID)
+	order, err := h.orderService.GetOrderForUser(orderID, claims.UserID.String(), claims.UserRole)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Pedido no encontrado",
@@ -418,7 +602,7 @@ func (h *OrderHandler) GetOrderRepartidor(c *fiber.Ctx) error {
 			})
 		}
 	case models.UserRoleAdmin:
-		// Los administradores pueden ver cualquier información
+		// Los administradores ven la información de los pedidos de su sucursal (validado en el servicio)
 	}
 
 	// Retornar solo la información del repartidor (sin datos sensibles como password)
@@ -447,6 +631,7 @@ func (h *OrderHandler) GetOrderRepartidor(c *fiber.Ctx) error {
 // @Failure 401 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Security BearerAuth
 // @Router /orders/{id}/status [put]
@@ -489,6 +674,14 @@ func (h *OrderHandler) UpdateOrderStatus(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "Transición de estado inválida",
 			})
+		case services.ErrStockBelowZero:
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Stock insuficiente en la sucursal para confirmar el pedido",
+			})
+		case services.ErrBranchAccessDenied:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "El pedido pertenece a otra sucursal",
+			})
 		default:
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Error al actualizar el estado del pedido",
@@ -500,7 +693,7 @@ func (h *OrderHandler) UpdateOrderStatus(c *fiber.Ctx) error {
 }
 
 // @Summary Asignar un repartidor a un pedido
-// @Description Asigna un repartidor a un pedido según el rol del usuario
+// @Description Asigna un repartidor a un pedido según el rol del usuario. Si un administrador no indica el repartidor, se elige el de menos pedidos en curso y mejor puntaje de entrega
 // @Tags pedidos
 // @Accept json
 // @Produce json
@@ -511,6 +704,7 @@ func (h *OrderHandler) UpdateOrderStatus(c *fiber.Ctx) error {
 // @Failure 401 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Security BearerAuth
 // @Router /orders/{id}/assign [post]
@@ -556,7 +750,7 @@ func (h *OrderHandler) AssignRepartidor(c *fiber.Ctx) error {
 	}
 
 	// Asignar el repartidor
-	updatedOrder, err := h.orderService.AssignRepartidor(orderID, repartidorID)
+	updatedOrder, err := h.orderService.AssignRepartidor(orderID, repartidorID, claims.UserID.String(), claims.UserRole)
 	if err != nil {
 		switch err {
 		case services.ErrOrderNotFound:
@@ -579,6 +773,22 @@ func (h *OrderHandler) AssignRepartidor(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "El usuario asignado debe ser un repartidor o administrador",
 			})
+		case services.ErrBranchMismatch:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "El pedido pertenece a otra sucursal",
+			})
+		case services.ErrBranchAccessDenied:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "El pedido pertenece a otra sucursal",
+			})
+		case services.ErrNoRepartidorAvailable:
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "No hay repartidores disponibles para el pedido",
+			})
+		case services.ErrStockBelowZero:
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Stock insuficiente en la sucursal para confirmar el pedido",
+			})
 		default:
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Error al asignar el repartidor",
@@ -648,7 +858,7 @@ func (h *OrderHandler) SetEstimatedArrivalTime(c *fiber.Ctx) error {
 	}
 
 	// Establecer el tiempo estimado de llegada
-	updatedOrder, err := h.orderService.SetEstimatedArrivalTime(orderID, eta)
+	updatedOrder, err := h.orderService.SetEstimatedArrivalTime(orderID, eta, claims.UserID.String(), claims.UserRole)
 	if err != nil {
 		switch err {
 		case services.ErrOrderNotFound:
@@ -659,6 +869,10 @@ func (h *OrderHandler) SetEstimatedArrivalTime(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"error": "El pedido no está en un estado que permita establecer tiempo estimado de llegada",
 			})
+		case services.ErrBranchAccessDenied:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "El pedido pertenece a otra sucursal",
+			})
 		default:
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Error al establecer el tiempo estimado de llegada",
@@ -734,6 +948,174 @@ func (h *OrderHandler) FindNearbyOrders(c *fiber.Ctx) error {
 	return c.JSON(orders)
 }
 
+// @Summary Dejar propina en un pedido
+// @Description Permite al cliente dejar o cambiar la propina para el repartidor antes de la entrega o dentro de las 24h posteriores
+// @Tags pedidos
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del pedido"
+// @Param tip body SetTipRequest true "Monto de la propina"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /orders/{id}/tip [put]
+// SetTip registra la propina de un pedido
+func (h *OrderHandler) SetTip(c *fiber.Ctx) error {
+	// Obtener el usuario autenticado del contexto
+	claims := c.Locals("user").(*auth.Claims)
+
+	if claims.UserRole != models.UserRoleClient {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Solo los clientes pueden dejar propina",
+		})
+	}
+
+	orderID := c.Params("id")
+	if orderID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de pedido requerido",
+		})
+	}
+
+	var req SetTipRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos inválidos",
+		})
+	}
+
+	updatedOrder, err := h.orderService.SetTip(orderID, claims.UserID.String(), req.TipAmount)
+	if err != nil {
+		switch err {
+		case services.ErrOrderNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Pedido no encontrado",
+			})
+		case services.ErrNotOrderOwner:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "No tienes permiso para modificar este pedido",
+			})
+		case services.ErrInvalidTipAmount:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("La propina debe estar entre S/ 0.00 y S/ %.2f", models.MaxTipAmount),
+			})
+		case services.ErrTipNotAllowed:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Solo se puede dejar propina antes de la entrega o dentro de las 24 horas posteriores",
+			})
+		default:
+			log.Printf("Error al registrar propina: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error al registrar la propina",
+			})
+		}
+	}
+
+	return c.JSON(updatedOrder)
+}
+
+// @Summary Confirmar balones vacíos recibidos
+// @Description El repartidor asignado (o un admin) confirma cuántos vacíos recibió por ítem; se recalculan garantías y total
+// @Tags pedidos
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del pedido"
+// @Param cylinders body ConfirmCylindersRequest true "Vacíos recibidos por ítem"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /orders/{id}/cylinders [put]
+// ConfirmCylinders registra los balones vacíos recibidos en la entrega
+func (h *OrderHandler) ConfirmCylinders(c *fiber.Ctx) error {
+	// Obtener el usuario autenticado del contexto
+	claims := c.Locals("user").(*auth.Claims)
+
+	orderID := c.Params("id")
+	if orderID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de pedido requerido",
+		})
+	}
+
+	var req ConfirmCylindersRequest
+	if err := c.BodyParser(&req); err != nil || len(req.Items) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Debe indicar los vacíos recibidos por ítem",
+		})
+	}
+
+	updatedOrder, err := h.orderService.ConfirmCylinderReturns(orderID, claims.UserID.String(), claims.UserRole, req.Items)
+	if err != nil {
+		switch err {
+		case services.ErrOrderNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Pedido no encontrado",
+			})
+		case services.ErrOrderAccessDenied:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Solo el repartidor asignado puede confirmar los balones",
+			})
+		case services.ErrInvalidOrderStatus:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Los balones se confirman mientras el pedido está asignado o en camino",
+			})
+		case services.ErrInvalidCylindersReturned:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Cantidad de balones vacíos inválida para uno o más ítems",
+			})
+		default:
+			log.Printf("Error al confirmar balones: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error al confirmar los balones",
+			})
+		}
+	}
+
+	return c.JSON(updatedOrder)
+}
+
+// @Summary Repartidores sugeridos para un pedido
+// @Description Ordena los repartidores activos que pueden recibir el pedido: menos pedidos en curso primero y, a igual carga, mejor puntaje de entrega
+// @Tags pedidos
+// @Produce json
+// @Param id path string true "ID del pedido"
+// @Success 200 {array} models.DispatchCandidate
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/orders/{id}/repartidor-suggestions [get]
+// SuggestRepartidores obtiene los repartidores sugeridos para despachar un pedido
+func (h *OrderHandler) SuggestRepartidores(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	if _, err := uuid.Parse(orderID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de pedido inválido",
+		})
+	}
+
+	candidates, err := h.orderService.SuggestRepartidores(orderID)
+	if err != nil {
+		if err == services.ErrOrderNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Pedido no encontrado",
+			})
+		}
+		log.Printf("Error al sugerir repartidores: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al sugerir repartidores",
+		})
+	}
+
+	return c.JSON(candidates)
+}
+
 // RegisterRoutes registra las rutas del handler en el router
 func (h *OrderHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler, repartidorOrAdmin fiber.Handler) {
 	orders := router.Group("/orders", authMiddleware)
@@ -744,10 +1126,18 @@ func (h *OrderHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.
 	orders.Get("/paginated", h.GetOrdersPaginated) // Obtener pedidos con paginación (lazy loading)
 	orders.Get("/:id", h.GetOrderByID)             // Obtener un pedido específico (según permisos)
 	orders.Put("/:id/status", h.UpdateOrderStatus) // Actualizar estado (según permisos)
+	orders.Put("/:id/tip", h.SetTip)               // Dejar propina (solo el cliente del pedido)
 
 	// Rutas para repartidores y administradores
 	orders.Post("/:id/assign", repartidorOrAdmin, h.AssignRepartidor)    // Asignar repartidor
 	orders.Put("/:id/eta", repartidorOrAdmin, h.SetEstimatedArrivalTime) // Establecer ETA
+	orders.Put("/:id/cylinders", repartidorOrAdmin, h.ConfirmCylinders)  // Confirmar balones vacíos recibidos
 	orders.Get("/nearby", repartidorOrAdmin, h.FindNearbyOrders)         // Buscar pedidos cercanos
 	orders.Get("/:id/repartidor", h.GetOrderRepartidor)                  // Obtener info del repartidor del pedido
+
+	// Exportación para administradores
+	router.Get("/admin/orders/export", authMiddleware, adminOnly, h.ExportOrders) // Descargar pedidos en CSV o XLSX
+
+	// Despacho: repartidores sugeridos según carga y puntaje
+	router.Get("/admin/orders/:id/repartidor-suggestions", authMiddleware, adminOnly, h.SuggestRepartidores) // GET /admin/orders/:id/repartidor-suggestions
 }
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// cursorQuery lee los parámetros de paginación por cursor (?cursor=&limit=). La presencia del
+// parámetro cursor, aunque esté vacío, activa este modo; sin él se mantiene la paginación por página.
+func cursorQuery(c *fiber.Ctx) (cursor string, limit int, ok bool) {
+	if !c.Context().QueryArgs().Has("cursor") {
+		return "", 0, false
+	}
+	return c.Query("cursor"), c.QueryInt("limit", models.DefaultCursorLimit), true
+}
+
+// invalidCursorResponse responde 400 cuando el cursor no se puede interpretar
+func invalidCursorResponse(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "Cursor de paginación inválido",
+	})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"log"
+
+	"backend/internal/auth"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PerformanceHandler maneja las peticiones HTTP de desempeño de repartidores
+type PerformanceHandler struct {
+	performanceService *services.PerformanceService
+}
+
+// NewPerformanceHandler crea un nuevo handler de desempeño
+func NewPerformanceHandler(performanceService *services.PerformanceService) *PerformanceHandler {
+	return &PerformanceHandler{
+		performanceService: performanceService,
+	}
+}
+
+// @Summary Ranking de desempeño de repartidores
+// @Description Entregas por día, tiempos entre estados, puntualidad frente a la hora estimada y cancelaciones de los pedidos asignados en el período
+// @Tags repartidores
+// @Produce json
+// @Param from query string false "Jornada inicial YYYY-MM-DD (por defecto, últimos 30 días)"
+// @Param to query string false "Jornada final YYYY-MM-DD"
+// @Success 200 {object} models.PerformanceReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/repartidores/performance [get]
+// GetLeaderboard obtiene el ranking de desempeño de todos los repartidores
+func (h *PerformanceHandler) GetLeaderboard(c *fiber.Ctx) error {
+	report, err := h.performanceService.GetLeaderboard(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return h.handlePerformanceError(c, err)
+	}
+
+	return c.JSON(report)
+}
+
+// @Summary Desempeño de un repartidor
+// @Description Indicadores de desempeño de un repartidor en el período
+// @Tags repartidores
+// @Produce json
+// @Param id path string true "ID del repartidor"
+// @Param from query string false "Jornada inicial YYYY-MM-DD (por defecto, últimos 30 días)"
+// @Param to query string false "Jornada final YYYY-MM-DD"
+// @Success 200 {object} models.PerformanceReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/repartidores/{id}/performance [get]
+// GetRepartidorPerformance obtiene el desempeño de un repartidor
+func (h *PerformanceHandler) GetRepartidorPerformance(c *fiber.Ctx) error {
+	report, err := h.performanceService.GetRepartidorPerformance(c.Params("id"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		return h.handlePerformanceError(c, err)
+	}
+
+	return c.JSON(report)
+}
+
+// @Summary Mi desempeño
+// @Description Indicadores de desempeño del repartidor autenticado en el período
+// @Tags repartidores
+// @Produce json
+// @Param from query string false "Jornada inicial YYYY-MM-DD (por defecto, últimos 30 días)"
+// @Param to query string false "Jornada final YYYY-MM-DD"
+// @Success 200 {object} models.PerformanceReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /repartidores/me/performance [get]
+// GetMyPerformance obtiene el desempeño del repartidor autenticado
+func (h *PerformanceHandler) GetMyPerformance(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	report, err := h.performanceService.GetRepartidorPerformance(claims.UserID.String(), c.Query("from"), c.Query("to"))
+	if err != nil {
+		return h.handlePerformanceError(c, err)
+	}
+
+	return c.JSON(report)
+}
+
+// handlePerformanceError traduce los errores del servicio de desempeño a respuestas HTTP
+func (h *PerformanceHandler) handlePerformanceError(c *fiber.Ctx, err error) error {
+	switch err {
+	case services.ErrInvalidBusinessDate:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Rango de fechas inválido, use el formato YYYY-MM-DD",
+		})
+	case services.ErrNotARepartidor:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Repartidor no encontrado",
+		})
+	default:
+		log.Printf("Error al obtener desempeño de repartidores: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener el desempeño",
+		})
+	}
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *PerformanceHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler, repartidorOrAdmin fiber.Handler) {
+	// Desempeño propio del repartidor
+	router.Get("/repartidores/me/performance", authMiddleware, repartidorOrAdmin, h.GetMyPerformance) // GET /repartidores/me/performance
+
+	// Ranking y detalle para administradores
+	router.Get("/admin/repartidores/performance", authMiddleware, adminOnly, h.GetLeaderboard)               // GET /admin/repartidores/performance
+	router.Get("/admin/repartidores/:id/performance", authMiddleware, adminOnly, h.GetRepartidorPerformance) // GET /admin/repartidores/:id/performance
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"log"
+
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PopularityHandler expone el desglose de la puntuación de popularidad de los productos
+type PopularityHandler struct {
+	popularityService *services.PopularityService
+}
+
+// NewPopularityHandler crea un nuevo handler de popularidad
+func NewPopularityHandler(popularityService *services.PopularityService) *PopularityHandler {
+	return &PopularityHandler{
+		popularityService: popularityService,
+	}
+}
+
+// @Summary Vista previa de la popularidad
+// @Description Calcula ahora la puntuación de popularidad de cada producto con su desglose (vistas y compras con decaimiento, calificaciones) y la puntuación guardada, sin modificarla. La tarea en segundo plano guarda la puntuación periódicamente
+// @Tags productos
+// @Produce json
+// @Success 200 {object} models.PopularityPreview
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/popularity [get]
+// PreviewPopularity muestra el desglose de la popularidad de los productos
+func (h *PopularityHandler) PreviewPopularity(c *fiber.Ctx) error {
+	preview, err := h.popularityService.Preview()
+	if err != nil {
+		log.Printf("Error al calcular la popularidad: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al calcular la popularidad de los productos",
+		})
+	}
+
+	return c.JSON(preview)
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *PopularityHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler) {
+	router.Get("/admin/products/popularity", authMiddleware, adminOnly, h.PreviewPopularity) // GET /admin/products/popularity
+}
@@ -1,6 +1,16 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"backend/internal/export"
 	"backend/internal/models"
 	"backend/internal/services"
 
@@ -27,13 +37,30 @@ func NewProductHandler(productService *services.ProductService) *ProductHandler
 // @Accept json
 // @Produce json
 // @Param active query boolean false "Solo productos activos"
+// @Param cursor query string false "Cursor opaco (vacío para la primera página); activa la paginación por cursor"
+// @Param limit query int false "Cantidad por página en modo cursor (por defecto: 20, máximo: 100)"
 // @Success 200 {array} models.Product
+// @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /products [get]
 func (h *ProductHandler) GetAllProducts(c *fiber.Ctx) error {
 	// Obtener parámetros de consulta opcionales
 	onlyActive := c.Query("active") == "true"
 
+	// Paginación por cursor: devuelve models.CursorPage en lugar del arreglo completo
+	if cursor, limit, ok := cursorQuery(c); ok {
+		page, err := h.productService.GetWithCursor(cursor, limit, onlyActive)
+		if err != nil {
+			if err == services.ErrInvalidCursor {
+				return invalidCursorResponse(c)
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error al obtener los productos",
+			})
+		}
+		return c.JSON(page)
+	}
+
 	var products []*models.Product
 	var err error
 
@@ -80,6 +107,11 @@ func (h *ProductHandler) GetProductByID(c *fiber.Ctx) error {
 		})
 	}
 
+	// El detalle incluye las variantes activas con su precio final
+	if variants, err := h.productService.ListVariants(productID, true); err == nil {
+		product.Variants = variants
+	}
+
 	return c.JSON(product)
 }
 
@@ -94,11 +126,17 @@ type CreateProductRequest struct {
 	PackageSize   string  `json:"package_size"`
 	StockQuantity       int     `json:"stock_quantity" validate:"min=0"`
 	IsActive            bool    `json:"is_active"`
+	IsReturnable        bool    `json:"is_returnable"`                   // Balón que se intercambia por uno vacío
+	DepositAmount       float64 `json:"deposit_amount" validate:"min=0"` // Garantía si el cliente no entrega vacío
+	ReorderThreshold    int     `json:"reorder_threshold" validate:"min=0"`  // Aviso de stock bajo (0 = sin aviso)
+
+	// Componentes del combo; si se indican, el producto es un combo y stock_quantity se ignora
+	BundleItems []models.BundleItemRequest `json:"bundle_items"`
 }
 
 // CreateProduct crea un nuevo producto (solo para administradores)
 // @Summary Crear un nuevo producto
-// @Description Crea un nuevo producto (solo para administradores)
+// @Description Crea un nuevo producto (solo para administradores). Con bundle_items crea un combo: su stock se calcula a partir de los componentes
 // @Tags productos
 // @Accept json
 // @Produce json
@@ -107,6 +145,7 @@ type CreateProductRequest struct {
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Security BearerAuth
 // @Router /products [post]
@@ -126,16 +165,31 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.DepositAmount < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "La garantía no puede ser negativa",
+		})
+	}
+
+	if req.ReorderThreshold < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "El umbral de reposición no puede ser negativo",
+		})
+	}
+
 	// Crear el producto en el modelo
 	product := &models.Product{
-		Name:          req.Name,
-		Description:   req.Description,
-		Price:         req.Price,
-		ImageURL:      req.ImageURL,
-		UnitOfMeasure: req.UnitOfMeasure,
-		PackageSize:   req.PackageSize,
-		StockQuantity: req.StockQuantity,
-		IsActive:      req.IsActive,
+		Name:             req.Name,
+		Description:      req.Description,
+		Price:            req.Price,
+		ImageURL:         req.ImageURL,
+		UnitOfMeasure:    req.UnitOfMeasure,
+		PackageSize:      req.PackageSize,
+		StockQuantity:    req.StockQuantity,
+		IsActive:         req.IsActive,
+		IsReturnable:     req.IsReturnable,
+		DepositAmount:    req.DepositAmount,
+		ReorderThreshold: req.ReorderThreshold,
 	}
 
 	// Asignar categoría si se proporciona
@@ -149,8 +203,24 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		product.CategoryID = &categoryUUID
 	}
 
-	// Crear el producto usando el servicio
-	if err := h.productService.Create(product); err != nil {
+	// Crear el producto (o el combo con sus componentes) usando el servicio
+	var err error
+	if len(req.BundleItems) > 0 {
+		err = h.productService.CreateBundle(product, req.BundleItems)
+	} else {
+		err = h.productService.Create(product)
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidBundle) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrBranchStockRequired) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Con sucursales el producto se crea sin stock y se carga por sucursal",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error al crear el producto",
 		})
@@ -170,6 +240,9 @@ type UpdateProductRequest struct {
 	PackageSize         string  `json:"package_size,omitempty"`
 	StockQuantity       *int    `json:"stock_quantity,omitempty" validate:"omitempty,min=0"`
 	IsActive            *bool   `json:"is_active,omitempty"`
+	IsReturnable        *bool    `json:"is_returnable,omitempty"`
+	DepositAmount       *float64 `json:"deposit_amount,omitempty" validate:"omitempty,min=0"`
+	ReorderThreshold    *int     `json:"reorder_threshold,omitempty" validate:"omitempty,min=0"`
 }
 
 // UpdateProduct actualiza un producto existente (solo para administradores)
@@ -185,6 +258,7 @@ type UpdateProductRequest struct {
 // @Failure 401 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Security BearerAuth
 // @Router /products/{id} [put]
@@ -253,11 +327,48 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 	}
 
 	if req.StockQuantity != nil {
+		if product.IsBundle {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "El stock de un combo se calcula a partir de sus componentes",
+			})
+		}
 		product.StockQuantity = *req.StockQuantity
 	}
 
+	if req.IsReturnable != nil {
+		product.IsReturnable = *req.IsReturnable
+	}
+
+	if req.DepositAmount != nil {
+		if *req.DepositAmount < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "La garantía no puede ser negativa",
+			})
+		}
+		product.DepositAmount = *req.DepositAmount
+	}
+
+	if req.ReorderThreshold != nil {
+		if *req.ReorderThreshold < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "El umbral de reposición no puede ser negativo",
+			})
+		}
+		product.ReorderThreshold = *req.ReorderThreshold
+	}
+
 	// Guardar los cambios
 	if err := h.productService.Update(product); err != nil {
+		if errors.Is(err, services.ErrBranchStockRequired) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Con sucursales el stock se modifica por sucursal",
+			})
+		}
+		if errors.Is(err, services.ErrStockBelowZero) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "El stock no alcanza para las otras variantes activas del producto",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error al actualizar el producto",
 		})
@@ -268,7 +379,7 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 
 // DeleteProduct elimina un producto (solo para administradores)
 // @Summary Eliminar un producto
-// @Description Elimina un producto existente (solo para administradores)
+// @Description Retira el producto del catálogo (borrado lógico; se conserva en pedidos, calificaciones y favoritos y puede restaurarse). Solo para administradores
 // @Tags productos
 // @Accept json
 // @Produce json
@@ -278,6 +389,7 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 // @Failure 401 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Security BearerAuth
 // @Router /products/{id} [delete]
@@ -292,6 +404,11 @@ func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 
 	// Eliminar el producto
 	if err := h.productService.Delete(productID); err != nil {
+		if errors.Is(err, services.ErrProductInBundle) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Error al eliminar el producto",
 		})
@@ -300,6 +417,183 @@ func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// ListDeletedProducts lista los productos eliminados (solo para administradores)
+// @Summary Listar productos eliminados
+// @Description Productos eliminados del catálogo, los más recientes primero. Pueden restaurarse
+// @Tags productos
+// @Produce json
+// @Success 200 {array} models.Product
+// @Failure 500 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/deleted [get]
+func (h *ProductHandler) ListDeletedProducts(c *fiber.Ctx) error {
+	products, err := h.productService.ListDeleted()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener los productos eliminados",
+		})
+	}
+
+	return c.JSON(products)
+}
+
+// RestoreProduct devuelve al catálogo un producto eliminado (solo para administradores)
+// @Summary Restaurar producto eliminado
+// @Description Devuelve el producto al catálogo. Falla si otro producto usa su nombre
+// @Tags productos
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Success 200 {object} models.Product
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/{id}/restore [post]
+func (h *ProductHandler) RestoreProduct(c *fiber.Ctx) error {
+	product, err := h.productService.Restore(c.Params("id"))
+	if err != nil {
+		return h.handleDeletionError(c, err)
+	}
+
+	return c.JSON(product)
+}
+
+// PurgeProduct borra definitivamente un producto eliminado (solo para administradores)
+// @Summary Borrar producto definitivamente
+// @Description Borra el producto con sus variantes, imágenes, ofertas e historial. Solo para productos ya eliminados que ningún pedido incluye ni forman parte de un combo
+// @Tags productos
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Success 204 "No Content"
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/{id}/permanent [delete]
+func (h *ProductHandler) PurgeProduct(c *fiber.Ctx) error {
+	if err := h.productService.Purge(c.Params("id")); err != nil {
+		return h.handleDeletionError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// handleDeletionError traduce los errores de restauración y borrado definitivo a respuestas HTTP
+func (h *ProductHandler) handleDeletionError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrProductNotFoundService):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Producto no encontrado",
+		})
+	case errors.Is(err, services.ErrProductNameExists),
+		errors.Is(err, services.ErrProductNotDeleted),
+		errors.Is(err, services.ErrProductHasOrders),
+		errors.Is(err, services.ErrProductInBundle):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al procesar el producto eliminado",
+		})
+	}
+}
+
+// SearchProducts busca productos con texto completo, filtros y facetas
+// @Summary Buscar productos
+// @Description Búsqueda de texto completo en nombre y descripción (español, por prefijo) sobre productos activos, con filtros, orden, paginación y conteos por faceta. El precio considera la oferta vigente
+// @Tags productos
+// @Produce json
+// @Param q query string false "Texto a buscar"
+// @Param category_id query string false "ID de la categoría"
+// @Param min_price query number false "Precio final mínimo"
+// @Param max_price query number false "Precio final máximo"
+// @Param on_offer query boolean false "Solo productos en oferta"
+// @Param in_stock query boolean false "Solo productos con stock"
+// @Param min_rating query number false "Calificación promedio mínima (0-5)"
+// @Param sort query string false "relevance, price_asc, price_desc, popularity, rating o newest (por defecto: relevance con texto, popularity sin texto)"
+// @Param page query int false "Página (por defecto: 1)"
+// @Param page_size query int false "Resultados por página (por defecto: 20, máximo: 100)"
+// @Success 200 {object} models.ProductSearchResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/search [get]
+func (h *ProductHandler) SearchProducts(c *fiber.Ctx) error {
+	params := models.ProductSearchParams{
+		Query:    c.Query("q"),
+		OnOffer:  c.QueryBool("on_offer", false),
+		InStock:  c.QueryBool("in_stock", false),
+		Sort:     c.Query("sort"),
+		Page:     c.QueryInt("page", 1),
+		PageSize: c.QueryInt("page_size", models.ProductSearchDefaultPageSize),
+	}
+
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		id, err := uuid.Parse(categoryID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "ID de categoría inválido",
+			})
+		}
+		params.CategoryID = &id
+	}
+
+	var err error
+	if params.MinPrice, err = optionalFloatQuery(c, "min_price"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Precio mínimo inválido",
+		})
+	}
+	if params.MaxPrice, err = optionalFloatQuery(c, "max_price"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Precio máximo inválido",
+		})
+	}
+	if params.MinRating, err = optionalFloatQuery(c, "min_rating"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Calificación mínima inválida",
+		})
+	}
+
+	result, err := h.productService.Search(params)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidProductSearch) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al buscar productos",
+		})
+	}
+
+	return c.JSON(result)
+}
+
+// optionalFloatQuery lee un parámetro numérico opcional (nil si no viene)
+func optionalFloatQuery(c *fiber.Ctx, key string) (*float64, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// optionalUUIDQuery lee un parámetro UUID opcional (nil si no viene)
+func optionalUUIDQuery(c *fiber.Ctx, key string) (*uuid.UUID, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
 // GetPopularProducts obtiene productos populares
 // @Summary Obtener productos populares
 // @Description Obtiene una lista de los productos más populares
@@ -348,56 +642,357 @@ func (h *ProductHandler) GetRecentProducts(c *fiber.Ctx) error {
 	return c.JSON(products)
 }
 
-// IncrementProductView incrementa el contador de vistas de un producto
-// @Summary Incrementar vistas de producto
-// @Description Incrementa el contador de vistas para analytics
+// ListProductVariants obtiene las variantes activas de un producto
+// @Summary Listar variantes de un producto
+// @Description Devuelve las presentaciones activas del producto (tamaño, válvula, marca) con SKU, stock y precio final
 // @Tags productos
-// @Accept json
 // @Produce json
 // @Param id path string true "ID del producto"
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {array} models.ProductVariant
 // @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
-// @Failure 500 {object} map[string]interface{}
-// @Router /products/{id}/view [post]
-func (h *ProductHandler) IncrementProductView(c *fiber.Ctx) error {
-	productID := c.Params("id")
+// @Router /products/{id}/variants [get]
+func (h *ProductHandler) ListProductVariants(c *fiber.Ctx) error {
+	return h.listVariants(c, true)
+}
 
-	// Validar UUID
+// ListAllProductVariants obtiene todas las variantes de un producto, incluidas las inactivas
+// @Summary Listar todas las variantes de un producto
+// @Description Devuelve las presentaciones del producto incluidas las inactivas. Solo para administradores
+// @Tags productos
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Success 200 {array} models.ProductVariant
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/{id}/variants [get]
+func (h *ProductHandler) ListAllProductVariants(c *fiber.Ctx) error {
+	return h.listVariants(c, false)
+}
+
+func (h *ProductHandler) listVariants(c *fiber.Ctx, activeOnly bool) error {
+	productID := c.Params("id")
 	if _, err := uuid.Parse(productID); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "ID de producto inválido",
 		})
 	}
 
-	if err := h.productService.IncrementViewCount(productID); err != nil {
-		if err == services.ErrProductNotFoundService {
+	variants, err := h.productService.ListVariants(productID, activeOnly)
+	if err != nil {
+		return h.handleVariantError(c, err)
+	}
+
+	return c.JSON(variants)
+}
+
+// CreateProductVariant agrega una variante a un producto
+// @Summary Crear variante de producto
+// @Description Agrega una presentación con SKU único, precio y stock propios. Si es la predeterminada, define el precio del producto
+// @Tags productos
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param variant body models.CreateVariantRequest true "Datos de la variante"
+// @Success 201 {object} models.ProductVariant
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /products/{id}/variants [post]
+func (h *ProductHandler) CreateProductVariant(c *fiber.Ctx) error {
+	var req models.CreateVariantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos de variante inválidos",
+		})
+	}
+
+	variant, err := h.productService.CreateVariant(c.Params("id"), &req)
+	if err != nil {
+		return h.handleVariantError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(variant)
+}
+
+// UpdateProductVariant actualiza una variante de un producto
+// @Summary Actualizar variante de producto
+// @Tags productos
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param variantId path string true "ID de la variante"
+// @Param variant body models.UpdateVariantRequest true "Campos a actualizar"
+// @Success 200 {object} models.ProductVariant
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /products/{id}/variants/{variantId} [put]
+func (h *ProductHandler) UpdateProductVariant(c *fiber.Ctx) error {
+	var req models.UpdateVariantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos de variante inválidos",
+		})
+	}
+
+	variant, err := h.productService.UpdateVariant(c.Params("id"), c.Params("variantId"), &req)
+	if err != nil {
+		return h.handleVariantError(c, err)
+	}
+
+	return c.JSON(variant)
+}
+
+// DeleteProductVariant desactiva una variante de un producto
+// @Summary Desactivar variante de producto
+// @Description Desactiva la variante; se conserva para el historial de pedidos. La predeterminada no puede desactivarse
+// @Tags productos
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param variantId path string true "ID de la variante"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /products/{id}/variants/{variantId} [delete]
+func (h *ProductHandler) DeleteProductVariant(c *fiber.Ctx) error {
+	if err := h.productService.DeleteVariant(c.Params("id"), c.Params("variantId")); err != nil {
+		return h.handleVariantError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Variante desactivada correctamente",
+	})
+}
+
+// handleVariantError traduce los errores de variantes a respuestas HTTP
+func (h *ProductHandler) handleVariantError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrProductNotFoundService):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Producto no encontrado",
+		})
+	case errors.Is(err, services.ErrVariantNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Variante no encontrada",
+		})
+	case errors.Is(err, services.ErrInvalidVariant):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrVariantSKUExists), errors.Is(err, services.ErrDefaultVariantRequired),
+		errors.Is(err, services.ErrBranchStockRequired):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al procesar la variante",
+		})
+	}
+}
+
+// SetBundleItems reemplaza los componentes de un combo
+// @Summary Reemplazar componentes de un combo
+// @Description Reemplaza los productos y cantidades que incluye el combo y recalcula su stock (combos que alcanzan a armarse con el stock de los componentes). Un combo no puede incluir otro combo
+// @Tags productos
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del combo"
+// @Param items body models.SetBundleItemsRequest true "Componentes del combo"
+// @Success 200 {object} models.Product
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /products/{id}/bundle-items [put]
+func (h *ProductHandler) SetBundleItems(c *fiber.Ctx) error {
+	var req models.SetBundleItemsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Datos de componentes inválidos",
+		})
+	}
+
+	product, err := h.productService.SetBundleItems(c.Params("id"), req.Items)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrProductNotFoundService):
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Producto no encontrado",
 			})
+		case errors.Is(err, services.ErrInvalidBundle):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, services.ErrNotBundle):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error al actualizar los componentes del combo",
+			})
+		}
+	}
+
+	return c.JSON(product)
+}
+
+// ExportProducts descarga todos los productos en el formato que acepta la importación
+// @Summary Exportar productos
+// @Description Descarga los productos (sku, nombre, categoría, precio, stock, unidad, presentación, estado) en CSV o XLSX
+// @Tags productos
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (por defecto) o xlsx"
+// @Param branch_id query string false "Exportar el stock de esta sucursal en lugar del total"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/export [get]
+func (h *ProductHandler) ExportProducts(c *fiber.Ctx) error {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Formato inválido, use csv o xlsx",
+		})
+	}
+	branchID, err := optionalUUIDQuery(c, "branch_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de sucursal inválido",
+		})
+	}
+	if err := h.productService.CheckStockBranch(branchID); err != nil {
+		if errors.Is(err, services.ErrBranchNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Sucursal no encontrada",
+			})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Error al incrementar vistas",
+			"error": "Error al exportar los productos",
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"message": "Vista registrada correctamente",
+	filename := fmt.Sprintf("productos_%s%s", time.Now().Format(models.BusinessDateLayout), format.Extension())
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.productService.ExportProducts(w, format, branchID); err != nil {
+			log.Printf("Error al exportar productos: %v", err)
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("Error al enviar la exportación de productos: %v", err)
+		}
 	})
+
+	return nil
+}
+
+// ImportProducts crea y actualiza productos desde un archivo CSV
+// @Summary Importar productos desde CSV
+// @Description Crea o actualiza productos buscándolos por SKU o por nombre. Columnas: sku, name, description, category, price, stock_quantity, unit_of_measure, package_size, is_active (las celdas vacías conservan el valor actual). Si alguna fila tiene errores no se aplica ningún cambio. Con dry_run=true solo valida. Con sucursales, stock_quantity es el stock de branch_id y sin branch_id el stock no puede cambiar
+// @Tags productos
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Produce json
+// @Param file formData file false "Archivo CSV (también se acepta el CSV como cuerpo de la petición)"
+// @Param dry_run query bool false "Solo validar, sin guardar cambios"
+// @Param branch_id query string false "Sucursal cuyo stock se importa"
+// @Success 200 {object} models.ProductImportResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} models.ProductImportResult
+// @Failure 409 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/import [post]
+func (h *ProductHandler) ImportProducts(c *fiber.Ctx) error {
+	branchID, err := optionalUUIDQuery(c, "branch_id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de sucursal inválido",
+		})
+	}
+
+	var content io.Reader = bytes.NewReader(c.Body())
+	if header, err := c.FormFile("file"); err == nil {
+		file, err := header.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "No se pudo leer el archivo",
+			})
+		}
+		defer file.Close()
+		content = file
+	}
+
+	result, err := h.productService.ImportCSV(content, c.QueryBool("dry_run"), branchID)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidProductImport) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, services.ErrBranchStockRequired) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Con sucursales indique branch_id para importar el stock de una sucursal",
+			})
+		}
+		if errors.Is(err, services.ErrBranchNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Sucursal no encontrada",
+			})
+		}
+		log.Printf("Error al importar productos: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al importar los productos",
+		})
+	}
+
+	if result.Failed > 0 && !result.DryRun {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(result)
+	}
+	return c.JSON(result)
 }
 
 // RegisterRoutes registra las rutas del handler en el router
 func (h *ProductHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler) {
 	// Rutas públicas para productos (sin grupo para evitar conflictos con ratings)
 	router.Get("/products", h.GetAllProducts)
+	router.Get("/products/search", h.SearchProducts)
 	router.Get("/products/popular", h.GetPopularProducts)
 	router.Get("/products/recent", h.GetRecentProducts)
 	router.Get("/products/:id", h.GetProductByID)
-	router.Post("/products/:id/view", h.IncrementProductView)
+	router.Get("/products/:id/variants", h.ListProductVariants)
+
+	// Importación y exportación masiva (solo administradores)
+	router.Get("/admin/products/export", authMiddleware, adminOnly, h.ExportProducts)
+	router.Post("/admin/products/import", authMiddleware, adminOnly, h.ImportProducts)
+
+	// Productos eliminados: listado, restauración y borrado definitivo (solo administradores)
+	router.Get("/admin/products/deleted", authMiddleware, adminOnly, h.ListDeletedProducts)
+	router.Post("/admin/products/:id/restore", authMiddleware, adminOnly, h.RestoreProduct)
+	router.Delete("/admin/products/:id/permanent", authMiddleware, adminOnly, h.PurgeProduct)
+
+	// Variantes inactivas incluidas (solo administradores)
+	router.Get("/admin/products/:id/variants", authMiddleware, adminOnly, h.ListAllProductVariants)
 
 	// Rutas solo para administradores (con grupo específico para admin)
 	adminProducts := router.Group("/products", authMiddleware, adminOnly)
 	adminProducts.Post("/", h.CreateProduct)
 	adminProducts.Put("/:id", h.UpdateProduct)
 	adminProducts.Delete("/:id", h.DeleteProduct)
+	adminProducts.Post("/:id/variants", h.CreateProductVariant)
+	adminProducts.Put("/:id/variants/:variantId", h.UpdateProductVariant)
+	adminProducts.Delete("/:id/variants/:variantId", h.DeleteProductVariant)
+	adminProducts.Put("/:id/bundle-items", h.SetBundleItems)
 }
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime/multipart"
+
+	"backend/api/v1/middlewares"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ProductImageHandler maneja las peticiones HTTP de las imágenes de productos
+type ProductImageHandler struct {
+	imageService *services.ProductImageService
+}
+
+// NewProductImageHandler crea un nuevo handler de imágenes de productos
+func NewProductImageHandler(imageService *services.ProductImageService) *ProductImageHandler {
+	return &ProductImageHandler{
+		imageService: imageService,
+	}
+}
+
+// @Summary Listar imágenes de un producto
+// @Description Devuelve las imágenes en orden (la primera es la principal) con sus miniaturas y versiones WebP
+// @Tags productos
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Success 200 {array} models.ProductImage
+// @Failure 404 {object} map[string]interface{}
+// @Router /products/{id}/images [get]
+// ListImages lista las imágenes de un producto
+func (h *ProductImageHandler) ListImages(c *fiber.Ctx) error {
+	images, err := h.imageService.List(c.Params("id"))
+	if err != nil {
+		return h.handleImageError(c, err)
+	}
+
+	return c.JSON(images)
+}
+
+// @Summary Subir imágenes de un producto
+// @Description Sube hasta 5 imágenes JPEG, PNG o GIF (campo "images"). Se generan miniatura y versión mediana en el formato original y en WebP
+// @Tags productos
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param images formData file true "Imágenes (se puede repetir el campo)"
+// @Success 201 {array} models.ProductImage
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 413 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /products/{id}/images [post]
+// UploadImages sube imágenes a un producto
+func (h *ProductImageHandler) UploadImages(c *fiber.Ctx) error {
+	// El límite global no aplica a esta ruta: el cuerpo se lee aquí, ya autenticado el administrador
+	if err := middlewares.ReadBody(c, h.imageService.UploadBodyLimit()); err != nil {
+		c.Context().SetConnectionClose()
+		if !errors.Is(err, fiber.ErrRequestEntityTooLarge) {
+			return err
+		}
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error":     "La subida supera el tamaño máximo permitido",
+			"max_bytes": h.imageService.UploadBodyLimit(),
+		})
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Se esperaba un formulario multipart con el campo images",
+		})
+	}
+
+	headers := form.File["images"]
+	if len(headers) == 0 {
+		headers = form.File["image"]
+	}
+	if len(headers) > models.MaxImagesPerRequest {
+		return h.handleImageError(c, services.ErrTooManyImageFiles)
+	}
+
+	files := make([][]byte, 0, len(headers))
+	for _, header := range headers {
+		if header.Size > h.imageService.MaxUploadBytes() {
+			return h.handleImageError(c, services.ErrImageTooLarge)
+		}
+		data, err := readUploadedFile(header, h.imageService.MaxUploadBytes())
+		if err != nil {
+			return h.handleImageError(c, err)
+		}
+		files = append(files, data)
+	}
+
+	images, err := h.imageService.Upload(c.Params("id"), files)
+	if err != nil {
+		return h.handleImageError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(images)
+}
+
+// @Summary Reordenar imágenes de un producto
+// @Description Recibe todos los IDs de imagen en el nuevo orden; la primera pasa a ser la imagen principal
+// @Tags productos
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param order body models.ReorderImagesRequest true "IDs de imagen en orden"
+// @Success 200 {array} models.ProductImage
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /products/{id}/images/order [put]
+// ReorderImages cambia el orden de las imágenes de un producto
+func (h *ProductImageHandler) ReorderImages(c *fiber.Ctx) error {
+	var req models.ReorderImagesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Formato de solicitud inválido",
+		})
+	}
+
+	images, err := h.imageService.Reorder(c.Params("id"), req.ImageIDs)
+	if err != nil {
+		return h.handleImageError(c, err)
+	}
+
+	return c.JSON(images)
+}
+
+// @Summary Eliminar imagen de un producto
+// @Description Elimina la imagen y todos sus archivos. Si era la principal, la siguiente ocupa su lugar
+// @Tags productos
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param imageId path string true "ID de la imagen"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /products/{id}/images/{imageId} [delete]
+// DeleteImage elimina una imagen de un producto
+func (h *ProductImageHandler) DeleteImage(c *fiber.Ctx) error {
+	if err := h.imageService.Delete(c.Params("id"), c.Params("imageId")); err != nil {
+		return h.handleImageError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Imagen eliminada correctamente",
+	})
+}
+
+// readUploadedFile lee el archivo completo sin pasar del tamaño máximo
+func readUploadedFile(header *multipart.FileHeader, maxBytes int64) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, services.ErrInvalidImage
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		return nil, services.ErrInvalidImage
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, services.ErrImageTooLarge
+	}
+	return data, nil
+}
+
+// handleImageError traduce los errores del servicio de imágenes a respuestas HTTP
+func (h *ProductImageHandler) handleImageError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrProductNotFoundService):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Producto no encontrado",
+		})
+	case errors.Is(err, services.ErrImageNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Imagen no encontrada",
+		})
+	case errors.Is(err, services.ErrImageTooLarge):
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error":     err.Error(),
+			"max_bytes": h.imageService.MaxUploadBytes(),
+		})
+	case errors.Is(err, services.ErrInvalidImage),
+		errors.Is(err, services.ErrNoImageFiles),
+		errors.Is(err, services.ErrTooManyImageFiles),
+		errors.Is(err, services.ErrImageLimitReached),
+		errors.Is(err, services.ErrInvalidImageOrder):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		log.Printf("Error en imágenes de producto: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al procesar las imágenes",
+		})
+	}
+}
+
+// RegisterRoutes registra las rutas del handler en el router. Debe registrarse antes que las rutas
+// de productos para que el grupo de administración de /products no intercepte la consulta pública.
+func (h *ProductImageHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler) {
+	router.Get("/products/:id/images", h.ListImages)                                         // GET /products/:id/images
+	router.Post("/products/:id/images", authMiddleware, adminOnly, h.UploadImages)           // POST /products/:id/images
+	router.Put("/products/:id/images/order", authMiddleware, adminOnly, h.ReorderImages)     // PUT /products/:id/images/order
+	router.Delete("/products/:id/images/:imageId", authMiddleware, adminOnly, h.DeleteImage) // DELETE /products/:id/images/:imageId
+}
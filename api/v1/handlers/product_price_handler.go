@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"backend/internal/auth"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ProductPriceHandler maneja el historial de precios y los cambios de precio programados
+type ProductPriceHandler struct {
+	priceService *services.ProductPriceService
+}
+
+// NewProductPriceHandler crea un nuevo handler de precios de productos
+func NewProductPriceHandler(priceService *services.ProductPriceService) *ProductPriceHandler {
+	return &ProductPriceHandler{
+		priceService: priceService,
+	}
+}
+
+// @Summary Historial de precios de un producto
+// @Description Cambios de precio del producto (edición, variantes, importación o cambio programado), del más reciente al más antiguo
+// @Tags productos
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param cursor query string false "Cursor devuelto en next_cursor (vacío = primera página)"
+// @Param limit query int false "Cambios por página (máximo 100)"
+// @Success 200 {object} models.CursorPage[models.ProductPriceHistory]
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/{id}/price-history [get]
+// GetPriceHistory obtiene el historial de precios de un producto
+func (h *ProductPriceHandler) GetPriceHistory(c *fiber.Ctx) error {
+	page, err := h.priceService.GetHistory(c.Params("id"), c.Query("cursor"), c.QueryInt("limit", models.DefaultCursorLimit))
+	if err != nil {
+		return h.handlePriceError(c, err)
+	}
+
+	return c.JSON(page)
+}
+
+// @Summary Listar cambios de precio programados
+// @Description Cambios de precio programados del producto por fecha de vigencia
+// @Tags productos
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param status query string false "PENDING, APPLIED o CANCELLED (vacío = todos)"
+// @Success 200 {array} models.ScheduledPriceChange
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/{id}/scheduled-prices [get]
+// ListScheduledPrices lista los cambios de precio programados de un producto
+func (h *ProductPriceHandler) ListScheduledPrices(c *fiber.Ctx) error {
+	schedules, err := h.priceService.ListScheduled(c.Params("id"), c.Query("status"))
+	if err != nil {
+		return h.handlePriceError(c, err)
+	}
+
+	return c.JSON(schedules)
+}
+
+// @Summary Programar cambio de precio
+// @Description Programa un nuevo precio desde una fecha futura. Una tarea en segundo plano lo aplica (producto y variante predeterminada) y notifica la actualización del producto
+// @Tags productos
+// @Accept json
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param schedule body models.SchedulePriceChangeRequest true "Nuevo precio y fecha de vigencia"
+// @Success 201 {object} models.ScheduledPriceChange
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/{id}/scheduled-prices [post]
+// SchedulePriceChange programa un cambio de precio
+func (h *ProductPriceHandler) SchedulePriceChange(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	var req models.SchedulePriceChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Formato de solicitud inválido; effective_at debe estar en formato RFC 3339",
+		})
+	}
+
+	schedule, err := h.priceService.SchedulePriceChange(c.Params("id"), claims.UserID.String(), &req)
+	if err != nil {
+		return h.handlePriceError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(schedule)
+}
+
+// @Summary Cancelar cambio de precio programado
+// @Description Cancela un cambio de precio que aún no se aplicó
+// @Tags productos
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param scheduleId path string true "ID del cambio programado"
+// @Success 200 {object} models.ScheduledPriceChange
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /admin/products/{id}/scheduled-prices/{scheduleId} [delete]
+// CancelScheduledPrice cancela un cambio de precio programado
+func (h *ProductPriceHandler) CancelScheduledPrice(c *fiber.Ctx) error {
+	schedule, err := h.priceService.CancelScheduled(c.Params("id"), c.Params("scheduleId"))
+	if err != nil {
+		return h.handlePriceError(c, err)
+	}
+
+	return c.JSON(schedule)
+}
+
+// handlePriceError traduce los errores del servicio de precios a respuestas HTTP
+func (h *ProductPriceHandler) handlePriceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidCursor):
+		return invalidCursorResponse(c)
+	case errors.Is(err, services.ErrInvalidScheduledPrice):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrProductNotFoundService):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Producto no encontrado",
+		})
+	case errors.Is(err, services.ErrScheduledPriceNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Cambio de precio programado no encontrado",
+		})
+	case errors.Is(err, services.ErrScheduledPriceConflict), errors.Is(err, services.ErrScheduledPriceNotPending):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		log.Printf("Error en precios de producto: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al procesar los precios del producto",
+		})
+	}
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *ProductPriceHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler, adminOnly fiber.Handler) {
+	router.Get("/admin/products/:id/price-history", authMiddleware, adminOnly, h.GetPriceHistory)                        // GET /admin/products/:id/price-history
+	router.Get("/admin/products/:id/scheduled-prices", authMiddleware, adminOnly, h.ListScheduledPrices)                 // GET /admin/products/:id/scheduled-prices
+	router.Post("/admin/products/:id/scheduled-prices", authMiddleware, adminOnly, h.SchedulePriceChange)                // POST /admin/products/:id/scheduled-prices
+	router.Delete("/admin/products/:id/scheduled-prices/:scheduleId", authMiddleware, adminOnly, h.CancelScheduledPrice) // DELETE /admin/products/:id/scheduled-prices/:scheduleId
+}
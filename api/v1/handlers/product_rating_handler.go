@@ -88,6 +88,8 @@ func (h *ProductRatingHandler) CreateRating(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "ID del producto"
+// @Param cursor query string false "Cursor opaco (vacío para la primera página); activa la paginación por cursor"
+// @Param limit query int false "Cantidad por página en modo cursor (por defecto: 20, máximo: 100)"
 // @Success 200 {array} models.RatingResponse
 // @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
@@ -103,6 +105,26 @@ func (h *ProductRatingHandler) GetProductRatings(c *fiber.Ctx) error {
 		})
 	}
 
+	// Paginación por cursor: devuelve models.CursorPage
+	if cursor, limit, ok := cursorQuery(c); ok {
+		page, err := h.ratingService.GetByProductWithCursor(productID, cursor, limit)
+		if err != nil {
+			switch err {
+			case services.ErrInvalidCursor:
+				return invalidCursorResponse(c)
+			case services.ErrProductNotFoundService:
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Producto no encontrado",
+				})
+			default:
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Error al obtener calificaciones",
+				})
+			}
+		}
+		return c.JSON(page)
+	}
+
 	ratings, err := h.ratingService.GetByProduct(productID)
 	if err != nil {
 		if err == services.ErrProductNotFoundService {
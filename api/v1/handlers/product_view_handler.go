@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"backend/internal/auth"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/google/uuid"
+)
+
+// maxDeviceIDLength limita el identificador de dispositivo que envía la app
+const maxDeviceIDLength = 128
+
+// ProductViewHandler registra las vistas de productos
+type ProductViewHandler struct {
+	viewService *services.ProductViewService
+}
+
+// NewProductViewHandler crea un nuevo handler de vistas de productos
+func NewProductViewHandler(viewService *services.ProductViewService) *ProductViewHandler {
+	return &ProductViewHandler{
+		viewService: viewService,
+	}
+}
+
+// RecordProductView registra la vista de un producto
+//
+// @Summary Registrar vista de producto
+// @Description Registra una vista del producto para analítica y popularidad. Cuenta una vista por visitante y producto dentro de la ventana configurada: el usuario autenticado o, sin token, el dispositivo de la cabecera X-Device-ID (o la IP y el navegador). Las vistas se guardan por lotes, por lo que el contador del producto se actualiza con unos segundos de demora
+// @Tags productos
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param X-Device-ID header string false "Identificador del dispositivo para visitantes anónimos"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /products/{id}/view [post]
+func (h *ProductViewHandler) RecordProductView(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID de producto inválido",
+		})
+	}
+
+	counted, err := h.viewService.RecordView(productID, viewerKey(c))
+	if err != nil {
+		if errors.Is(err, services.ErrProductNotFoundService) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Producto no encontrado",
+			})
+		}
+		log.Printf("Error al registrar vista de producto: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al incrementar vistas",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Vista registrada correctamente",
+		"counted": counted,
+	})
+}
+
+// viewerKey identifica al visitante: el usuario autenticado, el dispositivo que informa la app
+// o, en su defecto, la IP y el navegador
+func viewerKey(c *fiber.Ctx) string {
+	if claims, ok := c.Locals("user").(*auth.Claims); ok {
+		return "user:" + claims.UserID.String()
+	}
+	if device := strings.TrimSpace(c.Get("X-Device-ID")); device != "" && len(device) <= maxDeviceIDLength {
+		return "device:" + device
+	}
+	userAgent := c.Get(fiber.HeaderUserAgent)
+	if len(userAgent) > 256 {
+		userAgent = userAgent[:256]
+	}
+	return "ip:" + c.IP() + "|" + userAgent
+}
+
+// viewRateLimiter limita las vistas que acepta cada IP por minuto
+func viewRateLimiter(max int) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        max,
+		Expiration: time.Minute,
+		Next: func(c *fiber.Ctx) bool {
+			return max <= 0
+		},
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "Demasiadas vistas registradas desde esta dirección; intente más tarde",
+			})
+		},
+	})
+}
+
+// RegisterRoutes registra las rutas del handler en el router. optionalAuth identifica al usuario
+// si envía su token, sin exigirlo. Sin servicio de vistas (pruebas) la ruta no tiene límite por IP.
+func (h *ProductViewHandler) RegisterRoutes(router fiber.Router, optionalAuth fiber.Handler) {
+	rateLimit := 0
+	if h.viewService != nil {
+		rateLimit = h.viewService.RateLimit()
+	}
+	router.Post("/products/:id/view", viewRateLimiter(rateLimit), optionalAuth, h.RecordProductView) // POST /products/:id/view
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"backend/internal/auth"
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RecommendationHandler maneja las recomendaciones de productos
+type RecommendationHandler struct {
+	recommendationService *services.RecommendationService
+}
+
+// NewRecommendationHandler crea un nuevo handler de recomendaciones
+func NewRecommendationHandler(recommendationService *services.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{
+		recommendationService: recommendationService,
+	}
+}
+
+// @Summary Productos comprados juntos con frecuencia
+// @Description Productos activos y con stock que suelen comprarse junto con el producto, de mayor a menor confianza (fracción de los pedidos del producto que los incluyen). Lift mayor a 1 indica que se compran juntos más de lo esperado por azar
+// @Tags productos
+// @Produce json
+// @Param id path string true "ID del producto"
+// @Param exclude query string false "IDs de productos a omitir separados por coma (ej: los que ya están en el carrito)"
+// @Param limit query int false "Cantidad de productos (por defecto 6, máximo 20)"
+// @Success 200 {array} models.RelatedProduct
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /products/{id}/related [get]
+// GetRelatedProducts obtiene los productos comprados junto con un producto
+func (h *RecommendationHandler) GetRelatedProducts(c *fiber.Ctx) error {
+	exclude, err := parseProductIDs(c.Query("exclude"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "exclude debe ser una lista de IDs de producto separados por coma",
+		})
+	}
+
+	related, err := h.recommendationService.GetRelated(c.Params("id"), exclude, c.QueryInt("limit", models.DefaultRelatedLimit))
+	if err != nil {
+		if errors.Is(err, services.ErrProductNotFoundService) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Producto no encontrado",
+			})
+		}
+		log.Printf("Error al obtener productos relacionados: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener los productos relacionados",
+		})
+	}
+
+	return c.JSON(related)
+}
+
+// @Summary Productos recomendados para el usuario
+// @Description Productos activos y con stock para la pantalla de inicio según las compras, favoritos y calificaciones del usuario, su interés por categoría, los productos que se compran junto con los suyos y la popularidad general. Sin actividad previa devuelve los productos populares (personalized = false)
+// @Tags productos
+// @Produce json
+// @Param limit query int false "Cantidad de productos (por defecto 10, máximo 30)"
+// @Success 200 {object} models.ProductRecommendations
+// @Failure 401 {object} map[string]interface{}
+// @Security BearerAuth
+// @Router /products/recommended [get]
+// GetRecommendedProducts obtiene los productos recomendados para el usuario autenticado
+func (h *RecommendationHandler) GetRecommendedProducts(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*auth.Claims)
+
+	recommendations, err := h.recommendationService.GetRecommended(claims.UserID.String(), c.QueryInt("limit", models.DefaultRecommendedLimit))
+	if err != nil {
+		log.Printf("Error al obtener productos recomendados: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Error al obtener los productos recomendados",
+		})
+	}
+
+	return c.JSON(recommendations)
+}
+
+// parseProductIDs convierte una lista de IDs separados por coma; vacía = ninguno
+func parseProductIDs(value string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := uuid.Parse(part)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// RegisterRoutes registra las rutas del handler en el router
+func (h *RecommendationHandler) RegisterRoutes(router fiber.Router, authMiddleware fiber.Handler) {
+	router.Get("/products/recommended", authMiddleware, h.GetRecommendedProducts) // GET /products/recommended
+	router.Get("/products/:id/related", h.GetRelatedProducts)                     // GET /products/:id/related
+}
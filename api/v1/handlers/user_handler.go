@@ -201,6 +201,8 @@ func (h *UserHandler) GetUserByID(c *fiber.Ctx) error {
 // @Param page query int false "Página (por defecto: 1)"
 // @Param page_size query int false "Tamaño de página (por defecto: 10, máximo: 100)"
 // @Param role query string false "Filtrar por rol (CLIENT, REPARTIDOR, ADMIN)"
+// @Param cursor query string false "Cursor opaco (vacío para la primera página); activa la paginación por cursor"
+// @Param limit query int false "Cantidad por página en modo cursor (por defecto: 20, máximo: 100)"
 // @Success 200 {object} services.PaginatedUsersResponse
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
@@ -227,6 +229,20 @@ func (h *UserHandler) GetAllUsersAdmin(c *fiber.Ctx) error {
 		}
 	}
 
+	// Paginación por cursor: devuelve models.CursorPage
+	if cursor, limit, ok := cursorQuery(c); ok {
+		result, err := h.userService.GetUsersWithCursor(cursor, limit, roleFilter)
+		if err != nil {
+			if err == services.ErrInvalidCursor {
+				return invalidCursorResponse(c)
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Error al obtener los usuarios",
+			})
+		}
+		return c.JSON(result)
+	}
+
 	// Obtener usuarios con paginación
 	result, err := h.userService.GetUsersWithPagination(page, pageSize, roleFilter)
 	if err != nil {
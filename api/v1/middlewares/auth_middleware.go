@@ -57,6 +57,20 @@ func AuthMiddleware(authService auth.Service) fiber.Handler {
 	}
 }
 
+// OptionalAuthMiddleware identifica al usuario si la petición trae un token válido y, si no, la
+// deja continuar como anónima. No consulta la base de datos: solo sirve para reconocer al visitante.
+func OptionalAuthMiddleware(authService auth.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		parts := strings.Split(c.Get("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if claims, err := authService.ValidateToken(parts[1]); err == nil {
+				c.Locals("user", claims)
+			}
+		}
+		return c.Next()
+	}
+}
+
 // RequireRole verifica que el usuario tenga al menos uno de los roles especificados
 func RequireRole(roles ...models.UserRole) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -0,0 +1,57 @@
+package middlewares
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLimit rechaza con 413 las peticiones cuyo cuerpo supera limit bytes. La aplicación usa
+// StreamRequestBody: Fiber solo guarda en memoria hasta su BodyLimit y el resto del cuerpo queda
+// sin leer, así que este middleware lee como máximo limit bytes y corta la conexión si hay más.
+// skip indica las peticiones que leen su cuerpo con otro límite (ver ReadBody).
+func BodyLimit(limit int, skip func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if skip != nil && skip(c) {
+			err := c.Next()
+			// Si la ruta no leyó el cuerpo (por ejemplo, sin autenticación), lo que queda en la conexión
+			// no es la siguiente petición: se cierra después de responder
+			if c.Request().IsBodyStream() {
+				c.Context().SetConnectionClose()
+			}
+			return err
+		}
+		if err := ReadBody(c, limit); err != nil {
+			c.Context().SetConnectionClose()
+			if !errors.Is(err, fiber.ErrRequestEntityTooLarge) {
+				return err
+			}
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error": "El cuerpo de la petición es demasiado grande",
+			})
+		}
+		return c.Next()
+	}
+}
+
+// ReadBody carga en memoria el cuerpo de la petición leyendo como máximo limit bytes, de modo que
+// c.Body() ya no lea del stream. Devuelve fiber.ErrRequestEntityTooLarge si el cuerpo es mayor.
+func ReadBody(c *fiber.Ctx, limit int) error {
+	if c.Request().Header.ContentLength() > limit {
+		return fiber.ErrRequestEntityTooLarge
+	}
+	if !c.Request().IsBodyStream() {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Context().RequestBodyStream(), int64(limit)+1))
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	if len(body) > limit {
+		return fiber.ErrRequestEntityTooLarge
+	}
+	c.Request().SetBody(body)
+	return nil
+}
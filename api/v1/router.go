@@ -11,12 +11,13 @@ import (
 )
 
 // SetupRoutes configura todas las rutas de la API v1
-func SetupRoutes(app *fiber.App, authService auth.Service, userService *services.UserService, productService *services.ProductService, categoryService *services.CategoryService, orderService *services.OrderService, productRatingService *services.ProductRatingService, favoriteService *services.FavoriteService, offerService services.OfferService) {
+func SetupRoutes(app *fiber.App, authService auth.Service, userService *services.UserService, productService *services.ProductService, categoryService *services.CategoryService, orderService *services.OrderService, productRatingService *services.ProductRatingService, favoriteService *services.FavoriteService, offerService services.OfferService, cashService *services.CashService, earningsService *services.EarningsService, deliveryRatingService *services.DeliveryRatingService, chatService *services.ChatService, addressService *services.AddressService, cylinderService *services.CylinderService, analyticsService *services.AnalyticsService, performanceService *services.PerformanceService, forecastService *services.ForecastService, businessCalendarService *services.BusinessCalendarService, branchService *services.BranchService, productImageService *services.ProductImageService, inventoryService *services.InventoryService, productPriceService *services.ProductPriceService, popularityService *services.PopularityService, recommendationService *services.RecommendationService, productViewService *services.ProductViewService) {
 	// Crear grupo de rutas para API v1
 	api := app.Group("/api/v1")
 
 	// Middlewares de autenticación
 	authMiddleware := middlewares.AuthMiddleware(authService)
+	optionalAuth := middlewares.OptionalAuthMiddleware(authService)
 	adminOnly := middlewares.RequireRole(models.UserRoleAdmin)
 	repartidorOrAdmin := middlewares.RequireRole(models.UserRoleRepartidor, models.UserRoleAdmin)
 
@@ -36,6 +37,18 @@ func SetupRoutes(app *fiber.App, authService auth.Service, userService *services
 	offerHandler := handlers.NewOfferHandler(offerService)
 	setupOfferRoutes(api, offerHandler, authMiddleware, adminOnly)
 
+	// Rutas de imágenes de productos (DEBE ir ANTES que las rutas de productos para evitar conflictos)
+	productImageHandler := handlers.NewProductImageHandler(productImageService)
+	productImageHandler.RegisterRoutes(api, authMiddleware, adminOnly)
+
+	// Rutas de recomendaciones de productos (DEBE ir ANTES que las rutas de productos para evitar conflictos)
+	recommendationHandler := handlers.NewRecommendationHandler(recommendationService)
+	recommendationHandler.RegisterRoutes(api, authMiddleware)
+
+	// Ruta de vistas de productos con límite por IP (DEBE ir ANTES que las rutas de productos para evitar conflictos)
+	productViewHandler := handlers.NewProductViewHandler(productViewService)
+	productViewHandler.RegisterRoutes(api, optionalAuth)
+
 	// Rutas de productos (DEBE ir DESPUÉS de las ofertas para evitar conflictos)
 	productHandler := handlers.NewProductHandler(productService)
 	productHandler.RegisterRoutes(api, authMiddleware, adminOnly)
@@ -45,13 +58,69 @@ func SetupRoutes(app *fiber.App, authService auth.Service, userService *services
 	categoryHandler.RegisterRoutes(api, authMiddleware, adminOnly)
 
 	// Rutas de pedidos
-	orderHandler := handlers.NewOrderHandler(orderService, authService)
+	orderHandler := handlers.NewOrderHandler(orderService, authService, addressService)
 	orderHandler.RegisterRoutes(api, authMiddleware, adminOnly, repartidorOrAdmin)
 
 	// Rutas de favoritos
 	favoriteHandler := handlers.NewFavoriteHandler(favoriteService)
 	favoriteHandler.RegisterRoutes(api, authMiddleware, adminOnly)
 
+	// Rutas de caja de repartidores
+	cashHandler := handlers.NewCashHandler(cashService)
+	cashHandler.RegisterRoutes(api, authMiddleware, adminOnly, repartidorOrAdmin)
+
+	// Rutas de propinas y ganancias de repartidores
+	earningsHandler := handlers.NewEarningsHandler(earningsService)
+	earningsHandler.RegisterRoutes(api, authMiddleware, adminOnly, repartidorOrAdmin)
+
+	// Rutas de calificación de entregas
+	deliveryRatingHandler := handlers.NewDeliveryRatingHandler(deliveryRatingService)
+	deliveryRatingHandler.RegisterRoutes(api, authMiddleware, adminOnly)
+
+	// Rutas del chat de pedidos (historial y alternativa REST al WebSocket)
+	chatHandler := handlers.NewChatHandler(chatService)
+	chatHandler.RegisterRoutes(api, authMiddleware)
+
+	// Rutas de la libreta de direcciones
+	addressHandler := handlers.NewAddressHandler(addressService)
+	addressHandler.RegisterRoutes(api, authMiddleware)
+
+	// Rutas de conciliación de balones retornables
+	cylinderHandler := handlers.NewCylinderHandler(cylinderService)
+	cylinderHandler.RegisterRoutes(api, authMiddleware, adminOnly)
+
+	// Rutas de analítica de ventas (solo administradores)
+	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	analyticsHandler.RegisterRoutes(api, authMiddleware, adminOnly)
+
+	// Rutas de desempeño de repartidores
+	performanceHandler := handlers.NewPerformanceHandler(performanceService)
+	performanceHandler.RegisterRoutes(api, authMiddleware, adminOnly, repartidorOrAdmin)
+
+	// Rutas de pronóstico de demanda (solo administradores)
+	forecastHandler := handlers.NewForecastHandler(forecastService)
+	forecastHandler.RegisterRoutes(api, authMiddleware, adminOnly)
+
+	// Rutas del horario de atención (consulta pública, edición solo administradores)
+	businessCalendarHandler := handlers.NewBusinessCalendarHandler(businessCalendarService)
+	businessCalendarHandler.RegisterRoutes(api, authMiddleware, adminOnly)
+
+	// Rutas de sucursales (listado público, gestión solo administradores)
+	branchHandler := handlers.NewBranchHandler(branchService)
+	branchHandler.RegisterRoutes(api, authMiddleware, adminOnly)
+
+	// Rutas de inventario: libro de movimientos de stock y stock bajo (solo administradores)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+	inventoryHandler.RegisterRoutes(api, authMiddleware, adminOnly)
+
+	// Rutas de historial de precios y precios programados (solo administradores)
+	productPriceHandler := handlers.NewProductPriceHandler(productPriceService)
+	productPriceHandler.RegisterRoutes(api, authMiddleware, adminOnly)
+
+	// Ruta del desglose de popularidad de productos (solo administradores)
+	popularityHandler := handlers.NewPopularityHandler(popularityService)
+	popularityHandler.RegisterRoutes(api, authMiddleware, adminOnly)
+
 	// Ruta de salud
 	api.Get("/health", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
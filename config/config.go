@@ -16,11 +16,15 @@ import (
 
 // Config contiene toda la configuración de la aplicación
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Firebase FirebaseConfig
-	App      AppConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	JWT        JWTConfig
+	Firebase   FirebaseConfig
+	App        AppConfig
+	Storage    StorageConfig
+	Jobs       JobsConfig
+	Popularity PopularityConfig
+	Views      ViewsConfig
 }
 
 // ServerConfig contiene la configuración del servidor HTTP
@@ -31,6 +35,8 @@ type ServerConfig struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	IdleTimeout     time.Duration
+	ProxyHeader     string   // Cabecera con la IP del cliente detrás de un proxy (ej: X-Forwarded-For); vacío = IP de la conexión
+	TrustedProxies  []string // IPs o rangos CIDR de los proxies cuya ProxyHeader se acepta; sin ellos se usa la IP de la conexión
 }
 
 // DatabaseConfig contiene la configuración de la base de datos
@@ -63,6 +69,51 @@ type AppConfig struct {
 	TimeZone           string        // Zona horaria para el horario de atención
 }
 
+// StorageConfig contiene la configuración del almacenamiento de archivos (imágenes de productos)
+type StorageConfig struct {
+	Driver         string // "local" (por defecto) o "s3"
+	LocalDir       string // Directorio de los archivos con el driver local
+	PublicURL      string // Ruta o URL pública desde la que se sirven los archivos
+	MaxUploadBytes int64  // Tamaño máximo de cada imagen subida
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3PathStyle    bool // Requerido por MinIO y otros servicios sin subdominio por bucket
+}
+
+// LocalPublicPath es la ruta HTTP desde la que se sirven los archivos locales
+func (c *StorageConfig) LocalPublicPath() string {
+	if parsed, err := url.Parse(c.PublicURL); err == nil && parsed.Path != "" {
+		return parsed.Path
+	}
+	return "/uploads"
+}
+
+// JobsConfig contiene la frecuencia de las tareas en segundo plano (0 = desactivada)
+type JobsConfig struct {
+	ScheduledPriceInterval time.Duration // Aplicación de cambios de precio programados
+	PopularityInterval     time.Duration // Recálculo de la popularidad de los productos
+	AssociationsInterval   time.Duration // Recálculo de los productos comprados juntos
+	ViewFlushInterval      time.Duration // Guardado por lotes de las vistas de productos
+}
+
+// PopularityConfig contiene los pesos de la puntuación de popularidad de los productos
+type PopularityConfig struct {
+	HalfLife            time.Duration // Tiempo en que una compra o vista pierde la mitad de su peso
+	PurchaseWeight      float64
+	ViewWeight          float64
+	RatingAverageWeight float64
+	RatingCountWeight   float64
+}
+
+// ViewsConfig contiene las reglas para contar las vistas de productos
+type ViewsConfig struct {
+	DedupWindow time.Duration // Un mismo visitante cuenta una vista por producto en este periodo
+	RateLimit   int           // Vistas por minuto aceptadas de cada IP (0 = sin límite)
+}
+
 // parseDuration parsea duraciones incluyendo días (ej: "7d")
 func parseDuration(env string) (time.Duration, error) {
 	log.Printf("🔍 DEBUG parseDuration: input='%s'", env)
@@ -123,6 +174,8 @@ func LoadConfig() (*Config, error) {
 			ReadTimeout:     viper.GetDuration("SERVER_READ_TIMEOUT"),
 			WriteTimeout:    viper.GetDuration("SERVER_WRITE_TIMEOUT"),
 			IdleTimeout:     viper.GetDuration("SERVER_IDLE_TIMEOUT"),
+			ProxyHeader:     viper.GetString("SERVER_PROXY_HEADER"),
+			TrustedProxies:  splitList(viper.GetString("SERVER_TRUSTED_PROXIES")),
 		},
 		Database: DatabaseConfig{
 			Host:     viper.GetString("DB_HOST"),
@@ -156,11 +209,51 @@ func LoadConfig() (*Config, error) {
 			BusinessHoursEnd:   viper.GetDuration("APP_BUSINESS_HOURS_END"),
 			TimeZone:           viper.GetString("APP_TIMEZONE"),
 		},
+		Storage: StorageConfig{
+			Driver:         viper.GetString("STORAGE_DRIVER"),
+			LocalDir:       viper.GetString("STORAGE_LOCAL_DIR"),
+			PublicURL:      viper.GetString("STORAGE_PUBLIC_URL"),
+			MaxUploadBytes: viper.GetInt64("STORAGE_MAX_UPLOAD_MB") << 20,
+			S3Endpoint:     viper.GetString("S3_ENDPOINT"),
+			S3Region:       viper.GetString("S3_REGION"),
+			S3Bucket:       viper.GetString("S3_BUCKET"),
+			S3AccessKey:    viper.GetString("S3_ACCESS_KEY"),
+			S3SecretKey:    viper.GetString("S3_SECRET_KEY"),
+			S3PathStyle:    viper.GetBool("S3_PATH_STYLE"),
+		},
+		Jobs: JobsConfig{
+			ScheduledPriceInterval: viper.GetDuration("JOBS_SCHEDULED_PRICE_INTERVAL"),
+			PopularityInterval:     viper.GetDuration("JOBS_POPULARITY_INTERVAL"),
+			AssociationsInterval:   viper.GetDuration("JOBS_ASSOCIATIONS_INTERVAL"),
+			ViewFlushInterval:      viper.GetDuration("JOBS_VIEW_FLUSH_INTERVAL"),
+		},
+		Popularity: PopularityConfig{
+			HalfLife:            viper.GetDuration("POPULARITY_HALF_LIFE"),
+			PurchaseWeight:      viper.GetFloat64("POPULARITY_PURCHASE_WEIGHT"),
+			ViewWeight:          viper.GetFloat64("POPULARITY_VIEW_WEIGHT"),
+			RatingAverageWeight: viper.GetFloat64("POPULARITY_RATING_AVERAGE_WEIGHT"),
+			RatingCountWeight:   viper.GetFloat64("POPULARITY_RATING_COUNT_WEIGHT"),
+		},
+		Views: ViewsConfig{
+			DedupWindow: viper.GetDuration("VIEWS_DEDUP_WINDOW"),
+			RateLimit:   viper.GetInt("VIEWS_RATE_LIMIT"),
+		},
 	}
 
 	return cfg, nil
 }
 
+// splitList separa una lista de valores separados por comas, sin espacios ni elementos vacíos
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // setDefaults establece valores por defecto para la configuración
 func setDefaults() {
 	// Servidor
@@ -193,6 +286,30 @@ func setDefaults() {
 	viper.SetDefault("APP_BUSINESS_HOURS_START", "6h") // 6:00 AM
 	viper.SetDefault("APP_BUSINESS_HOURS_END", "20h")  // 8:00 PM
 	viper.SetDefault("APP_TIMEZONE", "America/Lima")   // Zona horaria de Perú
+
+	// Almacenamiento de imágenes
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_LOCAL_DIR", "uploads")
+	viper.SetDefault("STORAGE_PUBLIC_URL", "/uploads")
+	viper.SetDefault("STORAGE_MAX_UPLOAD_MB", 5)
+	viper.SetDefault("S3_REGION", "us-east-1")
+
+	// Tareas en segundo plano
+	viper.SetDefault("JOBS_SCHEDULED_PRICE_INTERVAL", "1m")
+	viper.SetDefault("JOBS_POPULARITY_INTERVAL", "15m")
+	viper.SetDefault("JOBS_ASSOCIATIONS_INTERVAL", "1h")
+	viper.SetDefault("JOBS_VIEW_FLUSH_INTERVAL", "30s")
+
+	// Popularidad de productos (los pesos por defecto son los de la fórmula anterior)
+	viper.SetDefault("POPULARITY_HALF_LIFE", "168h") // 7 días
+	viper.SetDefault("POPULARITY_PURCHASE_WEIGHT", 3.0)
+	viper.SetDefault("POPULARITY_VIEW_WEIGHT", 0.1)
+	viper.SetDefault("POPULARITY_RATING_AVERAGE_WEIGHT", 2.0)
+	viper.SetDefault("POPULARITY_RATING_COUNT_WEIGHT", 1.0)
+
+	// Vistas de productos
+	viper.SetDefault("VIEWS_DEDUP_WINDOW", "30m")
+	viper.SetDefault("VIEWS_RATE_LIMIT", 60)
 }
 
 // parseAndSetDatabaseURL parsea una URL de base de datos completa y establece las variables individuales
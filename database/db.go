@@ -108,7 +108,7 @@ func MigrateSchema(db *gorm.DB) error {
 	}
 
 	// Luego migrar tablas con relaciones
-	err = db.AutoMigrate(&models.Order{}, &models.OrderItem{}, &models.UserFavorite{})
+	err = db.AutoMigrate(&models.Order{}, &models.OrderItem{}, &models.UserFavorite{}, &models.CashSettlement{}, &models.DeliveryRating{}, &models.OrderMessage{}, &models.UserAddress{}, &models.BusinessHours{}, &models.SpecialDay{}, &models.Branch{}, &models.BranchStock{}, &models.ProductVariant{}, &models.ProductImage{}, &models.StockMovement{}, &models.ProductPriceHistory{}, &models.ScheduledPriceChange{}, &models.ProductBundleItem{}, &models.OrderItemComponent{}, &models.ProductViewDaily{}, &models.ProductAssociation{})
 	if err != nil {
 		return fmt.Errorf("error al migrar tablas con relaciones: %w", err)
 	}
@@ -135,22 +135,28 @@ func SeedInitialData(db *gorm.DB) error {
 	// Productos de ejemplo
 	products := []models.Product{
 		{
-			Name:        "Balón de Gas 10kg",
-			Description: "Balón de gas doméstico de 10 kilogramos, para cocina y uso general",
-			Price:       50.00,
-			IsActive:    true,
+			Name:          "Balón de Gas 10kg",
+			Description:   "Balón de gas doméstico de 10 kilogramos, para cocina y uso general",
+			Price:         50.00,
+			IsActive:      true,
+			IsReturnable:  true,
+			DepositAmount: 80.00,
 		},
 		{
-			Name:        "Balón de Gas 5kg",
-			Description: "Balón de gas doméstico de 5 kilogramos, ideal para uso ocasional o espacios reducidos",
-			Price:       30.00,
-			IsActive:    true,
+			Name:          "Balón de Gas 5kg",
+			Description:   "Balón de gas doméstico de 5 kilogramos, ideal para uso ocasional o espacios reducidos",
+			Price:         30.00,
+			IsActive:      true,
+			IsReturnable:  true,
+			DepositAmount: 60.00,
 		},
 		{
-			Name:        "Balón de Gas 15kg",
-			Description: "Balón de gas doméstico de 15 kilogramos, para uso intensivo o comercios pequeños",
-			Price:       70.00,
-			IsActive:    true,
+			Name:          "Balón de Gas 15kg",
+			Description:   "Balón de gas doméstico de 15 kilogramos, para uso intensivo o comercios pequeños",
+			Price:         70.00,
+			IsActive:      true,
+			IsReturnable:  true,
+			DepositAmount: 100.00,
 		},
 	}
 
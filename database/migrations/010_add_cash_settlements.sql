@@ -0,0 +1,34 @@
+-- Migration: 010_add_cash_settlements.sql
+-- Description: Método de pago en pedidos y registro de entregas de efectivo de repartidores
+-- Author: Sistema de Caja
+
+-- Método de pago del pedido (los pedidos existentes se consideran en efectivo)
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_method VARCHAR(20) NOT NULL DEFAULT 'CASH';
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_payment_method_check;
+ALTER TABLE orders ADD CONSTRAINT orders_payment_method_check
+    CHECK (payment_method IN ('CASH', 'YAPE', 'PLIN', 'CARD'));
+
+-- Entregas de efectivo registradas por un administrador al cierre de turno
+CREATE TABLE IF NOT EXISTS cash_settlements (
+    settlement_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    repartidor_id UUID NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    business_date DATE NOT NULL,
+    expected_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    handed_in_amount DECIMAL(10,2) NOT NULL CHECK (handed_in_amount >= 0),
+    notes TEXT,
+    recorded_by UUID NOT NULL REFERENCES users(user_id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Índices para las consultas de caja por jornada
+CREATE INDEX IF NOT EXISTS idx_cash_settlements_repartidor_date ON cash_settlements(repartidor_id, business_date);
+CREATE INDEX IF NOT EXISTS idx_cash_settlements_business_date ON cash_settlements(business_date);
+CREATE INDEX IF NOT EXISTS idx_orders_repartidor_delivered ON orders(assigned_repartidor_id, delivered_at)
+    WHERE order_status = 'DELIVERED';
+
+-- Comentarios para documentación
+COMMENT ON TABLE cash_settlements IS 'Entregas de efectivo de los repartidores al cierre de turno';
+COMMENT ON COLUMN cash_settlements.business_date IS 'Jornada (zona horaria del negocio) a la que corresponde la entrega';
+COMMENT ON COLUMN cash_settlements.expected_amount IS 'Efectivo esperado al momento del registro';
+COMMENT ON COLUMN cash_settlements.handed_in_amount IS 'Efectivo realmente entregado por el repartidor';
+COMMENT ON COLUMN orders.payment_method IS 'Método de pago: CASH, YAPE, PLIN o CARD';
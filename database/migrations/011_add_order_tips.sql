@@ -0,0 +1,17 @@
+-- Migration: 011_add_order_tips.sql
+-- Description: Propinas para repartidores, separadas del total del pedido
+-- Author: Sistema de Propinas
+
+-- La propina no forma parte de total_amount (facturación)
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tip_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tip_updated_at TIMESTAMPTZ;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_tip_amount_check;
+ALTER TABLE orders ADD CONSTRAINT orders_tip_amount_check CHECK (tip_amount >= 0);
+
+-- Índice para los reportes de propinas por repartidor
+CREATE INDEX IF NOT EXISTS idx_orders_tips_by_repartidor ON orders(assigned_repartidor_id, delivered_at)
+    WHERE order_status = 'DELIVERED' AND tip_amount > 0;
+
+-- Comentarios para documentación
+COMMENT ON COLUMN orders.tip_amount IS 'Propina para el repartidor asignado (no incluida en total_amount)';
+COMMENT ON COLUMN orders.tip_updated_at IS 'Última vez que el cliente registró o cambió la propina';
@@ -0,0 +1,23 @@
+-- Migration: 012_add_delivery_ratings.sql
+-- Description: Calificación de la experiencia de entrega (una por pedido) y puntaje del repartidor
+-- Author: Sistema de Calificaciones de Entrega
+
+CREATE TABLE IF NOT EXISTS delivery_ratings (
+    delivery_rating_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(order_id) ON DELETE CASCADE,
+    repartidor_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    client_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    rating INTEGER NOT NULL CHECK (rating >= 1 AND rating <= 5),
+    tags JSONB NOT NULL DEFAULT '[]',
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Índices para los puntajes agregados por repartidor
+CREATE INDEX IF NOT EXISTS idx_delivery_ratings_repartidor_id ON delivery_ratings(repartidor_id);
+CREATE INDEX IF NOT EXISTS idx_delivery_ratings_client_id ON delivery_ratings(client_id);
+
+-- Comentarios para documentación
+COMMENT ON TABLE delivery_ratings IS 'Calificación del cliente sobre la entrega de un pedido';
+COMMENT ON COLUMN delivery_ratings.repartidor_id IS 'Repartidor asignado al momento de la entrega';
+COMMENT ON COLUMN delivery_ratings.tags IS 'Etiquetas: late, on_time, friendly, rude, damaged_cylinder, careful_handling, good_communication';
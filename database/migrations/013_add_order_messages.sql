@@ -0,0 +1,21 @@
+-- Migration: 013_add_order_messages.sql
+-- Description: Chat por pedido entre cliente, repartidor asignado y administradores
+-- Author: Sistema de Chat
+
+CREATE TABLE IF NOT EXISTS order_messages (
+    message_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(order_id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    sender_role VARCHAR(20) NOT NULL,
+    content TEXT NOT NULL CHECK (char_length(content) BETWEEN 1 AND 1000),
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Índices para el historial y los acuses de lectura
+CREATE INDEX IF NOT EXISTS idx_order_messages_order_created ON order_messages(order_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_order_messages_unread ON order_messages(order_id) WHERE read_at IS NULL;
+
+-- Comentarios para documentación
+COMMENT ON TABLE order_messages IS 'Mensajes del chat de un pedido (solo mientras está ASSIGNED o IN_TRANSIT)';
+COMMENT ON COLUMN order_messages.read_at IS 'Momento en que otro participante leyó el mensaje';
@@ -0,0 +1,31 @@
+-- Migration: 014_add_user_addresses.sql
+-- Description: Libreta de direcciones de los usuarios y copia de la dirección en el pedido
+-- Author: Sistema de Direcciones
+
+CREATE TABLE IF NOT EXISTS user_addresses (
+    address_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    label VARCHAR(50) NOT NULL,
+    address_text TEXT NOT NULL,
+    latitude NUMERIC(9,6) NOT NULL,
+    longitude NUMERIC(9,6) NOT NULL,
+    reference TEXT,
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_addresses_user_id ON user_addresses(user_id);
+
+-- Solo una dirección predeterminada por usuario
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_addresses_one_default ON user_addresses(user_id) WHERE is_default;
+
+-- El pedido guarda una copia de la dirección; address_id es solo informativo
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS address_id UUID REFERENCES user_addresses(address_id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_reference TEXT;
+
+-- Comentarios para documentación
+COMMENT ON TABLE user_addresses IS 'Direcciones guardadas de los usuarios (Casa, Trabajo, etc.)';
+COMMENT ON COLUMN user_addresses.reference IS 'Referencias para el repartidor';
+COMMENT ON COLUMN orders.address_id IS 'Dirección guardada usada al crear el pedido (los datos se copian al pedido)';
+COMMENT ON COLUMN orders.delivery_reference IS 'Referencias para el repartidor al momento del pedido';
@@ -0,0 +1,36 @@
+-- Migration: 015_add_cylinder_exchange.sql
+-- Description: Intercambio de balones (lleno por vacío), garantías y conciliación diaria de balones
+-- Author: Sistema de Balones
+
+-- Productos retornables y su garantía
+ALTER TABLE products ADD COLUMN IF NOT EXISTS is_returnable BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deposit_amount DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (deposit_amount >= 0);
+
+-- Vacíos recibidos y garantía cobrada por ítem
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS cylinders_returned INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS deposit_charge DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS chk_order_items_cylinders_returned;
+ALTER TABLE order_items ADD CONSTRAINT chk_order_items_cylinders_returned
+    CHECK (cylinders_returned >= 0 AND cylinders_returned <= quantity);
+
+-- Total de garantías del pedido (incluido en total_amount) y confirmación del repartidor
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS deposit_total DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cylinders_confirmed_at TIMESTAMP WITH TIME ZONE;
+
+-- Los balones de gas existentes se marcan como retornables
+UPDATE products SET is_returnable = true, deposit_amount = 60.00 WHERE name ILIKE 'Balón de Gas 5kg%';
+UPDATE products SET is_returnable = true, deposit_amount = 80.00 WHERE name ILIKE 'Balón de Gas 10kg%';
+UPDATE products SET is_returnable = true, deposit_amount = 100.00 WHERE name ILIKE 'Balón de Gas 15kg%';
+
+-- Los pedidos anteriores se consideran intercambios completos
+UPDATE order_items oi SET cylinders_returned = oi.quantity
+FROM products p
+WHERE p.product_id = oi.product_id AND p.is_returnable;
+
+-- Comentarios para documentación
+COMMENT ON COLUMN products.is_returnable IS 'El producto se entrega a cambio de un envase vacío';
+COMMENT ON COLUMN products.deposit_amount IS 'Garantía cobrada por cada envase no devuelto';
+COMMENT ON COLUMN order_items.cylinders_returned IS 'Balones vacíos entregados por el cliente';
+COMMENT ON COLUMN order_items.deposit_charge IS 'Garantía cobrada por los balones no devueltos';
+COMMENT ON COLUMN orders.deposit_total IS 'Suma de garantías del pedido (incluida en total_amount)';
+COMMENT ON COLUMN orders.cylinders_confirmed_at IS 'Momento en que el repartidor confirmó los vacíos recibidos';
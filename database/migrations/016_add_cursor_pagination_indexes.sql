@@ -0,0 +1,18 @@
+-- Migration: 016_add_cursor_pagination_indexes.sql
+-- Description: Índices para la paginación por cursor ordenada por (fecha, id)
+-- Author: Sistema de Paginación
+
+-- Pedidos: (order_time, order_id) descendente
+CREATE INDEX IF NOT EXISTS idx_orders_cursor ON orders (order_time DESC, order_id DESC);
+
+-- Productos: (created_at, product_id) descendente
+CREATE INDEX IF NOT EXISTS idx_products_cursor ON products (created_at DESC, product_id DESC);
+
+-- Favoritos de un usuario: (created_at, product_id) descendente
+CREATE INDEX IF NOT EXISTS idx_user_favorites_cursor ON user_favorites (user_id, created_at DESC, product_id DESC);
+
+-- Calificaciones de un producto: (created_at, rating_id) descendente
+CREATE INDEX IF NOT EXISTS idx_product_ratings_cursor ON product_ratings (product_id, created_at DESC, rating_id DESC);
+
+-- Usuarios: (created_at, user_id) descendente
+CREATE INDEX IF NOT EXISTS idx_users_cursor ON users (created_at DESC, user_id DESC);
@@ -0,0 +1,13 @@
+-- Migration: 017_add_order_in_transit_at.sql
+-- Description: Momento en que el pedido sale en camino, para medir el desempeño de los repartidores
+-- Author: Sistema de Desempeño
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS in_transit_at TIMESTAMP WITH TIME ZONE;
+
+-- Los pedidos anteriores no tienen este dato; sus tiempos entre estados no se consideran en los promedios
+
+-- Índice para el ranking por repartidor y período de asignación
+CREATE INDEX IF NOT EXISTS idx_orders_repartidor_assigned_at ON orders (assigned_repartidor_id, assigned_at);
+
+-- Comentarios para documentación
+COMMENT ON COLUMN orders.in_transit_at IS 'Momento en que el pedido pasó a IN_TRANSIT';
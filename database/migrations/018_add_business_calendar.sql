@@ -0,0 +1,33 @@
+-- Migration: 018_add_business_calendar.sql
+-- Description: Calendario de atención administrable: horario por día de la semana, feriados y horarios especiales
+-- Author: Sistema de Horarios
+
+CREATE TABLE IF NOT EXISTS business_hours (
+    weekday SMALLINT PRIMARY KEY CHECK (weekday >= 0 AND weekday <= 6),
+    is_open BOOLEAN NOT NULL DEFAULT TRUE,
+    opens_at VARCHAR(5) NOT NULL DEFAULT '06:00',
+    closes_at VARCHAR(5) NOT NULL DEFAULT '20:00',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Sin filas, cada día usa APP_BUSINESS_HOURS_START/END; no se siembra para no ocultar esa configuración
+
+CREATE TABLE IF NOT EXISTS business_special_days (
+    special_day_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    date DATE NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    is_holiday BOOLEAN NOT NULL DEFAULT FALSE,
+    is_open BOOLEAN NOT NULL DEFAULT FALSE,
+    opens_at VARCHAR(5),
+    closes_at VARCHAR(5),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_special_day_hours CHECK (NOT is_open OR (opens_at IS NOT NULL AND closes_at IS NOT NULL))
+);
+
+-- Comentarios para documentación
+COMMENT ON TABLE business_hours IS 'Horario de atención por día de la semana (0 = domingo)';
+COMMENT ON COLUMN business_hours.opens_at IS 'Hora de apertura HH:MM en la zona horaria del negocio';
+COMMENT ON COLUMN business_hours.closes_at IS 'Hora de cierre HH:MM (24:00 = fin del día)';
+COMMENT ON TABLE business_special_days IS 'Feriados, cierres y horarios especiales que reemplazan al horario semanal';
+COMMENT ON COLUMN business_special_days.is_open IS 'FALSE = cerrado todo el día; TRUE = atiende en opens_at/closes_at';
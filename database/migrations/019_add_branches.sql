@@ -0,0 +1,70 @@
+-- Migration: 019_add_branches.sql
+-- Description: Sucursales con zona de reparto, stock por sucursal y enrutamiento de pedidos
+-- Author: Sistema de Sucursales
+
+CREATE TABLE IF NOT EXISTS branches (
+    branch_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    address_text TEXT NOT NULL,
+    phone_number VARCHAR(20),
+    latitude NUMERIC(9,6) NOT NULL,
+    longitude NUMERIC(9,6) NOT NULL,
+    service_radius_km DECIMAL(6,2) NOT NULL DEFAULT 5 CHECK (service_radius_km > 0),
+    opens_at VARCHAR(5),
+    closes_at VARCHAR(5),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS branch_stock (
+    branch_id UUID NOT NULL REFERENCES branches(branch_id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (branch_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_branch_stock_product ON branch_stock (product_id);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS branch_id UUID REFERENCES branches(branch_id);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS branch_id UUID REFERENCES branches(branch_id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_orders_branch_status ON orders (branch_id, order_status);
+
+-- No se crea una sucursal por defecto: sin sucursales activas los pedidos no se enrutan.
+-- Al crear la primera sucursal desde la API, el stock de cada producto se copia a ella.
+
+-- Al entregar un pedido se descuenta el stock de su sucursal además del total del producto
+CREATE OR REPLACE FUNCTION update_product_stock_on_sale()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.order_status = 'DELIVERED' AND OLD.order_status != 'DELIVERED' THEN
+        IF NEW.branch_id IS NOT NULL THEN
+            UPDATE branch_stock
+            SET quantity = GREATEST(branch_stock.quantity - oi.quantity, 0),
+                updated_at = NOW()
+            FROM order_items oi
+            WHERE branch_stock.product_id = oi.product_id
+            AND branch_stock.branch_id = NEW.branch_id
+            AND oi.order_id = NEW.order_id;
+        END IF;
+
+        UPDATE products
+        SET stock_quantity = stock_quantity - oi.quantity
+        FROM order_items oi
+        WHERE products.product_id = oi.product_id
+        AND oi.order_id = NEW.order_id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Comentarios para documentación
+COMMENT ON TABLE branches IS 'Sucursales o depósitos desde los que se despachan pedidos';
+COMMENT ON COLUMN branches.service_radius_km IS 'Radio de la zona de reparto en kilómetros';
+COMMENT ON COLUMN branches.opens_at IS 'Horario propio HH:MM; NULL = horario general del negocio';
+COMMENT ON TABLE branch_stock IS 'Stock por sucursal; products.stock_quantity es la suma de todas las sucursales';
+COMMENT ON COLUMN orders.branch_id IS 'Sucursal más cercana con stock que despacha el pedido';
+COMMENT ON COLUMN users.branch_id IS 'Sucursal del repartidor o administrador; NULL = todas';
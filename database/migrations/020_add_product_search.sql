@@ -0,0 +1,20 @@
+-- Migration: 020_add_product_search.sql
+-- Description: Búsqueda de texto completo de productos (configuración en español) sobre nombre y descripción
+-- Author: Sistema de Búsqueda
+
+-- El nombre pesa más que la descripción en la relevancia
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector TSVECTOR
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('spanish', COALESCE(name, '')), 'A') ||
+        setweight(to_tsvector('spanish', COALESCE(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
+
+-- Índices para los filtros y órdenes más usados en la búsqueda
+CREATE INDEX IF NOT EXISTS idx_products_active_popularity ON products (is_active, popularity_score DESC);
+CREATE INDEX IF NOT EXISTS idx_products_active_rating ON products (is_active, rating_average DESC);
+CREATE INDEX IF NOT EXISTS idx_product_offers_active_product ON product_offers (product_id, start_date DESC) WHERE is_active = TRUE;
+
+-- Comentarios para documentación
+COMMENT ON COLUMN products.search_vector IS 'Vector de búsqueda (nombre peso A, descripción peso B), calculado por la base de datos';
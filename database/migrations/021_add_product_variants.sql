@@ -0,0 +1,105 @@
+-- Migration: 021_add_product_variants.sql
+-- Description: Variantes de producto (tamaño, válvula, marca) con SKU, precio, stock y ofertas propias
+-- Author: Sistema de Productos
+
+CREATE TABLE IF NOT EXISTS product_variants (
+    variant_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(150) NOT NULL,
+    size VARCHAR(50),
+    valve_type VARCHAR(50),
+    brand VARCHAR(100),
+    price DECIMAL(10,2) NOT NULL CHECK (price > 0),
+    stock_quantity INTEGER NOT NULL DEFAULT 0 CHECK (stock_quantity >= 0),
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_variants_product ON product_variants (product_id, sort_order);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_default ON product_variants (product_id) WHERE is_default;
+
+-- Cada producto existente pasa a tener una variante predeterminada con su precio y stock
+INSERT INTO product_variants (product_id, sku, name, size, price, stock_quantity, is_default, is_active)
+SELECT p.product_id,
+       'SKU-' || UPPER(LEFT(p.product_id::text, 8)),
+       COALESCE(NULLIF(p.package_size, ''), p.name),
+       NULLIF(p.package_size, ''),
+       p.price,
+       GREATEST(p.stock_quantity, 0),
+       TRUE,
+       TRUE
+FROM products p
+WHERE p.price > 0
+AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.product_id);
+
+-- Los ítems de pedido guardan la variante y su SKU al momento de la compra
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS variant_id UUID REFERENCES product_variants(variant_id);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+
+UPDATE order_items oi
+SET variant_id = v.variant_id, sku = v.sku
+FROM product_variants v
+WHERE v.product_id = oi.product_id AND v.is_default
+AND oi.variant_id IS NULL;
+
+-- Una oferta puede aplicar a una sola variante; NULL = todas las variantes del producto
+ALTER TABLE product_offers ADD COLUMN IF NOT EXISTS variant_id UUID REFERENCES product_variants(variant_id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_product_offers_variant ON product_offers (variant_id) WHERE variant_id IS NOT NULL;
+
+-- Al entregar un pedido también se descuenta el stock de cada variante vendida.
+-- Las cantidades se agrupan porque un pedido puede traer varias variantes del mismo producto.
+CREATE OR REPLACE FUNCTION update_product_stock_on_sale()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.order_status = 'DELIVERED' AND OLD.order_status != 'DELIVERED' THEN
+        IF NEW.branch_id IS NOT NULL THEN
+            UPDATE branch_stock
+            SET quantity = GREATEST(branch_stock.quantity - sold.quantity, 0),
+                updated_at = NOW()
+            FROM (
+                SELECT product_id, SUM(quantity) AS quantity
+                FROM order_items
+                WHERE order_id = NEW.order_id
+                GROUP BY product_id
+            ) sold
+            WHERE branch_stock.product_id = sold.product_id
+            AND branch_stock.branch_id = NEW.branch_id;
+        END IF;
+
+        UPDATE product_variants
+        SET stock_quantity = GREATEST(product_variants.stock_quantity - sold.quantity, 0),
+            updated_at = NOW()
+        FROM (
+            SELECT variant_id, SUM(quantity) AS quantity
+            FROM order_items
+            WHERE order_id = NEW.order_id AND variant_id IS NOT NULL
+            GROUP BY variant_id
+        ) sold
+        WHERE product_variants.variant_id = sold.variant_id;
+
+        UPDATE products
+        SET stock_quantity = stock_quantity - sold.quantity
+        FROM (
+            SELECT product_id, SUM(quantity) AS quantity
+            FROM order_items
+            WHERE order_id = NEW.order_id
+            GROUP BY product_id
+        ) sold
+        WHERE products.product_id = sold.product_id;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Comentarios para documentación
+COMMENT ON TABLE product_variants IS 'Presentaciones vendibles de un producto con SKU, precio y stock propios';
+COMMENT ON COLUMN product_variants.is_default IS 'Variante usada cuando el pedido no indica una; su precio es el del producto';
+COMMENT ON COLUMN order_items.variant_id IS 'Variante comprada';
+COMMENT ON COLUMN order_items.sku IS 'SKU de la variante al momento de la compra';
+COMMENT ON COLUMN product_offers.variant_id IS 'Variante a la que aplica la oferta; NULL = todas las variantes';
@@ -0,0 +1,28 @@
+-- Migration: 022_add_product_images.sql
+-- Description: Imágenes subidas de productos con miniaturas y versiones WebP
+-- Author: Sistema de Productos
+
+CREATE TABLE IF NOT EXISTS product_images (
+    image_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    url TEXT NOT NULL,
+    medium_url TEXT,
+    medium_webp_url TEXT,
+    thumbnail_url TEXT,
+    thumbnail_webp_url TEXT,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    storage_keys TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product ON product_images (product_id, sort_order);
+
+-- Comentarios para documentación
+COMMENT ON TABLE product_images IS 'Imágenes subidas de productos; la primera según sort_order es la principal';
+COMMENT ON COLUMN product_images.url IS 'Archivo original tal como se subió';
+COMMENT ON COLUMN product_images.medium_url IS 'Versión mediana (800 px); se copia en products.image_url si es la imagen principal';
+COMMENT ON COLUMN product_images.storage_keys IS 'Claves de todos los archivos en el almacenamiento, una por línea';
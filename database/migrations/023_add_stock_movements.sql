@@ -0,0 +1,124 @@
+-- Migration: 023_add_stock_movements.sql
+-- Description: Libro de movimientos de stock (solo inserciones) y umbral de reposición por producto
+-- Author: Sistema de Inventario
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_threshold INTEGER NOT NULL DEFAULT 0 CHECK (reorder_threshold >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS low_stock_alerted_at TIMESTAMP WITH TIME ZONE;
+
+-- variant_id, branch_id y order_id no tienen clave foránea: el libro no debe cambiar si se borran
+CREATE TABLE IF NOT EXISTS stock_movements (
+    movement_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    variant_id UUID,
+    branch_id UUID,
+    order_id UUID,
+    movement_type VARCHAR(30) NOT NULL CHECK (movement_type IN ('PURCHASE_RECEIPT', 'SALE', 'CANCELLATION_RETURN', 'ADJUSTMENT', 'DAMAGE')),
+    quantity INTEGER NOT NULL CHECK (quantity <> 0),
+    balance_after INTEGER NOT NULL,
+    reason TEXT,
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product ON stock_movements (product_id, created_at DESC, movement_id DESC);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_order ON stock_movements (order_id) WHERE order_id IS NOT NULL;
+
+-- El libro solo admite inserciones. Se permite borrar los movimientos de un producto eliminado
+-- (borrado en cascada), nunca modificarlos.
+CREATE OR REPLACE FUNCTION prevent_stock_movement_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND NOT EXISTS (SELECT 1 FROM products WHERE product_id = OLD.product_id) THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'stock_movements solo admite inserciones; registre un ajuste para corregir el stock';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_stock_movements_append_only ON stock_movements;
+CREATE TRIGGER trigger_stock_movements_append_only
+BEFORE UPDATE OR DELETE ON stock_movements
+FOR EACH ROW
+EXECUTE FUNCTION prevent_stock_movement_changes();
+
+-- Saldo inicial: el stock actual de cada producto abre su libro
+INSERT INTO stock_movements (product_id, movement_type, quantity, balance_after, reason)
+SELECT p.product_id, 'ADJUSTMENT', p.stock_quantity, p.stock_quantity, 'Stock inicial'
+FROM products p
+WHERE p.stock_quantity <> 0
+AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.product_id = p.product_id);
+
+-- La entrega de un pedido descuenta el stock y lo registra como venta en el libro.
+-- Si un pedido entregado pasa a cancelado, el stock se repone como reingreso por cancelación.
+CREATE OR REPLACE FUNCTION update_product_stock_on_sale()
+RETURNS TRIGGER AS $$
+DECLARE
+    direction INTEGER;
+    kind VARCHAR(30);
+BEGIN
+    IF NEW.order_status = 'DELIVERED' AND OLD.order_status != 'DELIVERED' THEN
+        direction := -1;
+        kind := 'SALE';
+    ELSIF NEW.order_status = 'CANCELLED' AND OLD.order_status = 'DELIVERED' THEN
+        direction := 1;
+        kind := 'CANCELLATION_RETURN';
+    ELSE
+        RETURN NEW;
+    END IF;
+
+    IF NEW.branch_id IS NOT NULL THEN
+        UPDATE branch_stock
+        SET quantity = GREATEST(branch_stock.quantity + direction * sold.quantity, 0),
+            updated_at = NOW()
+        FROM (
+            SELECT product_id, SUM(quantity) AS quantity
+            FROM order_items
+            WHERE order_id = NEW.order_id
+            GROUP BY product_id
+        ) sold
+        WHERE branch_stock.product_id = sold.product_id
+        AND branch_stock.branch_id = NEW.branch_id;
+    END IF;
+
+    UPDATE product_variants
+    SET stock_quantity = GREATEST(product_variants.stock_quantity + direction * sold.quantity, 0),
+        updated_at = NOW()
+    FROM (
+        SELECT variant_id, SUM(quantity) AS quantity
+        FROM order_items
+        WHERE order_id = NEW.order_id AND variant_id IS NOT NULL
+        GROUP BY variant_id
+    ) sold
+    WHERE product_variants.variant_id = sold.variant_id;
+
+    UPDATE products
+    SET stock_quantity = stock_quantity + direction * sold.quantity
+    FROM (
+        SELECT product_id, SUM(quantity) AS quantity
+        FROM order_items
+        WHERE order_id = NEW.order_id
+        GROUP BY product_id
+    ) sold
+    WHERE products.product_id = sold.product_id;
+
+    INSERT INTO stock_movements (product_id, branch_id, order_id, movement_type, quantity, balance_after)
+    SELECT sold.product_id, NEW.branch_id, NEW.order_id, kind, direction * sold.quantity, p.stock_quantity
+    FROM (
+        SELECT product_id, SUM(quantity) AS quantity
+        FROM order_items
+        WHERE order_id = NEW.order_id
+        GROUP BY product_id
+    ) sold
+    JOIN products p ON p.product_id = sold.product_id
+    WHERE sold.quantity <> 0;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Comentarios para documentación
+COMMENT ON TABLE stock_movements IS 'Libro de movimientos de stock; solo inserciones. La suma de quantity de un producto es su stock';
+COMMENT ON COLUMN stock_movements.quantity IS 'Variación de stock: positiva = ingreso, negativa = salida';
+COMMENT ON COLUMN stock_movements.balance_after IS 'Stock del producto después del movimiento';
+COMMENT ON COLUMN products.reorder_threshold IS 'Se avisa a los administradores cuando el stock baja de este valor (0 = sin aviso)';
+COMMENT ON COLUMN products.low_stock_alerted_at IS 'Momento del último aviso de stock bajo; se limpia al reponer';
@@ -0,0 +1,51 @@
+-- Migration: 024_add_price_history.sql
+-- Description: Historial de precios de productos y cambios de precio programados
+-- Author: Sistema de Productos
+
+CREATE TABLE IF NOT EXISTS product_price_history (
+    history_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    old_price DECIMAL(10,2),
+    new_price DECIMAL(10,2) NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('CREATED', 'MANUAL', 'VARIANT', 'IMPORT', 'SCHEDULED')),
+    scheduled_change_id UUID,
+    changed_by UUID,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_price_history_product ON product_price_history (product_id, changed_at DESC, history_id DESC);
+
+CREATE TABLE IF NOT EXISTS scheduled_price_changes (
+    schedule_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    new_price DECIMAL(10,2) NOT NULL CHECK (new_price > 0),
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'APPLIED', 'CANCELLED')),
+    note TEXT,
+    created_by UUID,
+    applied_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_price_changes_product ON scheduled_price_changes (product_id, effective_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_price_changes_due ON scheduled_price_changes (effective_at) WHERE status = 'PENDING';
+
+-- Un producto no puede tener dos cambios pendientes para el mismo instante
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scheduled_price_changes_pending_unique
+    ON scheduled_price_changes (product_id, effective_at) WHERE status = 'PENDING';
+
+-- Precio actual de los productos existentes como punto de partida del historial
+INSERT INTO product_price_history (product_id, old_price, new_price, source, changed_at)
+SELECT p.product_id, NULL, p.price, 'CREATED', p.created_at
+FROM products p
+WHERE NOT EXISTS (
+    SELECT 1 FROM product_price_history h WHERE h.product_id = p.product_id
+);
+
+-- Comentarios para documentación
+COMMENT ON TABLE product_price_history IS 'Cada cambio de products.price, del precio inicial en adelante';
+COMMENT ON COLUMN product_price_history.source IS 'CREATED, MANUAL (edición), VARIANT (variante predeterminada), IMPORT (CSV) o SCHEDULED (cambio programado)';
+COMMENT ON COLUMN product_price_history.scheduled_change_id IS 'Cambio programado que originó el registro, si corresponde';
+COMMENT ON TABLE scheduled_price_changes IS 'Cambios de precio futuros; una tarea en segundo plano los aplica desde effective_at';
+COMMENT ON COLUMN scheduled_price_changes.status IS 'PENDING hasta que se aplica (APPLIED) o se cancela (CANCELLED)';
@@ -0,0 +1,151 @@
+-- Migration: 025_add_product_bundles.sql
+-- Description: Combos de productos con stock calculado a partir de sus componentes
+-- Author: Sistema de Productos
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS is_bundle BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS product_bundle_items (
+    bundle_item_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    bundle_id UUID NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    component_id UUID NOT NULL REFERENCES products(product_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_product_bundle_items_component UNIQUE (bundle_id, component_id),
+    CONSTRAINT chk_product_bundle_items_not_self CHECK (bundle_id <> component_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_bundle_items_bundle ON product_bundle_items (bundle_id, sort_order);
+CREATE INDEX IF NOT EXISTS idx_product_bundle_items_component ON product_bundle_items (component_id);
+
+-- Desglose de los combos vendidos; product_id y variant_id sin clave foránea, como order_items
+CREATE TABLE IF NOT EXISTS order_item_components (
+    order_item_component_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_item_id UUID NOT NULL REFERENCES order_items(order_item_id) ON DELETE CASCADE,
+    product_id UUID NOT NULL,
+    variant_id UUID,
+    sku VARCHAR(64),
+    name VARCHAR(255) NOT NULL,
+    units_per_bundle INTEGER NOT NULL CHECK (units_per_bundle > 0),
+    quantity INTEGER NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_item_components_item ON order_item_components (order_item_id);
+
+-- Unidades que descuenta un pedido: los ítems con desglose (combos) cuentan por sus componentes
+CREATE OR REPLACE FUNCTION order_stock_lines(p_order_id UUID)
+RETURNS TABLE (product_id UUID, variant_id UUID, quantity INTEGER) AS $$
+    SELECT oi.product_id, oi.variant_id, oi.quantity
+    FROM order_items oi
+    WHERE oi.order_id = p_order_id
+    AND NOT EXISTS (SELECT 1 FROM order_item_components c WHERE c.order_item_id = oi.order_item_id)
+    UNION ALL
+    SELECT c.product_id, c.variant_id, c.quantity
+    FROM order_item_components c
+    JOIN order_items oi ON oi.order_item_id = c.order_item_id
+    WHERE oi.order_id = p_order_id;
+$$ LANGUAGE sql STABLE;
+
+-- La entrega descuenta el stock de los componentes de los combos en lugar del combo
+CREATE OR REPLACE FUNCTION update_product_stock_on_sale()
+RETURNS TRIGGER AS $$
+DECLARE
+    direction INTEGER;
+    kind VARCHAR(30);
+BEGIN
+    IF NEW.order_status = 'DELIVERED' AND OLD.order_status != 'DELIVERED' THEN
+        direction := -1;
+        kind := 'SALE';
+    ELSIF NEW.order_status = 'CANCELLED' AND OLD.order_status = 'DELIVERED' THEN
+        direction := 1;
+        kind := 'CANCELLATION_RETURN';
+    ELSE
+        RETURN NEW;
+    END IF;
+
+    IF NEW.branch_id IS NOT NULL THEN
+        UPDATE branch_stock
+        SET quantity = GREATEST(branch_stock.quantity + direction * sold.quantity, 0),
+            updated_at = NOW()
+        FROM (
+            SELECT l.product_id, SUM(l.quantity) AS quantity
+            FROM order_stock_lines(NEW.order_id) l
+            GROUP BY l.product_id
+        ) sold
+        WHERE branch_stock.product_id = sold.product_id
+        AND branch_stock.branch_id = NEW.branch_id;
+    END IF;
+
+    UPDATE product_variants
+    SET stock_quantity = GREATEST(product_variants.stock_quantity + direction * sold.quantity, 0),
+        updated_at = NOW()
+    FROM (
+        SELECT l.variant_id, SUM(l.quantity) AS quantity
+        FROM order_stock_lines(NEW.order_id) l
+        WHERE l.variant_id IS NOT NULL
+        GROUP BY l.variant_id
+    ) sold
+    WHERE product_variants.variant_id = sold.variant_id;
+
+    UPDATE products
+    SET stock_quantity = stock_quantity + direction * sold.quantity
+    FROM (
+        SELECT l.product_id, SUM(l.quantity) AS quantity
+        FROM order_stock_lines(NEW.order_id) l
+        GROUP BY l.product_id
+    ) sold
+    WHERE products.product_id = sold.product_id;
+
+    INSERT INTO stock_movements (product_id, branch_id, order_id, movement_type, quantity, balance_after)
+    SELECT sold.product_id, NEW.branch_id, NEW.order_id, kind, direction * sold.quantity, p.stock_quantity
+    FROM (
+        SELECT l.product_id, SUM(l.quantity) AS quantity
+        FROM order_stock_lines(NEW.order_id) l
+        GROUP BY l.product_id
+    ) sold
+    JOIN products p ON p.product_id = sold.product_id
+    WHERE sold.quantity <> 0;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Cuando cambia el stock o el estado de un componente se recalcula el stock de sus combos
+-- (combos que alcanzan a armarse) y el de la variante de cada combo
+CREATE OR REPLACE FUNCTION refresh_bundle_stock()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE products p
+    SET stock_quantity = COALESCE((
+            SELECT MIN(CASE WHEN c.is_active THEN c.stock_quantity / b.quantity ELSE 0 END)
+            FROM product_bundle_items b
+            JOIN products c ON c.product_id = b.component_id
+            WHERE b.bundle_id = p.product_id
+        ), 0),
+        updated_at = NOW()
+    WHERE p.is_bundle
+    AND p.product_id IN (SELECT bundle_id FROM product_bundle_items WHERE component_id = NEW.product_id);
+
+    UPDATE product_variants v
+    SET stock_quantity = p.stock_quantity, updated_at = NOW()
+    FROM products p
+    WHERE p.product_id = v.product_id
+    AND p.is_bundle
+    AND p.product_id IN (SELECT bundle_id FROM product_bundle_items WHERE component_id = NEW.product_id);
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_refresh_bundle_stock ON products;
+CREATE TRIGGER trigger_refresh_bundle_stock
+AFTER UPDATE OF stock_quantity, is_active ON products
+FOR EACH ROW
+WHEN (NOT NEW.is_bundle AND (OLD.stock_quantity IS DISTINCT FROM NEW.stock_quantity OR OLD.is_active IS DISTINCT FROM NEW.is_active))
+EXECUTE FUNCTION refresh_bundle_stock();
+
+-- Comentarios para documentación
+COMMENT ON COLUMN products.is_bundle IS 'Combo: stock calculado a partir de product_bundle_items; la venta descuenta los componentes';
+COMMENT ON TABLE product_bundle_items IS 'Componentes de cada combo y unidades por combo; un combo no incluye otros combos';
+COMMENT ON TABLE order_item_components IS 'Desglose de los combos vendidos copiado al comprar; la entrega descuenta estas unidades';
+COMMENT ON FUNCTION order_stock_lines(UUID) IS 'Productos, variantes y unidades que descuenta la entrega de un pedido';
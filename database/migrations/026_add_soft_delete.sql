@@ -0,0 +1,75 @@
+-- Migration: 026_add_soft_delete.sql
+-- Description: Borrado lógico de productos y categorías; los pedidos conservan sus productos
+-- Author: Sistema de Productos
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+
+-- El nombre solo es único entre los registros no eliminados: un producto o categoría eliminada no
+-- impide crear otra con el mismo nombre (restaurarla sí se rechaza mientras el nombre esté en uso)
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_name_key;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS uni_products_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_name_active ON products (name) WHERE deleted_at IS NULL;
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_name_key;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS uni_categories_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_name_active ON categories (name) WHERE deleted_at IS NULL;
+
+-- Un producto incluido en algún pedido nunca se borra físicamente, aunque falte la clave foránea
+-- de order_items (bases creadas con AutoMigrate)
+CREATE OR REPLACE FUNCTION prevent_ordered_product_delete()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM order_items WHERE product_id = OLD.product_id) THEN
+        RAISE EXCEPTION 'el producto % figura en pedidos; use el borrado lógico (deleted_at)', OLD.product_id;
+    END IF;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_prevent_ordered_product_delete ON products;
+CREATE TRIGGER trigger_prevent_ordered_product_delete
+BEFORE DELETE ON products
+FOR EACH ROW
+EXECUTE FUNCTION prevent_ordered_product_delete();
+
+-- Las vistas del catálogo excluyen los registros eliminados
+CREATE OR REPLACE VIEW view_active_products AS
+SELECT
+    p.product_id,
+    p.name,
+    p.description,
+    p.price,
+    p.image_url,
+    p.stock_quantity,
+    p.category_id,
+    c.name AS category_name,
+    c.icon_name AS category_icon,
+    c.color_hex AS category_color
+FROM products p
+LEFT JOIN categories c ON p.category_id = c.category_id AND c.deleted_at IS NULL
+WHERE p.is_active = TRUE AND p.deleted_at IS NULL;
+
+CREATE OR REPLACE VIEW view_categories_with_product_count AS
+SELECT
+    c.category_id,
+    c.name,
+    c.description,
+    c.icon_name,
+    c.color_hex,
+    c.is_active,
+    c.created_at,
+    c.updated_at,
+    COUNT(p.product_id) AS product_count
+FROM categories c
+LEFT JOIN products p ON c.category_id = p.category_id AND p.is_active = TRUE AND p.deleted_at IS NULL
+WHERE c.is_active = TRUE AND c.deleted_at IS NULL
+GROUP BY c.category_id, c.name, c.description, c.icon_name, c.color_hex, c.is_active, c.created_at, c.updated_at
+ORDER BY c.name;
+
+-- Comentarios para documentación
+COMMENT ON COLUMN products.deleted_at IS 'Borrado lógico: el producto sale del catálogo pero se conserva en pedidos, calificaciones y favoritos';
+COMMENT ON COLUMN categories.deleted_at IS 'Borrado lógico: la categoría deja de listarse; sus productos conservan la referencia';
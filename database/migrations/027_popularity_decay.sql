@@ -0,0 +1,47 @@
+-- Migration: 027_popularity_decay.sql
+-- Description: Popularidad con decaimiento en el tiempo calculada por una tarea en segundo plano
+-- Author: Sistema de Productos
+
+-- Vistas por producto y día: la tarea pondera cada día según su antigüedad. Las vistas anteriores
+-- a esta migración no tienen fecha y solo quedan en view_count.
+CREATE TABLE IF NOT EXISTS product_view_daily (
+    product_id UUID NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    view_date DATE NOT NULL,
+    views INTEGER NOT NULL DEFAULT 0 CHECK (views >= 0),
+    PRIMARY KEY (product_id, view_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_view_daily_date ON product_view_daily (view_date);
+
+-- La tarea calcula las compras a partir de los pedidos entregados
+CREATE INDEX IF NOT EXISTS idx_orders_delivered_at ON orders (delivered_at) WHERE order_status = 'DELIVERED';
+
+-- popularity_score ya no se calcula con la fórmula fija sobre los contadores acumulados
+DROP TRIGGER IF EXISTS trigger_update_popularity_score ON products;
+DROP FUNCTION IF EXISTS update_popularity_score();
+
+CREATE OR REPLACE FUNCTION update_product_rating_stats()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE products
+    SET
+        rating_average = (
+            SELECT COALESCE(AVG(rating), 0)
+            FROM product_ratings
+            WHERE product_id = COALESCE(NEW.product_id, OLD.product_id)
+        ),
+        rating_count = (
+            SELECT COUNT(*)
+            FROM product_ratings
+            WHERE product_id = COALESCE(NEW.product_id, OLD.product_id)
+        ),
+        updated_at = CURRENT_TIMESTAMP
+    WHERE product_id = COALESCE(NEW.product_id, OLD.product_id);
+
+    RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Comentarios para documentación
+COMMENT ON TABLE product_view_daily IS 'Vistas de cada producto por día, para la popularidad con decaimiento';
+COMMENT ON COLUMN products.popularity_score IS 'Calculada por la tarea de popularidad: compras y vistas recientes con decaimiento y calificaciones, con pesos configurables';
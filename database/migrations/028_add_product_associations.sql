@@ -0,0 +1,21 @@
+-- Migration: 028_add_product_associations.sql
+-- Description: Productos comprados juntos con frecuencia, recalculados por una tarea en segundo plano
+-- Author: Sistema de Productos
+
+CREATE TABLE IF NOT EXISTS product_associations (
+    product_id UUID NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    related_product_id UUID NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    support_count INTEGER NOT NULL CHECK (support_count > 0),
+    confidence DECIMAL(6,4) NOT NULL,
+    lift DECIMAL(10,4) NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, related_product_id),
+    CONSTRAINT chk_product_associations_not_self CHECK (product_id <> related_product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_associations_rank ON product_associations (product_id, confidence DESC, lift DESC);
+
+-- Comentarios para documentación
+COMMENT ON TABLE product_associations IS 'Productos comprados juntos en pedidos entregados; la tarea en segundo plano reemplaza la tabla completa';
+COMMENT ON COLUMN product_associations.confidence IS 'Fracción de los pedidos de product_id que incluyen related_product_id';
+COMMENT ON COLUMN product_associations.lift IS 'Confianza dividida por la fracción de pedidos con related_product_id; mayor a 1 = se compran juntos más de lo esperado';
@@ -0,0 +1,93 @@
+-- Migration: 029_strict_sale_stock.sql
+-- Description: La entrega descuenta lo mismo de la sucursal, la variante y el producto; si alguno no alcanza, se rechaza
+-- Author: Sistema de Inventario
+
+-- Antes la sucursal y la variante se recortaban en 0 (GREATEST) y el producto no, con lo que
+-- products.stock_quantity dejaba de ser la suma de branch_stock. Ahora se descuenta la cantidad
+-- exacta en las tres tablas y los CHECK (>= 0) rechazan la entrega si falta stock.
+-- Las líneas sin variante descuentan de la variante predeterminada del producto.
+CREATE OR REPLACE FUNCTION update_product_stock_on_sale()
+RETURNS TRIGGER AS $$
+DECLARE
+    direction INTEGER;
+    kind VARCHAR(30);
+BEGIN
+    IF NEW.order_status = 'DELIVERED' AND OLD.order_status != 'DELIVERED' THEN
+        direction := -1;
+        kind := 'SALE';
+    ELSIF NEW.order_status = 'CANCELLED' AND OLD.order_status = 'DELIVERED' THEN
+        direction := 1;
+        kind := 'CANCELLATION_RETURN';
+    ELSE
+        RETURN NEW;
+    END IF;
+
+    IF NEW.branch_id IS NOT NULL THEN
+        -- Una sucursal sin fila de stock para el producto no tiene unidades
+        IF direction < 0 AND EXISTS (
+            SELECT 1
+            FROM (
+                SELECT l.product_id, SUM(l.quantity) AS quantity
+                FROM order_stock_lines(NEW.order_id) l
+                GROUP BY l.product_id
+            ) sold
+            LEFT JOIN branch_stock bs ON bs.product_id = sold.product_id AND bs.branch_id = NEW.branch_id
+            WHERE COALESCE(bs.quantity, 0) < sold.quantity
+        ) THEN
+            RAISE EXCEPTION 'stock insuficiente en la sucursal para el pedido %', NEW.order_id
+                USING ERRCODE = 'check_violation', CONSTRAINT = 'branch_stock_quantity_check';
+        END IF;
+
+        UPDATE branch_stock
+        SET quantity = branch_stock.quantity + direction * sold.quantity,
+            updated_at = NOW()
+        FROM (
+            SELECT l.product_id, SUM(l.quantity) AS quantity
+            FROM order_stock_lines(NEW.order_id) l
+            GROUP BY l.product_id
+        ) sold
+        WHERE branch_stock.product_id = sold.product_id
+        AND branch_stock.branch_id = NEW.branch_id;
+    END IF;
+
+    UPDATE product_variants
+    SET stock_quantity = product_variants.stock_quantity + direction * sold.quantity,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(l.variant_id, d.variant_id) AS variant_id, SUM(l.quantity) AS quantity
+        FROM order_stock_lines(NEW.order_id) l
+        LEFT JOIN product_variants d ON d.product_id = l.product_id AND d.is_default
+        GROUP BY COALESCE(l.variant_id, d.variant_id)
+    ) sold
+    WHERE product_variants.variant_id = sold.variant_id;
+
+    UPDATE products
+    SET stock_quantity = stock_quantity + direction * sold.quantity
+    FROM (
+        SELECT l.product_id, SUM(l.quantity) AS quantity
+        FROM order_stock_lines(NEW.order_id) l
+        GROUP BY l.product_id
+    ) sold
+    WHERE products.product_id = sold.product_id;
+
+    INSERT INTO stock_movements (product_id, branch_id, order_id, movement_type, quantity, balance_after)
+    SELECT sold.product_id, NEW.branch_id, NEW.order_id, kind, direction * sold.quantity, p.stock_quantity
+    FROM (
+        SELECT l.product_id, SUM(l.quantity) AS quantity
+        FROM order_stock_lines(NEW.order_id) l
+        GROUP BY l.product_id
+    ) sold
+    JOIN products p ON p.product_id = sold.product_id
+    WHERE sold.quantity <> 0;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Los combos no tienen stock propio en las sucursales: se descuentan sus componentes
+DELETE FROM branch_stock bs
+USING products p
+WHERE p.product_id = bs.product_id AND p.is_bundle;
+
+-- Comentarios para documentación
+COMMENT ON FUNCTION update_product_stock_on_sale() IS 'Descuenta (o repone) la cantidad exacta en branch_stock, product_variants y products; falla si alguno queda negativo';
@@ -0,0 +1,29 @@
+-- Migration: 030_variant_stock_total.sql
+-- Description: El stock de un producto es la suma del stock de sus variantes activas
+-- Author: Sistema de Productos
+
+-- Hasta ahora el producto y la variante solo se sincronizaban cuando había una única variante
+-- activa. Se ajusta la variante predeterminada para que las variantes activas sumen el stock del
+-- producto; así no cambia el total (ni el libro de movimientos ni branch_stock). Los productos cuyas
+-- otras variantes ya superan el total quedan como están para corregirse con un ajuste.
+WITH target AS (
+    SELECT v.variant_id,
+           p.stock_quantity - COALESCE((
+               SELECT SUM(o.stock_quantity)
+               FROM product_variants o
+               WHERE o.product_id = v.product_id AND o.is_active AND o.variant_id <> v.variant_id
+           ), 0) AS quantity
+    FROM product_variants v
+    JOIN products p ON p.product_id = v.product_id
+    WHERE v.is_default AND NOT p.is_bundle
+)
+UPDATE product_variants v
+SET stock_quantity = target.quantity,
+    updated_at = NOW()
+FROM target
+WHERE v.variant_id = target.variant_id
+AND target.quantity >= 0
+AND v.stock_quantity <> target.quantity;
+
+-- Comentarios para documentación
+COMMENT ON COLUMN product_variants.stock_quantity IS 'Stock de la variante; las variantes activas de un producto suman products.stock_quantity';
@@ -0,0 +1,105 @@
+-- Migration: 031_stock_on_confirmation.sql
+-- Description: El pedido descuenta el stock al confirmarse y lo repone si se cancela después
+-- Author: Sistema de Inventario
+
+-- Antes el stock se descontaba al entregar y solo se reponía al cancelar un pedido entregado,
+-- transición que la aplicación no permite: ninguna cancelación llegaba al libro. Ahora el stock
+-- se descuenta la primera vez que el pedido pasa a CONFIRMED, ASSIGNED, IN_TRANSIT o DELIVERED
+-- (así los pedidos anteriores a esta migración se descuentan al avanzar) y se repone al cancelarlo
+-- si ya se había descontado. El libro de movimientos del pedido evita descontar o reponer dos veces.
+CREATE OR REPLACE FUNCTION update_product_stock_on_sale()
+RETURNS TRIGGER AS $$
+DECLARE
+    direction INTEGER;
+    kind VARCHAR(30);
+    sold_before BOOLEAN;
+    returned_before BOOLEAN;
+BEGIN
+    IF NEW.order_status = OLD.order_status THEN
+        RETURN NEW;
+    END IF;
+
+    sold_before := EXISTS (
+        SELECT 1 FROM stock_movements WHERE order_id = NEW.order_id AND movement_type = 'SALE'
+    );
+    returned_before := EXISTS (
+        SELECT 1 FROM stock_movements WHERE order_id = NEW.order_id AND movement_type = 'CANCELLATION_RETURN'
+    );
+
+    IF NEW.order_status IN ('CONFIRMED', 'ASSIGNED', 'IN_TRANSIT', 'DELIVERED') AND NOT sold_before THEN
+        direction := -1;
+        kind := 'SALE';
+    ELSIF NEW.order_status = 'CANCELLED' AND sold_before AND NOT returned_before THEN
+        direction := 1;
+        kind := 'CANCELLATION_RETURN';
+    ELSE
+        RETURN NEW;
+    END IF;
+
+    IF NEW.branch_id IS NOT NULL THEN
+        -- Una sucursal sin fila de stock para el producto no tiene unidades
+        IF direction < 0 AND EXISTS (
+            SELECT 1
+            FROM (
+                SELECT l.product_id, SUM(l.quantity) AS quantity
+                FROM order_stock_lines(NEW.order_id) l
+                GROUP BY l.product_id
+            ) sold
+            LEFT JOIN branch_stock bs ON bs.product_id = sold.product_id AND bs.branch_id = NEW.branch_id
+            WHERE COALESCE(bs.quantity, 0) < sold.quantity
+        ) THEN
+            RAISE EXCEPTION 'stock insuficiente en la sucursal para el pedido %', NEW.order_id
+                USING ERRCODE = 'check_violation', CONSTRAINT = 'branch_stock_quantity_check';
+        END IF;
+
+        UPDATE branch_stock
+        SET quantity = branch_stock.quantity + direction * sold.quantity,
+            updated_at = NOW()
+        FROM (
+            SELECT l.product_id, SUM(l.quantity) AS quantity
+            FROM order_stock_lines(NEW.order_id) l
+            GROUP BY l.product_id
+        ) sold
+        WHERE branch_stock.product_id = sold.product_id
+        AND branch_stock.branch_id = NEW.branch_id;
+    END IF;
+
+    UPDATE product_variants
+    SET stock_quantity = product_variants.stock_quantity + direction * sold.quantity,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(l.variant_id, d.variant_id) AS variant_id, SUM(l.quantity) AS quantity
+        FROM order_stock_lines(NEW.order_id) l
+        LEFT JOIN product_variants d ON d.product_id = l.product_id AND d.is_default
+        GROUP BY COALESCE(l.variant_id, d.variant_id)
+    ) sold
+    WHERE product_variants.variant_id = sold.variant_id;
+
+    UPDATE products
+    SET stock_quantity = stock_quantity + direction * sold.quantity
+    FROM (
+        SELECT l.product_id, SUM(l.quantity) AS quantity
+        FROM order_stock_lines(NEW.order_id) l
+        GROUP BY l.product_id
+    ) sold
+    WHERE products.product_id = sold.product_id;
+
+    INSERT INTO stock_movements (product_id, branch_id, order_id, movement_type, quantity, balance_after)
+    SELECT sold.product_id, NEW.branch_id, NEW.order_id, kind, direction * sold.quantity, p.stock_quantity
+    FROM (
+        SELECT l.product_id, SUM(l.quantity) AS quantity
+        FROM order_stock_lines(NEW.order_id) l
+        GROUP BY l.product_id
+    ) sold
+    JOIN products p ON p.product_id = sold.product_id
+    WHERE sold.quantity <> 0;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_order_type ON stock_movements(order_id, movement_type)
+WHERE order_id IS NOT NULL;
+
+-- Comentarios para documentación
+COMMENT ON FUNCTION update_product_stock_on_sale() IS 'Descuenta el stock del pedido al confirmarlo y lo repone si se cancela después; una sola vez por pedido';
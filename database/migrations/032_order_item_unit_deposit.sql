@@ -0,0 +1,26 @@
+-- Migration: 032_order_item_unit_deposit.sql
+-- Description: Garantía por balón fijada en cada ítem al comprar
+-- Author: Sistema de Balones
+
+-- La confirmación de vacíos calculaba la garantía con la del producto al momento de entregar: si
+-- cambiaba entre la compra y la entrega, el total del pedido ya no coincidía con lo cobrado
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS unit_deposit DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+-- Ítems con garantía cobrada: la garantía por balón se deduce de lo cobrado
+UPDATE order_items
+SET unit_deposit = ROUND(deposit_charge / (quantity - cylinders_returned), 2)
+WHERE unit_deposit = 0 AND deposit_charge > 0 AND quantity > cylinders_returned;
+
+-- Ítems retornables de pedidos aún sin confirmar: la garantía actual del producto
+UPDATE order_items oi
+SET unit_deposit = p.deposit_amount
+FROM products p, orders o
+WHERE p.product_id = oi.product_id
+AND o.order_id = oi.order_id
+AND oi.unit_deposit = 0
+AND p.is_returnable
+AND o.cylinders_confirmed_at IS NULL
+AND o.order_status NOT IN ('DELIVERED', 'CANCELLED');
+
+-- Comentarios para documentación
+COMMENT ON COLUMN order_items.unit_deposit IS 'Garantía por balón del producto al momento de la compra; la confirmación de vacíos la usa para cobrar';
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Caracteres iniciales con los que una hoja de cálculo interpreta el texto como fórmula
+const formulaPrefixes = "=+-@\t\r"
+
+// csvWriter escribe filas CSV; los montos se escriben con dos decimales
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	// BOM UTF-8 para que Excel muestre correctamente tildes y eñes
+	_, _ = w.Write([]byte("\xEF\xBB\xBF"))
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+// WriteRow escribe una fila
+func (cw *csvWriter) WriteRow(values []interface{}) error {
+	cw.record = cw.record[:0]
+	for _, value := range values {
+		cw.record = append(cw.record, formatCSVValue(value))
+	}
+	return cw.w.Write(cw.record)
+}
+
+// Close vacía el buffer del escritor
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func formatCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return escapeFormula(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	default:
+		return ""
+	}
+}
+
+// escapeFormula antepone un apóstrofo a los textos que Excel o LibreOffice ejecutarían como
+// fórmula al abrir el CSV (inyección de fórmulas); los números no se tocan
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// UnescapeFormula quita el apóstrofo que la exportación antepone a los textos con forma de
+// fórmula, para que un archivo exportado pueda volver a importarse sin cambios
+func UnescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+// Package export escribe reportes tabulares (CSV y XLSX) fila por fila,
+// sin mantener el archivo completo en memoria.
+package export
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+// Format identifica el formato de un archivo exportado
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// ErrUnsupportedFormat indica que el formato solicitado no está soportado
+var ErrUnsupportedFormat = errors.New("formato de exportación no soportado")
+
+// ParseFormat convierte el valor recibido en la consulta a un formato (por defecto CSV)
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// ContentType devuelve el tipo MIME del formato
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Extension devuelve la extensión de archivo del formato
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// RowWriter escribe filas de una hoja. Los valores pueden ser string, int, int64 o float64;
+// cualquier otro tipo se escribe como texto vacío. Close debe llamarse al final para
+// completar el archivo.
+type RowWriter interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// NewRowWriter crea el escritor del formato indicado sobre w
+func NewRowWriter(format Format, w io.Writer, sheetName string) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, sheetName)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
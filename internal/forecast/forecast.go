@@ -0,0 +1,241 @@
+// Package forecast estima la demanda diaria y horaria a partir del historial de ventas,
+// combinando suavizado exponencial del nivel con factores estacionales por día de la semana,
+// inicio de mes y hora del día.
+package forecast
+
+import (
+	"math"
+	"time"
+)
+
+// Config define los parámetros del modelo
+type Config struct {
+	Alpha          float64 // Peso de la observación más reciente en el suavizado del nivel (0-1)
+	MonthStartDays int     // Días del inicio de mes con demanda propia (p. ej. 3 = días 1 al 3)
+}
+
+// DefaultConfig es la configuración usada por el servicio de pronósticos
+var DefaultConfig = Config{
+	Alpha:          0.3,
+	MonthStartDays: 3,
+}
+
+// Observation es la demanda registrada en una hora de una jornada
+type Observation struct {
+	Date  time.Time // Inicio de la jornada en la zona horaria del negocio
+	Hour  int       // Hora del día (0-23)
+	Units float64
+}
+
+// Model es un modelo ajustado para una serie de demanda
+type Model struct {
+	Level            float64        // Demanda diaria desestacionalizada al final del historial
+	WeekdayFactors   [7]float64     // Multiplicador por día de la semana (time.Weekday)
+	MonthStartFactor float64        // Multiplicador de los primeros días del mes
+	HourlyProfile    [7][24]float64 // Proporción de la demanda diaria en cada hora, por día de la semana
+	ResidualStdDev   float64        // Desviación estándar del error diario de ajuste
+	HistoryDays      int            // Jornadas usadas para ajustar el modelo
+	config           Config
+}
+
+// Fit ajusta el modelo con las observaciones de las jornadas [start, end). Las jornadas sin
+// observaciones cuentan como demanda cero.
+func Fit(observations []Observation, start, end time.Time, cfg Config) *Model {
+	days := dailySeries(observations, start, end)
+	model := &Model{
+		MonthStartFactor: 1,
+		HistoryDays:      len(days),
+		config:           cfg,
+	}
+	for i := range model.WeekdayFactors {
+		model.WeekdayFactors[i] = 1
+	}
+	if len(days) == 0 {
+		return model
+	}
+
+	mean := 0.0
+	for _, d := range days {
+		mean += d.units
+	}
+	mean /= float64(len(days))
+	if mean == 0 {
+		return model
+	}
+
+	model.fitWeekdayFactors(days, mean)
+	model.fitMonthStartFactor(days)
+	model.fitHourlyProfile(observations)
+	model.fitLevel(days)
+
+	return model
+}
+
+// PredictDay estima la demanda total de una jornada
+func (m *Model) PredictDay(date time.Time) float64 {
+	return m.Level * m.seasonalFactor(date)
+}
+
+// PredictHours reparte la demanda estimada de la jornada entre sus horas
+func (m *Model) PredictHours(date time.Time) [24]float64 {
+	var hours [24]float64
+	total := m.PredictDay(date)
+	profile := m.HourlyProfile[date.Weekday()]
+	for h := range hours {
+		hours[h] = total * profile[h]
+	}
+	return hours
+}
+
+// SafetyStock estima el stock de seguridad para cubrir horizonDays jornadas con el nivel de
+// servicio indicado por z (1.65 ≈ 95%)
+func (m *Model) SafetyStock(horizonDays int, z float64) float64 {
+	if horizonDays <= 0 {
+		return 0
+	}
+	return z * m.ResidualStdDev * math.Sqrt(float64(horizonDays))
+}
+
+type daySample struct {
+	date  time.Time
+	units float64
+}
+
+// dailySeries suma las observaciones por jornada y completa con ceros las jornadas sin ventas
+func dailySeries(observations []Observation, start, end time.Time) []daySample {
+	totals := make(map[string]float64)
+	for _, o := range observations {
+		totals[o.Date.Format("2006-01-02")] += o.Units
+	}
+
+	var days []daySample
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, daySample{date: d, units: totals[d.Format("2006-01-02")]})
+	}
+	return days
+}
+
+// fitWeekdayFactors calcula el promedio de cada día de la semana respecto al promedio general
+func (m *Model) fitWeekdayFactors(days []daySample, mean float64) {
+	var sums [7]float64
+	var counts [7]int
+	for _, d := range days {
+		sums[d.date.Weekday()] += d.units
+		counts[d.date.Weekday()]++
+	}
+	for wd := range m.WeekdayFactors {
+		if counts[wd] > 0 {
+			m.WeekdayFactors[wd] = (sums[wd] / float64(counts[wd])) / mean
+		}
+	}
+}
+
+// fitMonthStartFactor compara la demanda de los primeros días del mes con la del resto,
+// una vez descontado el efecto del día de la semana
+func (m *Model) fitMonthStartFactor(days []daySample) {
+	var startSum, restSum float64
+	var startCount, restCount int
+	for _, d := range days {
+		factor := m.WeekdayFactors[d.date.Weekday()]
+		if factor == 0 {
+			continue
+		}
+		adjusted := d.units / factor
+		if m.isMonthStart(d.date) {
+			startSum += adjusted
+			startCount++
+		} else {
+			restSum += adjusted
+			restCount++
+		}
+	}
+	if startCount > 0 && restCount > 0 && restSum > 0 {
+		m.MonthStartFactor = (startSum / float64(startCount)) / (restSum / float64(restCount))
+	}
+}
+
+// fitHourlyProfile calcula qué parte de la demanda diaria cae en cada hora; si un día de la
+// semana no tiene ventas se usa el perfil de todos los días
+func (m *Model) fitHourlyProfile(observations []Observation) {
+	var byWeekday [7][24]float64
+	var overall [24]float64
+	for _, o := range observations {
+		if o.Hour < 0 || o.Hour > 23 {
+			continue
+		}
+		byWeekday[o.Date.Weekday()][o.Hour] += o.Units
+		overall[o.Hour] += o.Units
+	}
+
+	overallProfile := normalize(overall)
+	for wd := range byWeekday {
+		profile := normalize(byWeekday[wd])
+		if profile == ([24]float64{}) {
+			profile = overallProfile
+		}
+		m.HourlyProfile[wd] = profile
+	}
+}
+
+// fitLevel aplica suavizado exponencial a la serie desestacionalizada y mide el error de
+// pronóstico a un día
+func (m *Model) fitLevel(days []daySample) {
+	alpha := m.config.Alpha
+	if alpha <= 0 || alpha > 1 {
+		alpha = DefaultConfig.Alpha
+	}
+
+	level := -1.0
+	var sumSquares float64
+	var residuals int
+	for _, d := range days {
+		factor := m.seasonalFactor(d.date)
+		if factor == 0 {
+			continue
+		}
+		deseasonalized := d.units / factor
+		if level < 0 {
+			level = deseasonalized
+			continue
+		}
+
+		residual := d.units - level*factor
+		sumSquares += residual * residual
+		residuals++
+
+		level = alpha*deseasonalized + (1-alpha)*level
+	}
+
+	if level > 0 {
+		m.Level = level
+	}
+	if residuals > 1 {
+		m.ResidualStdDev = math.Sqrt(sumSquares / float64(residuals-1))
+	}
+}
+
+func (m *Model) seasonalFactor(date time.Time) float64 {
+	factor := m.WeekdayFactors[date.Weekday()]
+	if m.isMonthStart(date) {
+		factor *= m.MonthStartFactor
+	}
+	return factor
+}
+
+func (m *Model) isMonthStart(date time.Time) bool {
+	return date.Day() <= m.config.MonthStartDays
+}
+
+func normalize(values [24]float64) [24]float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	if total == 0 {
+		return [24]float64{}
+	}
+	for i := range values {
+		values[i] /= total
+	}
+	return values
+}
@@ -0,0 +1,152 @@
+// Package imaging valida imágenes subidas y genera versiones redimensionadas en su formato
+// original (JPEG o PNG) y en WebP, sin dependencias externas.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	_ "image/gif" // Registra el decodificador GIF
+)
+
+const (
+	maxPixels   = 4096 * 4096 // ~64 MB decodificada; evita agotar la memoria con imágenes enormes
+	jpegQuality = 85
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("formato de imagen no soportado (use JPEG, PNG o GIF)")
+	ErrInvalidImage      = errors.New("la imagen está dañada o no se puede leer")
+	ErrImageTooLarge     = errors.New("la imagen tiene demasiados píxeles")
+)
+
+// supportedTypes son los tipos MIME aceptados, detectados por el contenido y no por la extensión
+var supportedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// Size es una versión redimensionada a generar: cabe en un cuadrado de MaxSide píxeles
+type Size struct {
+	Name    string
+	MaxSide int
+}
+
+// DefaultSizes son las versiones que se generan para cada imagen de producto
+var DefaultSizes = []Size{
+	{Name: "thumb", MaxSide: 200},
+	{Name: "medium", MaxSide: 800},
+}
+
+// Rendition es un archivo generado a partir de la imagen original
+type Rendition struct {
+	Size        string
+	Width       int
+	Height      int
+	ContentType string
+	Ext         string
+	Data        []byte
+}
+
+// Result es una imagen validada con sus versiones generadas
+type Result struct {
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+	Renditions  []Rendition
+}
+
+// DetectType devuelve el tipo MIME real del contenido o ErrUnsupportedFormat
+func DetectType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !supportedTypes[contentType] {
+		return "", ErrUnsupportedFormat
+	}
+	return contentType, nil
+}
+
+// Process valida la imagen y genera, para cada tamaño, una versión en JPEG (PNG si tiene
+// transparencia) y otra en WebP
+func Process(data []byte, sizes []Size) (*Result, error) {
+	contentType, err := DetectType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	result := &Result{
+		ContentType: contentType,
+		Ext:         extensionFor(contentType),
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}
+	for _, size := range sizes {
+		resized := Resize(src, size.MaxSide)
+		renditions, err := encodeRenditions(size.Name, resized)
+		if err != nil {
+			return nil, fmt.Errorf("error al generar la versión %s: %w", size.Name, err)
+		}
+		result.Renditions = append(result.Renditions, renditions...)
+	}
+	return result, nil
+}
+
+// encodeRenditions codifica una versión redimensionada en su formato clásico y en WebP
+func encodeRenditions(name string, img *image.NRGBA) ([]Rendition, error) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+
+	var classic bytes.Buffer
+	classicType := "image/jpeg"
+	if IsOpaque(img) {
+		if err := jpeg.Encode(&classic, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+	} else {
+		classicType = "image/png"
+		if err := png.Encode(&classic, img); err != nil {
+			return nil, err
+		}
+	}
+
+	var webp bytes.Buffer
+	if err := EncodeWebP(&webp, img); err != nil {
+		return nil, err
+	}
+
+	return []Rendition{
+		{Size: name, Width: w, Height: h, ContentType: classicType, Ext: extensionFor(classicType), Data: classic.Bytes()},
+		{Size: name, Width: w, Height: h, ContentType: "image/webp", Ext: "webp", Data: webp.Bytes()},
+	}, nil
+}
+
+func extensionFor(contentType string) string {
+	switch contentType {
+	case "image/png":
+		return "png"
+	case "image/gif":
+		return "gif"
+	case "image/webp":
+		return "webp"
+	default:
+		return "jpg"
+	}
+}
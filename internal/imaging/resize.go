@@ -0,0 +1,99 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+)
+
+// toNRGBA convierte cualquier imagen a NRGBA (sin alfa premultiplicado) con origen en (0,0)
+func toNRGBA(src image.Image) *image.NRGBA {
+	if img, ok := src.(*image.NRGBA); ok && img.Rect.Min == (image.Point{}) && img.Stride == 4*img.Rect.Dx() {
+		return img
+	}
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// FitSize calcula las dimensiones para que la imagen quepa en un cuadrado de maxSide
+// conservando la proporción. Nunca agranda la imagen.
+func FitSize(width, height, maxSide int) (int, int) {
+	if width <= maxSide && height <= maxSide {
+		return width, height
+	}
+	if width >= height {
+		h := height * maxSide / width
+		if h < 1 {
+			h = 1
+		}
+		return maxSide, h
+	}
+	w := width * maxSide / height
+	if w < 1 {
+		w = 1
+	}
+	return w, maxSide
+}
+
+// Resize reduce la imagen para que quepa en maxSide promediando los píxeles de origen que cubre
+// cada píxel de destino. El promedio se pondera por alfa para no oscurecer bordes transparentes.
+func Resize(src image.Image, maxSide int) *image.NRGBA {
+	img := toNRGBA(src)
+	sw, sh := img.Rect.Dx(), img.Rect.Dy()
+	dw, dh := FitSize(sw, sh, maxSide)
+	if dw == sw && dh == sh {
+		return img
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := span(y, dh, sh)
+		for x := 0; x < dw; x++ {
+			x0, x1 := span(x, dw, sw)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := img.Pix[sy*img.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					alpha := uint64(p[3])
+					r += uint64(p[0]) * alpha
+					g += uint64(p[1]) * alpha
+					b += uint64(p[2]) * alpha
+					a += alpha
+					n++
+				}
+			}
+
+			o := dst.PixOffset(x, y)
+			if a > 0 {
+				dst.Pix[o] = uint8((r + a/2) / a)
+				dst.Pix[o+1] = uint8((g + a/2) / a)
+				dst.Pix[o+2] = uint8((b + a/2) / a)
+			}
+			dst.Pix[o+3] = uint8((a + n/2) / n)
+		}
+	}
+	return dst
+}
+
+// span devuelve el rango [from, to) de píxeles de origen que cubre el píxel de destino i
+func span(i, dstSize, srcSize int) (int, int) {
+	from := i * srcSize / dstSize
+	to := (i + 1) * srcSize / dstSize
+	if to <= from {
+		to = from + 1
+	}
+	return from, to
+}
+
+// IsOpaque indica si ningún píxel de la imagen es transparente
+func IsOpaque(img *image.NRGBA) bool {
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0xff {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,410 @@
+package imaging
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+)
+
+// Codificador WebP sin pérdida (VP8L) en Go puro. Aplica las transformaciones "restar verde" y
+// predictor (promedio del píxel izquierdo y el superior) y codifica los residuos con códigos de
+// Huffman por canal, sin referencias hacia atrás ni caché de colores. Comprime menos que libwebp,
+// pero produce archivos válidos sin depender de cgo.
+
+const (
+	vp8lSignature        = 0x2f
+	vp8lMaxDimension     = 1 << 14
+	vp8lPredictorBits    = 9 // Bloques de 512x512 con el mismo modo de predicción
+	vp8lPredictorAverage = 7 // Modo Average2(L, T)
+	vp8lMaxCodeLength    = 15
+	vp8lMaxCLCodeLength  = 7
+
+	transformPredictor     = 0
+	transformSubtractGreen = 2
+
+	greenAlphabetSize    = 256 + 24 // Literales + prefijos de longitud
+	channelAlphabetSize  = 256
+	distanceAlphabetSize = 40
+)
+
+// Orden en que se escriben las longitudes del código de longitudes
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// EncodeWebP escribe la imagen como WebP sin pérdida
+func EncodeWebP(w io.Writer, src image.Image) error {
+	img := toNRGBA(src)
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	if width == 0 || height == 0 || width > vp8lMaxDimension || height > vp8lMaxDimension {
+		return errors.New("dimensiones no soportadas por WebP")
+	}
+
+	bw := &bitWriter{}
+	bw.write(vp8lSignature, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if IsOpaque(img) {
+		bw.write(0, 1)
+	} else {
+		bw.write(1, 1)
+	}
+	bw.write(0, 3) // Versión
+
+	argb := make([]uint32, width*height)
+	for i := range argb {
+		p := img.Pix[i*4 : i*4+4]
+		argb[i] = uint32(p[3])<<24 | uint32(p[0])<<16 | uint32(p[1])<<8 | uint32(p[2])
+	}
+
+	// Transformación "restar verde"
+	bw.write(1, 1)
+	bw.write(transformSubtractGreen, 2)
+	subtractGreen(argb)
+
+	// Transformación predictor con un único modo para todos los bloques
+	bw.write(1, 1)
+	bw.write(transformPredictor, 2)
+	bw.write(vp8lPredictorBits-2, 3)
+	blocksW := subSampleSize(width, vp8lPredictorBits)
+	blocksH := subSampleSize(height, vp8lPredictorBits)
+	modes := make([]uint32, blocksW*blocksH)
+	for i := range modes {
+		modes[i] = 0xff000000 | vp8lPredictorAverage<<8
+	}
+	bw.write(0, 1) // Sin caché de colores
+	writeImageData(bw, modes)
+	argb = predictAverage(argb, width, height)
+
+	bw.write(0, 1) // Sin más transformaciones
+
+	// Imagen principal: sin caché de colores ni códigos por región
+	bw.write(0, 1)
+	bw.write(0, 1)
+	writeImageData(bw, argb)
+
+	return writeRIFF(w, bw.bytes())
+}
+
+// subSampleSize calcula cuántos bloques de 2^bits cubren size píxeles
+func subSampleSize(size, bits int) int {
+	return (size + (1 << bits) - 1) >> bits
+}
+
+// subtractGreen resta el canal verde de los canales rojo y azul
+func subtractGreen(argb []uint32) {
+	for i, p := range argb {
+		green := (p >> 8) & 0xff
+		red := ((p >> 16) - green) & 0xff
+		blue := (p - green) & 0xff
+		argb[i] = p&0xff00ff00 | red<<16 | blue
+	}
+}
+
+// predictAverage reemplaza cada píxel por su residuo respecto de la predicción: negro opaco para el
+// primero, el izquierdo en la primera fila, el superior en la primera columna y el promedio del
+// izquierdo y el superior en el resto
+func predictAverage(argb []uint32, width, height int) []uint32 {
+	residuals := make([]uint32, len(argb))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			var pred uint32
+			switch {
+			case x == 0 && y == 0:
+				pred = 0xff000000
+			case y == 0:
+				pred = argb[i-1]
+			case x == 0:
+				pred = argb[i-width]
+			default:
+				pred = average2(argb[i-1], argb[i-width])
+			}
+			residuals[i] = subPixels(argb[i], pred)
+		}
+	}
+	return residuals
+}
+
+// average2 promedia canal por canal dos píxeles ARGB
+func average2(a, b uint32) uint32 {
+	return (((a ^ b) & 0xfefefefe) >> 1) + (a & b)
+}
+
+// subPixels resta canal por canal (módulo 256)
+func subPixels(a, b uint32) uint32 {
+	alphaGreen := 0x00ff00ff + (a & 0xff00ff00) - (b & 0xff00ff00)
+	redBlue := 0xff00ff00 + (a & 0x00ff00ff) - (b & 0x00ff00ff)
+	return alphaGreen&0xff00ff00 | redBlue&0x00ff00ff
+}
+
+// writeImageData escribe los cinco códigos de prefijo y los píxeles como literales
+func writeImageData(bw *bitWriter, argb []uint32) {
+	green := make([]int, greenAlphabetSize)
+	red := make([]int, channelAlphabetSize)
+	blue := make([]int, channelAlphabetSize)
+	alpha := make([]int, channelAlphabetSize)
+	for _, p := range argb {
+		green[(p>>8)&0xff]++
+		red[(p>>16)&0xff]++
+		blue[p&0xff]++
+		alpha[p>>24]++
+	}
+
+	codes := [4]*prefixCode{
+		writePrefixCode(bw, green),
+		writePrefixCode(bw, red),
+		writePrefixCode(bw, blue),
+		writePrefixCode(bw, alpha),
+	}
+	writePrefixCode(bw, make([]int, distanceAlphabetSize)) // Sin referencias hacia atrás
+
+	for _, p := range argb {
+		codes[0].write(bw, int((p>>8)&0xff))
+		codes[1].write(bw, int((p>>16)&0xff))
+		codes[2].write(bw, int(p&0xff))
+		codes[3].write(bw, int(p>>24))
+	}
+}
+
+// prefixCode es un código de Huffman canónico listo para escribir (bits invertidos)
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+}
+
+func (c *prefixCode) write(bw *bitWriter, symbol int) {
+	if n := c.lengths[symbol]; n > 0 {
+		bw.write(c.codes[symbol], uint(n))
+	}
+}
+
+// writePrefixCode escribe el código para las frecuencias dadas y lo devuelve. Con uno o ningún
+// símbolo usado se escribe un código simple, que no consume bits por píxel.
+func writePrefixCode(bw *bitWriter, freq []int) *prefixCode {
+	used, symbol := 0, 0
+	for s, f := range freq {
+		if f > 0 {
+			used++
+			symbol = s
+		}
+	}
+	if used <= 1 {
+		bw.write(1, 1) // Código simple
+		bw.write(0, 1) // Un solo símbolo
+		if symbol < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(symbol), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(symbol), 8)
+		}
+		return &prefixCode{lengths: make([]uint8, len(freq)), codes: make([]uint32, len(freq))}
+	}
+
+	lengths := huffmanLengths(freq, vp8lMaxCodeLength)
+
+	// Código de longitudes: solo se usan los símbolos 0-15 (longitudes literales)
+	clFreq := make([]int, len(codeLengthCodeOrder))
+	for _, l := range lengths {
+		clFreq[l]++
+	}
+	clLengths := huffmanLengths(clFreq, vp8lMaxCLCodeLength)
+	clCode := &prefixCode{lengths: clLengths, codes: canonicalCodes(clLengths)}
+
+	count := len(codeLengthCodeOrder)
+	for count > 4 && clLengths[codeLengthCodeOrder[count-1]] == 0 {
+		count--
+	}
+	bw.write(0, 1) // Código normal
+	bw.write(uint32(count-4), 4)
+	for _, s := range codeLengthCodeOrder[:count] {
+		bw.write(uint32(clLengths[s]), 3)
+	}
+	bw.write(0, 1) // Se escriben longitudes para todo el alfabeto
+	for _, l := range lengths {
+		clCode.write(bw, int(l))
+	}
+
+	return &prefixCode{lengths: lengths, codes: canonicalCodes(lengths)}
+}
+
+// huffmanLengths calcula longitudes de código de Huffman limitadas a maxLength. Si el árbol es
+// demasiado profundo, se repite con las frecuencias bajas elevadas a un mínimo creciente.
+// Siempre devuelve un código completo: con un solo símbolo usado se agrega otro de relleno.
+func huffmanLengths(freq []int, maxLength int) []uint8 {
+	lengths := make([]uint8, len(freq))
+	adjusted := make([]int, len(freq))
+	used := 0
+	for _, f := range freq {
+		if f > 0 {
+			used++
+		}
+	}
+	switch used {
+	case 0:
+		return lengths
+	case 1:
+		for s, f := range freq {
+			if f > 0 {
+				lengths[s] = 1
+				lengths[(s+1)%len(freq)] = 1
+			}
+		}
+		return lengths
+	}
+
+	for minCount := 1; ; minCount *= 2 {
+		for s, f := range freq {
+			adjusted[s] = f
+			if f > 0 && f < minCount {
+				adjusted[s] = minCount
+			}
+		}
+		if buildHuffmanLengths(adjusted, lengths) <= maxLength {
+			return lengths
+		}
+	}
+}
+
+type huffmanNode struct {
+	weight      int
+	symbol      int // -1 para nodos internos
+	left, right *huffmanNode
+}
+
+type huffmanHeap []*huffmanNode
+
+func (h huffmanHeap) Len() int { return len(h) }
+func (h huffmanHeap) Less(i, j int) bool {
+	if h[i].weight != h[j].weight {
+		return h[i].weight < h[j].weight
+	}
+	return h[i].symbol > h[j].symbol
+}
+func (h huffmanHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *huffmanHeap) Push(x interface{}) { *h = append(*h, x.(*huffmanNode)) }
+func (h *huffmanHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// buildHuffmanLengths arma el árbol de Huffman, guarda la profundidad de cada símbolo y
+// devuelve la máxima
+func buildHuffmanLengths(freq []int, lengths []uint8) int {
+	h := &huffmanHeap{}
+	for s, f := range freq {
+		lengths[s] = 0
+		if f > 0 {
+			*h = append(*h, &huffmanNode{weight: f, symbol: s})
+		}
+	}
+	heap.Init(h)
+	for h.Len() > 1 {
+		a := heap.Pop(h).(*huffmanNode)
+		b := heap.Pop(h).(*huffmanNode)
+		heap.Push(h, &huffmanNode{weight: a.weight + b.weight, symbol: -1, left: a, right: b})
+	}
+
+	maxDepth := 0
+	var walk func(n *huffmanNode, depth int)
+	walk = func(n *huffmanNode, depth int) {
+		if n.left == nil {
+			lengths[n.symbol] = uint8(depth)
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+			return
+		}
+		walk(n.left, depth+1)
+		walk(n.right, depth+1)
+	}
+	walk(heap.Pop(h).(*huffmanNode), 0)
+	return maxDepth
+}
+
+// canonicalCodes asigna los códigos canónicos (como en DEFLATE) con los bits invertidos, porque
+// el flujo VP8L se escribe desde el bit menos significativo
+func canonicalCodes(lengths []uint8) []uint32 {
+	var count [vp8lMaxCodeLength + 1]uint32
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+
+	var next [vp8lMaxCodeLength + 1]uint32
+	code := uint32(0)
+	for bits := 1; bits <= vp8lMaxCodeLength; bits++ {
+		code = (code + count[bits-1]) << 1
+		next[bits] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		codes[s] = reverseBits(next[l], l)
+		next[l]++
+	}
+	return codes
+}
+
+func reverseBits(code uint32, length uint8) uint32 {
+	var reversed uint32
+	for i := uint8(0); i < length; i++ {
+		reversed = reversed<<1 | code&1
+		code >>= 1
+	}
+	return reversed
+}
+
+// bitWriter acumula bits empezando por el menos significativo de cada byte
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits uint
+}
+
+func (w *bitWriter) write(value uint32, n uint) {
+	w.acc |= uint64(value) << w.nbits
+	w.nbits += n
+	for w.nbits >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nbits -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.nbits > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nbits = 0, 0
+	}
+	return w.buf
+}
+
+// writeRIFF envuelve el flujo VP8L en el contenedor RIFF de WebP
+func writeRIFF(w io.Writer, vp8l []byte) error {
+	padded := len(vp8l) + len(vp8l)%2
+	header := make([]byte, 20)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(4+8+padded))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(len(vp8l)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(vp8l); err != nil {
+		return err
+	}
+	if padded != len(vp8l) {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
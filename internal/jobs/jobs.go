@@ -0,0 +1,73 @@
+// Package jobs ejecuta tareas periódicas en segundo plano (cambios de precio programados,
+// recálculos) y las detiene de forma ordenada al apagar el servidor.
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Task es una tarea periódica. Debe respetar la cancelación del contexto.
+type Task func(ctx context.Context) error
+
+// Runner ejecuta tareas periódicas hasta que se detiene
+type Runner struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRunner crea un ejecutor de tareas sin tareas registradas
+func NewRunner() *Runner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{ctx: ctx, cancel: cancel}
+}
+
+// Every ejecuta la tarea al iniciar y luego cada interval. Un error solo se registra: la tarea se
+// reintenta en el siguiente ciclo. Un intervalo menor o igual a 0 desactiva la tarea.
+func (r *Runner) Every(name string, interval time.Duration, task Task) {
+	if interval <= 0 {
+		log.Printf("[Jobs] Tarea %q desactivada", name)
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			r.run(name, task)
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Printf("[Jobs] Tarea %q programada cada %s", name, interval)
+}
+
+// run ejecuta una vez la tarea; un panic se registra sin detener las demás tareas
+func (r *Runner) run(name string, task Task) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("[Jobs] Panic en la tarea %q: %v", name, p)
+		}
+	}()
+	if r.ctx.Err() != nil {
+		return
+	}
+	if err := task(r.ctx); err != nil && r.ctx.Err() == nil {
+		log.Printf("[Jobs] Error en la tarea %q: %v", name, err)
+	}
+}
+
+// Stop cancela las tareas y espera a que termine la ejecución en curso
+func (r *Runner) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
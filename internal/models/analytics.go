@@ -0,0 +1,114 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Agrupaciones soportadas por el reporte de ventas
+const (
+	AnalyticsGroupDay        = "day"
+	AnalyticsGroupWeek       = "week"
+	AnalyticsGroupMonth      = "month"
+	AnalyticsGroupProduct    = "product"
+	AnalyticsGroupCategory   = "category"
+	AnalyticsGroupRepartidor = "repartidor"
+	AnalyticsGroupHour       = "hour"
+)
+
+// AnalyticsDefaultDays es el período analizado cuando no se indica rango (incluye hoy)
+const AnalyticsDefaultDays = 30
+
+// IsValidAnalyticsGroup verifica si la agrupación es una de las soportadas
+func IsValidAnalyticsGroup(groupBy string) bool {
+	switch groupBy {
+	case AnalyticsGroupDay, AnalyticsGroupWeek, AnalyticsGroupMonth,
+		AnalyticsGroupProduct, AnalyticsGroupCategory, AnalyticsGroupRepartidor, AnalyticsGroupHour:
+		return true
+	default:
+		return false
+	}
+}
+
+// SalesMetrics son los indicadores de ventas de un período o grupo.
+// Los ingresos son los de pedidos entregados, sin garantías de balones ni propinas.
+type SalesMetrics struct {
+	OrderCount       int64   `json:"order_count"`       // Pedidos realizados en el período
+	DeliveredCount   int64   `json:"delivered_count"`   // Pedidos entregados
+	CancelledCount   int64   `json:"cancelled_count"`   // Pedidos cancelados
+	UnitsSold        int64   `json:"units_sold"`        // Unidades entregadas
+	Revenue          float64 `json:"revenue"`           // Ingresos de pedidos entregados
+	AverageTicket    float64 `json:"average_ticket"`    // Ingreso promedio por pedido entregado
+	CancellationRate float64 `json:"cancellation_rate"` // Porcentaje de pedidos cancelados (0-100)
+}
+
+// Finalize redondea los ingresos y calcula ticket promedio y tasa de cancelación
+func (m *SalesMetrics) Finalize() {
+	m.Revenue = RoundCurrency(m.Revenue)
+	m.AverageTicket = 0
+	if m.DeliveredCount > 0 {
+		m.AverageTicket = RoundCurrency(m.Revenue / float64(m.DeliveredCount))
+	}
+	m.CancellationRate = 0
+	if m.OrderCount > 0 {
+		m.CancellationRate = RoundCurrency(float64(m.CancelledCount) * 100 / float64(m.OrderCount))
+	}
+}
+
+// SalesAnalyticsRow representa los indicadores de un grupo (día, producto, repartidor, etc.)
+type SalesAnalyticsRow struct {
+	Key   string `json:"key"`   // Identificador del grupo (fecha, ID u hora)
+	Label string `json:"label"` // Nombre legible del grupo
+	SalesMetrics
+}
+
+// SalesPeriod representa los indicadores de un rango de jornadas
+type SalesPeriod struct {
+	From   string              `json:"from"`
+	To     string              `json:"to"`
+	Totals SalesMetrics        `json:"totals"`
+	Rows   []SalesAnalyticsRow `json:"rows,omitempty"`
+}
+
+// SalesMetricsChange es la variación porcentual de cada indicador respecto al período de comparación.
+// Es nil cuando el período de comparación vale 0.
+type SalesMetricsChange struct {
+	OrderCount       *float64 `json:"order_count"`
+	Revenue          *float64 `json:"revenue"`
+	AverageTicket    *float64 `json:"average_ticket"`
+	CancellationRate *float64 `json:"cancellation_rate"` // Diferencia en puntos porcentuales
+}
+
+// SalesAnalytics es la respuesta del reporte de ventas
+type SalesAnalytics struct {
+	GroupBy    string              `json:"group_by,omitempty"`
+	TimeZone   string              `json:"timezone"`
+	Period     SalesPeriod         `json:"period"`
+	Comparison *SalesPeriod        `json:"comparison,omitempty"`
+	Change     *SalesMetricsChange `json:"change,omitempty"`
+}
+
+// CompareSalesMetrics calcula la variación de los indicadores actuales frente a los anteriores
+func CompareSalesMetrics(current, previous SalesMetrics) *SalesMetricsChange {
+	rate := RoundCurrency(current.CancellationRate - previous.CancellationRate)
+	return &SalesMetricsChange{
+		OrderCount:       percentChange(float64(current.OrderCount), float64(previous.OrderCount)),
+		Revenue:          percentChange(current.Revenue, previous.Revenue),
+		AverageTicket:    percentChange(current.AverageTicket, previous.AverageTicket),
+		CancellationRate: &rate,
+	}
+}
+
+func percentChange(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := RoundCurrency((current - previous) * 100 / math.Abs(previous))
+	return &change
+}
+
+// PreviousPeriod devuelve el rango de igual duración inmediatamente anterior a [start, end)
+func PreviousPeriod(start, end time.Time) (time.Time, time.Time) {
+	days := int(math.Round(end.Sub(start).Hours() / 24))
+	return start.AddDate(0, 0, -days), start
+}
@@ -0,0 +1,195 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultServiceRadiusKm es el radio de reparto de una sucursal si no se indica otro
+const DefaultServiceRadiusKm = 5.0
+
+// Branch representa una sucursal o depósito desde el que se despachan pedidos
+type Branch struct {
+	BranchID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"branch_id"`
+	Name            string    `gorm:"type:varchar(100);not null;unique" json:"name"`
+	AddressText     string    `gorm:"type:text;not null" json:"address_text"`
+	PhoneNumber     string    `gorm:"type:varchar(20)" json:"phone_number"`
+	Latitude        float64   `gorm:"type:numeric(9,6);not null" json:"latitude"`
+	Longitude       float64   `gorm:"type:numeric(9,6);not null" json:"longitude"`
+	ServiceRadiusKm float64   `gorm:"type:decimal(6,2);not null;default:5;check:service_radius_km > 0" json:"service_radius_km"` // Zona de reparto
+	OpensAt         string    `gorm:"type:varchar(5)" json:"opens_at,omitempty"`                                                 // Vacío = horario general del negocio
+	ClosesAt        string    `gorm:"type:varchar(5)" json:"closes_at,omitempty"`
+	IsActive        bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt       time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// BeforeCreate se ejecuta antes de crear una nueva sucursal
+func (b *Branch) BeforeCreate(tx *gorm.DB) (err error) {
+	if b.BranchID == uuid.Nil {
+		b.BranchID = uuid.New()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla para Branch
+func (Branch) TableName() string {
+	return "branches"
+}
+
+// Validate verifica la ubicación, la zona de reparto y el horario propio de la sucursal
+func (b *Branch) Validate() error {
+	if b.Name == "" || b.AddressText == "" {
+		return errors.New("el nombre y la dirección son obligatorios")
+	}
+	if b.Latitude < -90 || b.Latitude > 90 || b.Longitude < -180 || b.Longitude > 180 {
+		return errors.New("coordenadas inválidas")
+	}
+	if b.ServiceRadiusKm <= 0 {
+		return errors.New("el radio de reparto debe ser mayor a 0")
+	}
+	if b.OpensAt == "" && b.ClosesAt == "" {
+		return nil
+	}
+	return validateOpeningTimes(b.OpensAt, b.ClosesAt)
+}
+
+// DistanceKm calcula la distancia desde la sucursal hasta un punto
+func (b *Branch) DistanceKm(lat, lng float64) float64 {
+	return DistanceKm(b.Latitude, b.Longitude, lat, lng)
+}
+
+// IsOpenAt verifica el horario propio de la sucursal; sin horario propio rige el del negocio
+func (b *Branch) IsOpenAt(t time.Time, loc *time.Location) bool {
+	if b.OpensAt == "" || b.ClosesAt == "" {
+		return true
+	}
+	opens, err1 := parseClock(b.OpensAt)
+	closes, err2 := parseClock(b.ClosesAt)
+	if err1 != nil || err2 != nil {
+		return true
+	}
+
+	local := t.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	return !local.Before(midnight.Add(opens)) && local.Before(midnight.Add(closes))
+}
+
+// BranchStock es el stock de un producto en una sucursal.
+// Product.StockQuantity se mantiene como la suma de todas las sucursales.
+type BranchStock struct {
+	BranchID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"branch_id"`
+	ProductID uuid.UUID `gorm:"type:uuid;primaryKey" json:"product_id"`
+	Product   *Product  `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Quantity  int       `gorm:"type:integer;not null;default:0;check:quantity >= 0" json:"quantity"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName especifica el nombre de la tabla para BranchStock
+func (BranchStock) TableName() string {
+	return "branch_stock"
+}
+
+// CreateBranchRequest representa la solicitud para crear una sucursal
+type CreateBranchRequest struct {
+	Name            string   `json:"name" validate:"required,max=100"`
+	AddressText     string   `json:"address_text" validate:"required"`
+	PhoneNumber     string   `json:"phone_number"`
+	Latitude        float64  `json:"latitude" validate:"required"`
+	Longitude       float64  `json:"longitude" validate:"required"`
+	ServiceRadiusKm *float64 `json:"service_radius_km"` // Por defecto 5 km
+	OpensAt         string   `json:"opens_at"`
+	ClosesAt        string   `json:"closes_at"`
+}
+
+// UpdateBranchRequest representa la solicitud para actualizar una sucursal
+type UpdateBranchRequest struct {
+	Name            *string  `json:"name,omitempty"`
+	AddressText     *string  `json:"address_text,omitempty"`
+	PhoneNumber     *string  `json:"phone_number,omitempty"`
+	Latitude        *float64 `json:"latitude,omitempty"`
+	Longitude       *float64 `json:"longitude,omitempty"`
+	ServiceRadiusKm *float64 `json:"service_radius_km,omitempty"`
+	OpensAt         *string  `json:"opens_at,omitempty"` // "" quita el horario propio
+	ClosesAt        *string  `json:"closes_at,omitempty"`
+	IsActive        *bool    `json:"is_active,omitempty"`
+}
+
+// SetBranchStockRequest representa la solicitud para fijar el stock de un producto en una sucursal
+type SetBranchStockRequest struct {
+	Quantity int `json:"quantity" validate:"min=0"`
+}
+
+// AssignUserBranchRequest asigna un repartidor o administrador a una sucursal (null = sin sucursal)
+type AssignUserBranchRequest struct {
+	BranchID *string `json:"branch_id"`
+}
+
+// BranchRouting es el resultado de buscar la sucursal que atiende un pedido
+type BranchRouting struct {
+	Branch     *Branch // nil si ninguna puede atenderlo
+	DistanceKm float64
+	InZone     bool // Alguna sucursal cubre la dirección (aunque no tenga stock)
+}
+
+// SelectBranch elige la sucursal activa más cercana cuya zona de reparto cubre el punto y que
+// tiene stock para todas las unidades pedidas. stock es sucursal -> producto -> cantidad.
+func SelectBranch(branches []Branch, stock map[uuid.UUID]map[uuid.UUID]int, demand map[uuid.UUID]int, lat, lng float64) BranchRouting {
+	type candidate struct {
+		branch   *Branch
+		distance float64
+	}
+
+	var candidates []candidate
+	for i := range branches {
+		if !branches[i].IsActive {
+			continue
+		}
+		distance := branches[i].DistanceKm(lat, lng)
+		if distance <= branches[i].ServiceRadiusKm {
+			candidates = append(candidates, candidate{branch: &branches[i], distance: distance})
+		}
+	}
+
+	routing := BranchRouting{InZone: len(candidates) > 0}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	for _, c := range candidates {
+		if canFulfil(stock[c.branch.BranchID], demand) {
+			routing.Branch = c.branch
+			routing.DistanceKm = math.Round(c.distance*100) / 100
+			return routing
+		}
+	}
+	return routing
+}
+
+// canFulfil verifica que el stock de la sucursal cubra las unidades pedidas de cada producto
+func canFulfil(available map[uuid.UUID]int, demand map[uuid.UUID]int) bool {
+	for productID, quantity := range demand {
+		if available[productID] < quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// DistanceKm calcula la distancia entre dos puntos con la fórmula de Haversine
+func DistanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadiusKm = 6371.0
+
+	dLat := (lat2 - lat1) * math.Pi / 180.0
+	dLng := (lng2 - lng1) * math.Pi / 180.0
+	lat1 = lat1 * math.Pi / 180.0
+	lat2 = lat2 * math.Pi / 180.0
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Sin(dLng/2)*math.Sin(dLng/2)*math.Cos(lat1)*math.Cos(lat2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
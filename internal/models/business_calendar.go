@@ -0,0 +1,277 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BusinessTimeLayout es el formato de las horas de apertura y cierre (HH:MM, 24 h)
+const BusinessTimeLayout = "15:04"
+
+// calendarLookaheadDays es cuántos días hacia adelante se busca la próxima apertura
+const calendarLookaheadDays = 14
+
+// BusinessHours es el horario de atención de un día de la semana
+type BusinessHours struct {
+	Weekday   int       `gorm:"primaryKey;autoIncrement:false;check:weekday >= 0 AND weekday <= 6" json:"weekday"` // 0 = domingo
+	IsOpen    bool      `gorm:"not null;default:true" json:"is_open"`
+	OpensAt   string    `gorm:"type:varchar(5);not null;default:'06:00'" json:"opens_at"`
+	ClosesAt  string    `gorm:"type:varchar(5);not null;default:'20:00'" json:"closes_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName especifica el nombre de la tabla para BusinessHours
+func (BusinessHours) TableName() string {
+	return "business_hours"
+}
+
+// Validate verifica el día y que el horario de apertura sea anterior al cierre
+func (h *BusinessHours) Validate() error {
+	if h.Weekday < 0 || h.Weekday > 6 {
+		return errors.New("el día de la semana debe estar entre 0 (domingo) y 6 (sábado)")
+	}
+	if !h.IsOpen {
+		return nil
+	}
+	return validateOpeningTimes(h.OpensAt, h.ClosesAt)
+}
+
+// SpecialDay es una excepción al horario semanal: feriado, día cerrado u horario especial
+type SpecialDay struct {
+	SpecialDayID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"special_day_id"`
+	Date         time.Time `gorm:"type:date;not null;uniqueIndex" json:"date"`
+	Name         string    `gorm:"type:varchar(100);not null" json:"name"`
+	IsHoliday    bool      `gorm:"not null;default:false" json:"is_holiday"`
+	IsOpen       bool      `gorm:"not null;default:false" json:"is_open"` // Si abre, usa OpensAt/ClosesAt
+	OpensAt      string    `gorm:"type:varchar(5)" json:"opens_at,omitempty"`
+	ClosesAt     string    `gorm:"type:varchar(5)" json:"closes_at,omitempty"`
+	CreatedAt    time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// BeforeCreate se ejecuta antes de crear un nuevo día especial
+func (sd *SpecialDay) BeforeCreate(tx *gorm.DB) (err error) {
+	if sd.SpecialDayID == uuid.Nil {
+		sd.SpecialDayID = uuid.New()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla para SpecialDay
+func (SpecialDay) TableName() string {
+	return "business_special_days"
+}
+
+// SpecialDayRequest representa la solicitud para crear o actualizar un día especial
+type SpecialDayRequest struct {
+	Date      string `json:"date" validate:"required"` // YYYY-MM-DD
+	Name      string `json:"name" validate:"required,max=100"`
+	IsHoliday bool   `json:"is_holiday"`
+	IsOpen    bool   `json:"is_open"`
+	OpensAt   string `json:"opens_at"`
+	ClosesAt  string `json:"closes_at"`
+}
+
+// ToSpecialDay valida la solicitud y la convierte en un día especial
+func (r *SpecialDayRequest) ToSpecialDay() (*SpecialDay, error) {
+	date, err := time.Parse(BusinessDateLayout, r.Date)
+	if err != nil {
+		return nil, errors.New("formato de fecha inválido, use YYYY-MM-DD")
+	}
+	if r.Name == "" || len(r.Name) > 100 {
+		return nil, errors.New("el nombre es obligatorio y no puede superar los 100 caracteres")
+	}
+
+	day := &SpecialDay{
+		Date:      date,
+		Name:      r.Name,
+		IsHoliday: r.IsHoliday,
+		IsOpen:    r.IsOpen,
+	}
+	if r.IsOpen {
+		if err := validateOpeningTimes(r.OpensAt, r.ClosesAt); err != nil {
+			return nil, err
+		}
+		day.OpensAt, day.ClosesAt = r.OpensAt, r.ClosesAt
+	}
+	return day, nil
+}
+
+// DaySchedule es el horario efectivo de una jornada concreta
+type DaySchedule struct {
+	Date      string `json:"date"`
+	Weekday   int    `json:"weekday"`
+	IsOpen    bool   `json:"is_open"`
+	OpensAt   string `json:"opens_at,omitempty"`
+	ClosesAt  string `json:"closes_at,omitempty"`
+	IsHoliday bool   `json:"is_holiday"`
+	IsSpecial bool   `json:"is_special"`     // Viene de un día especial y no del horario semanal
+	Name      string `json:"name,omitempty"` // Nombre del feriado o día especial
+}
+
+// StoreHours es la respuesta pública del horario de atención
+type StoreHours struct {
+	TimeZone    string        `json:"timezone"`
+	IsOpenNow   bool          `json:"is_open_now"`
+	NextOpening *time.Time    `json:"next_opening,omitempty"` // Solo cuando está cerrado
+	Today       DaySchedule   `json:"today"`
+	Week        []DaySchedule `json:"week"` // Hoy y los 6 días siguientes
+}
+
+// BusinessCalendar combina el horario semanal con los días especiales
+type BusinessCalendar struct {
+	Location    *time.Location
+	Weekly      [7]BusinessHours
+	SpecialDays map[string]SpecialDay // Clave: fecha YYYY-MM-DD
+}
+
+// NewBusinessCalendar arma el calendario. Los días de la semana sin horario configurado usan
+// el horario por defecto [defaultStart, defaultEnd) (variables de entorno).
+func NewBusinessCalendar(weekly []BusinessHours, specialDays []SpecialDay, defaultStart, defaultEnd time.Duration, timezone string) *BusinessCalendar {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	calendar := &BusinessCalendar{
+		Location:    loc,
+		SpecialDays: make(map[string]SpecialDay, len(specialDays)),
+	}
+	for wd := range calendar.Weekly {
+		calendar.Weekly[wd] = BusinessHours{
+			Weekday:  wd,
+			IsOpen:   defaultEnd > defaultStart,
+			OpensAt:  formatClock(defaultStart),
+			ClosesAt: formatClock(defaultEnd),
+		}
+	}
+	for _, h := range weekly {
+		if h.Weekday >= 0 && h.Weekday <= 6 {
+			calendar.Weekly[h.Weekday] = h
+		}
+	}
+	for _, sd := range specialDays {
+		calendar.SpecialDays[sd.Date.Format(BusinessDateLayout)] = sd
+	}
+	return calendar
+}
+
+// ScheduleFor devuelve el horario efectivo de la jornada que contiene t
+func (c *BusinessCalendar) ScheduleFor(t time.Time) DaySchedule {
+	local := t.In(c.Location)
+	date := local.Format(BusinessDateLayout)
+
+	if sd, ok := c.SpecialDays[date]; ok {
+		schedule := DaySchedule{
+			Date:      date,
+			Weekday:   int(local.Weekday()),
+			IsOpen:    sd.IsOpen,
+			IsHoliday: sd.IsHoliday,
+			IsSpecial: true,
+			Name:      sd.Name,
+		}
+		if sd.IsOpen {
+			schedule.OpensAt, schedule.ClosesAt = sd.OpensAt, sd.ClosesAt
+		}
+		return schedule
+	}
+
+	weekly := c.Weekly[local.Weekday()]
+	schedule := DaySchedule{
+		Date:    date,
+		Weekday: int(local.Weekday()),
+		IsOpen:  weekly.IsOpen,
+	}
+	if weekly.IsOpen {
+		schedule.OpensAt, schedule.ClosesAt = weekly.OpensAt, weekly.ClosesAt
+	}
+	return schedule
+}
+
+// IsOpenAt verifica si el negocio atiende en el instante t
+func (c *BusinessCalendar) IsOpenAt(t time.Time) bool {
+	opens, closes, ok := c.openingWindow(t)
+	return ok && !t.Before(opens) && t.Before(closes)
+}
+
+// NextOpening devuelve el próximo instante de apertura posterior a t (nil si no abre en los próximos días)
+func (c *BusinessCalendar) NextOpening(t time.Time) *time.Time {
+	for i := 0; i <= calendarLookaheadDays; i++ {
+		day := t.In(c.Location).AddDate(0, 0, i)
+		opens, _, ok := c.openingWindow(day)
+		if ok && opens.After(t) {
+			return &opens
+		}
+	}
+	return nil
+}
+
+// Week devuelve los horarios de la jornada de t y los 6 días siguientes
+func (c *BusinessCalendar) Week(t time.Time) []DaySchedule {
+	week := make([]DaySchedule, 0, 7)
+	for i := 0; i < 7; i++ {
+		week = append(week, c.ScheduleFor(t.In(c.Location).AddDate(0, 0, i)))
+	}
+	return week
+}
+
+// openingWindow devuelve la apertura y el cierre de la jornada que contiene t
+func (c *BusinessCalendar) openingWindow(t time.Time) (time.Time, time.Time, bool) {
+	schedule := c.ScheduleFor(t)
+	if !schedule.IsOpen {
+		return time.Time{}, time.Time{}, false
+	}
+
+	opens, err1 := parseClock(schedule.OpensAt)
+	closes, err2 := parseClock(schedule.ClosesAt)
+	if err1 != nil || err2 != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	local := t.In(c.Location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.Location)
+	return midnight.Add(opens), midnight.Add(closes), true
+}
+
+// validateOpeningTimes verifica el formato HH:MM y que la apertura sea anterior al cierre
+func validateOpeningTimes(opensAt, closesAt string) error {
+	opens, err := parseClock(opensAt)
+	if err != nil {
+		return fmt.Errorf("hora de apertura inválida: %w", err)
+	}
+	closes, err := parseClock(closesAt)
+	if err != nil {
+		return fmt.Errorf("hora de cierre inválida: %w", err)
+	}
+	if opens >= closes {
+		return errors.New("la hora de apertura debe ser anterior a la de cierre")
+	}
+	return nil
+}
+
+// parseClock convierte HH:MM en la duración desde medianoche; acepta 24:00 como fin del día
+func parseClock(value string) (time.Duration, error) {
+	if value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse(BusinessTimeLayout, value)
+	if err != nil {
+		return 0, errors.New("use el formato HH:MM")
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// formatClock convierte una duración desde medianoche en HH:MM
+func formatClock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	if d > 24*time.Hour {
+		d = 24 * time.Hour
+	}
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BusinessDateLayout es el formato usado para identificar una jornada (YYYY-MM-DD)
+const BusinessDateLayout = "2006-01-02"
+
+// Estados posibles de la liquidación de caja de un repartidor
+const (
+	CashStatusPending  = "PENDING"  // El repartidor aún no entrega efectivo
+	CashStatusBalanced = "BALANCED" // Lo entregado coincide con lo esperado
+	CashStatusShort    = "SHORT"    // Falta dinero
+	CashStatusOver     = "OVER"     // Se entregó más de lo esperado
+)
+
+// cashTolerance es la diferencia máxima (en soles) que se considera cuadre exacto
+const cashTolerance = 0.01
+
+// CashSettlement representa una entrega de efectivo registrada por un administrador
+type CashSettlement struct {
+	SettlementID   uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"settlement_id"`
+	RepartidorID   uuid.UUID `gorm:"type:uuid;not null;index" json:"repartidor_id"`
+	BusinessDate   time.Time `gorm:"type:date;not null;index" json:"business_date"`
+	ExpectedAmount float64   `gorm:"type:decimal(10,2);not null;default:0" json:"expected_amount"` // Esperado al momento del registro
+	HandedInAmount float64   `gorm:"type:decimal(10,2);not null;check:handed_in_amount >= 0" json:"handed_in_amount"`
+	Notes          string    `gorm:"type:text" json:"notes"`
+	RecordedBy     uuid.UUID `gorm:"type:uuid;not null" json:"recorded_by"`
+	CreatedAt      time.Time `gorm:"not null;default:now()" json:"created_at"`
+
+	// Relaciones
+	Repartidor *User `gorm:"foreignKey:RepartidorID" json:"repartidor,omitempty"`
+}
+
+// BeforeCreate se ejecuta antes de crear una nueva entrega de efectivo
+func (cs *CashSettlement) BeforeCreate(tx *gorm.DB) (err error) {
+	if cs.SettlementID == uuid.Nil {
+		cs.SettlementID = uuid.New()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla para CashSettlement
+func (CashSettlement) TableName() string {
+	return "cash_settlements"
+}
+
+// CashLedgerEntry representa un pedido entregado y cobrado en efectivo
+type CashLedgerEntry struct {
+	OrderID             uuid.UUID `json:"order_id"`
+	DeliveredAt         time.Time `json:"delivered_at"`
+	Amount              float64   `json:"amount"`
+	DeliveryAddressText string    `json:"delivery_address_text"`
+}
+
+// CashLedger representa la caja de un repartidor en una jornada
+type CashLedger struct {
+	RepartidorID   uuid.UUID         `json:"repartidor_id"`
+	RepartidorName string            `json:"repartidor_name,omitempty"`
+	BusinessDate   string            `json:"business_date"`
+	Entries        []CashLedgerEntry `json:"entries"`
+	OrderCount     int               `json:"order_count"`
+	ExpectedCash   float64           `json:"expected_cash"`
+	HandedIn       float64           `json:"handed_in"`
+	Discrepancy    float64           `json:"discrepancy"` // Negativo = faltante, positivo = sobrante
+	Status         string            `json:"status"`
+	Settlements    []CashSettlement  `json:"settlements"`
+}
+
+// DailySettlementReport representa la liquidación diaria de todos los repartidores
+type DailySettlementReport struct {
+	BusinessDate     string       `json:"business_date"`
+	Ledgers          []CashLedger `json:"ledgers"`
+	TotalExpected    float64      `json:"total_expected"`
+	TotalHandedIn    float64      `json:"total_handed_in"`
+	TotalDiscrepancy float64      `json:"total_discrepancy"`
+}
+
+// BusinessDayBounds devuelve el inicio (inclusive) y fin (exclusivo) de una jornada
+// en la zona horaria del negocio. Si date está vacío se usa el día actual.
+func BusinessDayBounds(date string, timezone string, now time.Time) (time.Time, time.Time, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	var day time.Time
+	if date == "" {
+		local := now.In(loc)
+		day = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	} else {
+		day, err = time.ParseInLocation(BusinessDateLayout, date, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("formato de fecha inválido, use YYYY-MM-DD")
+		}
+	}
+
+	return day, day.AddDate(0, 0, 1), nil
+}
+
+// BuildCashLedger arma la caja de un repartidor a partir de sus pedidos y entregas registradas.
+// Solo cuentan los pedidos DELIVERED pagados en efectivo cuya entrega cae dentro de la jornada.
+func BuildCashLedger(repartidorID uuid.UUID, dayStart, dayEnd time.Time, orders []*Order, settlements []CashSettlement) *CashLedger {
+	ledger := &CashLedger{
+		RepartidorID: repartidorID,
+		BusinessDate: dayStart.Format(BusinessDateLayout),
+		Entries:      []CashLedgerEntry{},
+		Settlements:  settlements,
+	}
+	if ledger.Settlements == nil {
+		ledger.Settlements = []CashSettlement{}
+	}
+
+	for _, order := range orders {
+		if order.OrderStatus != OrderStatusDelivered || order.DeliveredAt == nil {
+			continue
+		}
+		if order.PaymentMethod != PaymentMethodCash && order.PaymentMethod != "" {
+			continue
+		}
+		if order.DeliveredAt.Before(dayStart) || !order.DeliveredAt.Before(dayEnd) {
+			continue
+		}
+
+		ledger.Entries = append(ledger.Entries, CashLedgerEntry{
+			OrderID:             order.OrderID,
+			DeliveredAt:         *order.DeliveredAt,
+			Amount:              order.TotalAmount,
+			DeliveryAddressText: order.DeliveryAddressText,
+		})
+		ledger.ExpectedCash += order.TotalAmount
+	}
+
+	for _, settlement := range ledger.Settlements {
+		ledger.HandedIn += settlement.HandedInAmount
+	}
+
+	ledger.OrderCount = len(ledger.Entries)
+	ledger.ExpectedCash = RoundCurrency(ledger.ExpectedCash)
+	ledger.HandedIn = RoundCurrency(ledger.HandedIn)
+	ledger.Discrepancy = RoundCurrency(ledger.HandedIn - ledger.ExpectedCash)
+	ledger.Status = cashStatus(ledger)
+
+	return ledger
+}
+
+// cashStatus determina el estado de cuadre de la caja
+func cashStatus(ledger *CashLedger) string {
+	if len(ledger.Settlements) == 0 {
+		if ledger.ExpectedCash == 0 {
+			return CashStatusBalanced
+		}
+		return CashStatusPending
+	}
+
+	switch {
+	case math.Abs(ledger.Discrepancy) < cashTolerance:
+		return CashStatusBalanced
+	case ledger.Discrepancy < 0:
+		return CashStatusShort
+	default:
+		return CashStatusOver
+	}
+}
+
+// RoundCurrency redondea un monto a dos decimales
+func RoundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
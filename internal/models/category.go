@@ -10,7 +10,7 @@ import (
 // Category representa una categoría de productos en el sistema
 type Category struct {
 	CategoryID  uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"category_id"`
-	Name        string    `gorm:"type:varchar(100);not null;unique" json:"name"`
+	Name        string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_categories_name_active,where:deleted_at IS NULL" json:"name"` // Único entre las no eliminadas
 	Description string    `gorm:"type:text" json:"description"`
 	IconName    string    `gorm:"type:varchar(50);not null" json:"icon_name"`
 	ColorHex    string    `gorm:"type:varchar(7);not null" json:"color_hex"`
@@ -18,6 +18,8 @@ type Category struct {
 	CreatedAt   time.Time `gorm:"not null;default:now()" json:"created_at"`
 	UpdatedAt   time.Time `gorm:"not null;default:now()" json:"updated_at"`
 
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"` // Borrado lógico
+
 	// Relación con productos
 	Products []Product `gorm:"foreignKey:CategoryID" json:"products,omitempty"`
 }
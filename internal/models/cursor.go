@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Límites de la paginación por cursor
+const (
+	DefaultCursorLimit = 20
+	MaxCursorLimit     = 100
+)
+
+// ErrInvalidCursor indica que el cursor recibido no es válido
+var ErrInvalidCursor = errors.New("cursor de paginación inválido")
+
+// Cursor identifica la última fila entregada en una paginación ordenada por (fecha, id) descendente.
+// Para el cliente es un valor opaco.
+type Cursor struct {
+	Time time.Time `json:"t"`
+	ID   string    `json:"id"`
+}
+
+// EncodeCursor convierte la fecha e ID de la última fila en un cursor opaco
+func EncodeCursor(t time.Time, id string) string {
+	data, _ := json.Marshal(Cursor{Time: t, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor interpreta un cursor opaco; un cursor vacío corresponde a la primera página
+func DecodeCursor(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Time.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(cursor.ID); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// NormalizeCursorLimit aplica el límite por defecto y el máximo permitido
+func NormalizeCursorLimit(limit int) int {
+	if limit < 1 {
+		return DefaultCursorLimit
+	}
+	if limit > MaxCursorLimit {
+		return MaxCursorLimit
+	}
+	return limit
+}
+
+// CursorPage es una página de resultados paginados por cursor
+type CursorPage[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Limit      int    `json:"limit"`
+}
+
+// NewCursorPage arma la página a partir de hasta limit+1 filas leídas: la fila extra solo indica
+// que hay más resultados. key devuelve la fecha e ID de una fila para construir el siguiente cursor.
+func NewCursorPage[T any](rows []T, limit int, key func(T) (time.Time, string)) *CursorPage[T] {
+	page := &CursorPage[T]{Items: rows, Limit: limit}
+	if len(rows) > limit {
+		page.Items = rows[:limit]
+		page.HasMore = true
+		t, id := key(page.Items[limit-1])
+		page.NextCursor = EncodeCursor(t, id)
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return page
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCylindersReturned indica que la cantidad de balones vacíos no es válida para el ítem
+var ErrInvalidCylindersReturned = errors.New("cantidad de balones vacíos inválida")
+
+// SetUnitDeposit fija en el ítem la garantía por balón del producto al momento de la compra: si
+// luego cambia la garantía del producto, el pedido no cambia. Los productos no retornables no tienen garantía.
+func (oi *OrderItem) SetUnitDeposit(product *Product) {
+	oi.UnitDeposit = 0
+	if product.IsReturnable {
+		oi.UnitDeposit = product.DepositAmount
+	}
+}
+
+// ApplyCylinderExchange registra los balones vacíos devueltos en el ítem y calcula la garantía
+// por los que faltan con la garantía fijada al comprar (UnitDeposit). Para productos no retornables
+// no hay intercambio ni garantía.
+func (oi *OrderItem) ApplyCylinderExchange(product *Product, returned int) error {
+	if !product.IsReturnable {
+		oi.CylindersReturned = 0
+		oi.DepositCharge = 0
+		return nil
+	}
+
+	if returned < 0 || returned > oi.Quantity {
+		return ErrInvalidCylindersReturned
+	}
+
+	oi.CylindersReturned = returned
+	oi.DepositCharge = RoundCurrency(float64(oi.Quantity-returned) * oi.UnitDeposit)
+	return nil
+}
+
+// CylinderHolding representa los balones de la empresa que tiene un cliente (pagó garantía)
+type CylinderHolding struct {
+	ClientID      uuid.UUID `json:"client_id"`
+	ClientName    string    `json:"client_name,omitempty"`
+	ProductID     uuid.UUID `json:"product_id"`
+	ProductName   string    `json:"product_name"`
+	CylindersHeld int64     `json:"cylinders_held"`
+	DepositsPaid  float64   `json:"deposits_paid"`
+}
+
+// CylinderCollection representa los balones movidos por un repartidor en una jornada
+type CylinderCollection struct {
+	RepartidorID     uuid.UUID `json:"repartidor_id"`
+	RepartidorName   string    `json:"repartidor_name"`
+	ProductID        uuid.UUID `json:"product_id"`
+	ProductName      string    `json:"product_name"`
+	FullDelivered    int64     `json:"full_delivered"`    // Balones llenos entregados
+	EmptiesCollected int64     `json:"empties_collected"` // Balones vacíos recogidos
+	DepositsCharged  float64   `json:"deposits_charged"`  // Garantías cobradas por balones no devueltos
+}
+
+// CylinderDailyReport resume el movimiento de balones de una jornada para la conciliación del almacén
+type CylinderDailyReport struct {
+	BusinessDate          string               `json:"business_date"`
+	Collections           []CylinderCollection `json:"collections"`
+	TotalFullDelivered    int64                `json:"total_full_delivered"`
+	TotalEmptiesCollected int64                `json:"total_empties_collected"`
+	TotalDepositsCharged  float64              `json:"total_deposits_charged"`
+}
+
+// CylinderReturnRequest representa los vacíos confirmados por el repartidor para un ítem
+type CylinderReturnRequest struct {
+	OrderItemID       uuid.UUID `json:"order_item_id"`
+	CylindersReturned int       `json:"cylinders_returned"`
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Etiquetas permitidas para calificar la entrega
+const (
+	DeliveryTagLate              = "late"
+	DeliveryTagOnTime            = "on_time"
+	DeliveryTagFriendly          = "friendly"
+	DeliveryTagRude              = "rude"
+	DeliveryTagDamagedCylinder   = "damaged_cylinder"
+	DeliveryTagCarefulHandling   = "careful_handling"
+	DeliveryTagGoodCommunication = "good_communication"
+)
+
+// validDeliveryTags contiene el conjunto cerrado de etiquetas aceptadas
+var validDeliveryTags = map[string]bool{
+	DeliveryTagLate:              true,
+	DeliveryTagOnTime:            true,
+	DeliveryTagFriendly:          true,
+	DeliveryTagRude:              true,
+	DeliveryTagDamagedCylinder:   true,
+	DeliveryTagCarefulHandling:   true,
+	DeliveryTagGoodCommunication: true,
+}
+
+// Parámetros del puntaje ponderado: con pocas calificaciones el puntaje
+// se acerca al promedio global en lugar de al promedio propio
+const deliveryScorePriorWeight = 5.0
+
+// ErrDeliveryRatingExists indica que la entrega del pedido ya fue calificada
+var ErrDeliveryRatingExists = errors.New("la entrega de este pedido ya fue calificada")
+
+// DeliveryTags es la lista de etiquetas de una calificación, guardada como JSONB
+type DeliveryTags []string
+
+// Value implementa driver.Valuer
+func (t DeliveryTags) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(t))
+	return string(b), err
+}
+
+// Scan implementa sql.Scanner
+func (t *DeliveryTags) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*t = DeliveryTags{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("tipo no soportado para DeliveryTags: %T", value)
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// DeliveryRating representa la calificación de la entrega de un pedido por parte del cliente
+type DeliveryRating struct {
+	DeliveryRatingID uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"delivery_rating_id"`
+	OrderID          uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
+	RepartidorID     uuid.UUID    `gorm:"type:uuid;not null;index" json:"repartidor_id"`
+	ClientID         uuid.UUID    `gorm:"type:uuid;not null;index" json:"client_id"`
+	Rating           int          `gorm:"type:integer;not null;check:rating >= 1 AND rating <= 5" json:"rating"`
+	Tags             DeliveryTags `gorm:"type:jsonb;not null;default:'[]'" json:"tags"`
+	Comment          string       `gorm:"type:text" json:"comment"`
+	CreatedAt        time.Time    `gorm:"not null;default:now()" json:"created_at"`
+
+	// Relaciones
+	Repartidor *User `gorm:"foreignKey:RepartidorID" json:"repartidor,omitempty"`
+}
+
+// BeforeCreate se ejecuta antes de crear una nueva calificación de entrega
+func (dr *DeliveryRating) BeforeCreate(tx *gorm.DB) (err error) {
+	if dr.DeliveryRatingID == uuid.Nil {
+		dr.DeliveryRatingID = uuid.New()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla para DeliveryRating
+func (DeliveryRating) TableName() string {
+	return "delivery_ratings"
+}
+
+// CreateDeliveryRatingRequest representa la solicitud para calificar una entrega
+type CreateDeliveryRatingRequest struct {
+	Rating  int      `json:"rating" validate:"required,min=1,max=5"`
+	Tags    []string `json:"tags"`
+	Comment string   `json:"comment" validate:"omitempty,max=1000"`
+}
+
+// Validate verifica estrellas, etiquetas y comentario, y normaliza las etiquetas (sin duplicados)
+func (r *CreateDeliveryRatingRequest) Validate() error {
+	if r.Rating < 1 || r.Rating > 5 {
+		return errors.New("la calificación debe estar entre 1 y 5")
+	}
+	if len(r.Comment) > 1000 {
+		return errors.New("el comentario no puede superar los 1000 caracteres")
+	}
+
+	seen := make(map[string]bool, len(r.Tags))
+	tags := make([]string, 0, len(r.Tags))
+	for _, tag := range r.Tags {
+		if !validDeliveryTags[tag] {
+			return fmt.Errorf("etiqueta no válida: %s", tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	r.Tags = tags
+
+	return nil
+}
+
+// RepartidorRatingSummary representa el puntaje agregado de un repartidor
+type RepartidorRatingSummary struct {
+	RepartidorID   uuid.UUID        `json:"repartidor_id"`
+	RepartidorName string           `json:"repartidor_name"`
+	RatingCount    int64            `json:"rating_count"`
+	AverageRating  float64          `json:"average_rating"`
+	Score          float64          `json:"score"` // Promedio ponderado usado para despacho y reportes
+	TagCounts      map[string]int64 `json:"tag_counts,omitempty"`
+}
+
+// WeightedDeliveryScore calcula un promedio bayesiano: mezcla el promedio del repartidor
+// con el promedio global según cuántas calificaciones tenga
+func WeightedDeliveryScore(average float64, count int64, globalAverage float64) float64 {
+	if count <= 0 {
+		return RoundCurrency(globalAverage)
+	}
+	n := float64(count)
+	score := (deliveryScorePriorWeight*globalAverage + n*average) / (deliveryScorePriorWeight + n)
+	return RoundCurrency(score)
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// DispatchCandidate es un repartidor que puede recibir un pedido, con su carga actual y su puntaje
+type DispatchCandidate struct {
+	RepartidorID   uuid.UUID  `json:"repartidor_id"`
+	RepartidorName string     `json:"repartidor_name"`
+	BranchID       *uuid.UUID `json:"branch_id,omitempty"`
+	ActiveOrders   int        `json:"active_orders"` // Pedidos asignados o en camino
+	Score          float64    `json:"score"`         // Puntaje ponderado de calificaciones de entrega
+}
+
+// RankDispatchCandidates ordena los repartidores para despachar un pedido: primero los que tienen
+// menos pedidos en curso y, a igual carga, los de mejor puntaje de entrega
+func RankDispatchCandidates(candidates []DispatchCandidate) []DispatchCandidate {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.ActiveOrders != b.ActiveOrders {
+			return a.ActiveOrders < b.ActiveOrders
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.RepartidorName < b.RepartidorName
+	})
+	return candidates
+}
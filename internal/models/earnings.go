@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RepartidorEarnings resume las entregas y propinas de un repartidor en un período
+type RepartidorEarnings struct {
+	RepartidorID    uuid.UUID `json:"repartidor_id"`
+	RepartidorName  string    `json:"repartidor_name"`
+	DeliveredOrders int64     `json:"delivered_orders"`
+	TippedOrders    int64     `json:"tipped_orders"`
+	TipsTotal       float64   `json:"tips_total"`
+	AverageTip      float64   `json:"average_tip"` // Promedio sobre los pedidos con propina
+}
+
+// TipsReport representa el reporte de propinas de todos los repartidores
+type TipsReport struct {
+	From              string               `json:"from"`
+	To                string               `json:"to"`
+	Repartidores      []RepartidorEarnings `json:"repartidores"`
+	TotalTips         float64              `json:"total_tips"`
+	TotalTippedOrders int64                `json:"total_tipped_orders"`
+}
+
+// BusinessDateRange devuelve el rango [inicio, fin) que cubre las jornadas from..to (ambas inclusive).
+// Si falta una de las fechas se usa la otra; si faltan ambas se usa el día actual.
+func BusinessDateRange(from, to string, timezone string, now time.Time) (time.Time, time.Time, error) {
+	if from == "" {
+		from = to
+	}
+	if to == "" {
+		to = from
+	}
+
+	start, _, err := BusinessDayBounds(from, timezone, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	_, end, err := BusinessDayBounds(to, timezone, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, errors.New("la fecha final debe ser posterior a la inicial")
+	}
+
+	return start, end, nil
+}
+
+// RecentBusinessDateRange es como BusinessDateRange, pero sin fechas devuelve las últimas
+// days jornadas (incluida la actual) en lugar de solo el día actual
+func RecentBusinessDateRange(from, to string, days int, timezone string, now time.Time) (time.Time, time.Time, error) {
+	if from == "" && to == "" {
+		_, end, err := BusinessDayBounds("", timezone, now)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return end.AddDate(0, 0, -days), end, nil
+	}
+	return BusinessDateRange(from, to, timezone, now)
+}
+
+// BusinessDaysBetween cuenta las jornadas completas del rango [start, end)
+func BusinessDaysBetween(start, end time.Time) int {
+	return int(math.Round(end.Sub(start).Hours() / 24))
+}
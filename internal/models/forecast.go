@@ -0,0 +1,62 @@
+package models
+
+import "github.com/google/uuid"
+
+// Parámetros de los pronósticos de demanda
+const (
+	ForecastHistoryDays        = 56   // Historial usado para ajustar el modelo (8 semanas)
+	ForecastDefaultHorizonDays = 7    // Jornadas pronosticadas por defecto
+	ForecastMaxHorizonDays     = 30   // Máximo de jornadas a pronosticar
+	ForecastServiceLevelZ      = 1.65 // Nivel de servicio del stock de seguridad (≈ 95%)
+)
+
+// DemandObservation son las unidades vendidas de un producto en una hora de una jornada
+type DemandObservation struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Date      string    `json:"date"` // Jornada YYYY-MM-DD
+	Hour      int       `json:"hour"`
+	Units     float64   `json:"units"`
+}
+
+// DailyForecast es la demanda estimada de una jornada
+type DailyForecast struct {
+	Date   string    `json:"date"`
+	Units  float64   `json:"units"`
+	Hourly []float64 `json:"hourly,omitempty"` // Unidades estimadas por hora (0-23)
+}
+
+// ProductDemandForecast es el pronóstico de un producto
+type ProductDemandForecast struct {
+	ProductID   uuid.UUID       `json:"product_id"`
+	ProductName string          `json:"product_name"`
+	TotalUnits  float64         `json:"total_units"`
+	Days        []DailyForecast `json:"days"`
+}
+
+// DemandForecastReport es el pronóstico de demanda por producto y el total por hora para planificar personal
+type DemandForecastReport struct {
+	From        string                  `json:"from"`
+	To          string                  `json:"to"`
+	HistoryFrom string                  `json:"history_from"`
+	HistoryTo   string                  `json:"history_to"`
+	Products    []ProductDemandForecast `json:"products"`
+	Totals      []DailyForecast         `json:"totals"` // Suma de todos los productos, con detalle por hora
+}
+
+// StockSuggestion es el stock sugerido de un producto para cubrir el horizonte pronosticado
+type StockSuggestion struct {
+	ProductID       uuid.UUID `json:"product_id"`
+	ProductName     string    `json:"product_name"`
+	CurrentStock    int       `json:"current_stock"`
+	ForecastUnits   float64   `json:"forecast_units"`
+	SafetyStock     float64   `json:"safety_stock"`
+	SuggestedStock  int       `json:"suggested_stock"`
+	ReorderQuantity int       `json:"reorder_quantity"` // Unidades a reponer (0 si el stock alcanza)
+}
+
+// StockSuggestionReport agrupa las sugerencias de stock de todos los productos activos
+type StockSuggestionReport struct {
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Suggestions []StockSuggestion `json:"suggestions"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,41 +21,98 @@ const (
 	OrderStatusCancelled         OrderStatus = "CANCELLED"
 )
 
+// ErrOrderAlreadyAssigned indica que el pedido ya tiene repartidor o ya no está en un estado asignable
+var ErrOrderAlreadyAssigned = errors.New("pedido ya asignado")
+
+// AssignableOrderStatuses son los estados desde los que se puede asignar un repartidor al pedido
+var AssignableOrderStatuses = []OrderStatus{OrderStatusPending, OrderStatusPendingOutOfHours, OrderStatusConfirmed}
+
+// PaymentMethod define los medios de pago aceptados para un pedido
+type PaymentMethod string
+
+const (
+	PaymentMethodCash PaymentMethod = "CASH" // Efectivo contra entrega (lo cobra el repartidor)
+	PaymentMethodYape PaymentMethod = "YAPE"
+	PaymentMethodPlin PaymentMethod = "PLIN"
+	PaymentMethodCard PaymentMethod = "CARD"
+)
+
+// IsValidPaymentMethod verifica si el medio de pago es uno de los soportados
+func IsValidPaymentMethod(method PaymentMethod) bool {
+	switch method {
+	case PaymentMethodCash, PaymentMethodYape, PaymentMethodPlin, PaymentMethodCard:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reglas de propinas para repartidores
+const (
+	TipWindow    = 24 * time.Hour // Plazo para dejar propina después de la entrega
+	MaxTipAmount = 100.00         // Propina máxima por pedido (S/)
+)
+
+// IsValidTipAmount verifica que la propina esté dentro de los límites permitidos
+func IsValidTipAmount(amount float64) bool {
+	return amount >= 0 && amount <= MaxTipAmount
+}
+
 // Order representa un pedido en el sistema
 type Order struct {
-	OrderID              uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"order_id"`
-	ClientID             uuid.UUID   `gorm:"type:uuid;not null" json:"client_id"`
-	Client               User        `gorm:"foreignKey:ClientID" json:"client"`
-	TotalAmount          float64     `gorm:"type:decimal(10,2);not null;check:total_amount >= 0" json:"total_amount"`
-	Latitude             float64     `gorm:"type:numeric(9,6);not null" json:"latitude"`
-	Longitude            float64     `gorm:"type:numeric(9,6);not null" json:"longitude"`
-	DeliveryAddressText  string      `gorm:"type:text;not null" json:"delivery_address_text"`
-	PaymentNote          string      `gorm:"type:varchar(255)" json:"payment_note"`
-	OrderStatus          OrderStatus `gorm:"type:varchar(20);not null" json:"order_status"`
-	OrderTime            time.Time   `gorm:"not null" json:"order_time"`
-	ConfirmedAt          *time.Time  `json:"confirmed_at"`
-	EstimatedArrivalTime *time.Time  `json:"estimated_arrival_time"`
-	AssignedRepartidorID *uuid.UUID  `gorm:"type:uuid" json:"assigned_repartidor_id"`
-	AssignedRepartidor   *User       `gorm:"foreignKey:AssignedRepartidorID" json:"assigned_repartidor"`
-	AssignedAt           *time.Time  `json:"assigned_at"`
-	DeliveredAt          *time.Time  `json:"delivered_at"`
-	CancelledAt          *time.Time  `json:"cancelled_at"`
-	CreatedAt            time.Time   `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt            time.Time   `gorm:"not null;default:now()" json:"updated_at"`
-	OrderItems           []OrderItem `gorm:"foreignKey:OrderID" json:"order_items"`
+	OrderID              uuid.UUID     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"order_id"`
+	ClientID             uuid.UUID     `gorm:"type:uuid;not null" json:"client_id"`
+	Client               User          `gorm:"foreignKey:ClientID" json:"client"`
+	TotalAmount          float64       `gorm:"type:decimal(10,2);not null;check:total_amount >= 0" json:"total_amount"`
+	Latitude             float64       `gorm:"type:numeric(9,6);not null" json:"latitude"`
+	Longitude            float64       `gorm:"type:numeric(9,6);not null" json:"longitude"`
+	DeliveryAddressText  string        `gorm:"type:text;not null" json:"delivery_address_text"`
+	DeliveryReference    string        `gorm:"type:text" json:"delivery_reference"`
+	AddressID            *uuid.UUID    `gorm:"type:uuid" json:"address_id"` // Dirección guardada usada (copiada al pedido)
+	BranchID             *uuid.UUID    `gorm:"type:uuid" json:"branch_id"`  // Sucursal que despacha el pedido
+	PaymentNote          string        `gorm:"type:varchar(255)" json:"payment_note"`
+	PaymentMethod        PaymentMethod `gorm:"type:varchar(20);not null;default:'CASH'" json:"payment_method"`
+	TipAmount            float64       `gorm:"type:decimal(10,2);not null;default:0;check:tip_amount >= 0" json:"tip_amount"` // No forma parte de TotalAmount
+	TipUpdatedAt         *time.Time    `json:"tip_updated_at"`
+	DepositTotal         float64       `gorm:"type:decimal(10,2);not null;default:0" json:"deposit_total"` // Garantías por balones no devueltos (incluidas en TotalAmount)
+	CylindersConfirmedAt *time.Time    `json:"cylinders_confirmed_at"`                                     // Cuando el repartidor confirmó los vacíos recibidos
+	OrderStatus          OrderStatus   `gorm:"type:varchar(20);not null" json:"order_status"`
+	OrderTime            time.Time     `gorm:"not null" json:"order_time"`
+	ConfirmedAt          *time.Time    `json:"confirmed_at"`
+	EstimatedArrivalTime *time.Time    `json:"estimated_arrival_time"`
+	AssignedRepartidorID *uuid.UUID    `gorm:"type:uuid" json:"assigned_repartidor_id"`
+	AssignedRepartidor   *User         `gorm:"foreignKey:AssignedRepartidorID" json:"assigned_repartidor"`
+	AssignedAt           *time.Time    `json:"assigned_at"`
+	InTransitAt          *time.Time    `json:"in_transit_at"`
+	DeliveredAt          *time.Time    `json:"delivered_at"`
+	CancelledAt          *time.Time    `json:"cancelled_at"`
+	CreatedAt            time.Time     `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt            time.Time     `gorm:"not null;default:now()" json:"updated_at"`
+	OrderItems           []OrderItem   `gorm:"foreignKey:OrderID" json:"order_items"`
 }
 
 // OrderItem representa un ítem dentro de un pedido
 type OrderItem struct {
-	OrderItemID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"order_item_id"`
-	OrderID     uuid.UUID `gorm:"type:uuid;not null" json:"order_id"`
-	ProductID   uuid.UUID `gorm:"type:uuid;not null" json:"product_id"`
-	Product     Product   `gorm:"foreignKey:ProductID" json:"product"`
-	Quantity    int       `gorm:"type:integer;not null;check:quantity > 0" json:"quantity"`
-	UnitPrice   float64   `gorm:"type:decimal(10,2);not null;check:unit_price > 0" json:"unit_price"`
-	Subtotal    float64   `gorm:"type:decimal(10,2);not null;check:subtotal >= 0" json:"subtotal"`
-	CreatedAt   time.Time `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt   time.Time `gorm:"not null;default:now()" json:"updated_at"`
+	OrderItemID uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"order_item_id"`
+	OrderID     uuid.UUID       `gorm:"type:uuid;not null" json:"order_id"`
+	ProductID   uuid.UUID       `gorm:"type:uuid;not null" json:"product_id"`
+	Product     Product         `gorm:"foreignKey:ProductID" json:"product"`
+	VariantID   *uuid.UUID      `gorm:"type:uuid" json:"variant_id"`
+	Variant     *ProductVariant `gorm:"foreignKey:VariantID" json:"variant,omitempty"`
+	SKU         string          `gorm:"type:varchar(64)" json:"sku"` // SKU de la variante al momento de la compra
+	Quantity    int             `gorm:"type:integer;not null;check:quantity > 0" json:"quantity"`
+	UnitPrice   float64         `gorm:"type:decimal(10,2);not null;check:unit_price > 0" json:"unit_price"`
+	Subtotal    float64         `gorm:"type:decimal(10,2);not null;check:subtotal >= 0" json:"subtotal"`
+	CreatedAt   time.Time       `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt   time.Time       `gorm:"not null;default:now()" json:"updated_at"`
+
+	// Intercambio de balones (solo productos retornables)
+	CylindersReturned int     `gorm:"type:integer;not null;default:0;check:cylinders_returned >= 0" json:"cylinders_returned"`
+	UnitDeposit       float64 `gorm:"type:decimal(10,2);not null;default:0" json:"unit_deposit"` // Garantía por balón al momento de la compra
+	DepositCharge     float64 `gorm:"type:decimal(10,2);not null;default:0" json:"deposit_charge"`
+
+	// Desglose de los combos: componentes que descuenta la confirmación
+	Components []OrderItemComponent `gorm:"foreignKey:OrderItemID" json:"components,omitempty"`
 }
 
 // BeforeCreate se ejecuta antes de crear un nuevo pedido
@@ -107,6 +165,19 @@ func IsWithinBusinessHours(t time.Time, businessStart, businessEnd time.Duration
 	return currentTime >= businessStart && currentTime < businessEnd
 }
 
+// CanSetTip verifica si el cliente aún puede dejar o cambiar la propina:
+// antes de la entrega o dentro de las 24h posteriores a ella
+func (o *Order) CanSetTip(now time.Time) bool {
+	switch o.OrderStatus {
+	case OrderStatusCancelled:
+		return false
+	case OrderStatusDelivered:
+		return o.DeliveredAt != nil && !now.After(o.DeliveredAt.Add(TipWindow))
+	default:
+		return true
+	}
+}
+
 // CanTransitionTo verifica si un pedido puede cambiar al estado especificado
 func (o *Order) CanTransitionTo(newStatus OrderStatus) bool {
 	switch o.OrderStatus {
@@ -127,3 +198,14 @@ func (o *Order) CanTransitionTo(newStatus OrderStatus) bool {
 		return false
 	}
 }
+
+// OrderStatusHoldsStock indica si el pedido ya descontó su stock en ese estado: el trigger de la base
+// de datos lo descuenta al confirmarlo y lo repone si se cancela después
+func OrderStatusHoldsStock(status OrderStatus) bool {
+	switch status {
+	case OrderStatusConfirmed, OrderStatusAssigned, OrderStatusInTransit, OrderStatusDelivered:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// OrderExportBatchSize es la cantidad de pedidos que se leen por consulta al exportar
+const OrderExportBatchSize = 200
+
+// OrderExportFilter agrupa los filtros de la exportación de pedidos (los mismos del listado
+// paginado más un rango de fechas sobre order_time)
+type OrderExportFilter struct {
+	Status *OrderStatus
+	Search string
+	From   time.Time // Inclusive
+	To     time.Time // Exclusive
+	UserID string    // Admin que exporta: si está asignado a una sucursal, solo se exportan sus pedidos
+}
+
+// OrderExportColumns son los encabezados del archivo exportado: una fila por ítem de pedido
+var OrderExportColumns = []interface{}{
+	"order_id", "order_time", "order_status", "client_name", "client_email", "client_phone",
+	"repartidor_name", "delivery_address", "delivery_reference", "payment_method",
+	"tip_amount", "deposit_total", "total_amount", "delivered_at",
+	"product_name", "quantity", "unit_price", "subtotal", "cylinders_returned", "deposit_charge",
+}
+
+// OrderExportRows aplana un pedido en filas (una por ítem, repitiendo los datos del pedido).
+// Un pedido sin ítems genera una sola fila con las columnas de ítem vacías.
+func OrderExportRows(order *Order, loc *time.Location) [][]interface{} {
+	repartidorName := ""
+	if order.AssignedRepartidor != nil {
+		repartidorName = order.AssignedRepartidor.FullName
+	}
+	deliveredAt := ""
+	if order.DeliveredAt != nil {
+		deliveredAt = order.DeliveredAt.In(loc).Format(time.DateTime)
+	}
+
+	base := []interface{}{
+		order.OrderID.String(),
+		order.OrderTime.In(loc).Format(time.DateTime),
+		string(order.OrderStatus),
+		order.Client.FullName,
+		order.Client.Email,
+		order.Client.PhoneNumber,
+		repartidorName,
+		order.DeliveryAddressText,
+		order.DeliveryReference,
+		string(order.PaymentMethod),
+		order.TipAmount,
+		order.DepositTotal,
+		order.TotalAmount,
+		deliveredAt,
+	}
+
+	if len(order.OrderItems) == 0 {
+		return [][]interface{}{append(base, "", "", "", "", "", "")}
+	}
+
+	rows := make([][]interface{}, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		row := make([]interface{}, 0, len(OrderExportColumns))
+		row = append(row, base...)
+		row = append(row,
+			item.Product.Name,
+			item.Quantity,
+			item.UnitPrice,
+			item.Subtotal,
+			item.CylindersReturned,
+			item.DepositCharge,
+		)
+		rows = append(rows, row)
+	}
+	return rows
+}
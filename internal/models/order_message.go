@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxChatMessageLength es la longitud máxima de un mensaje de chat
+const MaxChatMessageLength = 1000
+
+// OrderMessage representa un mensaje del chat de un pedido entre cliente, repartidor y administradores
+type OrderMessage struct {
+	MessageID  uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"message_id"`
+	OrderID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"order_id"`
+	SenderID   uuid.UUID  `gorm:"type:uuid;not null" json:"sender_id"`
+	SenderRole UserRole   `gorm:"type:varchar(20);not null" json:"sender_role"`
+	Content    string     `gorm:"type:text;not null" json:"content"`
+	ReadAt     *time.Time `json:"read_at"` // Cuando otro participante lo leyó
+	CreatedAt  time.Time  `gorm:"not null;default:now()" json:"created_at"`
+
+	// Relaciones
+	Sender *User `gorm:"foreignKey:SenderID" json:"sender,omitempty"`
+}
+
+// BeforeCreate se ejecuta antes de crear un nuevo mensaje
+func (m *OrderMessage) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.MessageID == uuid.Nil {
+		m.MessageID = uuid.New()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla para OrderMessage
+func (OrderMessage) TableName() string {
+	return "order_messages"
+}
+
+// SendOrderMessageRequest representa la solicitud para enviar un mensaje por REST
+type SendOrderMessageRequest struct {
+	Content string `json:"content" validate:"required,max=1000"`
+}
+
+// IsChatOpen indica si el chat del pedido acepta mensajes nuevos:
+// solo mientras el pedido está asignado o en camino
+func (o *Order) IsChatOpen() bool {
+	return o.OrderStatus == OrderStatusAssigned || o.OrderStatus == OrderStatusInTransit
+}
+
+// IsChatRecipient indica si los mensajes del chat van dirigidos al usuario: el cliente y el
+// repartidor asignado. Un administrador puede leer el chat, pero su lectura no cuenta como leído.
+func (o *Order) IsChatRecipient(userID string, role UserRole) bool {
+	return role != UserRoleAdmin && o.IsChatParticipant(userID, role)
+}
+
+// IsChatParticipant indica si el usuario puede leer o escribir en el chat del pedido
+func (o *Order) IsChatParticipant(userID string, role UserRole) bool {
+	switch role {
+	case UserRoleAdmin:
+		return true
+	case UserRoleClient:
+		return o.ClientID.String() == userID
+	case UserRoleRepartidor:
+		return o.AssignedRepartidorID != nil && o.AssignedRepartidorID.String() == userID
+	default:
+		return false
+	}
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// PerformanceDefaultDays es el período evaluado cuando no se indica rango (incluye hoy)
+const PerformanceDefaultDays = 30
+
+// RepartidorPerformance resume el desempeño de un repartidor sobre los pedidos que se le asignaron en un período
+type RepartidorPerformance struct {
+	RepartidorID                 uuid.UUID `json:"repartidor_id"`
+	RepartidorName               string    `json:"repartidor_name"`
+	AssignedOrders               int64     `json:"assigned_orders"`
+	DeliveredOrders              int64     `json:"delivered_orders"`
+	DeliveriesPerDay             float64   `json:"deliveries_per_day"`
+	AvgAssignedToTransitMinutes  *float64  `json:"avg_assigned_to_transit_minutes"`  // De ASSIGNED a IN_TRANSIT
+	AvgTransitToDeliveredMinutes *float64  `json:"avg_transit_to_delivered_minutes"` // De IN_TRANSIT a DELIVERED
+	EtaDeliveries                int64     `json:"eta_deliveries"`                   // Entregas con hora estimada de llegada
+	OnTimeDeliveries             int64     `json:"on_time_deliveries"`               // Entregadas antes de la hora estimada
+	OnTimeRate                   *float64  `json:"on_time_rate"`                     // Porcentaje (0-100) sobre EtaDeliveries
+	CancelledWhileAssigned       int64     `json:"cancelled_while_assigned"`
+	CancellationRate             float64   `json:"cancellation_rate"` // Porcentaje (0-100) sobre AssignedOrders
+	RatingScore                  float64   `json:"rating_score"`      // Puntaje ponderado de calificaciones de entrega
+	RatingCount                  int64     `json:"rating_count"`
+}
+
+// Finalize calcula los indicadores derivados para un período de days jornadas
+func (p *RepartidorPerformance) Finalize(days int) {
+	p.DeliveriesPerDay = 0
+	if days > 0 {
+		p.DeliveriesPerDay = RoundCurrency(float64(p.DeliveredOrders) / float64(days))
+	}
+
+	p.OnTimeRate = nil
+	if p.EtaDeliveries > 0 {
+		rate := RoundCurrency(float64(p.OnTimeDeliveries) * 100 / float64(p.EtaDeliveries))
+		p.OnTimeRate = &rate
+	}
+
+	p.CancellationRate = 0
+	if p.AssignedOrders > 0 {
+		p.CancellationRate = RoundCurrency(float64(p.CancelledWhileAssigned) * 100 / float64(p.AssignedOrders))
+	}
+
+	p.AvgAssignedToTransitMinutes = roundOptional(p.AvgAssignedToTransitMinutes)
+	p.AvgTransitToDeliveredMinutes = roundOptional(p.AvgTransitToDeliveredMinutes)
+}
+
+func roundOptional(value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	rounded := RoundCurrency(*value)
+	return &rounded
+}
+
+// PerformanceReport es el ranking de desempeño de los repartidores en un período
+type PerformanceReport struct {
+	From         string                  `json:"from"`
+	To           string                  `json:"to"`
+	Days         int                     `json:"days"`
+	Repartidores []RepartidorPerformance `json:"repartidores"`
+}
+
+// SortPerformanceLeaderboard ordena por entregas, luego puntualidad y luego calificación (de mayor a menor)
+func SortPerformanceLeaderboard(performance []RepartidorPerformance) {
+	onTime := func(p RepartidorPerformance) float64 {
+		if p.OnTimeRate == nil {
+			return -1
+		}
+		return *p.OnTimeRate
+	}
+
+	sort.SliceStable(performance, func(i, j int) bool {
+		a, b := performance[i], performance[j]
+		if a.DeliveredOrders != b.DeliveredOrders {
+			return a.DeliveredOrders > b.DeliveredOrders
+		}
+		if onTime(a) != onTime(b) {
+			return onTime(a) > onTime(b)
+		}
+		return a.RatingScore > b.RatingScore
+	})
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PopularityHalfLives es cuántas vidas medias de actividad se consideran: lo anterior pesa menos del 0,4 %
+const PopularityHalfLives = 8
+
+// ProductViewDaily acumula las vistas de un producto por día para calcular la popularidad con decaimiento
+type ProductViewDaily struct {
+	ProductID uuid.UUID `gorm:"type:uuid;primary_key" json:"product_id"`
+	ViewDate  time.Time `gorm:"type:date;primary_key" json:"view_date"`
+	Views     int       `gorm:"type:integer;not null;default:0" json:"views"`
+}
+
+// TableName especifica el nombre de la tabla para ProductViewDaily
+func (ProductViewDaily) TableName() string {
+	return "product_view_daily"
+}
+
+// PopularityWeights son los pesos de la puntuación de popularidad. Las compras y las vistas pierden
+// la mitad de su peso cada HalfLife; las calificaciones cuentan sin decaimiento.
+type PopularityWeights struct {
+	Purchase      float64       `json:"purchase"`       // Por compra entregada reciente
+	View          float64       `json:"view"`           // Por vista reciente
+	RatingAverage float64       `json:"rating_average"` // Por punto de calificación promedio
+	RatingCount   float64       `json:"rating_count"`   // Por calificación recibida
+	HalfLife      time.Duration `json:"-"`
+}
+
+// Window es el periodo de actividad que se considera al calcular la popularidad
+func (w PopularityWeights) Window() time.Duration {
+	return PopularityHalfLives * w.HalfLife
+}
+
+// Decay es el peso de una actividad de hace age: 1 al ocurrir y la mitad cada vida media
+func (w PopularityWeights) Decay(age time.Duration) float64 {
+	if age <= 0 || w.HalfLife <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(w.HalfLife))
+}
+
+// PopularityProduct son los datos de un producto que no dependen de la fecha de la actividad
+type PopularityProduct struct {
+	ProductID       uuid.UUID `json:"product_id"`
+	Name            string    `json:"name"`
+	RatingAverage   float64   `json:"rating_average"`
+	RatingCount     int       `json:"rating_count"`
+	PopularityScore float64   `json:"popularity_score"` // Puntuación guardada
+}
+
+// PopularityActivity son las vistas y compras entregadas de un producto en un día
+type PopularityActivity struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Day       time.Time `json:"day"`
+	Views     int       `json:"views"`
+	Purchases int       `json:"purchases"`
+}
+
+// PopularityBreakdown detalla la puntuación de popularidad de un producto
+type PopularityBreakdown struct {
+	ProductID        uuid.UUID `json:"product_id"`
+	Name             string    `json:"name"`
+	Views            int       `json:"views"`         // Vistas dentro del periodo
+	Purchases        int       `json:"purchases"`     // Compras entregadas dentro del periodo
+	DecayedViews     float64   `json:"decayed_views"` // Vistas ponderadas por antigüedad
+	DecayedPurchases float64   `json:"decayed_purchases"`
+	RatingAverage    float64   `json:"rating_average"`
+	RatingCount      int       `json:"rating_count"`
+	ViewPoints       float64   `json:"view_points"`
+	PurchasePoints   float64   `json:"purchase_points"`
+	RatingPoints     float64   `json:"rating_points"`
+	Score            float64   `json:"score"`         // Puntuación calculada ahora
+	CurrentScore     float64   `json:"current_score"` // Puntuación guardada en el producto
+}
+
+// PopularityPreview es el cálculo de la popularidad de todos los productos sin guardarla
+type PopularityPreview struct {
+	Weights       PopularityWeights     `json:"weights"`
+	HalfLifeHours float64               `json:"half_life_hours"`
+	Since         time.Time             `json:"since"`
+	ComputedAt    time.Time             `json:"computed_at"`
+	Products      []PopularityBreakdown `json:"products"`
+}
+
+// ComputePopularity calcula la puntuación de cada producto a partir de su actividad diaria. La
+// actividad de un día se fecha a su mediodía para no favorecer ni castigar el día en curso.
+// Devuelve los productos de mayor a menor puntuación.
+func ComputePopularity(products []PopularityProduct, activity []PopularityActivity, w PopularityWeights, now time.Time) []PopularityBreakdown {
+	breakdowns := make([]PopularityBreakdown, len(products))
+	index := make(map[uuid.UUID]*PopularityBreakdown, len(products))
+	for i, p := range products {
+		breakdowns[i] = PopularityBreakdown{
+			ProductID:     p.ProductID,
+			Name:          p.Name,
+			RatingAverage: p.RatingAverage,
+			RatingCount:   p.RatingCount,
+			CurrentScore:  p.PopularityScore,
+		}
+		index[p.ProductID] = &breakdowns[i]
+	}
+
+	for _, a := range activity {
+		b, ok := index[a.ProductID]
+		if !ok {
+			continue // Producto eliminado
+		}
+		decay := w.Decay(now.Sub(a.Day.Add(12 * time.Hour)))
+		b.Views += a.Views
+		b.Purchases += a.Purchases
+		b.DecayedViews += float64(a.Views) * decay
+		b.DecayedPurchases += float64(a.Purchases) * decay
+	}
+
+	for i := range breakdowns {
+		b := &breakdowns[i]
+		b.ViewPoints = RoundCurrency(b.DecayedViews * w.View)
+		b.PurchasePoints = RoundCurrency(b.DecayedPurchases * w.Purchase)
+		b.RatingPoints = RoundCurrency(b.RatingAverage*w.RatingAverage + float64(b.RatingCount)*w.RatingCount)
+		b.Score = RoundCurrency(b.ViewPoints + b.PurchasePoints + b.RatingPoints)
+		b.DecayedViews = RoundCurrency(b.DecayedViews)
+		b.DecayedPurchases = RoundCurrency(b.DecayedPurchases)
+	}
+
+	sort.SliceStable(breakdowns, func(i, j int) bool {
+		return breakdowns[i].Score > breakdowns[j].Score
+	})
+	return breakdowns
+}
@@ -10,7 +10,7 @@ import (
 // Product representa un producto en el sistema de tienda PedidoMendez
 type Product struct {
 	ProductID     uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"product_id"`
-	Name          string     `gorm:"type:varchar(255);not null;unique" json:"name"`
+	Name          string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_products_name_active,where:deleted_at IS NULL" json:"name"` // Único entre los no eliminados
 	Description   string     `gorm:"type:text" json:"description"`
 	Price         float64    `gorm:"type:decimal(10,2);not null;check:price > 0" json:"price"`
 	ImageURL            string     `gorm:"type:varchar(255)" json:"image_url"`
@@ -20,6 +20,17 @@ type Product struct {
 	CategoryID          *uuid.UUID `gorm:"type:uuid" json:"category_id"`
 	IsActive            bool       `gorm:"not null;default:true" json:"is_active"`
 
+	// Balones retornables: se entregan a cambio de uno vacío o se cobra una garantía
+	IsReturnable  bool    `gorm:"not null;default:false" json:"is_returnable"`
+	DepositAmount float64 `gorm:"type:decimal(10,2);not null;default:0;check:deposit_amount >= 0" json:"deposit_amount"`
+
+	// Combos: el stock se calcula a partir de los componentes y la venta descuenta los componentes
+	IsBundle bool `gorm:"not null;default:false" json:"is_bundle"`
+
+	// Inventario: se avisa a los administradores cuando el stock baja del umbral (0 = sin aviso)
+	ReorderThreshold  int        `gorm:"type:integer;not null;default:0;check:reorder_threshold >= 0" json:"reorder_threshold"`
+	LowStockAlertedAt *time.Time `json:"low_stock_alerted_at,omitempty"` // Aviso enviado; se limpia al reponer
+
 	// Analytics fields
 	ViewCount       int     `gorm:"type:integer;not null;default:0" json:"view_count"`
 	PurchaseCount   int     `gorm:"type:integer;not null;default:0" json:"purchase_count"`
@@ -27,13 +38,17 @@ type Product struct {
 	RatingCount     int     `gorm:"type:integer;not null;default:0" json:"rating_count"`
 	PopularityScore float64 `gorm:"type:decimal(10,2);not null;default:0.00" json:"popularity_score"`
 
-	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+	CreatedAt time.Time      `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"not null;default:now()" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"` // Borrado lógico: se conserva para pedidos, calificaciones y favoritos
 
 	// Relaciones
 	Category    *Category        `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
 	Ratings     []ProductRating  `gorm:"foreignKey:ProductID" json:"ratings,omitempty"`
 	CurrentOffer *ProductOffer   `gorm:"foreignKey:ProductID" json:"current_offer,omitempty"`
+	Variants     []ProductVariant `gorm:"foreignKey:ProductID" json:"variants,omitempty"`
+	Images       []ProductImage   `gorm:"foreignKey:ProductID" json:"images,omitempty"`
+	BundleItems  []ProductBundleItem `gorm:"foreignKey:BundleID" json:"bundle_items,omitempty"`
 }
 
 // BeforeCreate se ejecuta antes de crear un nuevo producto
@@ -78,3 +93,8 @@ func (p *Product) GetDiscountPercentage() float64 {
 func (p *Product) IsOnOffer() bool {
 	return p.CurrentOffer != nil && p.CurrentOffer.IsCurrentlyActive()
 }
+
+// IsLowStock indica si el stock está por debajo del umbral de reposición
+func (p *Product) IsLowStock() bool {
+	return p.ReorderThreshold > 0 && p.StockQuantity < p.ReorderThreshold
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// AssociationMinSupport es la cantidad mínima de pedidos en común para relacionar dos productos
+	AssociationMinSupport = 2
+	// AssociationWindow es el periodo de pedidos entregados que se analiza
+	AssociationWindow = 180 * 24 * time.Hour
+
+	DefaultRelatedLimit = 6
+	MaxRelatedLimit     = 20
+)
+
+// ProductAssociation indica que RelatedProductID suele comprarse junto con ProductID ("comprados
+// juntos con frecuencia"). Confidence es la fracción de los pedidos de ProductID que incluyen
+// RelatedProductID; Lift compara esa fracción con la popularidad general de RelatedProductID
+// (mayor a 1 = se compran juntos más de lo esperado por azar).
+type ProductAssociation struct {
+	ProductID        uuid.UUID `gorm:"type:uuid;primary_key" json:"product_id"`
+	RelatedProductID uuid.UUID `gorm:"type:uuid;primary_key" json:"related_product_id"`
+	RelatedProduct   *Product  `gorm:"foreignKey:RelatedProductID" json:"related_product,omitempty"`
+	SupportCount     int       `gorm:"type:integer;not null" json:"support_count"` // Pedidos con ambos productos
+	Confidence       float64   `gorm:"type:decimal(6,4);not null" json:"confidence"`
+	Lift             float64   `gorm:"type:decimal(10,4);not null" json:"lift"`
+	ComputedAt       time.Time `gorm:"not null;default:now()" json:"computed_at"`
+}
+
+// TableName especifica el nombre de la tabla para ProductAssociation
+func (ProductAssociation) TableName() string {
+	return "product_associations"
+}
+
+// OrderBasketLine es un producto incluido en un pedido entregado
+type OrderBasketLine struct {
+	OrderID   uuid.UUID
+	ProductID uuid.UUID
+}
+
+// RelatedProduct es un producto recomendado junto con otro y las métricas de la asociación
+type RelatedProduct struct {
+	Product      *Product `json:"product"`
+	SupportCount int      `json:"support_count"`
+	Confidence   float64  `json:"confidence"`
+	Lift         float64  `json:"lift"`
+}
+
+// NormalizeRelatedLimit aplica el valor por defecto y el máximo de productos relacionados
+func NormalizeRelatedLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultRelatedLimit
+	}
+	if limit > MaxRelatedLimit {
+		return MaxRelatedLimit
+	}
+	return limit
+}
+
+// ComputeProductAssociations calcula las asociaciones entre los productos que aparecen juntos en
+// al menos minSupport pedidos. Cada par genera dos asociaciones, una por sentido, ya que la
+// confianza depende del producto de partida. Las asociaciones se ordenan por producto y luego
+// por confianza y lift descendentes.
+func ComputeProductAssociations(lines []OrderBasketLine, minSupport int, computedAt time.Time) []ProductAssociation {
+	baskets := make(map[uuid.UUID]map[uuid.UUID]bool)
+	for _, line := range lines {
+		if baskets[line.OrderID] == nil {
+			baskets[line.OrderID] = make(map[uuid.UUID]bool)
+		}
+		baskets[line.OrderID][line.ProductID] = true
+	}
+
+	type pair struct{ product, related uuid.UUID }
+	productOrders := make(map[uuid.UUID]int)
+	together := make(map[pair]int)
+	for _, basket := range baskets {
+		for product := range basket {
+			productOrders[product]++
+			for related := range basket {
+				if related != product {
+					together[pair{product, related}]++
+				}
+			}
+		}
+	}
+
+	totalOrders := float64(len(baskets))
+	associations := make([]ProductAssociation, 0, len(together))
+	for p, count := range together {
+		if count < minSupport {
+			continue
+		}
+		confidence := float64(count) / float64(productOrders[p.product])
+		relatedShare := float64(productOrders[p.related]) / totalOrders
+		associations = append(associations, ProductAssociation{
+			ProductID:        p.product,
+			RelatedProductID: p.related,
+			SupportCount:     count,
+			Confidence:       roundTo(confidence, 4),
+			Lift:             roundTo(confidence/relatedShare, 4),
+			ComputedAt:       computedAt,
+		})
+	}
+
+	sort.Slice(associations, func(i, j int) bool {
+		a, b := associations[i], associations[j]
+		if a.ProductID != b.ProductID {
+			return a.ProductID.String() < b.ProductID.String()
+		}
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		if a.Lift != b.Lift {
+			return a.Lift > b.Lift
+		}
+		return a.RelatedProductID.String() < b.RelatedProductID.String()
+	})
+	return associations
+}
+
+// roundTo redondea value a la cantidad de decimales indicada
+func roundTo(value float64, decimals int) float64 {
+	factor := math.Pow10(decimals)
+	return math.Round(value*factor) / factor
+}
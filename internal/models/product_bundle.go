@@ -0,0 +1,125 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxBundleComponents limita los productos distintos que puede incluir un combo
+const MaxBundleComponents = 20
+
+// ProductBundleItem es un componente de un combo: el producto y las unidades que incluye cada combo.
+// El stock del combo es el número de combos que alcanzan a armarse con el stock de sus componentes.
+type ProductBundleItem struct {
+	BundleItemID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"bundle_item_id"`
+	BundleID     uuid.UUID `gorm:"type:uuid;not null;index" json:"bundle_id"`
+	ComponentID  uuid.UUID `gorm:"type:uuid;not null;index" json:"component_id"`
+	Component    *Product  `gorm:"foreignKey:ComponentID" json:"component,omitempty"`
+	Quantity     int       `gorm:"type:integer;not null;check:quantity > 0" json:"quantity"` // Unidades del componente por combo
+	SortOrder    int       `gorm:"type:integer;not null;default:0" json:"sort_order"`
+	CreatedAt    time.Time `gorm:"not null;default:now()" json:"created_at"`
+}
+
+// BeforeCreate se ejecuta antes de agregar un componente a un combo
+func (b *ProductBundleItem) BeforeCreate(tx *gorm.DB) (err error) {
+	if b.BundleItemID == uuid.Nil {
+		b.BundleItemID = uuid.New()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla para ProductBundleItem
+func (ProductBundleItem) TableName() string {
+	return "product_bundle_items"
+}
+
+// BundleItemRequest es un componente en la solicitud para crear o editar un combo
+type BundleItemRequest struct {
+	ComponentID uuid.UUID `json:"component_id"`
+	Quantity    int       `json:"quantity"`
+}
+
+// SetBundleItemsRequest reemplaza los componentes de un combo
+type SetBundleItemsRequest struct {
+	Items []BundleItemRequest `json:"items"`
+}
+
+// NewBundleItems valida los componentes de un combo y los devuelve en el orden recibido. Un combo
+// necesita al menos un componente, sin repetir productos ni incluirse a sí mismo.
+func NewBundleItems(bundleID uuid.UUID, reqs []BundleItemRequest) ([]ProductBundleItem, error) {
+	if len(reqs) == 0 {
+		return nil, errors.New("el combo necesita al menos un componente")
+	}
+	if len(reqs) > MaxBundleComponents {
+		return nil, fmt.Errorf("el combo admite como máximo %d componentes", MaxBundleComponents)
+	}
+
+	items := make([]ProductBundleItem, 0, len(reqs))
+	seen := make(map[uuid.UUID]bool, len(reqs))
+	for i, req := range reqs {
+		switch {
+		case req.ComponentID == uuid.Nil:
+			return nil, fmt.Errorf("componente %d: falta component_id", i+1)
+		case req.ComponentID == bundleID:
+			return nil, errors.New("el combo no puede incluirse a sí mismo")
+		case seen[req.ComponentID]:
+			return nil, fmt.Errorf("componente %d: el producto está repetido; indique la cantidad total", i+1)
+		case req.Quantity <= 0:
+			return nil, fmt.Errorf("componente %d: la cantidad debe ser mayor a 0", i+1)
+		}
+		seen[req.ComponentID] = true
+		items = append(items, ProductBundleItem{
+			BundleID:    bundleID,
+			ComponentID: req.ComponentID,
+			Quantity:    req.Quantity,
+			SortOrder:   i,
+		})
+	}
+	return items, nil
+}
+
+// OrderItemComponent es el desglose de un combo vendido: las unidades de cada componente que
+// descuenta la confirmación del pedido. Se copia al comprar para no depender de cambios posteriores del combo.
+type OrderItemComponent struct {
+	OrderItemComponentID uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"order_item_component_id"`
+	OrderItemID          uuid.UUID  `gorm:"type:uuid;not null;index" json:"order_item_id"`
+	ProductID            uuid.UUID  `gorm:"type:uuid;not null" json:"product_id"`
+	VariantID            *uuid.UUID `gorm:"type:uuid" json:"variant_id"`
+	SKU                  string     `gorm:"type:varchar(64)" json:"sku"`
+	Name                 string     `gorm:"type:varchar(255);not null" json:"name"`
+	UnitsPerBundle       int        `gorm:"type:integer;not null" json:"units_per_bundle"`
+	Quantity             int        `gorm:"type:integer;not null;check:quantity > 0" json:"quantity"` // Unidades por combo × combos del ítem
+}
+
+// BeforeCreate se ejecuta antes de registrar el desglose de un combo vendido
+func (c *OrderItemComponent) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.OrderItemComponentID == uuid.Nil {
+		c.OrderItemComponentID = uuid.New()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla para OrderItemComponent
+func (OrderItemComponent) TableName() string {
+	return "order_item_components"
+}
+
+// OrderStockDemand suma las unidades que un pedido descuenta de cada producto: los combos
+// cuentan por sus componentes y el resto de ítems por su propio producto
+func OrderStockDemand(items []OrderItem) map[uuid.UUID]int {
+	demand := make(map[uuid.UUID]int, len(items))
+	for _, item := range items {
+		if len(item.Components) == 0 {
+			demand[item.ProductID] += item.Quantity
+			continue
+		}
+		for _, component := range item.Components {
+			demand[component.ProductID] += component.Quantity
+		}
+	}
+	return demand
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxProductImages    = 10 // Imágenes por producto
+	MaxImagesPerRequest = 5  // Archivos por petición de subida
+)
+
+// ProductImage es una imagen subida de un producto con sus versiones redimensionadas. La primera
+// según SortOrder es la principal y su versión mediana se copia en Product.ImageURL.
+type ProductImage struct {
+	ImageID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"image_id"`
+	ProductID        uuid.UUID `gorm:"type:uuid;not null;index" json:"product_id"`
+	SortOrder        int       `gorm:"type:integer;not null;default:0" json:"sort_order"`
+	URL              string    `gorm:"type:text;not null" json:"url"` // Archivo original
+	MediumURL        string    `gorm:"type:text" json:"medium_url"`
+	MediumWebPURL    string    `gorm:"column:medium_webp_url;type:text" json:"medium_webp_url"`
+	ThumbnailURL     string    `gorm:"type:text" json:"thumbnail_url"`
+	ThumbnailWebPURL string    `gorm:"column:thumbnail_webp_url;type:text" json:"thumbnail_webp_url"`
+	Width            int       `gorm:"type:integer;not null" json:"width"`
+	Height           int       `gorm:"type:integer;not null" json:"height"`
+	ContentType      string    `gorm:"type:varchar(50);not null" json:"content_type"`
+	SizeBytes        int64     `gorm:"type:bigint;not null" json:"size_bytes"`
+	StorageKeys      string    `gorm:"type:text;not null" json:"-"` // Claves de todos los archivos, una por línea
+	CreatedAt        time.Time `gorm:"not null;default:now()" json:"created_at"`
+}
+
+// BeforeCreate se ejecuta antes de crear una nueva imagen
+func (i *ProductImage) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.ImageID == uuid.Nil {
+		i.ImageID = uuid.New()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla para ProductImage
+func (ProductImage) TableName() string {
+	return "product_images"
+}
+
+// Keys devuelve las claves de almacenamiento de todos los archivos de la imagen
+func (i *ProductImage) Keys() []string {
+	if i.StorageKeys == "" {
+		return nil
+	}
+	return strings.Split(i.StorageKeys, "\n")
+}
+
+// AddFile registra un archivo guardado y asigna su URL según la versión ("original", "medium", "thumb")
+func (i *ProductImage) AddFile(size, ext, key, url string) {
+	if i.StorageKeys != "" {
+		i.StorageKeys += "\n"
+	}
+	i.StorageKeys += key
+
+	webp := ext == "webp"
+	switch {
+	case size == "original":
+		i.URL = url
+	case size == "medium" && webp:
+		i.MediumWebPURL = url
+	case size == "medium":
+		i.MediumURL = url
+	case size == "thumb" && webp:
+		i.ThumbnailWebPURL = url
+	case size == "thumb":
+		i.ThumbnailURL = url
+	}
+}
+
+// ProductImageKey arma la clave de almacenamiento de un archivo de la imagen
+func ProductImageKey(productID, imageID uuid.UUID, size, ext string) string {
+	return "products/" + productID.String() + "/" + imageID.String() + "/" + size + "." + ext
+}
+
+// ReorderImagesRequest representa el nuevo orden de las imágenes de un producto
+type ReorderImagesRequest struct {
+	ImageIDs []string `json:"image_ids" validate:"required,min=1"`
+}
@@ -0,0 +1,492 @@
+package models
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"backend/internal/export"
+
+	"github.com/google/uuid"
+)
+
+// MaxProductImportRows es la cantidad máxima de filas de un archivo de importación
+const MaxProductImportRows = 5000
+
+// ProductCSVColumns son las columnas del archivo de productos, en el orden de la exportación.
+// La importación acepta las columnas en cualquier orden y exige al menos name o sku.
+var ProductCSVColumns = []interface{}{
+	"sku", "name", "description", "category", "price", "stock_quantity",
+	"unit_of_measure", "package_size", "is_active",
+}
+
+var (
+	ErrEmptyProductCSV      = errors.New("el archivo está vacío")
+	ErrProductCSVHeader     = errors.New("el encabezado debe incluir la columna name o sku")
+	ErrTooManyProductRows   = fmt.Errorf("el archivo supera las %d filas", MaxProductImportRows)
+	ErrUnreadableProductCSV = errors.New("el archivo no es un CSV válido")
+)
+
+// ProductExportRow es un producto tal como se escribe en el archivo exportado
+type ProductExportRow struct {
+	SKU           string  `gorm:"column:sku"`
+	Name          string  `gorm:"column:name"`
+	Description   string  `gorm:"column:description"`
+	Category      string  `gorm:"column:category"`
+	Price         float64 `gorm:"column:price"`
+	StockQuantity int     `gorm:"column:stock_quantity"`
+	UnitOfMeasure string  `gorm:"column:unit_of_measure"`
+	PackageSize   string  `gorm:"column:package_size"`
+	IsActive      bool    `gorm:"column:is_active"`
+}
+
+// Values devuelve la fila en el orden de ProductCSVColumns
+func (r ProductExportRow) Values() []interface{} {
+	return []interface{}{
+		r.SKU, r.Name, r.Description, r.Category, r.Price, r.StockQuantity,
+		r.UnitOfMeasure, r.PackageSize, strconv.FormatBool(r.IsActive),
+	}
+}
+
+// ProductImportError es un error de validación de una columna de una fila
+type ProductImportError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ProductImportRow es una fila leída del archivo. Los campos vacíos conservan el valor actual
+// del producto al actualizar.
+type ProductImportRow struct {
+	Line          int
+	SKU           string
+	Name          string
+	Description   *string
+	Category      string
+	Price         *float64
+	StockQuantity *int
+	UnitOfMeasure string
+	PackageSize   *string
+	IsActive      *bool
+	Errors        []ProductImportError
+}
+
+func (r *ProductImportRow) addError(field, message string) {
+	r.Errors = append(r.Errors, ProductImportError{Field: field, Message: message})
+}
+
+// ParseProductCSV lee el archivo de productos. Acepta BOM UTF-8, separador coma o punto y coma
+// (Excel en español) y decimales con punto o coma. Los errores de cada fila quedan en la fila.
+func ParseProductCSV(r io.Reader) ([]ProductImportRow, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, ErrUnreadableProductCSV
+	}
+	text := strings.TrimPrefix(string(content), "\xEF\xBB\xBF")
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrEmptyProductCSV
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if header, _, _ := strings.Cut(text, "\n"); strings.Count(header, ";") > strings.Count(header, ",") {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, ErrUnreadableProductCSV
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	_, hasName := columns["name"]
+	_, hasSKU := columns["sku"]
+	if !hasName && !hasSKU {
+		return nil, ErrProductCSVHeader
+	}
+
+	var rows []ProductImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnreadableProductCSV, err)
+		}
+		line, _ := reader.FieldPos(0)
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) == MaxProductImportRows {
+			return nil, ErrTooManyProductRows
+		}
+		rows = append(rows, parseProductRecord(line, record, columns))
+	}
+	if len(rows) == 0 {
+		return nil, ErrEmptyProductCSV
+	}
+	return rows, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseProductRecord convierte un registro en fila validando cada columna
+func parseProductRecord(line int, record []string, columns map[string]int) ProductImportRow {
+	get := func(column string) (string, bool) {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return "", false
+		}
+		// Los textos con forma de fórmula llegan escapados si el archivo salió de la exportación
+		return export.UnescapeFormula(strings.TrimSpace(record[i])), true
+	}
+
+	row := ProductImportRow{Line: line}
+	row.SKU, _ = get("sku")
+	row.SKU = NormalizeSKU(row.SKU)
+	row.Name, _ = get("name")
+	row.Category, _ = get("category")
+	row.UnitOfMeasure, _ = get("unit_of_measure")
+
+	if row.SKU != "" && !skuPattern.MatchString(row.SKU) {
+		row.addError("sku", "SKU inválido: use letras, números, punto, guion o guion bajo (máximo 64)")
+	}
+	if row.Name == "" && row.SKU == "" {
+		row.addError("name", "se requiere name o sku para identificar el producto")
+	}
+	if len(row.Name) > 255 {
+		row.addError("name", "el nombre supera los 255 caracteres")
+	}
+	if len(row.UnitOfMeasure) > 50 {
+		row.addError("unit_of_measure", "la unidad supera los 50 caracteres")
+	}
+
+	if value, ok := get("description"); ok && value != "" {
+		row.Description = &value
+	}
+	if value, ok := get("package_size"); ok && value != "" {
+		if len(value) > 50 {
+			row.addError("package_size", "la presentación supera los 50 caracteres")
+		}
+		row.PackageSize = &value
+	}
+	if value, ok := get("price"); ok && value != "" {
+		if !strings.Contains(value, ".") {
+			value = strings.Replace(value, ",", ".", 1)
+		}
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(price) || price <= 0 {
+			row.addError("price", "el precio debe ser un número mayor a 0")
+		} else {
+			price = RoundCurrency(price)
+			row.Price = &price
+		}
+	}
+	if value, ok := get("stock_quantity"); ok && value != "" {
+		stock, err := strconv.Atoi(value)
+		if err != nil || stock < 0 {
+			row.addError("stock_quantity", "el stock debe ser un entero mayor o igual a 0")
+		} else {
+			row.StockQuantity = &stock
+		}
+	}
+	if value, ok := get("is_active"); ok && value != "" {
+		active, valid := parseImportBool(value)
+		if !valid {
+			row.addError("is_active", "use true/false, sí/no o 1/0")
+		} else {
+			row.IsActive = &active
+		}
+	}
+	return row
+}
+
+func parseImportBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "true", "1", "si", "sí", "yes", "activo":
+		return true, true
+	case "false", "0", "no", "inactivo":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// ProductImportIndex reúne los productos, SKUs y categorías existentes para resolver cada fila
+type ProductImportIndex struct {
+	productsByID     map[uuid.UUID]*Product
+	productsByName   map[string]*Product
+	variantsBySKU    map[string]ProductVariant
+	defaultSKU       map[uuid.UUID]string
+	categoriesByName map[string]uuid.UUID
+}
+
+// NewProductImportIndex indexa productos por ID y nombre, variantes por SKU y categorías por nombre
+func NewProductImportIndex(products []*Product, variants []ProductVariant, categories []Category) *ProductImportIndex {
+	idx := &ProductImportIndex{
+		productsByID:     make(map[uuid.UUID]*Product, len(products)),
+		productsByName:   make(map[string]*Product, len(products)),
+		variantsBySKU:    make(map[string]ProductVariant, len(variants)),
+		defaultSKU:       make(map[uuid.UUID]string, len(products)),
+		categoriesByName: make(map[string]uuid.UUID, len(categories)),
+	}
+	for _, p := range products {
+		idx.productsByID[p.ProductID] = p
+		idx.productsByName[importKey(p.Name)] = p
+	}
+	for _, v := range variants {
+		idx.variantsBySKU[v.SKU] = v
+		if v.IsDefault {
+			idx.defaultSKU[v.ProductID] = v.SKU
+		}
+	}
+	for _, c := range categories {
+		idx.categoriesByName[importKey(c.Name)] = c.CategoryID
+	}
+	return idx
+}
+
+func importKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ProductImportAction es lo que la importación hace con una fila
+type ProductImportAction string
+
+const (
+	ProductImportCreate    ProductImportAction = "create"
+	ProductImportUpdate    ProductImportAction = "update"
+	ProductImportUnchanged ProductImportAction = "unchanged"
+)
+
+// ProductImportChange es un producto a crear o actualizar
+type ProductImportChange struct {
+	Product *Product
+	IsNew   bool
+	SKU     string // Nuevo SKU de la variante predeterminada ("" = sin cambios)
+}
+
+// ProductImportRowResult es el resultado de una fila
+type ProductImportRowResult struct {
+	Line      int                  `json:"line"`
+	Action    ProductImportAction  `json:"action,omitempty"`
+	ProductID *uuid.UUID           `json:"product_id,omitempty"`
+	Name      string               `json:"name"`
+	SKU       string               `json:"sku,omitempty"`
+	Errors    []ProductImportError `json:"errors,omitempty"`
+}
+
+// ProductImportResult resume la importación. Si alguna fila tiene errores no se aplica ningún cambio.
+type ProductImportResult struct {
+	DryRun    bool                     `json:"dry_run"`
+	Applied   bool                     `json:"applied"`
+	TotalRows int                      `json:"total_rows"`
+	Created   int                      `json:"created"`
+	Updated   int                      `json:"updated"`
+	Unchanged int                      `json:"unchanged"`
+	Failed    int                      `json:"failed"`
+	Rows      []ProductImportRowResult `json:"rows"`
+}
+
+// PlanProductImport resuelve cada fila contra los datos existentes: busca el producto por SKU
+// (de su variante predeterminada) o por nombre, valida categoría, duplicados y nombres en uso,
+// y devuelve los cambios a aplicar junto con el resultado por fila.
+func PlanProductImport(rows []ProductImportRow, idx *ProductImportIndex) ([]ProductImportChange, *ProductImportResult) {
+	result := &ProductImportResult{TotalRows: len(rows), Rows: make([]ProductImportRowResult, 0, len(rows))}
+	changes := make([]ProductImportChange, 0, len(rows))
+
+	// Línea que ya usó cada producto, nombre y SKU dentro del archivo
+	seenProducts := make(map[uuid.UUID]int)
+	seenNames := make(map[string]int)
+	seenSKUs := make(map[string]int)
+
+	for i := range rows {
+		row := &rows[i]
+		rowResult := ProductImportRowResult{Line: row.Line, Name: row.Name, SKU: row.SKU}
+
+		var existing *Product
+		if row.SKU != "" {
+			if variant, ok := idx.variantsBySKU[row.SKU]; ok {
+				if !variant.IsDefault {
+					row.addError("sku", "el SKU es de una variante no predeterminada; edítela desde las variantes del producto")
+				}
+				existing = idx.productsByID[variant.ProductID]
+				if existing == nil {
+					row.addError("sku", "el SKU es de un producto eliminado; restáurelo antes de importarlo")
+				}
+			}
+		}
+		if existing == nil && row.Name != "" {
+			existing = idx.productsByName[importKey(row.Name)]
+		}
+
+		var change ProductImportChange
+		if existing != nil {
+			change = planProductUpdate(row, existing, idx)
+			if line, dup := seenProducts[existing.ProductID]; dup {
+				row.addError("", fmt.Sprintf("el producto ya aparece en la línea %d", line))
+			}
+		} else {
+			change = planProductCreate(row, idx)
+		}
+
+		name := importKey(change.Product.Name)
+		if line, dup := seenNames[name]; dup && name != "" {
+			row.addError("name", fmt.Sprintf("el nombre ya aparece en la línea %d", line))
+		}
+		if line, dup := seenSKUs[row.SKU]; dup && row.SKU != "" {
+			row.addError("sku", fmt.Sprintf("el SKU ya aparece en la línea %d", line))
+		}
+
+		// Solo las filas válidas reservan producto, nombre y SKU, para no arrastrar errores a las siguientes
+		if len(row.Errors) == 0 {
+			seenProducts[change.Product.ProductID] = row.Line
+			seenNames[name] = row.Line
+			if row.SKU != "" {
+				seenSKUs[row.SKU] = row.Line
+			}
+		}
+
+		rowResult.Name = change.Product.Name
+		if len(row.Errors) > 0 {
+			rowResult.Errors = row.Errors
+			result.Failed++
+			result.Rows = append(result.Rows, rowResult)
+			continue
+		}
+
+		productID := change.Product.ProductID
+		rowResult.ProductID = &productID
+		switch {
+		case change.IsNew:
+			rowResult.Action = ProductImportCreate
+			result.Created++
+		case change.SKU == "" && sameImportedFields(existing, change.Product):
+			rowResult.Action = ProductImportUnchanged
+			result.Unchanged++
+		default:
+			rowResult.Action = ProductImportUpdate
+			result.Updated++
+		}
+		if rowResult.Action != ProductImportUnchanged {
+			changes = append(changes, change)
+		}
+		result.Rows = append(result.Rows, rowResult)
+	}
+
+	return changes, result
+}
+
+// planProductCreate arma un producto nuevo con los valores de la fila
+func planProductCreate(row *ProductImportRow, idx *ProductImportIndex) ProductImportChange {
+	product := &Product{
+		ProductID:     uuid.New(),
+		Name:          row.Name,
+		UnitOfMeasure: "unidad",
+		IsActive:      true,
+	}
+	if row.Name == "" {
+		row.addError("name", "el SKU no existe: se requiere el nombre para crear el producto")
+	}
+	if row.Price == nil && !hasFieldError(row, "price") {
+		row.addError("price", "se requiere el precio para crear el producto")
+	}
+	applyImportRow(row, product, idx)
+	return ProductImportChange{Product: product, IsNew: true, SKU: row.SKU}
+}
+
+// planProductUpdate copia el producto existente y le aplica los valores de la fila
+func planProductUpdate(row *ProductImportRow, existing *Product, idx *ProductImportIndex) ProductImportChange {
+	updated := *existing
+	updated.Category = nil
+	updated.Images = nil
+	updated.Variants = nil
+	updated.CurrentOffer = nil
+	updated.Ratings = nil
+	updated.BundleItems = nil
+
+	if row.Name != "" && importKey(row.Name) != importKey(existing.Name) {
+		if other, taken := idx.productsByName[importKey(row.Name)]; taken && other.ProductID != existing.ProductID {
+			row.addError("name", "ya existe otro producto con ese nombre")
+		}
+		updated.Name = row.Name
+	}
+	applyImportRow(row, &updated, idx)
+
+	change := ProductImportChange{Product: &updated}
+	if row.SKU != "" && row.SKU != idx.defaultSKU[existing.ProductID] {
+		if _, taken := idx.variantsBySKU[row.SKU]; taken {
+			row.addError("sku", "el SKU ya pertenece a otro producto")
+		}
+		change.SKU = row.SKU
+	}
+	return change
+}
+
+// applyImportRow copia al producto los campos enviados en la fila
+func applyImportRow(row *ProductImportRow, product *Product, idx *ProductImportIndex) {
+	if row.Description != nil {
+		product.Description = *row.Description
+	}
+	if row.Category != "" {
+		categoryID, ok := idx.categoriesByName[importKey(row.Category)]
+		if !ok {
+			row.addError("category", fmt.Sprintf("la categoría %q no existe", row.Category))
+		} else {
+			product.CategoryID = &categoryID
+		}
+	}
+	if row.Price != nil {
+		product.Price = *row.Price
+	}
+	// El stock de un combo se calcula a partir de sus componentes: la columna se ignora
+	if row.StockQuantity != nil && !product.IsBundle {
+		product.StockQuantity = *row.StockQuantity
+	}
+	if row.UnitOfMeasure != "" {
+		product.UnitOfMeasure = row.UnitOfMeasure
+	}
+	if row.PackageSize != nil {
+		product.PackageSize = *row.PackageSize
+	}
+	if row.IsActive != nil {
+		product.IsActive = *row.IsActive
+	}
+}
+
+func hasFieldError(row *ProductImportRow, field string) bool {
+	for _, e := range row.Errors {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// sameImportedFields indica si la fila no cambia ninguno de los campos importables
+func sameImportedFields(a, b *Product) bool {
+	sameCategory := (a.CategoryID == nil && b.CategoryID == nil) ||
+		(a.CategoryID != nil && b.CategoryID != nil && *a.CategoryID == *b.CategoryID)
+	return a.Name == b.Name &&
+		a.Description == b.Description &&
+		sameCategory &&
+		a.Price == b.Price &&
+		a.StockQuantity == b.StockQuantity &&
+		a.UnitOfMeasure == b.UnitOfMeasure &&
+		a.PackageSize == b.PackageSize &&
+		a.IsActive == b.IsActive
+}
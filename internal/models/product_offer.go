@@ -22,6 +22,7 @@ const (
 type ProductOffer struct {
 	OfferID       uuid.UUID         `json:"offer_id" gorm:"primaryKey;column:offer_id;type:uuid;default:gen_random_uuid()"`
 	ProductID     uuid.UUID         `json:"product_id" gorm:"column:product_id;type:uuid;not null"`
+	VariantID     *uuid.UUID        `json:"variant_id,omitempty" gorm:"column:variant_id;type:uuid"` // nil = aplica a todas las variantes
 	DiscountType  OfferDiscountType `json:"discount_type" gorm:"column:discount_type;type:offer_discount_type;not null"`
 	DiscountValue float64           `json:"discount_value" gorm:"column:discount_value;type:decimal(10,2);not null"`
 	StartDate     time.Time         `json:"start_date" gorm:"column:start_date;type:timestamptz;not null"`
@@ -32,8 +33,9 @@ type ProductOffer struct {
 	UpdatedAt     time.Time         `json:"updated_at" gorm:"column:updated_at;type:timestamptz;default:CURRENT_TIMESTAMP"`
 
 	// Relaciones
-	Product *Product `json:"product,omitempty" gorm:"foreignKey:ProductID;references:ProductID"`
-	Creator *User    `json:"creator,omitempty" gorm:"foreignKey:CreatedBy;references:UserID"`
+	Product *Product        `json:"product,omitempty" gorm:"foreignKey:ProductID;references:ProductID"`
+	Variant *ProductVariant `json:"variant,omitempty" gorm:"foreignKey:VariantID;references:VariantID"`
+	Creator *User           `json:"creator,omitempty" gorm:"foreignKey:CreatedBy;references:UserID"`
 }
 
 // TableName especifica el nombre de la tabla
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PriceChangeSource es el origen de un cambio de precio
+type PriceChangeSource string
+
+const (
+	PriceSourceCreated   PriceChangeSource = "CREATED"   // Precio inicial del producto
+	PriceSourceManual    PriceChangeSource = "MANUAL"    // Edición del producto
+	PriceSourceVariant   PriceChangeSource = "VARIANT"   // Cambio de precio de la variante predeterminada
+	PriceSourceImport    PriceChangeSource = "IMPORT"    // Importación CSV
+	PriceSourceScheduled PriceChangeSource = "SCHEDULED" // Cambio programado aplicado por la tarea en segundo plano
+)
+
+// ProductPriceHistory registra cada cambio del precio de un producto
+type ProductPriceHistory struct {
+	HistoryID         uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"history_id"`
+	ProductID         uuid.UUID         `gorm:"type:uuid;not null;index" json:"product_id"`
+	OldPrice          *float64          `gorm:"type:decimal(10,2)" json:"old_price"` // nil en el precio inicial
+	NewPrice          float64           `gorm:"type:decimal(10,2);not null" json:"new_price"`
+	Source            PriceChangeSource `gorm:"type:varchar(20);not null" json:"source"`
+	ScheduledChangeID *uuid.UUID        `gorm:"type:uuid" json:"scheduled_change_id,omitempty"`
+	ChangedBy         *uuid.UUID        `gorm:"type:uuid" json:"changed_by,omitempty"`
+	ChangedAt         time.Time         `gorm:"not null;default:now()" json:"changed_at"`
+}
+
+// BeforeCreate se ejecuta antes de registrar un cambio de precio
+func (h *ProductPriceHistory) BeforeCreate(tx *gorm.DB) (err error) {
+	if h.HistoryID == uuid.Nil {
+		h.HistoryID = uuid.New()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla para ProductPriceHistory
+func (ProductPriceHistory) TableName() string {
+	return "product_price_history"
+}
+
+// ScheduledPriceStatus es el estado de un cambio de precio programado
+type ScheduledPriceStatus string
+
+const (
+	ScheduledPricePending   ScheduledPriceStatus = "PENDING"
+	ScheduledPriceApplied   ScheduledPriceStatus = "APPLIED"
+	ScheduledPriceCancelled ScheduledPriceStatus = "CANCELLED"
+)
+
+// ErrScheduledPriceConflict indica que el producto ya tiene un cambio pendiente para esa fecha
+var ErrScheduledPriceConflict = errors.New("ya hay un cambio de precio programado para esa fecha")
+
+// ScheduledPriceChange es un cambio de precio que se aplica automáticamente desde EffectiveAt
+type ScheduledPriceChange struct {
+	ScheduleID  uuid.UUID            `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"schedule_id"`
+	ProductID   uuid.UUID            `gorm:"type:uuid;not null;index" json:"product_id"`
+	NewPrice    float64              `gorm:"type:decimal(10,2);not null;check:new_price > 0" json:"new_price"`
+	EffectiveAt time.Time            `gorm:"not null" json:"effective_at"`
+	Status      ScheduledPriceStatus `gorm:"type:varchar(20);not null;default:'PENDING'" json:"status"`
+	Note        string               `gorm:"type:text" json:"note,omitempty"`
+	CreatedBy   *uuid.UUID           `gorm:"type:uuid" json:"created_by,omitempty"`
+	AppliedAt   *time.Time           `json:"applied_at,omitempty"`
+	CreatedAt   time.Time            `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt   time.Time            `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// BeforeCreate se ejecuta antes de programar un cambio de precio
+func (s *ScheduledPriceChange) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.ScheduleID == uuid.Nil {
+		s.ScheduleID = uuid.New()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla para ScheduledPriceChange
+func (ScheduledPriceChange) TableName() string {
+	return "scheduled_price_changes"
+}
+
+// SchedulePriceChangeRequest es la solicitud para programar un cambio de precio
+type SchedulePriceChangeRequest struct {
+	NewPrice    float64   `json:"new_price"`
+	EffectiveAt time.Time `json:"effective_at"` // RFC 3339, debe ser futura
+	Note        string    `json:"note"`
+}
+
+// Validate verifica el precio y que la fecha de vigencia sea posterior a now
+func (r *SchedulePriceChangeRequest) Validate(now time.Time) error {
+	r.Note = strings.TrimSpace(r.Note)
+	if r.NewPrice <= 0 {
+		return errors.New("el precio debe ser mayor a 0")
+	}
+	if r.EffectiveAt.IsZero() {
+		return errors.New("la fecha de vigencia es obligatoria")
+	}
+	if !r.EffectiveAt.After(now) {
+		return errors.New("la fecha de vigencia debe ser futura")
+	}
+	r.NewPrice = RoundCurrency(r.NewPrice)
+	return nil
+}
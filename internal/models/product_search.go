@@ -0,0 +1,145 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// Ordenamientos disponibles en la búsqueda de productos
+const (
+	ProductSortRelevance  = "relevance"
+	ProductSortPriceAsc   = "price_asc"
+	ProductSortPriceDesc  = "price_desc"
+	ProductSortPopularity = "popularity"
+	ProductSortRating     = "rating"
+	ProductSortNewest     = "newest"
+)
+
+// Paginación de la búsqueda de productos
+const (
+	ProductSearchDefaultPageSize = 20
+	ProductSearchMaxPageSize     = 100
+)
+
+// ProductPriceRange es un rango de precio final [Min, Max) para las facetas; Max 0 = sin tope
+type ProductPriceRange struct {
+	Key string
+	Min float64
+	Max float64
+}
+
+// ProductPriceRanges son los rangos de precio (S/) que se cuentan en las facetas
+var ProductPriceRanges = []ProductPriceRange{
+	{Key: "0-20", Min: 0, Max: 20},
+	{Key: "20-50", Min: 20, Max: 50},
+	{Key: "50-100", Min: 50, Max: 100},
+	{Key: "100+", Min: 100},
+}
+
+// ProductRatingThresholds son las calificaciones mínimas que se cuentan en las facetas
+var ProductRatingThresholds = []int{4, 3, 2, 1}
+
+// ProductSearchParams son los criterios de búsqueda de productos
+type ProductSearchParams struct {
+	Query      string
+	CategoryID *uuid.UUID
+	MinPrice   *float64 // Sobre el precio final, con la oferta vigente aplicada
+	MaxPrice   *float64
+	OnOffer    bool
+	InStock    bool
+	MinRating  *float64
+	Sort       string
+	Page       int
+	PageSize   int
+}
+
+// Normalize valida los criterios y completa el orden y la paginación por defecto
+func (p *ProductSearchParams) Normalize() error {
+	p.Query = strings.TrimSpace(p.Query)
+
+	if p.MinPrice != nil && *p.MinPrice < 0 || p.MaxPrice != nil && *p.MaxPrice < 0 {
+		return errors.New("el precio no puede ser negativo")
+	}
+	if p.MinPrice != nil && p.MaxPrice != nil && *p.MinPrice > *p.MaxPrice {
+		return errors.New("el precio mínimo no puede ser mayor al máximo")
+	}
+	if p.MinRating != nil && (*p.MinRating < 0 || *p.MinRating > 5) {
+		return errors.New("la calificación mínima debe estar entre 0 y 5")
+	}
+
+	switch p.Sort {
+	case "":
+		p.Sort = ProductSortPopularity
+		if p.Query != "" {
+			p.Sort = ProductSortRelevance
+		}
+	case ProductSortRelevance:
+		if p.Query == "" {
+			p.Sort = ProductSortPopularity
+		}
+	case ProductSortPriceAsc, ProductSortPriceDesc, ProductSortPopularity, ProductSortRating, ProductSortNewest:
+	default:
+		return errors.New("orden inválido, use relevance, price_asc, price_desc, popularity, rating o newest")
+	}
+
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PageSize < 1 {
+		p.PageSize = ProductSearchDefaultPageSize
+	}
+	if p.PageSize > ProductSearchMaxPageSize {
+		p.PageSize = ProductSearchMaxPageSize
+	}
+	return nil
+}
+
+// Offset devuelve cuántos resultados saltar para la página pedida
+func (p *ProductSearchParams) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// BuildPrefixTSQuery convierte el texto del usuario en una consulta to_tsquery donde cada
+// palabra se busca por prefijo ("bal 10" -> "bal:* & 10:*"). Descarta los operadores del usuario.
+func BuildPrefixTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, w+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// FacetCount es la cantidad de productos para un valor de una faceta
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// ProductSearchFacets son los conteos para refinar la búsqueda. Cada faceta aplica todos los
+// filtros excepto el propio, para mostrar cuántos resultados habría al cambiarlo.
+type ProductSearchFacets struct {
+	Categories  []FacetCount `json:"categories"`
+	PriceRanges []FacetCount `json:"price_ranges"`
+	MinRatings  []FacetCount `json:"min_ratings"` // Acumulados: "4" = calificación de 4 o más
+	OnOffer     int64        `json:"on_offer"`
+	InStock     int64        `json:"in_stock"`
+}
+
+// ProductSearchResult es una página de resultados de búsqueda con sus facetas
+type ProductSearchResult struct {
+	Products   []*Product          `json:"products"`
+	TotalCount int64               `json:"total_count"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"page_size"`
+	TotalPages int                 `json:"total_pages"`
+	Sort       string              `json:"sort"`
+	Facets     ProductSearchFacets `json:"facets"`
+}
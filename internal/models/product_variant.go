@@ -0,0 +1,182 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var skuPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9._-]{0,63}$`)
+
+// ProductVariant es una presentación vendible de un producto (tamaño, tipo de válvula, marca)
+// con su propio SKU, precio, stock y oferta. Todo producto tiene una variante predeterminada.
+type ProductVariant struct {
+	VariantID     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"variant_id"`
+	ProductID     uuid.UUID `gorm:"type:uuid;not null;index" json:"product_id"`
+	SKU           string    `gorm:"type:varchar(64);not null;unique" json:"sku"`
+	Name          string    `gorm:"type:varchar(150);not null" json:"name"`
+	Size          string    `gorm:"type:varchar(50)" json:"size"`
+	ValveType     string    `gorm:"type:varchar(50)" json:"valve_type"`
+	Brand         string    `gorm:"type:varchar(100)" json:"brand"`
+	Price         float64   `gorm:"type:decimal(10,2);not null;check:price > 0" json:"price"`
+	StockQuantity int       `gorm:"type:integer;not null;default:0;check:stock_quantity >= 0" json:"stock_quantity"`
+	IsDefault     bool      `gorm:"not null;default:false" json:"is_default"`
+	IsActive      bool      `gorm:"not null;default:true" json:"is_active"`
+	SortOrder     int       `gorm:"type:integer;not null;default:0" json:"sort_order"`
+	CreatedAt     time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"not null;default:now()" json:"updated_at"`
+
+	CurrentOffer *ProductOffer `gorm:"foreignKey:VariantID;references:VariantID" json:"current_offer,omitempty"`
+	FinalPrice   float64       `gorm:"-" json:"final_price"` // Precio con la oferta vigente (de la variante o del producto)
+}
+
+// BeforeCreate se ejecuta antes de crear una nueva variante
+func (v *ProductVariant) BeforeCreate(tx *gorm.DB) (err error) {
+	if v.VariantID == uuid.Nil {
+		v.VariantID = uuid.New()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla para ProductVariant
+func (ProductVariant) TableName() string {
+	return "product_variants"
+}
+
+// Validate normaliza el SKU y el nombre y verifica precio y stock
+func (v *ProductVariant) Validate() error {
+	v.SKU = NormalizeSKU(v.SKU)
+	if !skuPattern.MatchString(v.SKU) {
+		return errors.New("SKU inválido: use letras, números, punto, guion o guion bajo (máximo 64)")
+	}
+	if v.Name = strings.TrimSpace(v.Name); v.Name == "" {
+		v.Name = v.describe()
+	}
+	if v.Name == "" {
+		return errors.New("la variante necesita un nombre o al menos tamaño, válvula o marca")
+	}
+	if v.Price <= 0 {
+		return errors.New("el precio debe ser mayor a 0")
+	}
+	if v.StockQuantity < 0 {
+		return errors.New("el stock no puede ser negativo")
+	}
+	return nil
+}
+
+// ApplyFinalPrice calcula el precio final: la oferta de la variante tiene prioridad sobre la del producto
+func (v *ProductVariant) ApplyFinalPrice(productOffer *ProductOffer) float64 {
+	v.FinalPrice = v.Price
+	switch {
+	case v.CurrentOffer != nil && v.CurrentOffer.IsCurrentlyActive():
+		v.FinalPrice = v.CurrentOffer.CalculateFinalPrice(v.Price)
+	case productOffer != nil && productOffer.VariantID == nil && productOffer.IsCurrentlyActive():
+		v.FinalPrice = productOffer.CalculateFinalPrice(v.Price)
+	}
+	return v.FinalPrice
+}
+
+// describe arma un nombre a partir de los atributos ("10kg · Premium · Solgas")
+func (v *ProductVariant) describe() string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{v.Size, v.ValveType, v.Brand} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// NormalizeSKU limpia espacios y pasa el SKU a mayúsculas
+func NormalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
+// DefaultVariantSKU genera el SKU de la variante predeterminada de un producto
+func DefaultVariantSKU(productID uuid.UUID) string {
+	return fmt.Sprintf("SKU-%s", strings.ToUpper(productID.String()[:8]))
+}
+
+// NewDefaultVariant crea la variante predeterminada de un producto con su precio y stock
+func NewDefaultVariant(product *Product) *ProductVariant {
+	name := product.PackageSize
+	if name == "" {
+		name = product.Name
+	}
+	return &ProductVariant{
+		ProductID:     product.ProductID,
+		SKU:           DefaultVariantSKU(product.ProductID),
+		Name:          name,
+		Size:          product.PackageSize,
+		Price:         product.Price,
+		StockQuantity: product.StockQuantity,
+		IsDefault:     true,
+		IsActive:      true,
+	}
+}
+
+// CreateVariantRequest representa la solicitud para crear una variante
+type CreateVariantRequest struct {
+	SKU           string  `json:"sku" validate:"required,max=64"`
+	Name          string  `json:"name"` // Por defecto se arma con tamaño, válvula y marca
+	Size          string  `json:"size"`
+	ValveType     string  `json:"valve_type"`
+	Brand         string  `json:"brand"`
+	Price         float64 `json:"price" validate:"required,gt=0"`
+	StockQuantity int     `json:"stock_quantity" validate:"min=0"`
+	SortOrder     int     `json:"sort_order"`
+	IsDefault     bool    `json:"is_default"`
+}
+
+// UpdateVariantRequest representa la solicitud para actualizar una variante
+type UpdateVariantRequest struct {
+	SKU           *string  `json:"sku,omitempty"`
+	Name          *string  `json:"name,omitempty"`
+	Size          *string  `json:"size,omitempty"`
+	ValveType     *string  `json:"valve_type,omitempty"`
+	Brand         *string  `json:"brand,omitempty"`
+	Price         *float64 `json:"price,omitempty"`
+	StockQuantity *int     `json:"stock_quantity,omitempty"`
+	SortOrder     *int     `json:"sort_order,omitempty"`
+	IsDefault     *bool    `json:"is_default,omitempty"`
+	IsActive      *bool    `json:"is_active,omitempty"`
+}
+
+// Apply copia los campos enviados a la variante
+func (r *UpdateVariantRequest) Apply(v *ProductVariant) {
+	if r.SKU != nil {
+		v.SKU = *r.SKU
+	}
+	if r.Name != nil {
+		v.Name = *r.Name
+	}
+	if r.Size != nil {
+		v.Size = *r.Size
+	}
+	if r.ValveType != nil {
+		v.ValveType = *r.ValveType
+	}
+	if r.Brand != nil {
+		v.Brand = *r.Brand
+	}
+	if r.Price != nil {
+		v.Price = *r.Price
+	}
+	if r.StockQuantity != nil {
+		v.StockQuantity = *r.StockQuantity
+	}
+	if r.SortOrder != nil {
+		v.SortOrder = *r.SortOrder
+	}
+	if r.IsDefault != nil {
+		v.IsDefault = *r.IsDefault
+	}
+	if r.IsActive != nil {
+		v.IsActive = *r.IsActive
+	}
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Motivos por los que se recomienda un producto
+const (
+	RecommendationReasonPurchased      = "purchased_before" // El usuario ya lo compró
+	RecommendationReasonFavorite       = "favorite"         // Está en sus favoritos
+	RecommendationReasonBoughtTogether = "bought_together"  // Se compra junto con sus productos
+	RecommendationReasonCategory       = "category"         // Es de una categoría que le interesa
+	RecommendationReasonPopular        = "popular"          // Popularidad general
+)
+
+// Pesos de cada señal en la puntuación de recomendación; la popularidad y la afinidad por
+// categoría se normalizan entre 0 y 1 antes de aplicar el peso
+const (
+	recommendationWeightPopularity = 1.0
+	recommendationWeightCategory   = 2.0
+	recommendationWeightPurchased  = 2.0
+	recommendationWeightFavorite   = 2.5
+	recommendationWeightAssociated = 3.0
+
+	// Una calificación igual o menor a esta excluye el producto de las recomendaciones del usuario
+	recommendationDislikedRating = 2
+)
+
+const (
+	DefaultRecommendedLimit = 10
+	MaxRecommendedLimit     = 30
+
+	// Productos que se puntúan como máximo para recomendar; el resto del catálogo no se carga
+	MaxRecommendationCandidates = 200
+)
+
+// UserProductSignal es la relación de un usuario con un producto: compras entregadas, favorito y calificación
+type UserProductSignal struct {
+	ProductID  uuid.UUID
+	CategoryID *uuid.UUID
+	Purchases  int
+	IsFavorite bool
+	Rating     *int
+}
+
+// RecommendedProduct es un producto recomendado al usuario con su puntuación y los motivos
+type RecommendedProduct struct {
+	Product *Product `json:"product"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// ProductRecommendations son las recomendaciones de la pantalla de inicio. Personalized es false
+// cuando el usuario aún no tiene actividad y se muestran los productos populares.
+type ProductRecommendations struct {
+	Personalized bool                 `json:"personalized"`
+	Products     []RecommendedProduct `json:"products"`
+}
+
+// NormalizeRecommendedLimit aplica el valor por defecto y el máximo de productos recomendados
+func NormalizeRecommendedLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultRecommendedLimit
+	}
+	if limit > MaxRecommendedLimit {
+		return MaxRecommendedLimit
+	}
+	return limit
+}
+
+// RecommendationCandidateKeys devuelve los productos y las categorías que más suman en la
+// puntuación del usuario: sus productos, los que se compran junto con ellos y sus categorías.
+// Los candidatos se cargan priorizándolos y se completan con los más populares.
+func RecommendationCandidateKeys(signals []UserProductSignal, associations []ProductAssociation) (productIDs, categoryIDs []uuid.UUID) {
+	seenProducts := make(map[uuid.UUID]bool)
+	seenCategories := make(map[uuid.UUID]bool)
+	addProduct := func(id uuid.UUID) {
+		if !seenProducts[id] {
+			seenProducts[id] = true
+			productIDs = append(productIDs, id)
+		}
+	}
+	for _, s := range signals {
+		addProduct(s.ProductID)
+		if s.CategoryID != nil && !seenCategories[*s.CategoryID] {
+			seenCategories[*s.CategoryID] = true
+			categoryIDs = append(categoryIDs, *s.CategoryID)
+		}
+	}
+	for _, a := range associations {
+		addProduct(a.RelatedProductID)
+	}
+	return productIDs, categoryIDs
+}
+
+// RecommendProducts ordena los productos candidatos para el usuario combinando sus compras,
+// favoritos y calificaciones, su afinidad por categoría, los productos que se compran junto con
+// los suyos (associations) y la popularidad general. Los productos que el usuario calificó mal no
+// se recomiendan. Devuelve hasta limit productos de mayor a menor puntuación.
+func RecommendProducts(candidates []*Product, signals []UserProductSignal, associations []ProductAssociation, limit int) []RecommendedProduct {
+	byProduct := make(map[uuid.UUID]UserProductSignal, len(signals))
+	affinity := make(map[uuid.UUID]float64)
+	for _, s := range signals {
+		byProduct[s.ProductID] = s
+		if s.CategoryID != nil {
+			affinity[*s.CategoryID] += s.categoryAffinity()
+		}
+	}
+	maxAffinity := 0.0
+	for _, a := range affinity {
+		if a > maxAffinity {
+			maxAffinity = a
+		}
+	}
+
+	// Confianza más alta con la que cada producto se compra junto con alguno del usuario
+	associated := make(map[uuid.UUID]float64)
+	for _, a := range associations {
+		if _, own := byProduct[a.ProductID]; !own {
+			continue
+		}
+		if a.Confidence > associated[a.RelatedProductID] {
+			associated[a.RelatedProductID] = a.Confidence
+		}
+	}
+
+	maxPopularity := 0.0
+	for _, p := range candidates {
+		if p.PopularityScore > maxPopularity {
+			maxPopularity = p.PopularityScore
+		}
+	}
+
+	recommended := make([]RecommendedProduct, 0, len(candidates))
+	for _, p := range candidates {
+		signal, known := byProduct[p.ProductID]
+		if known && signal.Rating != nil && *signal.Rating <= recommendationDislikedRating {
+			continue
+		}
+
+		var score float64
+		var reasons []string
+		if known && signal.Purchases > 0 {
+			score += recommendationWeightPurchased
+			reasons = append(reasons, RecommendationReasonPurchased)
+		}
+		if known && signal.IsFavorite {
+			score += recommendationWeightFavorite
+			reasons = append(reasons, RecommendationReasonFavorite)
+		}
+		if confidence, ok := associated[p.ProductID]; ok {
+			score += recommendationWeightAssociated * confidence
+			reasons = append(reasons, RecommendationReasonBoughtTogether)
+		}
+		if p.CategoryID != nil && maxAffinity > 0 && affinity[*p.CategoryID] > 0 {
+			score += recommendationWeightCategory * affinity[*p.CategoryID] / maxAffinity
+			reasons = append(reasons, RecommendationReasonCategory)
+		}
+		if maxPopularity > 0 && p.PopularityScore > 0 {
+			score += recommendationWeightPopularity * p.PopularityScore / maxPopularity
+			if len(reasons) == 0 {
+				reasons = append(reasons, RecommendationReasonPopular)
+			}
+		}
+		if reasons == nil {
+			reasons = []string{}
+		}
+
+		recommended = append(recommended, RecommendedProduct{Product: p, Score: roundTo(score, 4), Reasons: reasons})
+	}
+
+	sort.SliceStable(recommended, func(i, j int) bool {
+		return recommended[i].Score > recommended[j].Score
+	})
+	if len(recommended) > limit {
+		recommended = recommended[:limit]
+	}
+	return recommended
+}
+
+// categoryAffinity es cuánto aporta el producto al interés del usuario por su categoría: cada
+// compra suma 1, ser favorito suma 2 y la calificación suma o resta según su distancia a 3
+func (s UserProductSignal) categoryAffinity() float64 {
+	affinity := float64(s.Purchases)
+	if s.IsFavorite {
+		affinity += 2
+	}
+	if s.Rating != nil {
+		affinity += float64(*s.Rating - 3)
+	}
+	return affinity
+}
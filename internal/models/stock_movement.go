@@ -0,0 +1,124 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StockMovementType es el origen de un movimiento de stock
+type StockMovementType string
+
+const (
+	StockMovementPurchaseReceipt    StockMovementType = "PURCHASE_RECEIPT"    // Ingreso por compra a proveedor
+	StockMovementSale               StockMovementType = "SALE"                // Salida por pedido confirmado
+	StockMovementCancellationReturn StockMovementType = "CANCELLATION_RETURN" // Reingreso por cancelación de un pedido confirmado
+	StockMovementAdjustment         StockMovementType = "ADJUSTMENT"          // Ajuste manual o por edición del producto
+	StockMovementDamage             StockMovementType = "DAMAGE"              // Baja por daño o pérdida
+)
+
+// Motivos de los movimientos que registra el sistema
+const (
+	StockReasonInitial        = "Stock inicial"
+	StockReasonProductEdit    = "Edición del producto"
+	StockReasonVariantEdit    = "Edición de variantes"
+	StockReasonImport         = "Importación CSV"
+	StockReasonReconciliation = "Conciliación con el libro de movimientos"
+)
+
+// StockMovement es un asiento del libro de movimientos de stock de un producto. El libro solo
+// admite inserciones: la suma de Quantity de un producto es su stock.
+type StockMovement struct {
+	MovementID   uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"movement_id"`
+	ProductID    uuid.UUID         `gorm:"type:uuid;not null;index" json:"product_id"`
+	VariantID    *uuid.UUID        `gorm:"type:uuid" json:"variant_id,omitempty"`
+	BranchID     *uuid.UUID        `gorm:"type:uuid" json:"branch_id,omitempty"`
+	OrderID      *uuid.UUID        `gorm:"type:uuid;index" json:"order_id,omitempty"`
+	MovementType StockMovementType `gorm:"type:varchar(30);not null" json:"movement_type"`
+	Quantity     int               `gorm:"type:integer;not null" json:"quantity"`      // Positivo = ingreso, negativo = salida
+	BalanceAfter int               `gorm:"type:integer;not null" json:"balance_after"` // Stock del producto tras el movimiento
+	Reason       string            `gorm:"type:text" json:"reason,omitempty"`
+	CreatedBy    *uuid.UUID        `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt    time.Time         `gorm:"not null;default:now()" json:"created_at"`
+}
+
+// BeforeCreate se ejecuta antes de registrar un nuevo movimiento
+func (m *StockMovement) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.MovementID == uuid.Nil {
+		m.MovementID = uuid.New()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla para StockMovement
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
+
+// CreateStockMovementRequest es un movimiento registrado a mano por un administrador. Las ventas
+// y los reingresos por cancelación los registra el sistema al cambiar el estado del pedido.
+type CreateStockMovementRequest struct {
+	MovementType StockMovementType `json:"movement_type"`
+	Quantity     int               `json:"quantity"` // Unidades; solo el ajuste admite valores negativos
+	Reason       string            `json:"reason"`
+	VariantID    *uuid.UUID        `json:"variant_id"`
+	BranchID     *uuid.UUID        `json:"branch_id"` // Obligatorio si hay sucursales
+}
+
+// Delta valida la solicitud y devuelve la variación de stock: el ingreso suma, la baja por daño
+// resta y el ajuste se aplica con su signo y requiere un motivo
+func (r *CreateStockMovementRequest) Delta() (int, error) {
+	r.Reason = strings.TrimSpace(r.Reason)
+	switch r.MovementType {
+	case StockMovementPurchaseReceipt:
+		if r.Quantity <= 0 {
+			return 0, errors.New("la cantidad recibida debe ser mayor a 0")
+		}
+		return r.Quantity, nil
+	case StockMovementDamage:
+		if r.Quantity <= 0 {
+			return 0, errors.New("la cantidad dañada debe ser mayor a 0")
+		}
+		return -r.Quantity, nil
+	case StockMovementAdjustment:
+		if r.Quantity == 0 {
+			return 0, errors.New("el ajuste no puede ser 0")
+		}
+		if r.Reason == "" {
+			return 0, errors.New("el ajuste requiere un motivo")
+		}
+		return r.Quantity, nil
+	case StockMovementSale, StockMovementCancellationReturn:
+		return 0, errors.New("las ventas y cancelaciones se registran automáticamente con el pedido")
+	default:
+		return 0, errors.New("tipo de movimiento inválido: use PURCHASE_RECEIPT, ADJUSTMENT o DAMAGE")
+	}
+}
+
+// StockReconciliation compara el stock de un producto con la suma de su libro de movimientos
+type StockReconciliation struct {
+	ProductID     uuid.UUID `gorm:"column:product_id" json:"product_id"`
+	Name          string    `gorm:"column:name" json:"name"`
+	StockQuantity int       `gorm:"column:stock_quantity" json:"stock_quantity"`
+	LedgerBalance int       `gorm:"column:ledger_balance" json:"ledger_balance"`
+	Difference    int       `gorm:"column:difference" json:"difference"` // Stock menos libro: cambios que no pasaron por el libro
+}
+
+// LowStockProduct es un producto con stock por debajo de su umbral de reposición
+type LowStockProduct struct {
+	ProductID        uuid.UUID  `gorm:"column:product_id" json:"product_id"`
+	Name             string     `gorm:"column:name" json:"name"`
+	StockQuantity    int        `gorm:"column:stock_quantity" json:"stock_quantity"`
+	ReorderThreshold int        `gorm:"column:reorder_threshold" json:"reorder_threshold"`
+	AlertedAt        *time.Time `gorm:"column:low_stock_alerted_at" json:"alerted_at,omitempty"`
+}
+
+// ErrStockBelowZero indica que el movimiento dejaría el stock del producto o de la variante en negativo
+var ErrStockBelowZero = errors.New("el movimiento deja el stock en negativo")
+
+// ErrBranchStockRequired indica que hay sucursales: el stock total del producto es la suma de
+// branch_stock y solo se modifica por sucursal
+var ErrBranchStockRequired = errors.New("con sucursales el stock se modifica por sucursal")
@@ -19,15 +19,16 @@ const (
 
 // User representa un usuario en el sistema
 type User struct {
-	UserID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"user_id"`
-	Email        string    `gorm:"type:varchar(255);not null;unique" json:"email"`
-	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"` // No se envía en JSON
-	FullName     string    `gorm:"type:varchar(255);not null" json:"full_name"`
-	PhoneNumber  string    `gorm:"type:varchar(20);not null;unique" json:"phone_number"`
-	UserRole     UserRole  `gorm:"type:varchar(20);not null" json:"user_role"`
-	IsActive     bool      `gorm:"not null;default:true" json:"is_active"`
-	CreatedAt    time.Time `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt    time.Time `gorm:"not null;default:now()" json:"updated_at"`
+	UserID       uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"user_id"`
+	Email        string     `gorm:"type:varchar(255);not null;unique" json:"email"`
+	PasswordHash string     `gorm:"type:varchar(255);not null" json:"-"` // No se envía en JSON
+	FullName     string     `gorm:"type:varchar(255);not null" json:"full_name"`
+	PhoneNumber  string     `gorm:"type:varchar(20);not null;unique" json:"phone_number"`
+	UserRole     UserRole   `gorm:"type:varchar(20);not null" json:"user_role"`
+	IsActive     bool       `gorm:"not null;default:true" json:"is_active"`
+	BranchID     *uuid.UUID `gorm:"type:uuid" json:"branch_id"` // Sucursal del repartidor o administrador (nil = todas)
+	CreatedAt    time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"not null;default:now()" json:"updated_at"`
 }
 
 // BeforeCreate se ejecuta antes de crear un nuevo usuario
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxAddressesPerUser es la cantidad máxima de direcciones guardadas por usuario
+const MaxAddressesPerUser = 10
+
+// UserAddress representa una dirección guardada en la libreta del usuario
+type UserAddress struct {
+	AddressID   uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"address_id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Label       string    `gorm:"type:varchar(50);not null" json:"label"` // Ej: Casa, Trabajo
+	AddressText string    `gorm:"type:text;not null" json:"address_text"`
+	Latitude    float64   `gorm:"type:numeric(9,6);not null" json:"latitude"`
+	Longitude   float64   `gorm:"type:numeric(9,6);not null" json:"longitude"`
+	Reference   string    `gorm:"type:text" json:"reference"` // Notas para el repartidor: "portón verde", "2do piso"
+	IsDefault   bool      `gorm:"not null;default:false" json:"is_default"`
+	CreatedAt   time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// BeforeCreate se ejecuta antes de crear una nueva dirección
+func (a *UserAddress) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.AddressID == uuid.Nil {
+		a.AddressID = uuid.New()
+	}
+	return nil
+}
+
+// TableName especifica el nombre de la tabla para UserAddress
+func (UserAddress) TableName() string {
+	return "user_addresses"
+}
+
+// Validate verifica los campos obligatorios y el rango de las coordenadas
+func (a *UserAddress) Validate() error {
+	a.Label = strings.TrimSpace(a.Label)
+	a.AddressText = strings.TrimSpace(a.AddressText)
+	a.Reference = strings.TrimSpace(a.Reference)
+
+	if a.Label == "" || len(a.Label) > 50 {
+		return errors.New("la etiqueta es obligatoria y no puede superar los 50 caracteres")
+	}
+	if a.AddressText == "" {
+		return errors.New("la dirección es obligatoria")
+	}
+	if a.Latitude < -90 || a.Latitude > 90 || a.Longitude < -180 || a.Longitude > 180 {
+		return errors.New("coordenadas fuera de rango")
+	}
+	if a.Latitude == 0 && a.Longitude == 0 {
+		return errors.New("las coordenadas de la dirección son obligatorias")
+	}
+	if len(a.Reference) > 500 {
+		return errors.New("la referencia no puede superar los 500 caracteres")
+	}
+	return nil
+}
+
+// ApplyToOrder copia la dirección al pedido para que cambios posteriores no alteren pedidos existentes
+func (a *UserAddress) ApplyToOrder(order *Order) {
+	addressID := a.AddressID
+	order.AddressID = &addressID
+	order.Latitude = a.Latitude
+	order.Longitude = a.Longitude
+	order.DeliveryAddressText = a.AddressText
+	order.DeliveryReference = a.Reference
+}
+
+// CreateAddressRequest representa la solicitud para guardar una dirección
+type CreateAddressRequest struct {
+	Label       string  `json:"label" validate:"required,max=50"`
+	AddressText string  `json:"address_text" validate:"required"`
+	Latitude    float64 `json:"latitude" validate:"required"`
+	Longitude   float64 `json:"longitude" validate:"required"`
+	Reference   string  `json:"reference" validate:"omitempty,max=500"`
+	IsDefault   bool    `json:"is_default"`
+}
+
+// UpdateAddressRequest representa la solicitud para actualizar una dirección (campos opcionales)
+type UpdateAddressRequest struct {
+	Label       *string  `json:"label,omitempty"`
+	AddressText *string  `json:"address_text,omitempty"`
+	Latitude    *float64 `json:"latitude,omitempty"`
+	Longitude   *float64 `json:"longitude,omitempty"`
+	Reference   *string  `json:"reference,omitempty"`
+	IsDefault   *bool    `json:"is_default,omitempty"`
+}
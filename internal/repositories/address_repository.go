@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AddressRepository maneja la libreta de direcciones de los usuarios
+type AddressRepository interface {
+	Create(address *models.UserAddress) error
+	FindByID(addressID string) (*models.UserAddress, error)
+	FindByUserID(userID string) ([]models.UserAddress, error)
+	CountByUserID(userID string) (int64, error)
+	Update(address *models.UserAddress) error
+	Delete(address *models.UserAddress) error
+}
+
+type addressRepository struct {
+	db *gorm.DB
+}
+
+// NewAddressRepository crea una nueva instancia del repositorio
+func NewAddressRepository(db *gorm.DB) AddressRepository {
+	return &addressRepository{db: db}
+}
+
+// Create guarda una dirección; si es predeterminada desmarca las demás del usuario
+func (r *addressRepository) Create(address *models.UserAddress) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, address.UserID.String()); err != nil {
+				return err
+			}
+		}
+		return tx.Create(address).Error
+	})
+}
+
+// FindByID obtiene una dirección por su ID
+func (r *addressRepository) FindByID(addressID string) (*models.UserAddress, error) {
+	var address models.UserAddress
+	if err := r.db.Where("address_id = ?", addressID).First(&address).Error; err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// FindByUserID obtiene las direcciones de un usuario, primero la predeterminada
+func (r *addressRepository) FindByUserID(userID string) ([]models.UserAddress, error) {
+	var addresses []models.UserAddress
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("is_default DESC, created_at DESC").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+// CountByUserID cuenta las direcciones guardadas de un usuario
+func (r *addressRepository) CountByUserID(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserAddress{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Update actualiza una dirección; si es predeterminada desmarca las demás del usuario
+func (r *addressRepository) Update(address *models.UserAddress) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, address.UserID.String()); err != nil {
+				return err
+			}
+		}
+
+		updates := map[string]interface{}{
+			"label":        address.Label,
+			"address_text": address.AddressText,
+			"latitude":     address.Latitude,
+			"longitude":    address.Longitude,
+			"reference":    address.Reference,
+			"is_default":   address.IsDefault,
+			"updated_at":   time.Now(),
+		}
+		return tx.Model(&models.UserAddress{}).Where("address_id = ?", address.AddressID).Updates(updates).Error
+	})
+}
+
+// Delete elimina una dirección; si era la predeterminada, la más reciente pasa a serlo
+func (r *addressRepository) Delete(address *models.UserAddress) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.UserAddress{}, "address_id = ?", address.AddressID).Error; err != nil {
+			return err
+		}
+
+		if !address.IsDefault {
+			return nil
+		}
+
+		var next models.UserAddress
+		err := tx.Where("user_id = ?", address.UserID).Order("created_at DESC").First(&next).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+}
+
+// clearDefaultAddress desmarca la dirección predeterminada actual del usuario
+func clearDefaultAddress(tx *gorm.DB, userID string) error {
+	return tx.Model(&models.UserAddress{}).
+		Where("user_id = ? AND is_default = ?", userID, true).
+		Update("is_default", false).Error
+}
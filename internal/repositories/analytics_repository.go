@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AnalyticsRepository calcula los indicadores de ventas directamente en SQL
+type AnalyticsRepository interface {
+	GetSalesTotals(from, to time.Time) (*models.SalesMetrics, error)
+	GetSalesByGroup(from, to time.Time, groupBy string, timezone string) ([]models.SalesAnalyticsRow, error)
+}
+
+type analyticsRepository struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsRepository crea una nueva instancia del repositorio de analítica
+func NewAnalyticsRepository(db *gorm.DB) AnalyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+// Indicadores a nivel de pedido: los ingresos excluyen las garantías de balones (las propinas ya no forman parte del total)
+const orderMetricsSelect = `COUNT(*) AS order_count,
+	COUNT(*) FILTER (WHERE o.order_status = 'DELIVERED') AS delivered_count,
+	COUNT(*) FILTER (WHERE o.order_status = 'CANCELLED') AS cancelled_count,
+	COALESCE(SUM(iq.units) FILTER (WHERE o.order_status = 'DELIVERED'), 0) AS units_sold,
+	COALESCE(SUM(o.total_amount - o.deposit_total) FILTER (WHERE o.order_status = 'DELIVERED'), 0) AS revenue`
+
+// Unidades por pedido, para no duplicar filas de pedidos al unir con los ítems
+const orderUnitsJoin = `LEFT JOIN (SELECT order_id, SUM(quantity) AS units FROM order_items GROUP BY order_id) iq ON iq.order_id = o.order_id`
+
+// Indicadores a nivel de ítem: un pedido cuenta una vez por producto o categoría que contiene
+const itemMetricsSelect = `COUNT(DISTINCT o.order_id) AS order_count,
+	COUNT(DISTINCT o.order_id) FILTER (WHERE o.order_status = 'DELIVERED') AS delivered_count,
+	COUNT(DISTINCT o.order_id) FILTER (WHERE o.order_status = 'CANCELLED') AS cancelled_count,
+	COALESCE(SUM(oi.quantity) FILTER (WHERE o.order_status = 'DELIVERED'), 0) AS units_sold,
+	COALESCE(SUM(oi.subtotal) FILTER (WHERE o.order_status = 'DELIVERED'), 0) AS revenue`
+
+// GetSalesTotals obtiene los indicadores de los pedidos realizados en [from, to)
+func (r *analyticsRepository) GetSalesTotals(from, to time.Time) (*models.SalesMetrics, error) {
+	var metrics models.SalesMetrics
+	err := r.db.Raw(`SELECT `+orderMetricsSelect+`
+		FROM orders o `+orderUnitsJoin+`
+		WHERE o.order_time >= ? AND o.order_time < ?`, from, to).
+		Scan(&metrics).Error
+	return &metrics, err
+}
+
+// GetSalesByGroup obtiene los indicadores de los pedidos realizados en [from, to) agrupados según groupBy.
+// Las agrupaciones por fecha y hora usan la zona horaria del negocio.
+func (r *analyticsRepository) GetSalesByGroup(from, to time.Time, groupBy string, timezone string) ([]models.SalesAnalyticsRow, error) {
+	var query string
+	var args []interface{}
+
+	switch groupBy {
+	case models.AnalyticsGroupDay, models.AnalyticsGroupWeek, models.AnalyticsGroupMonth:
+		layout := map[string]string{
+			models.AnalyticsGroupDay:   "YYYY-MM-DD",
+			models.AnalyticsGroupWeek:  "YYYY-MM-DD", // Lunes de la semana
+			models.AnalyticsGroupMonth: "YYYY-MM",
+		}[groupBy]
+		query = fmt.Sprintf(`SELECT to_char(date_trunc('%s', o.order_time AT TIME ZONE ?), '%s') AS key,
+				to_char(date_trunc('%s', o.order_time AT TIME ZONE ?), '%s') AS label,
+				%s
+			FROM orders o %s
+			WHERE o.order_time >= ? AND o.order_time < ?
+			GROUP BY 1, 2
+			ORDER BY 1`, groupBy, layout, groupBy, layout, orderMetricsSelect, orderUnitsJoin)
+		args = []interface{}{timezone, timezone, from, to}
+
+	case models.AnalyticsGroupHour:
+		query = fmt.Sprintf(`SELECT lpad(EXTRACT(HOUR FROM o.order_time AT TIME ZONE ?)::int::text, 2, '0') AS key,
+				lpad(EXTRACT(HOUR FROM o.order_time AT TIME ZONE ?)::int::text, 2, '0') || ':00' AS label,
+				%s
+			FROM orders o %s
+			WHERE o.order_time >= ? AND o.order_time < ?
+			GROUP BY 1, 2
+			ORDER BY 1`, orderMetricsSelect, orderUnitsJoin)
+		args = []interface{}{timezone, timezone, from, to}
+
+	case models.AnalyticsGroupRepartidor:
+		query = fmt.Sprintf(`SELECT COALESCE(o.assigned_repartidor_id::text, '') AS key,
+				COALESCE(u.full_name, 'Sin asignar') AS label,
+				%s
+			FROM orders o %s
+			LEFT JOIN users u ON u.user_id = o.assigned_repartidor_id
+			WHERE o.order_time >= ? AND o.order_time < ?
+			GROUP BY 1, 2
+			ORDER BY revenue DESC, label ASC`, orderMetricsSelect, orderUnitsJoin)
+		args = []interface{}{from, to}
+
+	case models.AnalyticsGroupProduct:
+		query = fmt.Sprintf(`SELECT p.product_id::text AS key,
+				p.name AS label,
+				%s
+			FROM order_items oi
+			JOIN orders o ON o.order_id = oi.order_id
+			JOIN products p ON p.product_id = oi.product_id
+			WHERE o.order_time >= ? AND o.order_time < ?
+			GROUP BY 1, 2
+			ORDER BY revenue DESC, label ASC`, itemMetricsSelect)
+		args = []interface{}{from, to}
+
+	case models.AnalyticsGroupCategory:
+		query = fmt.Sprintf(`SELECT COALESCE(c.category_id::text, '') AS key,
+				COALESCE(c.name, 'Sin categoría') AS label,
+				%s
+			FROM order_items oi
+			JOIN orders o ON o.order_id = oi.order_id
+			JOIN products p ON p.product_id = oi.product_id
+			LEFT JOIN categories c ON c.category_id = p.category_id
+			WHERE o.order_time >= ? AND o.order_time < ?
+			GROUP BY 1, 2
+			ORDER BY revenue DESC, label ASC`, itemMetricsSelect)
+		args = []interface{}{from, to}
+
+	default:
+		return nil, fmt.Errorf("agrupación no soportada: %s", groupBy)
+	}
+
+	var rows []models.SalesAnalyticsRow
+	err := r.db.Raw(query, args...).Scan(&rows).Error
+	return rows, err
+}
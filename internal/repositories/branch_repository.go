@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BranchRepository maneja las sucursales y su stock
+type BranchRepository interface {
+	Create(branch *models.Branch) error
+	FindByID(branchID string) (*models.Branch, error)
+	FindAll(activeOnly bool) ([]models.Branch, error)
+	Count() (int64, error)
+	Update(branch *models.Branch) error
+	FindStockByBranch(branchID string) ([]models.BranchStock, error)
+	FindStockByProducts(productIDs []uuid.UUID) ([]models.BranchStock, error)
+	SetStock(branchID, productID uuid.UUID, quantity int) error
+	InitializeStockFromProducts(branchID uuid.UUID) error
+}
+
+type branchRepository struct {
+	db *gorm.DB
+}
+
+// NewBranchRepository crea una nueva instancia del repositorio de sucursales
+func NewBranchRepository(db *gorm.DB) BranchRepository {
+	return &branchRepository{db: db}
+}
+
+// Create guarda una nueva sucursal
+func (r *branchRepository) Create(branch *models.Branch) error {
+	return r.db.Create(branch).Error
+}
+
+// FindByID obtiene una sucursal por su ID
+func (r *branchRepository) FindByID(branchID string) (*models.Branch, error) {
+	var branch models.Branch
+	if err := r.db.Where("branch_id = ?", branchID).First(&branch).Error; err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+// FindAll obtiene las sucursales ordenadas por nombre
+func (r *branchRepository) FindAll(activeOnly bool) ([]models.Branch, error) {
+	var branches []models.Branch
+	query := r.db.Order("name ASC")
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Find(&branches).Error
+	return branches, err
+}
+
+// Count cuenta todas las sucursales, activas o no
+func (r *branchRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Branch{}).Count(&count).Error
+	return count, err
+}
+
+// Update actualiza los datos de una sucursal
+func (r *branchRepository) Update(branch *models.Branch) error {
+	updates := map[string]interface{}{
+		"name":              branch.Name,
+		"address_text":      branch.AddressText,
+		"phone_number":      branch.PhoneNumber,
+		"latitude":          branch.Latitude,
+		"longitude":         branch.Longitude,
+		"service_radius_km": branch.ServiceRadiusKm,
+		"opens_at":          branch.OpensAt,
+		"closes_at":         branch.ClosesAt,
+		"is_active":         branch.IsActive,
+		"updated_at":        time.Now(),
+	}
+	return r.db.Model(&models.Branch{}).Where("branch_id = ?", branch.BranchID).Updates(updates).Error
+}
+
+// FindStockByBranch obtiene el stock de una sucursal con los datos de cada producto
+func (r *branchRepository) FindStockByBranch(branchID string) ([]models.BranchStock, error) {
+	var stock []models.BranchStock
+	err := r.db.
+		Preload("Product").
+		Joins("JOIN products p ON p.product_id = branch_stock.product_id AND p.deleted_at IS NULL").
+		Where("branch_stock.branch_id = ?", branchID).
+		Order("p.name ASC").
+		Find(&stock).Error
+	return stock, err
+}
+
+// FindStockByProducts obtiene el stock de los productos indicados en todas las sucursales
+func (r *branchRepository) FindStockByProducts(productIDs []uuid.UUID) ([]models.BranchStock, error) {
+	var stock []models.BranchStock
+	if len(productIDs) == 0 {
+		return stock, nil
+	}
+	err := r.db.Where("product_id IN ?", productIDs).Find(&stock).Error
+	return stock, err
+}
+
+// SetStock fija el stock de un producto en una sucursal y recalcula el total del producto
+func (r *branchRepository) SetStock(branchID, productID uuid.UUID, quantity int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return setBranchStock(tx, branchID, productID, quantity)
+	})
+}
+
+// setBranchStock fija el stock de la sucursal, recalcula el total del producto como la suma de
+// sus sucursales y ajusta la variante predeterminada
+func setBranchStock(tx *gorm.DB, branchID, productID uuid.UUID, quantity int) error {
+	stock := models.BranchStock{
+		BranchID:  branchID,
+		ProductID: productID,
+		Quantity:  quantity,
+		UpdatedAt: time.Now(),
+	}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "branch_id"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "updated_at"}),
+	}).Create(&stock).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Exec(`UPDATE products
+		SET stock_quantity = (SELECT COALESCE(SUM(quantity), 0) FROM branch_stock WHERE product_id = ?), updated_at = NOW()
+		WHERE product_id = ?`, productID, productID).Error
+	if err != nil {
+		return err
+	}
+	return syncDefaultVariantStock(tx, productID)
+}
+
+// InitializeStockFromProducts copia el stock actual de cada producto a la sucursal (primera sucursal).
+// Los combos no tienen stock propio: se calcula a partir de sus componentes.
+func (r *branchRepository) InitializeStockFromProducts(branchID uuid.UUID) error {
+	return r.db.Exec(`INSERT INTO branch_stock (branch_id, product_id, quantity, updated_at)
+		SELECT ?, product_id, stock_quantity, NOW() FROM products WHERE deleted_at IS NULL AND NOT is_bundle
+		ON CONFLICT (branch_id, product_id) DO NOTHING`, branchID).Error
+}
+
+// requireStockWithoutBranches rechaza cambiar el stock total de un producto cuando hay sucursales:
+// products.stock_quantity es la suma de branch_stock y se modifica con SetStock. Un producto nuevo
+// parte de 0.
+func requireStockWithoutBranches(tx *gorm.DB, productID uuid.UUID, stock int) error {
+	var current int
+	if err := tx.Raw("SELECT stock_quantity FROM products WHERE product_id = ?", productID).Scan(&current).Error; err != nil {
+		return err
+	}
+	if current == stock {
+		return nil
+	}
+	branches, err := hasBranches(tx)
+	if err != nil {
+		return err
+	}
+	if branches {
+		return models.ErrBranchStockRequired
+	}
+	return nil
+}
+
+// hasBranches indica si existe alguna sucursal (activa o no): desde la primera, el stock es por sucursal
+func hasBranches(tx *gorm.DB) (bool, error) {
+	var exists bool
+	err := tx.Raw("SELECT EXISTS (SELECT 1 FROM branches)").Scan(&exists).Error
+	return exists, err
+}
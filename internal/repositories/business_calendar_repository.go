@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BusinessCalendarRepository maneja el horario semanal y los días especiales del negocio
+type BusinessCalendarRepository interface {
+	GetWeeklyHours() ([]models.BusinessHours, error)
+	SaveWeeklyHours(hours []models.BusinessHours) error
+	FindSpecialDays(from, to time.Time) ([]models.SpecialDay, error)
+	FindSpecialDayByID(specialDayID string) (*models.SpecialDay, error)
+	CreateSpecialDay(day *models.SpecialDay) error
+	UpdateSpecialDay(day *models.SpecialDay) error
+	DeleteSpecialDay(day *models.SpecialDay) error
+}
+
+type businessCalendarRepository struct {
+	db *gorm.DB
+}
+
+// NewBusinessCalendarRepository crea una nueva instancia del repositorio del calendario
+func NewBusinessCalendarRepository(db *gorm.DB) BusinessCalendarRepository {
+	return &businessCalendarRepository{db: db}
+}
+
+// GetWeeklyHours obtiene el horario configurado por día de la semana
+func (r *businessCalendarRepository) GetWeeklyHours() ([]models.BusinessHours, error) {
+	var hours []models.BusinessHours
+	err := r.db.Order("weekday ASC").Find(&hours).Error
+	return hours, err
+}
+
+// SaveWeeklyHours crea o reemplaza el horario de los días indicados en una sola transacción
+func (r *businessCalendarRepository) SaveWeeklyHours(hours []models.BusinessHours) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range hours {
+			hours[i].UpdatedAt = time.Now()
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "weekday"}},
+				DoUpdates: clause.AssignmentColumns([]string{"is_open", "opens_at", "closes_at", "updated_at"}),
+			}).Create(&hours[i]).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindSpecialDays obtiene los días especiales entre dos fechas (inclusive)
+func (r *businessCalendarRepository) FindSpecialDays(from, to time.Time) ([]models.SpecialDay, error) {
+	var days []models.SpecialDay
+	err := r.db.
+		Where("date BETWEEN ? AND ?", from.Format(models.BusinessDateLayout), to.Format(models.BusinessDateLayout)).
+		Order("date ASC").
+		Find(&days).Error
+	return days, err
+}
+
+// FindSpecialDayByID obtiene un día especial por su ID
+func (r *businessCalendarRepository) FindSpecialDayByID(specialDayID string) (*models.SpecialDay, error) {
+	var day models.SpecialDay
+	if err := r.db.Where("special_day_id = ?", specialDayID).First(&day).Error; err != nil {
+		return nil, err
+	}
+	return &day, nil
+}
+
+// CreateSpecialDay guarda un nuevo día especial
+func (r *businessCalendarRepository) CreateSpecialDay(day *models.SpecialDay) error {
+	return r.db.Create(day).Error
+}
+
+// UpdateSpecialDay actualiza un día especial
+func (r *businessCalendarRepository) UpdateSpecialDay(day *models.SpecialDay) error {
+	updates := map[string]interface{}{
+		"date":       day.Date,
+		"name":       day.Name,
+		"is_holiday": day.IsHoliday,
+		"is_open":    day.IsOpen,
+		"opens_at":   day.OpensAt,
+		"closes_at":  day.ClosesAt,
+		"updated_at": time.Now(),
+	}
+	return r.db.Model(&models.SpecialDay{}).Where("special_day_id = ?", day.SpecialDayID).Updates(updates).Error
+}
+
+// DeleteSpecialDay elimina un día especial
+func (r *businessCalendarRepository) DeleteSpecialDay(day *models.SpecialDay) error {
+	return r.db.Delete(day).Error
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CashSettlementRepository maneja las entregas de efectivo de los repartidores
+type CashSettlementRepository interface {
+	Create(settlement *models.CashSettlement) error
+	FindByRepartidorAndDate(repartidorID string, businessDate time.Time) ([]models.CashSettlement, error)
+	FindByDate(businessDate time.Time) ([]models.CashSettlement, error)
+}
+
+type cashSettlementRepository struct {
+	db *gorm.DB
+}
+
+// NewCashSettlementRepository crea una nueva instancia del repositorio
+func NewCashSettlementRepository(db *gorm.DB) CashSettlementRepository {
+	return &cashSettlementRepository{db: db}
+}
+
+// Create registra una nueva entrega de efectivo
+func (r *cashSettlementRepository) Create(settlement *models.CashSettlement) error {
+	return r.db.Create(settlement).Error
+}
+
+// FindByRepartidorAndDate obtiene las entregas de un repartidor para una jornada
+func (r *cashSettlementRepository) FindByRepartidorAndDate(repartidorID string, businessDate time.Time) ([]models.CashSettlement, error) {
+	var settlements []models.CashSettlement
+	err := r.db.
+		Where("repartidor_id = ? AND business_date = ?", repartidorID, businessDate.Format(models.BusinessDateLayout)).
+		Order("created_at ASC").
+		Find(&settlements).Error
+	return settlements, err
+}
+
+// FindByDate obtiene todas las entregas registradas para una jornada
+func (r *cashSettlementRepository) FindByDate(businessDate time.Time) ([]models.CashSettlement, error) {
+	var settlements []models.CashSettlement
+	err := r.db.
+		Where("business_date = ?", businessDate.Format(models.BusinessDateLayout)).
+		Order("created_at ASC").
+		Find(&settlements).Error
+	return settlements, err
+}
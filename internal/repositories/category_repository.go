@@ -3,6 +3,7 @@ package repositories
 import (
 	"backend/internal/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -14,6 +15,10 @@ type CategoryRepository interface {
 	FindWithProductCount() ([]*models.CategoryWithProductCount, error)
 	Update(category *models.Category) error
 	Delete(id string) error
+	FindDeleted() ([]*models.Category, error)
+	FindDeletedByID(id string) (*models.Category, error)
+	NameTaken(name string, excludeID uuid.UUID) (bool, error)
+	Restore(id string) error
 }
 
 type categoryRepository struct {
@@ -85,8 +90,8 @@ func (r *categoryRepository) FindWithProductCount() ([]*models.CategoryWithProdu
 			c.updated_at,
 			COUNT(p.product_id) AS product_count
 		FROM categories c
-		LEFT JOIN products p ON c.category_id = p.category_id AND p.is_active = true
-		WHERE c.is_active = true
+		LEFT JOIN products p ON c.category_id = p.category_id AND p.is_active = true AND p.deleted_at IS NULL
+		WHERE c.is_active = true AND c.deleted_at IS NULL
 		GROUP BY c.category_id, c.name, c.description, c.icon_name, c.color_hex, c.is_active, c.created_at, c.updated_at
 		ORDER BY c.name
 	`
@@ -102,6 +107,45 @@ func (r *categoryRepository) Update(category *models.Category) error {
 	return r.db.Save(category).Error
 }
 
+// Delete elimina la categoría de forma lógica (deleted_at); sus productos conservan la referencia
 func (r *categoryRepository) Delete(id string) error {
 	return r.db.Delete(&models.Category{}, "category_id = ?", id).Error
 }
+
+// FindDeleted obtiene las categorías eliminadas, las más recientes primero
+func (r *categoryRepository) FindDeleted() ([]*models.Category, error) {
+	var categories []*models.Category
+	err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&categories).Error
+	return categories, err
+}
+
+// FindDeletedByID obtiene una categoría eliminada
+func (r *categoryRepository) FindDeletedByID(id string) (*models.Category, error) {
+	var category models.Category
+	err := r.db.Unscoped().
+		Where("category_id = ? AND deleted_at IS NOT NULL", id).
+		First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// NameTaken indica si otra categoría no eliminada usa el nombre
+func (r *categoryRepository) NameTaken(name string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Category{}).
+		Where("LOWER(name) = LOWER(?) AND category_id <> ?", name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Restore devuelve una categoría eliminada
+func (r *categoryRepository) Restore(id string) error {
+	return r.db.Unscoped().Model(&models.Category{}).
+		Where("category_id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "updated_at": gorm.Expr("NOW()")}).Error
+}
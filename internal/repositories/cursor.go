@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"fmt"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// applyCursor ordena de más reciente a más antiguo por (timeColumn, idColumn), continúa después
+// del cursor y lee una fila extra para saber si hay más resultados
+func applyCursor(query *gorm.DB, cursor *models.Cursor, timeColumn, idColumn string, limit int) *gorm.DB {
+	if cursor != nil {
+		query = query.Where(fmt.Sprintf("(%s, %s) < (?, ?)", timeColumn, idColumn), cursor.Time, cursor.ID)
+	}
+	return query.
+		Order(fmt.Sprintf("%s DESC, %s DESC", timeColumn, idColumn)).
+		Limit(limit + 1)
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CylinderRepository calcula los movimientos de balones retornables a partir de los pedidos entregados
+type CylinderRepository interface {
+	GetHoldings(clientID string) ([]models.CylinderHolding, error)
+	GetCollections(from, to time.Time) ([]models.CylinderCollection, error)
+}
+
+type cylinderRepository struct {
+	db *gorm.DB
+}
+
+// NewCylinderRepository crea una nueva instancia del repositorio
+func NewCylinderRepository(db *gorm.DB) CylinderRepository {
+	return &cylinderRepository{db: db}
+}
+
+// GetHoldings obtiene los balones en poder de los clientes (entregados sin vacío a cambio).
+// clientID vacío = todos los clientes con al menos un balón.
+func (r *cylinderRepository) GetHoldings(clientID string) ([]models.CylinderHolding, error) {
+	var holdings []models.CylinderHolding
+
+	query := r.db.Table("order_items oi").
+		Select(`o.client_id,
+			u.full_name AS client_name,
+			oi.product_id,
+			p.name AS product_name,
+			SUM(oi.quantity - oi.cylinders_returned) AS cylinders_held,
+			COALESCE(SUM(oi.deposit_charge), 0) AS deposits_paid`).
+		Joins("JOIN orders o ON o.order_id = oi.order_id").
+		Joins("JOIN products p ON p.product_id = oi.product_id").
+		Joins("JOIN users u ON u.user_id = o.client_id").
+		Where("o.order_status = ? AND p.is_returnable = ?", models.OrderStatusDelivered, true)
+
+	if clientID != "" {
+		query = query.Where("o.client_id = ?", clientID)
+	}
+
+	err := query.
+		Group("o.client_id, u.full_name, oi.product_id, p.name").
+		Having("SUM(oi.quantity - oi.cylinders_returned) > 0").
+		Order("u.full_name ASC, p.name ASC").
+		Scan(&holdings).Error
+	return holdings, err
+}
+
+// GetCollections obtiene por repartidor y producto los balones llenos entregados,
+// los vacíos recogidos y las garantías cobradas en el rango [from, to)
+func (r *cylinderRepository) GetCollections(from, to time.Time) ([]models.CylinderCollection, error) {
+	var collections []models.CylinderCollection
+
+	err := r.db.Table("order_items oi").
+		Select(`o.assigned_repartidor_id AS repartidor_id,
+			u.full_name AS repartidor_name,
+			oi.product_id,
+			p.name AS product_name,
+			SUM(oi.quantity) AS full_delivered,
+			SUM(oi.cylinders_returned) AS empties_collected,
+			COALESCE(SUM(oi.deposit_charge), 0) AS deposits_charged`).
+		Joins("JOIN orders o ON o.order_id = oi.order_id").
+		Joins("JOIN products p ON p.product_id = oi.product_id").
+		Joins("JOIN users u ON u.user_id = o.assigned_repartidor_id").
+		Where("o.order_status = ? AND p.is_returnable = ?", models.OrderStatusDelivered, true).
+		Where("o.delivered_at >= ? AND o.delivered_at < ?", from, to).
+		Group("o.assigned_repartidor_id, u.full_name, oi.product_id, p.name").
+		Order("u.full_name ASC, p.name ASC").
+		Scan(&collections).Error
+	return collections, err
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// DeliveryRatingRepository maneja las calificaciones de entrega de los repartidores
+type DeliveryRatingRepository interface {
+	Create(rating *models.DeliveryRating) error
+	FindByOrderID(orderID string) (*models.DeliveryRating, error)
+	GetRepartidorSummaries(repartidorID string) ([]models.RepartidorRatingSummary, error)
+	GetTagCounts(repartidorID string) (map[string]int64, error)
+	GetGlobalAverage() (float64, error)
+}
+
+type deliveryRatingRepository struct {
+	db *gorm.DB
+}
+
+// NewDeliveryRatingRepository crea una nueva instancia del repositorio
+func NewDeliveryRatingRepository(db *gorm.DB) DeliveryRatingRepository {
+	return &deliveryRatingRepository{db: db}
+}
+
+// Create registra una nueva calificación de entrega. Devuelve models.ErrDeliveryRatingExists si
+// el pedido ya tiene una (dos calificaciones simultáneas del mismo pedido)
+func (r *deliveryRatingRepository) Create(rating *models.DeliveryRating) error {
+	err := r.db.Create(rating).Error
+	if isUniqueViolation(err, "") {
+		return models.ErrDeliveryRatingExists
+	}
+	return err
+}
+
+// FindByOrderID obtiene la calificación de entrega de un pedido
+func (r *deliveryRatingRepository) FindByOrderID(orderID string) (*models.DeliveryRating, error) {
+	var rating models.DeliveryRating
+	if err := r.db.Where("order_id = ?", orderID).First(&rating).Error; err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+// GetRepartidorSummaries obtiene cantidad y promedio de calificaciones por repartidor (repartidorID vacío = todos)
+func (r *deliveryRatingRepository) GetRepartidorSummaries(repartidorID string) ([]models.RepartidorRatingSummary, error) {
+	var summaries []models.RepartidorRatingSummary
+
+	query := r.db.Table("delivery_ratings dr").
+		Select(`dr.repartidor_id,
+			u.full_name AS repartidor_name,
+			COUNT(*) AS rating_count,
+			ROUND(AVG(dr.rating)::numeric, 2) AS average_rating`).
+		Joins("JOIN users u ON u.user_id = dr.repartidor_id")
+
+	if repartidorID != "" {
+		query = query.Where("dr.repartidor_id = ?", repartidorID)
+	}
+
+	err := query.
+		Group("dr.repartidor_id, u.full_name").
+		Scan(&summaries).Error
+	return summaries, err
+}
+
+// GetTagCounts cuenta cuántas veces se usó cada etiqueta para un repartidor
+func (r *deliveryRatingRepository) GetTagCounts(repartidorID string) (map[string]int64, error) {
+	var rows []struct {
+		Tag   string
+		Count int64
+	}
+
+	err := r.db.Raw(`
+		SELECT tag, COUNT(*) AS count
+		FROM delivery_ratings dr, jsonb_array_elements_text(dr.tags) AS tag
+		WHERE dr.repartidor_id = ?
+		GROUP BY tag`, repartidorID).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Tag] = row.Count
+	}
+	return counts, nil
+}
+
+// GetGlobalAverage obtiene el promedio de todas las calificaciones de entrega
+func (r *deliveryRatingRepository) GetGlobalAverage() (float64, error) {
+	var average float64
+	err := r.db.Model(&models.DeliveryRating{}).
+		Select("COALESCE(AVG(rating), 0)").
+		Scan(&average).Error
+	return average, err
+}
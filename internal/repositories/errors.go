@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Códigos de error de PostgreSQL que los repositorios traducen a errores del dominio
+const (
+	pgUniqueViolation = "23505"
+	pgCheckViolation  = "23514"
+)
+
+// isUniqueViolation indica si err es una violación de unicidad; con constraint vacío acepta cualquier índice
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation &&
+		(constraint == "" || pgErr.ConstraintName == constraint)
+}
+
+// isCheckViolation indica si err es una violación de un CHECK; con constraint vacío acepta cualquiera
+func isCheckViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgCheckViolation &&
+		(constraint == "" || pgErr.ConstraintName == constraint)
+}
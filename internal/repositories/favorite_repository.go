@@ -16,6 +16,7 @@ type FavoriteRepository interface {
 	IsFavorite(userID, productID uuid.UUID) (bool, error)
 	GetFavoriteInfo(userID, productID uuid.UUID) (*models.UserFavorite, error)
 	GetUserFavorites(userID uuid.UUID, page, limit int) ([]models.FavoriteResponse, int, error)
+	GetUserFavoritesWithCursor(userID uuid.UUID, cursor *models.Cursor, limit int) ([]models.FavoriteResponse, error)
 	GetFavoritesByProduct(productID uuid.UUID) ([]uuid.UUID, error)
 	GetFavoriteStats(userID uuid.UUID) (int, error)
 	GetMostFavorited(limit int) ([]models.Product, error)
@@ -129,7 +130,7 @@ func (r *favoriteRepository) GetUserFavorites(userID uuid.UUID, page, limit int)
 			uf.created_at as added_at
 		`).
 		Joins("INNER JOIN products p ON uf.product_id = p.product_id").
-		Where("uf.user_id = ? AND p.is_active = true", userID).
+		Where("uf.user_id = ? AND p.is_active = true AND p.deleted_at IS NULL", userID).
 		Order("uf.created_at DESC").
 		Limit(limit).
 		Offset(offset).
@@ -142,6 +143,36 @@ func (r *favoriteRepository) GetUserFavorites(userID uuid.UUID, page, limit int)
 	return favorites, int(totalCount), nil
 }
 
+// GetUserFavoritesWithCursor obtiene hasta limit+1 favoritos posteriores al cursor, ordenados por (fecha de agregado, producto)
+func (r *favoriteRepository) GetUserFavoritesWithCursor(userID uuid.UUID, cursor *models.Cursor, limit int) ([]models.FavoriteResponse, error) {
+	var favorites []models.FavoriteResponse
+
+	query := r.db.Table("user_favorites uf").
+		Select(`
+			p.product_id,
+			p.name,
+			p.description,
+			p.price,
+			p.image_url,
+			p.unit_of_measure,
+			p.package_size,
+			p.stock_quantity,
+			p.category_id,
+			p.is_active,
+			p.created_at,
+			p.updated_at,
+			uf.created_at as added_at
+		`).
+		Joins("INNER JOIN products p ON uf.product_id = p.product_id").
+		Where("uf.user_id = ? AND p.is_active = true AND p.deleted_at IS NULL", userID)
+
+	if err := applyCursor(query, cursor, "uf.created_at", "uf.product_id", limit).Scan(&favorites).Error; err != nil {
+		return nil, err
+	}
+
+	return favorites, nil
+}
+
 // GetFavoritesByProduct obtiene todos los usuarios que tienen un producto como favorito
 func (r *favoriteRepository) GetFavoritesByProduct(productID uuid.UUID) ([]uuid.UUID, error) {
 	var userIDs []uuid.UUID
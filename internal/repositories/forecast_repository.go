@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ForecastRepository obtiene el historial de demanda usado por los pronósticos
+type ForecastRepository interface {
+	GetDemandHistory(from, to time.Time, timezone string) ([]models.DemandObservation, error)
+}
+
+type forecastRepository struct {
+	db *gorm.DB
+}
+
+// NewForecastRepository crea una nueva instancia del repositorio de pronósticos
+func NewForecastRepository(db *gorm.DB) ForecastRepository {
+	return &forecastRepository{db: db}
+}
+
+// GetDemandHistory suma las unidades pedidas por producto, jornada y hora (zona horaria del negocio)
+// de los pedidos no cancelados realizados en [from, to)
+func (r *forecastRepository) GetDemandHistory(from, to time.Time, timezone string) ([]models.DemandObservation, error) {
+	var observations []models.DemandObservation
+	err := r.db.Raw(`
+		SELECT oi.product_id,
+			to_char(o.order_time AT TIME ZONE ?, 'YYYY-MM-DD') AS date,
+			EXTRACT(HOUR FROM o.order_time AT TIME ZONE ?)::int AS hour,
+			SUM(oi.quantity) AS units
+		FROM order_items oi
+		JOIN orders o ON o.order_id = oi.order_id
+		WHERE o.order_status <> ? AND o.order_time >= ? AND o.order_time < ?
+		GROUP BY 1, 2, 3`, timezone, timezone, models.OrderStatusCancelled, from, to).
+		Scan(&observations).Error
+	return observations, err
+}
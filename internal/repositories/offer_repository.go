@@ -15,9 +15,11 @@ type OfferRepository interface {
 	GetByID(offerID string) (*models.ProductOffer, error)
 	GetByProductID(productID string) (*models.ProductOffer, error)
 	GetActiveByProductID(productID string) (*models.ProductOffer, error)
+	GetActiveByVariantID(variantID string) (*models.ProductOffer, error)
 	Update(offer *models.ProductOffer) error
 	Delete(offerID string) error
 	DeactivateByProductID(productID string) error
+	DeactivateByVariantID(variantID string) error
 	FindActiveOffers(limit int) ([]*models.ProductOffer, error)
 	FindOffersByDateRange(startDate, endDate time.Time) ([]*models.ProductOffer, error)
 	FindByCreator(createdBy string) ([]*models.ProductOffer, error)
@@ -67,7 +69,7 @@ func (r *offerRepository) GetActiveByProductID(productID string) (*models.Produc
 	var offer models.ProductOffer
 	now := time.Now()
 
-	err := r.db.Where("product_id = ? AND is_active = ? AND start_date <= ? AND end_date >= ?",
+	err := r.db.Where("product_id = ? AND variant_id IS NULL AND is_active = ? AND start_date <= ? AND end_date >= ?",
 		productID, true, now, now).
 		First(&offer).Error
 
@@ -92,7 +94,7 @@ func (r *offerRepository) DeactivateByProductID(productID string) error {
 	fmt.Printf("🔍 DEBUG: DeactivateByProductID called with productID: %s\n", productID)
 
 	result := r.db.Model(&models.ProductOffer{}).
-		Where("product_id = ? AND is_active = ?", productID, true).
+		Where("product_id = ? AND variant_id IS NULL AND is_active = ?", productID, true).
 		Update("is_active", false)
 
 	fmt.Printf("🔍 DEBUG: DeactivateByProductID result: RowsAffected=%d, Error=%v\n", result.RowsAffected, result.Error)
@@ -100,12 +102,33 @@ func (r *offerRepository) DeactivateByProductID(productID string) error {
 	return result.Error
 }
 
+// GetActiveByVariantID obtiene la oferta activa propia de una variante
+func (r *offerRepository) GetActiveByVariantID(variantID string) (*models.ProductOffer, error) {
+	var offer models.ProductOffer
+	now := time.Now()
+
+	err := r.db.Where("variant_id = ? AND is_active = ? AND start_date <= ? AND end_date >= ?",
+		variantID, true, now, now).
+		First(&offer).Error
+	if err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// DeactivateByVariantID desactiva la oferta activa propia de una variante
+func (r *offerRepository) DeactivateByVariantID(variantID string) error {
+	return r.db.Model(&models.ProductOffer{}).
+		Where("variant_id = ? AND is_active = ?", variantID, true).
+		Update("is_active", false).Error
+}
+
 // FindActiveOffers encuentra todas las ofertas activas (para /products/offers)
 func (r *offerRepository) FindActiveOffers(limit int) ([]*models.ProductOffer, error) {
 	var offers []*models.ProductOffer
 	now := time.Now()
 
-	query := r.db.Preload("Product").
+	query := r.db.Preload("Product").Preload("Variant").
 		Where("is_active = ? AND start_date <= ? AND end_date >= ?", true, now, now).
 		Order("created_at DESC")
 
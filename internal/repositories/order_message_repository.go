@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// OrderMessageRepository maneja los mensajes del chat de pedidos
+type OrderMessageRepository interface {
+	Create(message *models.OrderMessage) error
+	FindByOrderID(orderID string) ([]models.OrderMessage, error)
+	MarkAsRead(orderID string, readerID string, readAt time.Time) (int64, error)
+}
+
+type orderMessageRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderMessageRepository crea una nueva instancia del repositorio
+func NewOrderMessageRepository(db *gorm.DB) OrderMessageRepository {
+	return &orderMessageRepository{db: db}
+}
+
+// Create guarda un nuevo mensaje
+func (r *orderMessageRepository) Create(message *models.OrderMessage) error {
+	return r.db.Create(message).Error
+}
+
+// FindByOrderID obtiene el historial del chat de un pedido en orden cronológico
+func (r *orderMessageRepository) FindByOrderID(orderID string) ([]models.OrderMessage, error) {
+	var messages []models.OrderMessage
+	err := r.db.
+		Preload("Sender").
+		Where("order_id = ?", orderID).
+		Order("created_at ASC, message_id ASC").
+		Find(&messages).Error
+	return messages, err
+}
+
+// MarkAsRead marca como leídos los mensajes del pedido que no envió el lector
+func (r *orderMessageRepository) MarkAsRead(orderID string, readerID string, readAt time.Time) (int64, error) {
+	result := r.db.Model(&models.OrderMessage{}).
+		Where("order_id = ? AND sender_id <> ? AND read_at IS NULL", orderID, readerID).
+		Update("read_at", readAt)
+	return result.RowsAffected, result.Error
+}
@@ -6,6 +6,7 @@ import (
 
 	"backend/internal/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -15,13 +16,18 @@ type OrderRepository interface {
 	FindAll() ([]*models.Order, error)
 	FindByClientID(clientID string) ([]*models.Order, error)
 	FindByRepartidorID(repartidorID string) ([]*models.Order, error)
+	FindDeliveredBetween(from, to time.Time, repartidorID string) ([]*models.Order, error)
+	CountActiveByRepartidor() (map[uuid.UUID]int, error)
 	FindByStatus(status models.OrderStatus) ([]*models.Order, error)
 	FindPendingOrders() ([]*models.Order, error)
 	FindNearbyOrders(lat, lng float64, radiusKm float64) ([]*models.Order, error)
 	Update(order *models.Order) error
 	UpdateStatus(id string, status models.OrderStatus) error
 	AssignRepartidor(orderID string, repartidorID string) error
+	MarkAssigned(orderID string, repartidorID string) error
 	SetEstimatedArrivalTime(orderID string, eta time.Time) error
+	SetTip(orderID string, amount float64) error
+	UpdateCylinderReturns(order *models.Order) error
 	Delete(id string) error
 	AddOrderItem(item *models.OrderItem) error
 	FindOrderItems(orderID string) ([]*models.OrderItem, error)
@@ -31,6 +37,12 @@ type OrderRepository interface {
 	FindByClientIDWithPagination(clientID string, offset, limit int) ([]*models.Order, int64, error)
 	FindByRepartidorIDWithPagination(repartidorID string, offset, limit int) ([]*models.Order, int64, error)
 	FindAllWithPagination(offset, limit int, status *models.OrderStatus, searchQuery string, userRole models.UserRole, userID string) ([]*models.Order, int64, error)
+	FindAllWithCursor(cursor *models.Cursor, limit int, status *models.OrderStatus, searchQuery string, userRole models.UserRole, userID string) ([]*models.Order, error)
+	GetRepartidorPerformance(from, to time.Time, repartidorID string) ([]models.RepartidorPerformance, error)
+	FindForExport(filter models.OrderExportFilter, batchSize int, fn func(orders []*models.Order) error) error
+
+	// Propinas de repartidores (repartidorID vacío = todos)
+	GetRepartidorEarnings(from, to time.Time, repartidorID string) ([]models.RepartidorEarnings, error)
 }
 
 type orderRepository struct {
@@ -52,7 +64,9 @@ func (r *orderRepository) FindByID(id string) (*models.Order, error) {
 	err := r.db.
 		Preload("Client").
 		Preload("AssignedRepartidor").
-		Preload("OrderItems.Product"). // Preload anidado para productos
+		Preload("OrderItems.Product", withDeleted). // Preload anidado para productos
+		Preload("OrderItems.Components").
+		Preload("OrderItems.Variant").
 		Where("order_id = ?", id).
 		First(&order).Error
 
@@ -93,6 +107,49 @@ func (r *orderRepository) FindByRepartidorID(repartidorID string) ([]*models.Ord
 	return orders, nil
 }
 
+// FindDeliveredBetween obtiene los pedidos entregados en el rango [from, to) que tienen repartidor
+// asignado (repartidorID vacío = todos los repartidores)
+func (r *orderRepository) FindDeliveredBetween(from, to time.Time, repartidorID string) ([]*models.Order, error) {
+	var orders []*models.Order
+
+	query := r.db.
+		Where("order_status = ? AND delivered_at >= ? AND delivered_at < ?", models.OrderStatusDelivered, from, to).
+		Where("assigned_repartidor_id IS NOT NULL")
+	if repartidorID != "" {
+		query = query.Where("assigned_repartidor_id = ?", repartidorID)
+	}
+
+	if err := query.Order("delivered_at ASC").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// CountActiveByRepartidor cuenta los pedidos asignados o en camino de cada repartidor
+func (r *orderRepository) CountActiveByRepartidor() (map[uuid.UUID]int, error) {
+	var rows []struct {
+		RepartidorID uuid.UUID
+		Count        int
+	}
+
+	err := r.db.Model(&models.Order{}).
+		Select("assigned_repartidor_id AS repartidor_id, COUNT(*) AS count").
+		Where("order_status IN ?", []models.OrderStatus{models.OrderStatusAssigned, models.OrderStatusInTransit}).
+		Where("assigned_repartidor_id IS NOT NULL").
+		Group("assigned_repartidor_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		counts[row.RepartidorID] = row.Count
+	}
+	return counts, nil
+}
+
 func (r *orderRepository) FindByStatus(status models.OrderStatus) ([]*models.Order, error) {
 	var orders []*models.Order
 
@@ -157,13 +214,20 @@ func (r *orderRepository) UpdateStatus(id string, status models.OrderStatus) err
 		updates["confirmed_at"] = now
 	case models.OrderStatusAssigned:
 		updates["assigned_at"] = now
+	case models.OrderStatusInTransit:
+		updates["in_transit_at"] = now
 	case models.OrderStatusDelivered:
 		updates["delivered_at"] = now
 	case models.OrderStatusCancelled:
 		updates["cancelled_at"] = now
 	}
 
-	return r.db.Model(&models.Order{}).Where("order_id = ?", id).Updates(updates).Error
+	err := r.db.Model(&models.Order{}).Where("order_id = ?", id).Updates(updates).Error
+	// El trigger de stock rechaza la confirmación si la sucursal, la variante o el producto no alcanzan
+	if isCheckViolation(err, "") {
+		return models.ErrStockBelowZero
+	}
+	return err
 }
 
 func (r *orderRepository) AssignRepartidor(orderID string, repartidorID string) error {
@@ -175,11 +239,68 @@ func (r *orderRepository) AssignRepartidor(orderID string, repartidorID string)
 	return r.db.Model(&models.Order{}).Where("order_id = ?", orderID).Updates(updates).Error
 }
 
+// MarkAssigned asigna el repartidor y pasa el pedido a ASSIGNED en una sola sentencia, solo si sigue
+// sin repartidor y en un estado asignable. Devuelve models.ErrOrderAlreadyAssigned si no actualizó el
+// pedido y models.ErrStockBelowZero si el trigger de stock rechaza la asignación.
+func (r *orderRepository) MarkAssigned(orderID string, repartidorID string) error {
+	result := r.db.Model(&models.Order{}).
+		Where("order_id = ? AND assigned_repartidor_id IS NULL AND order_status IN ?", orderID, models.AssignableOrderStatuses).
+		Updates(map[string]interface{}{
+			"assigned_repartidor_id": repartidorID,
+			"assigned_at":            time.Now(),
+			"order_status":           models.OrderStatusAssigned,
+		})
+	if isCheckViolation(result.Error, "") {
+		return models.ErrStockBelowZero
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrOrderAlreadyAssigned
+	}
+	return nil
+}
+
 func (r *orderRepository) SetEstimatedArrivalTime(orderID string, eta time.Time) error {
 	return r.db.Model(&models.Order{}).Where("order_id = ?", orderID).
 		Update("estimated_arrival_time", eta).Error
 }
 
+func (r *orderRepository) SetTip(orderID string, amount float64) error {
+	updates := map[string]interface{}{
+		"tip_amount":     amount,
+		"tip_updated_at": time.Now(),
+	}
+
+	return r.db.Model(&models.Order{}).Where("order_id = ?", orderID).Updates(updates).Error
+}
+
+// UpdateCylinderReturns guarda los vacíos confirmados por ítem y recalcula garantías y total del pedido
+func (r *orderRepository) UpdateCylinderReturns(order *models.Order) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range order.OrderItems {
+			err := tx.Model(&models.OrderItem{}).Where("order_item_id = ?", item.OrderItemID).
+				Updates(map[string]interface{}{
+					"cylinders_returned": item.CylindersReturned,
+					"deposit_charge":     item.DepositCharge,
+					"updated_at":         time.Now(),
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Order{}).Where("order_id = ?", order.OrderID).
+			Updates(map[string]interface{}{
+				"deposit_total":          order.DepositTotal,
+				"total_amount":           order.TotalAmount,
+				"cylinders_confirmed_at": order.CylindersConfirmedAt,
+				"updated_at":             time.Now(),
+			}).Error
+	})
+}
+
 func (r *orderRepository) Delete(id string) error {
 	// Primero eliminar los items relacionados
 	if err := r.db.Where("order_id = ?", id).Delete(&models.OrderItem{}).Error; err != nil {
@@ -197,7 +318,7 @@ func (r *orderRepository) AddOrderItem(item *models.OrderItem) error {
 func (r *orderRepository) FindOrderItems(orderID string) ([]*models.OrderItem, error) {
 	var items []*models.OrderItem
 
-	if err := r.db.Where("order_id = ?", orderID).Preload("Product").Find(&items).Error; err != nil {
+	if err := r.db.Where("order_id = ?", orderID).Preload("Product", withDeleted).Preload("Components").Find(&items).Error; err != nil {
 		return nil, err
 	}
 
@@ -243,7 +364,8 @@ func (r *orderRepository) FindByClientIDWithPagination(clientID string, offset,
 	if err := r.db.
 		Preload("Client").
 		Preload("AssignedRepartidor").
-		Preload("OrderItems.Product").
+		Preload("OrderItems.Product", withDeleted).
+		Preload("OrderItems.Components").
 		Where("client_id = ?", clientID).
 		Order("order_time DESC").
 		Limit(limit).
@@ -269,7 +391,8 @@ func (r *orderRepository) FindByRepartidorIDWithPagination(repartidorID string,
 	if err := r.db.
 		Preload("Client").
 		Preload("AssignedRepartidor").
-		Preload("OrderItems.Product").
+		Preload("OrderItems.Product", withDeleted).
+		Preload("OrderItems.Components").
 		Where("assigned_repartidor_id = ?", repartidorID).
 		Order("order_time DESC").
 		Limit(limit).
@@ -286,8 +409,48 @@ func (r *orderRepository) FindAllWithPagination(offset, limit int, status *model
 	var orders []*models.Order
 	var total int64
 
-	query := r.db.Model(&models.Order{})
+	query := applyOrderFilters(r.db.Model(&models.Order{}), status, searchQuery, userRole, userID)
+
+	// Contar total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Obtener órdenes paginadas con preloads
+	if err := query.
+		Preload("Client").
+		Preload("AssignedRepartidor").
+		Preload("OrderItems.Product", withDeleted).
+		Preload("OrderItems.Components").
+		Order("order_time DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// FindAllWithCursor obtiene hasta limit+1 órdenes posteriores al cursor, ordenadas por (order_time, order_id)
+func (r *orderRepository) FindAllWithCursor(cursor *models.Cursor, limit int, status *models.OrderStatus, searchQuery string, userRole models.UserRole, userID string) ([]*models.Order, error) {
+	var orders []*models.Order
+
+	query := applyOrderFilters(r.db.Model(&models.Order{}), status, searchQuery, userRole, userID)
+	if err := applyCursor(query, cursor, "order_time", "order_id", limit).
+		Preload("Client").
+		Preload("AssignedRepartidor").
+		Preload("OrderItems.Product", withDeleted).
+		Preload("OrderItems.Components").
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
 
+// applyOrderFilters aplica los filtros de rol, estado y búsqueda compartidos por el listado paginado y la exportación
+func applyOrderFilters(query *gorm.DB, status *models.OrderStatus, searchQuery string, userRole models.UserRole, userID string) *gorm.DB {
 	// Filtrar por rol de usuario
 	switch userRole {
 	case models.UserRoleClient:
@@ -295,7 +458,13 @@ func (r *orderRepository) FindAllWithPagination(offset, limit int, status *model
 	case models.UserRoleRepartidor:
 		query = query.Where("assigned_repartidor_id = ?", userID)
 	case models.UserRoleAdmin:
-		// Los admins ven todas las órdenes, sin filtro adicional
+		// Los admins ven todas las órdenes; los asignados a una sucursal, solo las de su sucursal
+		if userID != "" {
+			query = query.Where(
+				"((SELECT branch_id FROM users WHERE user_id = ?) IS NULL OR branch_id = (SELECT branch_id FROM users WHERE user_id = ?))",
+				userID, userID,
+			)
+		}
 	}
 
 	// Filtrar por estado si se especifica
@@ -313,22 +482,109 @@ func (r *orderRepository) FindAllWithPagination(offset, limit int, status *model
 		)
 	}
 
-	// Contar total
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	return query
+}
+
+// FindForExport recorre los pedidos filtrados de más reciente a más antiguo en lotes de batchSize,
+// paginando por (order_time, order_id) para no cargar todos los pedidos en memoria
+func (r *orderRepository) FindForExport(filter models.OrderExportFilter, batchSize int, fn func(orders []*models.Order) error) error {
+	var lastTime time.Time
+	var lastID string
+
+	for {
+		query := applyOrderFilters(r.db.Model(&models.Order{}), filter.Status, filter.Search, models.UserRoleAdmin, filter.UserID)
+		query = query.Where("order_time >= ? AND order_time < ?", filter.From, filter.To)
+		if lastID != "" {
+			query = query.Where("(order_time, order_id) < (?, ?)", lastTime, lastID)
+		}
+
+		var orders []*models.Order
+		if err := query.
+			Preload("Client").
+			Preload("AssignedRepartidor").
+			Preload("OrderItems.Product", withDeleted).
+			Preload("OrderItems.Components").
+			Order("order_time DESC, order_id DESC").
+			Limit(batchSize).
+			Find(&orders).Error; err != nil {
+			return err
+		}
+
+		if len(orders) == 0 {
+			return nil
+		}
+		if err := fn(orders); err != nil {
+			return err
+		}
+		if len(orders) < batchSize {
+			return nil
+		}
+
+		last := orders[len(orders)-1]
+		lastTime, lastID = last.OrderTime, last.OrderID.String()
 	}
+}
 
-	// Obtener órdenes paginadas con preloads
-	if err := query.
-		Preload("Client").
-		Preload("AssignedRepartidor").
-		Preload("OrderItems.Product").
-		Order("order_time DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&orders).Error; err != nil {
-		return nil, 0, err
+// GetRepartidorPerformance calcula los indicadores de desempeño de los pedidos asignados en [from, to)
+// (repartidorID vacío = todos los repartidores)
+func (r *orderRepository) GetRepartidorPerformance(from, to time.Time, repartidorID string) ([]models.RepartidorPerformance, error) {
+	var performance []models.RepartidorPerformance
+
+	query := r.db.Table("orders o").
+		Select(`o.assigned_repartidor_id AS repartidor_id,
+			u.full_name AS repartidor_name,
+			COUNT(*) AS assigned_orders,
+			COUNT(*) FILTER (WHERE o.order_status = 'DELIVERED') AS delivered_orders,
+			COUNT(*) FILTER (WHERE o.order_status = 'CANCELLED') AS cancelled_while_assigned,
+			AVG(EXTRACT(EPOCH FROM (o.in_transit_at - o.assigned_at)) / 60)
+				FILTER (WHERE o.in_transit_at IS NOT NULL) AS avg_assigned_to_transit_minutes,
+			AVG(EXTRACT(EPOCH FROM (o.delivered_at - o.in_transit_at)) / 60)
+				FILTER (WHERE o.order_status = 'DELIVERED' AND o.in_transit_at IS NOT NULL) AS avg_transit_to_delivered_minutes,
+			COUNT(*) FILTER (WHERE o.order_status = 'DELIVERED' AND o.estimated_arrival_time IS NOT NULL) AS eta_deliveries,
+			COUNT(*) FILTER (WHERE o.order_status = 'DELIVERED' AND o.delivered_at <= o.estimated_arrival_time) AS on_time_deliveries`).
+		Joins("JOIN users u ON u.user_id = o.assigned_repartidor_id").
+		Where("o.assigned_at >= ? AND o.assigned_at < ?", from, to)
+
+	if repartidorID != "" {
+		query = query.Where("o.assigned_repartidor_id = ?", repartidorID)
 	}
 
-	return orders, total, nil
+	err := query.
+		Group("o.assigned_repartidor_id, u.full_name").
+		Scan(&performance).Error
+	return performance, err
+}
+
+// GetRepartidorEarnings agrupa por repartidor asignado los pedidos entregados en el rango [from, to)
+func (r *orderRepository) GetRepartidorEarnings(from, to time.Time, repartidorID string) ([]models.RepartidorEarnings, error) {
+	var earnings []models.RepartidorEarnings
+
+	query := r.db.Table("orders o").
+		Select(`o.assigned_repartidor_id AS repartidor_id,
+			u.full_name AS repartidor_name,
+			COUNT(*) AS delivered_orders,
+			COUNT(*) FILTER (WHERE o.tip_amount > 0) AS tipped_orders,
+			COALESCE(SUM(o.tip_amount), 0) AS tips_total`).
+		Joins("JOIN users u ON u.user_id = o.assigned_repartidor_id").
+		Where("o.order_status = ? AND o.delivered_at >= ? AND o.delivered_at < ?", models.OrderStatusDelivered, from, to)
+
+	if repartidorID != "" {
+		query = query.Where("o.assigned_repartidor_id = ?", repartidorID)
+	}
+
+	err := query.
+		Group("o.assigned_repartidor_id, u.full_name").
+		Order("tips_total DESC").
+		Scan(&earnings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range earnings {
+		if earnings[i].TippedOrders > 0 {
+			earnings[i].AverageTip = models.RoundCurrency(earnings[i].TipsTotal / float64(earnings[i].TippedOrders))
+		}
+	}
+
+	return earnings, nil
 }
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Vistas y compras entregadas por producto y día; una compra es un pedido entregado que incluye el producto
+const popularityActivitySQL = `SELECT COALESCE(v.product_id, s.product_id) AS product_id,
+		COALESCE(v.view_date, s.day) AS day,
+		COALESCE(v.views, 0) AS views,
+		COALESCE(s.purchases, 0) AS purchases
+	FROM (
+		SELECT product_id, view_date, views FROM product_view_daily WHERE view_date >= ?::date
+	) v
+	FULL OUTER JOIN (
+		SELECT oi.product_id, o.delivered_at::date AS day, COUNT(DISTINCT o.order_id) AS purchases
+		FROM order_items oi
+		JOIN orders o ON o.order_id = oi.order_id
+		WHERE o.order_status = 'DELIVERED' AND o.delivered_at >= ?
+		GROUP BY 1, 2
+	) s ON s.product_id = v.product_id AND s.day = v.view_date`
+
+// PopularityRepository obtiene la actividad de los productos y guarda su puntuación de popularidad
+type PopularityRepository interface {
+	FindProducts() ([]models.PopularityProduct, error)
+	FindActivity(since time.Time) ([]models.PopularityActivity, error)
+	UpdateScores(scores map[uuid.UUID]float64) error
+}
+
+type popularityRepository struct {
+	db *gorm.DB
+}
+
+// NewPopularityRepository crea una nueva instancia del repositorio de popularidad
+func NewPopularityRepository(db *gorm.DB) PopularityRepository {
+	return &popularityRepository{db: db}
+}
+
+// FindProducts obtiene las calificaciones y la puntuación guardada de los productos no eliminados
+func (r *popularityRepository) FindProducts() ([]models.PopularityProduct, error) {
+	var products []models.PopularityProduct
+	err := r.db.Model(&models.Product{}).
+		Select("product_id, name, rating_average, rating_count, popularity_score").
+		Scan(&products).Error
+	return products, err
+}
+
+// FindActivity obtiene las vistas y compras diarias desde since
+func (r *popularityRepository) FindActivity(since time.Time) ([]models.PopularityActivity, error) {
+	var activity []models.PopularityActivity
+	err := r.db.Raw(popularityActivitySQL, since, since).Scan(&activity).Error
+	return activity, err
+}
+
+// UpdateScores guarda las puntuaciones en una transacción sin modificar updated_at: la popularidad
+// no es una edición del producto
+func (r *popularityRepository) UpdateScores(scores map[uuid.UUID]float64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for productID, score := range scores {
+			err := tx.Model(&models.Product{}).
+				Where("product_id = ?", productID).
+				UpdateColumn("popularity_score", score).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// El stock de un combo es la cantidad de combos que alcanza a armarse con sus componentes activos.
+// Su variante (los combos tienen una sola) lleva el mismo stock. Los cambios de stock de los
+// componentes los propaga el trigger refresh_bundle_stock de la base de datos.
+const refreshBundleStockSQL = `UPDATE products p
+	SET stock_quantity = COALESCE((
+		SELECT MIN(CASE WHEN c.is_active THEN c.stock_quantity / b.quantity ELSE 0 END)
+		FROM product_bundle_items b
+		JOIN products c ON c.product_id = b.component_id
+		WHERE b.bundle_id = p.product_id
+	), 0), updated_at = NOW()
+	WHERE p.product_id = ? AND p.is_bundle`
+
+const syncBundleVariantStockSQL = `UPDATE product_variants v
+	SET stock_quantity = p.stock_quantity, updated_at = NOW()
+	FROM products p
+	WHERE p.product_id = v.product_id AND p.product_id = ? AND p.is_bundle`
+
+// ReplaceBundleItems reemplaza los componentes de un combo y recalcula su stock
+func (r *productRepository) ReplaceBundleItems(bundleID uuid.UUID, items []models.ProductBundleItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_id = ?", bundleID).Delete(&models.ProductBundleItem{}).Error; err != nil {
+			return err
+		}
+		return insertBundleItems(tx, bundleID, items)
+	})
+}
+
+// IsBundleComponent indica si el producto forma parte de algún combo
+func (r *productRepository) IsBundleComponent(productID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ProductBundleItem{}).Where("component_id = ?", productID).Count(&count).Error
+	return count > 0, err
+}
+
+// insertBundleItems guarda los componentes del combo y recalcula su stock y el de su variante
+func insertBundleItems(tx *gorm.DB, bundleID uuid.UUID, items []models.ProductBundleItem) error {
+	for i := range items {
+		items[i].BundleID = bundleID
+		items[i].Component = nil // Solo se guarda la referencia, nunca el producto componente
+		if err := tx.Create(&items[i]).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Exec(refreshBundleStockSQL, bundleID).Error; err != nil {
+		return err
+	}
+	return tx.Exec(syncBundleVariantStockSQL, bundleID).Error
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// La imagen principal (primera según el orden) define products.image_url para los clientes que
+// solo leen ese campo
+const syncProductImageURLSQL = `UPDATE products
+	SET image_url = COALESCE((
+		SELECT COALESCE(NULLIF(medium_url, ''), url) FROM product_images
+		WHERE product_id = ?
+		ORDER BY sort_order, created_at
+		LIMIT 1
+	), ''), updated_at = NOW()
+	WHERE product_id = ?`
+
+// ProductImageRepository maneja las imágenes subidas de los productos
+type ProductImageRepository interface {
+	Create(image *models.ProductImage) error
+	FindByID(imageID string) (*models.ProductImage, error)
+	FindByProductID(productID string) ([]models.ProductImage, error)
+	CountByProductID(productID string) (int64, error)
+	Reorder(productID uuid.UUID, imageIDs []uuid.UUID) error
+	Delete(image *models.ProductImage) error
+}
+
+type productImageRepository struct {
+	db *gorm.DB
+}
+
+// NewProductImageRepository crea una nueva instancia del repositorio
+func NewProductImageRepository(db *gorm.DB) ProductImageRepository {
+	return &productImageRepository{db: db}
+}
+
+// Create guarda la imagen al final del orden del producto
+func (r *productImageRepository) Create(image *models.ProductImage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var next int
+		err := tx.Model(&models.ProductImage{}).
+			Select("COALESCE(MAX(sort_order) + 1, 0)").
+			Where("product_id = ?", image.ProductID).
+			Scan(&next).Error
+		if err != nil {
+			return err
+		}
+		image.SortOrder = next
+
+		if err := tx.Create(image).Error; err != nil {
+			return err
+		}
+		return tx.Exec(syncProductImageURLSQL, image.ProductID, image.ProductID).Error
+	})
+}
+
+// FindByID obtiene una imagen por su ID
+func (r *productImageRepository) FindByID(imageID string) (*models.ProductImage, error) {
+	var image models.ProductImage
+	if err := r.db.Where("image_id = ?", imageID).First(&image).Error; err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// FindByProductID obtiene las imágenes de un producto en orden
+func (r *productImageRepository) FindByProductID(productID string) ([]models.ProductImage, error) {
+	var images []models.ProductImage
+	err := r.db.
+		Where("product_id = ?", productID).
+		Order("sort_order, created_at").
+		Find(&images).Error
+	return images, err
+}
+
+// CountByProductID cuenta las imágenes de un producto
+func (r *productImageRepository) CountByProductID(productID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ProductImage{}).Where("product_id = ?", productID).Count(&count).Error
+	return count, err
+}
+
+// Reorder asigna el orden según la posición de cada imagen en la lista
+func (r *productImageRepository) Reorder(productID uuid.UUID, imageIDs []uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for position, imageID := range imageIDs {
+			err := tx.Model(&models.ProductImage{}).
+				Where("image_id = ? AND product_id = ?", imageID, productID).
+				Update("sort_order", position).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Exec(syncProductImageURLSQL, productID, productID).Error
+	})
+}
+
+// Delete elimina la imagen y actualiza la imagen principal del producto
+func (r *productImageRepository) Delete(image *models.ProductImage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.ProductImage{}, "image_id = ?", image.ImageID).Error; err != nil {
+			return err
+		}
+		return tx.Exec(syncProductImageURLSQL, image.ProductID, image.ProductID).Error
+	})
+}
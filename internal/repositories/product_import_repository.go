@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Un producto por fila con el SKU de su variante predeterminada. Con una sucursal, el stock es el
+// de esa sucursal (los combos conservan el calculado a partir de sus componentes).
+const productExportSQL = `SELECT COALESCE(v.sku, '') AS sku, p.name, COALESCE(p.description, '') AS description,
+		COALESCE(c.name, '') AS category, p.price,
+		CASE WHEN ?::uuid IS NULL OR p.is_bundle THEN p.stock_quantity ELSE COALESCE(bs.quantity, 0) END AS stock_quantity,
+		p.unit_of_measure, COALESCE(p.package_size, '') AS package_size, p.is_active
+	FROM products p
+	LEFT JOIN categories c ON c.category_id = p.category_id AND c.deleted_at IS NULL
+	LEFT JOIN product_variants v ON v.product_id = p.product_id AND v.is_default
+	LEFT JOIN branch_stock bs ON bs.product_id = p.product_id AND bs.branch_id = ?::uuid
+	WHERE p.deleted_at IS NULL
+	ORDER BY p.name`
+
+// FindImportIndex carga productos, variantes y categorías para resolver las filas de una importación.
+// Con una sucursal, el stock de cada producto es el de esa sucursal.
+func (r *productRepository) FindImportIndex(branchID *uuid.UUID) (*models.ProductImportIndex, error) {
+	var products []*models.Product
+	if err := r.db.Find(&products).Error; err != nil {
+		return nil, err
+	}
+	if branchID != nil {
+		var stock []models.BranchStock
+		if err := r.db.Where("branch_id = ?", *branchID).Find(&stock).Error; err != nil {
+			return nil, err
+		}
+		quantities := make(map[uuid.UUID]int, len(stock))
+		for _, row := range stock {
+			quantities[row.ProductID] = row.Quantity
+		}
+		for _, product := range products {
+			if !product.IsBundle {
+				product.StockQuantity = quantities[product.ProductID]
+			}
+		}
+	}
+	var variants []models.ProductVariant
+	if err := r.db.Select("variant_id, product_id, sku, is_default").Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	var categories []models.Category
+	if err := r.db.Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return models.NewProductImportIndex(products, variants, categories), nil
+}
+
+// ApplyImport crea y actualiza los productos de una importación en una sola transacción. Con una
+// sucursal, el stock de cada fila es el de esa sucursal y el total se recalcula a partir de branch_stock.
+func (r *productRepository) ApplyImport(changes []models.ProductImportChange, branchID *uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, change := range changes {
+			err := withStockLedger(tx, change.Product.ProductID, models.StockReasonImport, func(tx *gorm.DB) error {
+				return withPriceHistory(tx, change.Product.ProductID, priceChange{source: models.PriceSourceImport}, func(tx *gorm.DB) error {
+					if change.IsNew {
+						return createImportedProduct(tx, change, branchID)
+					}
+					return updateImportedProduct(tx, change, branchID)
+				})
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindForExport obtiene todos los productos con el formato del archivo de exportación
+func (r *productRepository) FindForExport(branchID *uuid.UUID) ([]models.ProductExportRow, error) {
+	var rows []models.ProductExportRow
+	err := r.db.Raw(productExportSQL, branchID, branchID).Scan(&rows).Error
+	return rows, err
+}
+
+// BranchExists indica si existe la sucursal
+func (r *productRepository) BranchExists(branchID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.Raw("SELECT EXISTS (SELECT 1 FROM branches WHERE branch_id = ?)", branchID).Scan(&exists).Error
+	return exists, err
+}
+
+// createImportedProduct crea el producto y su variante predeterminada
+func createImportedProduct(tx *gorm.DB, change models.ProductImportChange, branchID *uuid.UUID) error {
+	product := change.Product
+	branchStock := product.StockQuantity
+	if branchID != nil {
+		// El producto nace sin stock y recibe el de la fila en la sucursal
+		product.StockQuantity = 0
+	}
+	if err := requireStockWithoutBranches(tx, product.ProductID, product.StockQuantity); err != nil {
+		return err
+	}
+	if err := tx.Create(product).Error; err != nil {
+		return err
+	}
+	// GORM omite los valores cero de columnas con default (stock 0, inactivo)
+	err := tx.Model(&models.Product{}).
+		Where("product_id = ?", product.ProductID).
+		Updates(map[string]interface{}{
+			"stock_quantity": product.StockQuantity,
+			"is_active":      product.IsActive,
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	variant := models.NewDefaultVariant(product)
+	if change.SKU != "" {
+		variant.SKU = change.SKU
+	}
+	if err := tx.Create(variant).Error; err != nil {
+		return err
+	}
+	if branchID == nil {
+		return nil
+	}
+	return setImportedBranchStock(tx, *branchID, product, branchStock)
+}
+
+// updateImportedProduct actualiza los campos importables y los lleva a la variante predeterminada
+func updateImportedProduct(tx *gorm.DB, change models.ProductImportChange, branchID *uuid.UUID) error {
+	product := change.Product
+	fields := map[string]interface{}{
+		"name":            product.Name,
+		"description":     product.Description,
+		"category_id":     product.CategoryID,
+		"price":           product.Price,
+		"stock_quantity":  product.StockQuantity,
+		"unit_of_measure": product.UnitOfMeasure,
+		"package_size":    product.PackageSize,
+		"is_active":       product.IsActive,
+		"updated_at":      time.Now(),
+	}
+	// El stock de un combo se calcula y el de una sucursal se guarda en branch_stock
+	if product.IsBundle || branchID != nil {
+		delete(fields, "stock_quantity")
+	} else if err := requireStockWithoutBranches(tx, product.ProductID, product.StockQuantity); err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Product{}).Where("product_id = ?", product.ProductID).Updates(fields).Error; err != nil {
+		return err
+	}
+
+	variantUpdates := map[string]interface{}{"price": product.Price, "updated_at": time.Now()}
+	if change.SKU != "" {
+		variantUpdates["sku"] = change.SKU
+	}
+	err := tx.Model(&models.ProductVariant{}).
+		Where("product_id = ? AND is_default = ?", product.ProductID, true).
+		Updates(variantUpdates).Error
+	if err != nil {
+		return err
+	}
+	if branchID != nil && !product.IsBundle {
+		return setImportedBranchStock(tx, *branchID, product, product.StockQuantity)
+	}
+	return syncDefaultVariantStock(tx, product.ProductID)
+}
+
+// setImportedBranchStock guarda el stock de la fila en la sucursal y deja en el producto el total
+func setImportedBranchStock(tx *gorm.DB, branchID uuid.UUID, product *models.Product, quantity int) error {
+	if err := setBranchStock(tx, branchID, product.ProductID, quantity); err != nil {
+		return err
+	}
+	return tx.Raw("SELECT stock_quantity FROM products WHERE product_id = ?", product.ProductID).Scan(&product.StockQuantity).Error
+}
@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProductPriceRepository maneja el historial de precios y los cambios de precio programados
+type ProductPriceRepository interface {
+	FindHistory(productID string, cursor *models.Cursor, limit int) ([]models.ProductPriceHistory, error)
+	CreateSchedule(schedule *models.ScheduledPriceChange) error
+	FindSchedules(productID string, status *models.ScheduledPriceStatus) ([]models.ScheduledPriceChange, error)
+	FindScheduleByID(scheduleID string) (*models.ScheduledPriceChange, error)
+	CancelSchedule(schedule *models.ScheduledPriceChange) (bool, error)
+	ApplyNextDueSchedule(now time.Time) (*models.ScheduledPriceChange, error)
+}
+
+type productPriceRepository struct {
+	db *gorm.DB
+}
+
+// NewProductPriceRepository crea una nueva instancia del repositorio
+func NewProductPriceRepository(db *gorm.DB) ProductPriceRepository {
+	return &productPriceRepository{db: db}
+}
+
+// FindHistory obtiene hasta limit+1 cambios de precio de un producto, del más reciente al más antiguo
+func (r *productPriceRepository) FindHistory(productID string, cursor *models.Cursor, limit int) ([]models.ProductPriceHistory, error) {
+	var history []models.ProductPriceHistory
+	query := r.db.Where("product_id = ?", productID)
+	err := applyCursor(query, cursor, "changed_at", "history_id", limit).Find(&history).Error
+	return history, err
+}
+
+// CreateSchedule guarda un cambio de precio programado. Devuelve models.ErrScheduledPriceConflict si
+// el producto ya tiene un cambio pendiente para la misma fecha (dos altas simultáneas)
+func (r *productPriceRepository) CreateSchedule(schedule *models.ScheduledPriceChange) error {
+	err := r.db.Create(schedule).Error
+	if isUniqueViolation(err, "idx_scheduled_price_changes_pending_unique") {
+		return models.ErrScheduledPriceConflict
+	}
+	return err
+}
+
+// FindSchedules obtiene los cambios programados de un producto por fecha de vigencia
+func (r *productPriceRepository) FindSchedules(productID string, status *models.ScheduledPriceStatus) ([]models.ScheduledPriceChange, error) {
+	var schedules []models.ScheduledPriceChange
+	query := r.db.Where("product_id = ?", productID)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	err := query.Order("effective_at, created_at").Find(&schedules).Error
+	return schedules, err
+}
+
+// FindScheduleByID obtiene un cambio programado por su ID
+func (r *productPriceRepository) FindScheduleByID(scheduleID string) (*models.ScheduledPriceChange, error) {
+	var schedule models.ScheduledPriceChange
+	if err := r.db.Where("schedule_id = ?", scheduleID).First(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// CancelSchedule cancela el cambio si sigue pendiente; devuelve false si ya se aplicó o canceló
+func (r *productPriceRepository) CancelSchedule(schedule *models.ScheduledPriceChange) (bool, error) {
+	result := r.db.Model(&models.ScheduledPriceChange{}).
+		Where("schedule_id = ? AND status = ?", schedule.ScheduleID, models.ScheduledPricePending).
+		Updates(map[string]interface{}{
+			"status":     models.ScheduledPriceCancelled,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	schedule.Status = models.ScheduledPriceCancelled
+	return true, nil
+}
+
+// ApplyNextDueSchedule aplica el cambio pendiente más antiguo cuya vigencia ya empezó: actualiza
+// el precio del producto y de su variante predeterminada, lo registra en el historial y marca el
+// cambio como aplicado. Devuelve nil si no hay cambios vencidos. SKIP LOCKED permite que varias
+// instancias del servidor ejecuten la tarea sin aplicar dos veces el mismo cambio.
+// Los cambios de productos eliminados o inactivos quedan pendientes: se aplican si el producto se reactiva.
+func (r *productPriceRepository) ApplyNextDueSchedule(now time.Time) (*models.ScheduledPriceChange, error) {
+	var schedule models.ScheduledPriceChange
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{
+			Strength: "UPDATE",
+			Table:    clause.Table{Name: "scheduled_price_changes"},
+			Options:  "SKIP LOCKED",
+		}).
+			Joins("JOIN products ON products.product_id = scheduled_price_changes.product_id").
+			Where("scheduled_price_changes.status = ? AND scheduled_price_changes.effective_at <= ?", models.ScheduledPricePending, now).
+			Where("products.deleted_at IS NULL AND products.is_active").
+			Order("scheduled_price_changes.effective_at, scheduled_price_changes.created_at").
+			First(&schedule).Error
+		if err != nil {
+			return err
+		}
+
+		change := priceChange{
+			source:     models.PriceSourceScheduled,
+			scheduleID: &schedule.ScheduleID,
+			changedBy:  schedule.CreatedBy,
+		}
+		err = withPriceHistory(tx, schedule.ProductID, change, func(tx *gorm.DB) error {
+			err := tx.Model(&models.ProductVariant{}).
+				Where("product_id = ? AND is_default = ?", schedule.ProductID, true).
+				Updates(map[string]interface{}{"price": schedule.NewPrice, "updated_at": now}).Error
+			if err != nil {
+				return err
+			}
+			return tx.Model(&models.Product{}).
+				Where("product_id = ?", schedule.ProductID).
+				Updates(map[string]interface{}{"price": schedule.NewPrice, "updated_at": now}).Error
+		})
+		if err != nil {
+			return err
+		}
+
+		schedule.Status = models.ScheduledPriceApplied
+		schedule.AppliedAt = &now
+		return tx.Model(&schedule).Updates(map[string]interface{}{
+			"status":     schedule.Status,
+			"applied_at": now,
+			"updated_at": now,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// priceChange describe el origen de un cambio de precio para el historial
+type priceChange struct {
+	source     models.PriceChangeSource
+	scheduleID *uuid.UUID
+	changedBy  *uuid.UUID
+}
+
+// withPriceHistory ejecuta fn y registra en el historial el cambio de precio que haya producido en
+// el producto. Si el producto no existía antes, registra su precio inicial.
+func withPriceHistory(tx *gorm.DB, productID uuid.UUID, change priceChange, fn func(tx *gorm.DB) error) error {
+	var before []float64
+	if err := tx.Raw("SELECT price FROM products WHERE product_id = ? FOR UPDATE", productID).Scan(&before).Error; err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	var after []float64
+	if err := tx.Raw("SELECT price FROM products WHERE product_id = ?", productID).Scan(&after).Error; err != nil {
+		return err
+	}
+	if len(after) == 0 {
+		return nil
+	}
+
+	entry := &models.ProductPriceHistory{
+		ProductID:         productID,
+		NewPrice:          after[0],
+		Source:            change.source,
+		ScheduledChangeID: change.scheduleID,
+		ChangedBy:         change.changedBy,
+	}
+	if len(before) > 0 {
+		if before[0] == after[0] {
+			return nil
+		}
+		entry.OldPrice = &before[0]
+	}
+	return tx.Create(entry).Error
+}
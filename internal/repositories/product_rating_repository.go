@@ -11,6 +11,7 @@ type ProductRatingRepository interface {
 	FindByID(id string) (*models.ProductRating, error)
 	FindByProductAndUser(productID, userID string) (*models.ProductRating, error)
 	FindByProduct(productID string) ([]*models.ProductRating, error)
+	FindByProductWithCursor(productID string, cursor *models.Cursor, limit int) ([]*models.ProductRating, error)
 	FindByUser(userID string) ([]*models.ProductRating, error)
 	Update(rating *models.ProductRating) error
 	Delete(id string) error
@@ -32,7 +33,7 @@ func (r *productRatingRepository) Create(rating *models.ProductRating) error {
 
 func (r *productRatingRepository) FindByID(id string) (*models.ProductRating, error) {
 	var rating models.ProductRating
-	err := r.db.Preload("Product").Preload("User").
+	err := r.db.Preload("Product", withDeleted).Preload("User").
 		Where("rating_id = ?", id).
 		First(&rating).Error
 	if err != nil {
@@ -60,9 +61,17 @@ func (r *productRatingRepository) FindByProduct(productID string) ([]*models.Pro
 	return ratings, err
 }
 
+// FindByProductWithCursor obtiene hasta limit+1 calificaciones posteriores al cursor, ordenadas por (created_at, rating_id)
+func (r *productRatingRepository) FindByProductWithCursor(productID string, cursor *models.Cursor, limit int) ([]*models.ProductRating, error) {
+	var ratings []*models.ProductRating
+	query := r.db.Preload("User").Where("product_id = ?", productID)
+	err := applyCursor(query, cursor, "created_at", "rating_id", limit).Find(&ratings).Error
+	return ratings, err
+}
+
 func (r *productRatingRepository) FindByUser(userID string) ([]*models.ProductRating, error) {
 	var ratings []*models.ProductRating
-	err := r.db.Preload("Product").Preload("Product.Category").
+	err := r.db.Preload("Product", withDeleted).Preload("Product.Category", withDeleted).
 		Where("user_id = ?", userID).
 		Order("created_at DESC").
 		Find(&ratings).Error
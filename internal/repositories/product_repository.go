@@ -1,8 +1,12 @@
 package repositories
 
 import (
+	"context"
+	"strings"
+
 	"backend/internal/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -11,15 +15,37 @@ type ProductRepository interface {
 	FindByID(id string) (*models.Product, error)
 	FindAll() ([]*models.Product, error)
 	FindActive() ([]*models.Product, error)
+	FindWithCursor(cursor *models.Cursor, limit int, onlyActive bool) ([]*models.Product, error)
 	FindPopular(limit int) ([]*models.Product, error)
 	FindRecent(limit int) ([]*models.Product, error)
 	FindWithOffers() ([]*models.Product, error)
 	FindActiveWithOffers(limit int) ([]*models.Product, error)
-	IncrementViewCount(id string) error
+	AddViewCounts(ctx context.Context, counts map[uuid.UUID]int) error
 	IncrementPurchaseCount(id string) error
 	Update(product *models.Product) error
 	Delete(id string) error
 	LoadCurrentOffer(product *models.Product) error
+	Search(params models.ProductSearchParams) ([]*models.Product, int64, error)
+	FindVariants(productID string, activeOnly bool) ([]models.ProductVariant, error)
+	FindVariantByID(variantID string) (*models.ProductVariant, error)
+	FindVariantBySKU(sku string) (*models.ProductVariant, error)
+	FindDefaultVariant(productID string) (*models.ProductVariant, error)
+	CreateVariant(variant *models.ProductVariant) error
+	UpdateVariant(variant *models.ProductVariant) error
+	SyncDefaultVariant(product *models.Product) error
+	SearchFacets(params models.ProductSearchParams) (*models.ProductSearchFacets, error)
+	FindImportIndex(branchID *uuid.UUID) (*models.ProductImportIndex, error)
+	ApplyImport(changes []models.ProductImportChange, branchID *uuid.UUID) error
+	FindForExport(branchID *uuid.UUID) ([]models.ProductExportRow, error)
+	BranchExists(branchID uuid.UUID) (bool, error)
+	ReplaceBundleItems(bundleID uuid.UUID, items []models.ProductBundleItem) error
+	IsBundleComponent(productID string) (bool, error)
+	FindDeleted() ([]*models.Product, error)
+	FindDeletedByID(id string) (*models.Product, error)
+	NameTaken(name string, excludeID uuid.UUID) (bool, error)
+	Restore(id string) error
+	HasOrderItems(id string) (bool, error)
+	HardDelete(id string) error
 }
 
 type productRepository struct {
@@ -33,23 +59,77 @@ func NewProductRepository(db *gorm.DB) ProductRepository {
 }
 
 func (r *productRepository) Create(product *models.Product) error {
+	// El ID se asigna antes para registrar el stock y el precio inicial
+	if product.ProductID == uuid.Nil {
+		product.ProductID = uuid.New()
+	}
+	// Los componentes de un combo se guardan aparte: su stock se calcula a partir de ellos
+	bundleItems := product.BundleItems
+	product.BundleItems = nil
+	defer func() { product.BundleItems = bundleItems }()
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := withStockLedger(tx, product.ProductID, models.StockReasonInitial, func(tx *gorm.DB) error {
+			if !product.IsBundle {
+				if err := requireStockWithoutBranches(tx, product.ProductID, product.StockQuantity); err != nil {
+					return err
+				}
+			}
+			return withPriceHistory(tx, product.ProductID, priceChange{source: models.PriceSourceCreated}, func(tx *gorm.DB) error {
+				return insertProduct(tx, product)
+			})
+		})
+		if err != nil {
+			return err
+		}
+		if product.IsBundle {
+			if err := insertBundleItems(tx, product.ProductID, bundleItems); err != nil {
+				return err
+			}
+			if err := tx.Raw("SELECT stock_quantity FROM products WHERE product_id = ?", product.ProductID).Scan(&product.StockQuantity).Error; err != nil {
+				return err
+			}
+		}
+		// Todo producto con precio nace con su variante predeterminada (mismo precio y stock)
+		if product.Price > 0 {
+			return tx.Create(models.NewDefaultVariant(product)).Error
+		}
+		return nil
+	})
+}
+
+func insertProduct(tx *gorm.DB, product *models.Product) error {
 	// Special handling for inactive products due to GORM zero-value omission issue
 	if !product.IsActive {
 		// Use raw SQL for inactive products to ensure false value is preserved
-		return r.db.Exec(`
-			INSERT INTO products (product_id, name, description, price, category_id, image_url, stock_quantity, is_active, created_at, updated_at) 
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())`,
-			product.ProductID, product.Name, product.Description, product.Price, product.CategoryID, product.ImageURL, product.StockQuantity, false).Error
+		return tx.Exec(`
+			INSERT INTO products (product_id, name, description, price, category_id, image_url, stock_quantity, is_active, is_returnable, deposit_amount, reorder_threshold, is_bundle, created_at, updated_at) 
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())`,
+			product.ProductID, product.Name, product.Description, product.Price, product.CategoryID, product.ImageURL, product.StockQuantity, false, product.IsReturnable, product.DepositAmount, product.ReorderThreshold, product.IsBundle).Error
 	}
 
 	// For active products, use standard GORM create
-	return r.db.Create(product).Error
+	if err := tx.Create(product).Error; err != nil {
+		return err
+	}
+	// GORM omite el stock 0 (columna con default); los combos siempre nacen en 0
+	if product.StockQuantity == 0 {
+		return tx.Model(&models.Product{}).Where("product_id = ?", product.ProductID).Update("stock_quantity", 0).Error
+	}
+	return nil
 }
 
 func (r *productRepository) FindByID(id string) (*models.Product, error) {
 	var product models.Product
 
-	if err := r.db.Preload("Category").Where("product_id = ?", id).First(&product).Error; err != nil {
+	err := r.db.
+		Preload("Category").
+		Preload("Images", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order, created_at") }).
+		Preload("BundleItems", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order") }).
+		Preload("BundleItems.Component").
+		Where("product_id = ?", id).
+		First(&product).Error
+	if err != nil {
 		return nil, err
 	}
 
@@ -76,25 +156,122 @@ func (r *productRepository) FindActive() ([]*models.Product, error) {
 	return products, nil
 }
 
+// FindWithCursor obtiene hasta limit+1 productos posteriores al cursor, ordenados por (created_at, product_id)
+func (r *productRepository) FindWithCursor(cursor *models.Cursor, limit int, onlyActive bool) ([]*models.Product, error) {
+	var products []*models.Product
+
+	query := r.db.Preload("Category")
+	if onlyActive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	if err := applyCursor(query, cursor, "created_at", "product_id", limit).Find(&products).Error; err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
 func (r *productRepository) Update(product *models.Product) error {
+	// Los cambios de stock y de precio quedan registrados en el libro y en el historial
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return withStockLedger(tx, product.ProductID, models.StockReasonProductEdit, func(tx *gorm.DB) error {
+			return withPriceHistory(tx, product.ProductID, priceChange{source: models.PriceSourceManual}, func(tx *gorm.DB) error {
+				return updateProductFields(tx, product)
+			})
+		})
+	})
+}
+
+func updateProductFields(tx *gorm.DB, product *models.Product) error {
 	// Use Updates to handle pointer fields correctly
-	return r.db.Model(product).Where("product_id = ?", product.ProductID).Updates(map[string]interface{}{
-		"name":           product.Name,
-		"description":    product.Description,
-		"price":          product.Price,
-		"category_id":    product.CategoryID,
-		"image_url":      product.ImageURL,
-		"stock_quantity": product.StockQuantity,
-		"is_active":      product.IsActive,
-		"updated_at":     "NOW()",
-	}).Error
+	fields := map[string]interface{}{
+		"name":              product.Name,
+		"description":       product.Description,
+		"price":             product.Price,
+		"category_id":       product.CategoryID,
+		"image_url":         product.ImageURL,
+		"stock_quantity":    product.StockQuantity,
+		"is_active":         product.IsActive,
+		"is_returnable":     product.IsReturnable,
+		"deposit_amount":    product.DepositAmount,
+		"reorder_threshold": product.ReorderThreshold,
+		"updated_at":        "NOW()",
+	}
+	// El stock de un combo se calcula a partir de sus componentes
+	if product.IsBundle {
+		delete(fields, "stock_quantity")
+	} else if err := requireStockWithoutBranches(tx, product.ProductID, product.StockQuantity); err != nil {
+		return err
+	}
+	return tx.Model(product).Where("product_id = ?", product.ProductID).Updates(fields).Error
 }
 
+// Delete elimina el producto de forma lógica (deleted_at): deja de aparecer en el catálogo pero
+// se conserva para los pedidos, calificaciones y favoritos que lo referencian
 func (r *productRepository) Delete(id string) error {
 	return r.db.Delete(&models.Product{}, "product_id = ?", id).Error
 }
 
-// FindPopular obtiene productos populares basados en popularity_score
+// FindDeleted obtiene los productos eliminados, los más recientes primero
+func (r *productRepository) FindDeleted() ([]*models.Product, error) {
+	var products []*models.Product
+	err := r.db.Unscoped().
+		Preload("Category", withDeleted).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&products).Error
+	return products, err
+}
+
+// FindDeletedByID obtiene un producto eliminado
+func (r *productRepository) FindDeletedByID(id string) (*models.Product, error) {
+	var product models.Product
+	err := r.db.Unscoped().
+		Where("product_id = ? AND deleted_at IS NOT NULL", id).
+		First(&product).Error
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// NameTaken indica si otro producto no eliminado usa el nombre
+func (r *productRepository) NameTaken(name string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Product{}).
+		Where("LOWER(name) = LOWER(?) AND product_id <> ?", name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Restore devuelve al catálogo un producto eliminado
+func (r *productRepository) Restore(id string) error {
+	return r.db.Unscoped().Model(&models.Product{}).
+		Where("product_id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "updated_at": gorm.Expr("NOW()")}).Error
+}
+
+// HasOrderItems indica si algún pedido incluye el producto
+func (r *productRepository) HasOrderItems(id string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.OrderItem{}).Where("product_id = ?", id).Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+// HardDelete borra definitivamente el producto con sus variantes, imágenes, ofertas e historial
+// (claves foráneas en cascada). La base de datos lo impide si algún pedido lo referencia.
+func (r *productRepository) HardDelete(id string) error {
+	return r.db.Unscoped().Delete(&models.Product{}, "product_id = ?", id).Error
+}
+
+// withDeleted incluye los registros eliminados en una precarga: los pedidos y las calificaciones
+// conservan su producto aunque se haya eliminado del catálogo
+func withDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// FindPopular obtiene productos populares basados en popularity_score: activos, con stock y no eliminados
 func (r *productRepository) FindPopular(limit int) ([]*models.Product, error) {
 	var products []*models.Product
 
@@ -120,11 +297,34 @@ func (r *productRepository) FindRecent(limit int) ([]*models.Product, error) {
 	return products, err
 }
 
-// IncrementViewCount incrementa el contador de vistas de un producto
-func (r *productRepository) IncrementViewCount(id string) error {
-	return r.db.Model(&models.Product{}).
-		Where("product_id = ?", id).
-		Update("view_count", gorm.Expr("view_count + 1")).Error
+// AddViewCounts suma un lote de vistas al contador de cada producto y a sus vistas del día con
+// dos sentencias en una transacción. Los productos que ya no existen se ignoran.
+func (r *productRepository) AddViewCounts(ctx context.Context, counts map[uuid.UUID]int) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	rows := make([]string, 0, len(counts))
+	args := make([]interface{}, 0, 2*len(counts))
+	for productID, views := range counts {
+		rows = append(rows, "(?::uuid, ?::integer)")
+		args = append(args, productID, views)
+	}
+	values := "(VALUES " + strings.Join(rows, ", ") + ") AS v(product_id, views)"
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`UPDATE products p SET view_count = p.view_count + v.views
+			FROM `+values+`
+			WHERE p.product_id = v.product_id`, args...).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO product_view_daily (product_id, view_date, views)
+			SELECT v.product_id, CURRENT_DATE, v.views
+			FROM `+values+`
+			JOIN products p ON p.product_id = v.product_id
+			ON CONFLICT (product_id, view_date) DO UPDATE SET views = product_view_daily.views + EXCLUDED.views`, args...).Error
+	})
 }
 
 // IncrementPurchaseCount incrementa el contador de compras de un producto
@@ -140,10 +340,10 @@ func (r *productRepository) FindWithOffers() ([]*models.Product, error) {
 	
 	subquery := r.db.Table("product_offers").
 		Select("DISTINCT product_id").
-		Where("is_active = ? AND start_date <= NOW() AND end_date >= NOW()", true)
+		Where("is_active = ? AND start_date <= NOW() AND end_date >= NOW() AND variant_id IS NULL", true)
 	
 	err := r.db.Preload("Category").
-		Preload("CurrentOffer", "is_active = ? AND start_date <= NOW() AND end_date >= NOW()", true).
+		Preload("CurrentOffer", "is_active = ? AND start_date <= NOW() AND end_date >= NOW() AND variant_id IS NULL", true).
 		Where("is_active = ? AND product_id IN (?)", true, subquery).
 		Find(&products).Error
 	
@@ -155,9 +355,9 @@ func (r *productRepository) FindActiveWithOffers(limit int) ([]*models.Product,
 	var products []*models.Product
 	
 	query := r.db.Preload("Category").
-		Preload("CurrentOffer", "is_active = ? AND start_date <= NOW() AND end_date >= NOW()", true).
+		Preload("CurrentOffer", "is_active = ? AND start_date <= NOW() AND end_date >= NOW() AND variant_id IS NULL", true).
 		Joins("JOIN product_offers ON products.product_id = product_offers.product_id").
-		Where("products.is_active = ? AND product_offers.is_active = ? AND product_offers.start_date <= NOW() AND product_offers.end_date >= NOW()", 
+		Where("products.is_active = ? AND product_offers.is_active = ? AND product_offers.start_date <= NOW() AND product_offers.end_date >= NOW() AND product_offers.variant_id IS NULL", 
 			true, true).
 		Group("products.product_id")
 	
@@ -171,7 +371,7 @@ func (r *productRepository) FindActiveWithOffers(limit int) ([]*models.Product,
 
 // LoadCurrentOffer carga la oferta activa actual para un producto
 func (r *productRepository) LoadCurrentOffer(product *models.Product) error {
-	return r.db.Preload("CurrentOffer", "is_active = ? AND start_date <= NOW() AND end_date >= NOW()", true).
+	return r.db.Preload("CurrentOffer", "is_active = ? AND start_date <= NOW() AND end_date >= NOW() AND variant_id IS NULL", true).
 		Where("product_id = ?", product.ProductID).
 		First(product).Error
 }
@@ -0,0 +1,233 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Oferta vigente más reciente de cada producto (las ofertas por variante no cambian el precio del producto)
+const activeOfferJoin = `LEFT JOIN LATERAL (
+		SELECT po.discount_type::text AS discount_type, po.discount_value
+		FROM product_offers po
+		WHERE po.product_id = products.product_id AND po.variant_id IS NULL AND po.is_active = TRUE AND po.start_date <= NOW() AND po.end_date >= NOW()
+		ORDER BY po.start_date DESC
+		LIMIT 1
+	) active_offer ON TRUE`
+
+// Precio final con la oferta vigente, igual que ProductOffer.CalculateFinalPrice
+const effectivePriceSelect = `CASE active_offer.discount_type
+		WHEN 'percentage' THEN products.price * (1 - active_offer.discount_value / 100)
+		WHEN 'fixed_amount' THEN GREATEST(products.price - active_offer.discount_value, 0)
+		WHEN 'fixed_price' THEN active_offer.discount_value
+		ELSE products.price
+	END`
+
+// Dimensiones de filtro; cada faceta ignora la suya
+const (
+	searchDimCategory = "category"
+	searchDimPrice    = "price"
+	searchDimOffer    = "offer"
+	searchDimStock    = "stock"
+	searchDimRating   = "rating"
+)
+
+type searchCondition struct {
+	dimension string
+	sql       string
+	args      []interface{}
+}
+
+// Search busca productos activos con texto completo, filtros y orden; devuelve la página y el total
+func (r *productRepository) Search(params models.ProductSearchParams) ([]*models.Product, int64, error) {
+	conditions := productSearchConditions(params)
+
+	var total int64
+	if err := r.searchQuery(params, conditions, "").Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var ids []uuid.UUID
+	err := r.searchQuery(params, conditions, "").
+		Order(productSearchOrder(params.Sort)).
+		Offset(params.Offset()).
+		Limit(params.PageSize).
+		Pluck("p.product_id", &ids).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(ids) == 0 {
+		return []*models.Product{}, total, nil
+	}
+
+	var products []*models.Product
+	err = r.db.Preload("Category").
+		Preload("CurrentOffer", "is_active = ? AND start_date <= NOW() AND end_date >= NOW() AND variant_id IS NULL", true).
+		Where("product_id IN ?", ids).
+		Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Respetar el orden de la búsqueda
+	byID := make(map[uuid.UUID]*models.Product, len(products))
+	for _, p := range products {
+		byID[p.ProductID] = p
+	}
+	ordered := make([]*models.Product, 0, len(ids))
+	for _, id := range ids {
+		if p, ok := byID[id]; ok {
+			ordered = append(ordered, p)
+		}
+	}
+	return ordered, total, nil
+}
+
+// SearchFacets cuenta los resultados por categoría, rango de precio, calificación, oferta y stock
+func (r *productRepository) SearchFacets(params models.ProductSearchParams) (*models.ProductSearchFacets, error) {
+	conditions := productSearchConditions(params)
+	facets := &models.ProductSearchFacets{
+		Categories:  []models.FacetCount{},
+		PriceRanges: []models.FacetCount{},
+		MinRatings:  []models.FacetCount{},
+	}
+
+	err := r.searchQuery(params, conditions, searchDimCategory).
+		Select("COALESCE(p.category_id::text, '') AS value, COALESCE(c.name, 'Sin categoría') AS label, COUNT(*) AS count").
+		Joins("LEFT JOIN categories c ON c.category_id = p.category_id AND c.deleted_at IS NULL").
+		Group("p.category_id, c.name").
+		Order("count DESC, label ASC").
+		Scan(&facets.Categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Rangos de precio y calificaciones en una sola fila cada uno, con un COUNT FILTER por valor
+	priceSelects := make([]string, 0, len(models.ProductPriceRanges))
+	for i, pr := range models.ProductPriceRanges {
+		filter := fmt.Sprintf("p.effective_price >= %g", pr.Min)
+		if pr.Max > 0 {
+			filter += fmt.Sprintf(" AND p.effective_price < %g", pr.Max)
+		}
+		priceSelects = append(priceSelects, fmt.Sprintf("COUNT(*) FILTER (WHERE %s) AS r%d", filter, i))
+	}
+	priceRow := map[string]interface{}{}
+	if err := r.searchQuery(params, conditions, searchDimPrice).Select(strings.Join(priceSelects, ", ")).Take(&priceRow).Error; err != nil {
+		return nil, err
+	}
+	for i, pr := range models.ProductPriceRanges {
+		facets.PriceRanges = append(facets.PriceRanges, models.FacetCount{
+			Value: pr.Key,
+			Label: "S/ " + pr.Key,
+			Count: toInt64(priceRow[fmt.Sprintf("r%d", i)]),
+		})
+	}
+
+	ratingSelects := make([]string, 0, len(models.ProductRatingThresholds))
+	for _, threshold := range models.ProductRatingThresholds {
+		ratingSelects = append(ratingSelects, fmt.Sprintf("COUNT(*) FILTER (WHERE p.rating_average >= %d) AS r%d", threshold, threshold))
+	}
+	ratingRow := map[string]interface{}{}
+	if err := r.searchQuery(params, conditions, searchDimRating).Select(strings.Join(ratingSelects, ", ")).Take(&ratingRow).Error; err != nil {
+		return nil, err
+	}
+	for _, threshold := range models.ProductRatingThresholds {
+		facets.MinRatings = append(facets.MinRatings, models.FacetCount{
+			Value: fmt.Sprintf("%d", threshold),
+			Label: fmt.Sprintf("%d o más", threshold),
+			Count: toInt64(ratingRow[fmt.Sprintf("r%d", threshold)]),
+		})
+	}
+
+	if err := r.searchQuery(params, conditions, searchDimOffer).Where("p.on_offer").Count(&facets.OnOffer).Error; err != nil {
+		return nil, err
+	}
+	if err := r.searchQuery(params, conditions, searchDimStock).Where("p.stock_quantity > 0").Count(&facets.InStock).Error; err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+// searchQuery arma la consulta sobre los productos activos que coinciden con el texto, con
+// precio final y relevancia calculados, aplicando los filtros salvo la dimensión excluida
+func (r *productRepository) searchQuery(params models.ProductSearchParams, conditions []searchCondition, exclude string) *gorm.DB {
+	selectSQL := "products.*, " + effectivePriceSelect + " AS effective_price, active_offer.discount_type IS NOT NULL AS on_offer"
+	base := r.db.Table("products").Joins(activeOfferJoin).Where("products.is_active = ? AND products.deleted_at IS NULL", true)
+
+	if tsQuery := models.BuildPrefixTSQuery(params.Query); tsQuery != "" {
+		base = base.
+			Select(selectSQL+", ts_rank(products.search_vector, to_tsquery('spanish', ?)) AS rank", tsQuery).
+			Where("products.search_vector @@ to_tsquery('spanish', ?)", tsQuery)
+	} else {
+		base = base.Select(selectSQL + ", 0::real AS rank")
+	}
+
+	query := r.db.Table("(?) AS p", base)
+	for _, c := range conditions {
+		if c.dimension != exclude {
+			query = query.Where(c.sql, c.args...)
+		}
+	}
+	return query
+}
+
+// productSearchConditions traduce los filtros de búsqueda a condiciones sobre la consulta base
+func productSearchConditions(params models.ProductSearchParams) []searchCondition {
+	var conditions []searchCondition
+	if params.CategoryID != nil {
+		conditions = append(conditions, searchCondition{searchDimCategory, "p.category_id = ?", []interface{}{*params.CategoryID}})
+	}
+	if params.MinPrice != nil {
+		conditions = append(conditions, searchCondition{searchDimPrice, "p.effective_price >= ?", []interface{}{*params.MinPrice}})
+	}
+	if params.MaxPrice != nil {
+		conditions = append(conditions, searchCondition{searchDimPrice, "p.effective_price <= ?", []interface{}{*params.MaxPrice}})
+	}
+	if params.OnOffer {
+		conditions = append(conditions, searchCondition{searchDimOffer, "p.on_offer", nil})
+	}
+	if params.InStock {
+		conditions = append(conditions, searchCondition{searchDimStock, "p.stock_quantity > 0", nil})
+	}
+	if params.MinRating != nil {
+		conditions = append(conditions, searchCondition{searchDimRating, "p.rating_average >= ?", []interface{}{*params.MinRating}})
+	}
+	return conditions
+}
+
+// productSearchOrder devuelve el ORDER BY del criterio pedido, con el ID como desempate estable
+func productSearchOrder(sort string) string {
+	switch sort {
+	case models.ProductSortRelevance:
+		return "p.rank DESC, p.popularity_score DESC, p.product_id"
+	case models.ProductSortPriceAsc:
+		return "p.effective_price ASC, p.product_id"
+	case models.ProductSortPriceDesc:
+		return "p.effective_price DESC, p.product_id"
+	case models.ProductSortRating:
+		return "p.rating_average DESC, p.rating_count DESC, p.product_id"
+	case models.ProductSortNewest:
+		return "p.created_at DESC, p.product_id"
+	default:
+		return "p.popularity_score DESC, p.purchase_count DESC, p.product_id"
+	}
+}
+
+// toInt64 convierte un conteo leído en un mapa genérico
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	case int:
+		return int64(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Oferta vigente propia de la variante
+const activeVariantOfferCondition = "is_active = ? AND start_date <= NOW() AND end_date >= NOW()"
+
+// El precio del producto es el de su variante predeterminada
+const syncProductPriceSQL = `UPDATE products p
+	SET price = v.price, updated_at = NOW()
+	FROM product_variants v
+	WHERE v.product_id = p.product_id AND v.is_default AND p.product_id = ?`
+
+// El stock de un producto es la suma del stock de sus variantes activas
+const activeVariantStockSQL = `SELECT COALESCE(SUM(stock_quantity), 0)
+	FROM product_variants WHERE product_id = ? AND is_active`
+
+// Cuando se fija el stock total del producto, la variante predeterminada absorbe la diferencia
+// con el resto de las variantes activas. Los combos calculan el stock de su variante aparte.
+const syncDefaultVariantStockSQL = `UPDATE product_variants v
+	SET stock_quantity = p.stock_quantity - (
+			SELECT COALESCE(SUM(o.stock_quantity), 0) FROM product_variants o
+			WHERE o.product_id = v.product_id AND o.is_active AND o.variant_id <> v.variant_id
+		),
+		updated_at = NOW()
+	FROM products p
+	WHERE p.product_id = v.product_id AND v.is_default AND NOT p.is_bundle AND p.product_id = ?`
+
+// FindVariants obtiene las variantes de un producto con su oferta vigente
+func (r *productRepository) FindVariants(productID string, activeOnly bool) ([]models.ProductVariant, error) {
+	var variants []models.ProductVariant
+	query := r.db.
+		Preload("CurrentOffer", activeVariantOfferCondition, true).
+		Where("product_id = ?", productID).
+		Order("is_default DESC, sort_order ASC, name ASC")
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Find(&variants).Error
+	return variants, err
+}
+
+// FindVariantByID obtiene una variante con su oferta vigente
+func (r *productRepository) FindVariantByID(variantID string) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := r.db.
+		Preload("CurrentOffer", activeVariantOfferCondition, true).
+		Where("variant_id = ?", variantID).
+		First(&variant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// FindVariantBySKU obtiene una variante por su SKU
+func (r *productRepository) FindVariantBySKU(sku string) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	if err := r.db.Where("sku = ?", sku).First(&variant).Error; err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// FindDefaultVariant obtiene la variante predeterminada de un producto
+func (r *productRepository) FindDefaultVariant(productID string) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := r.db.
+		Preload("CurrentOffer", activeVariantOfferCondition, true).
+		Where("product_id = ? AND is_default = ?", productID, true).
+		First(&variant).Error
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// CreateVariant guarda una variante; si es la predeterminada, desmarca las demás y actualiza el precio del producto
+func (r *productRepository) CreateVariant(variant *models.ProductVariant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if variant.IsDefault {
+			if err := clearDefaultVariant(tx, variant); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(variant).Error; err != nil {
+			return err
+		}
+		return syncProductFromVariants(tx, variant)
+	})
+}
+
+// UpdateVariant actualiza una variante y sincroniza el precio y, si corresponde, el stock del producto
+func (r *productRepository) UpdateVariant(variant *models.ProductVariant) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if variant.IsDefault {
+			if err := clearDefaultVariant(tx, variant); err != nil {
+				return err
+			}
+		}
+		updates := map[string]interface{}{
+			"sku":            variant.SKU,
+			"name":           variant.Name,
+			"size":           variant.Size,
+			"valve_type":     variant.ValveType,
+			"brand":          variant.Brand,
+			"price":          variant.Price,
+			"stock_quantity": variant.StockQuantity,
+			"is_default":     variant.IsDefault,
+			"is_active":      variant.IsActive,
+			"sort_order":     variant.SortOrder,
+			"updated_at":     time.Now(),
+		}
+		if err := tx.Model(&models.ProductVariant{}).Where("variant_id = ?", variant.VariantID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return syncProductFromVariants(tx, variant)
+	})
+}
+
+// SyncDefaultVariant lleva a la variante predeterminada el precio del producto y la diferencia
+// entre el stock del producto y el del resto de sus variantes (edición desde los endpoints de producto)
+func (r *productRepository) SyncDefaultVariant(product *models.Product) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.ProductVariant{}).
+			Where("product_id = ? AND is_default = ?", product.ProductID, true).
+			Updates(map[string]interface{}{"price": product.Price, "updated_at": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+		return syncDefaultVariantStock(tx, product.ProductID)
+	})
+}
+
+// syncDefaultVariantStock ajusta la variante predeterminada al stock total del producto.
+// Devuelve models.ErrStockBelowZero si las otras variantes suman más que el producto.
+func syncDefaultVariantStock(tx *gorm.DB, productID uuid.UUID) error {
+	err := tx.Exec(syncDefaultVariantStockSQL, productID).Error
+	if isCheckViolation(err, "") {
+		return models.ErrStockBelowZero
+	}
+	return err
+}
+
+// clearDefaultVariant desmarca la variante predeterminada anterior del producto
+func clearDefaultVariant(tx *gorm.DB, variant *models.ProductVariant) error {
+	return tx.Model(&models.ProductVariant{}).
+		Where("product_id = ? AND is_default = ? AND variant_id <> ?", variant.ProductID, true, variant.VariantID).
+		Update("is_default", false).Error
+}
+
+// syncProductFromVariants copia al producto el precio de su variante predeterminada y, salvo en
+// los combos, la suma del stock de sus variantes activas
+func syncProductFromVariants(tx *gorm.DB, variant *models.ProductVariant) error {
+	err := withPriceHistory(tx, variant.ProductID, priceChange{source: models.PriceSourceVariant}, func(tx *gorm.DB) error {
+		return tx.Exec(syncProductPriceSQL, variant.ProductID).Error
+	})
+	if err != nil {
+		return err
+	}
+	var isBundle bool
+	if err := tx.Raw("SELECT is_bundle FROM products WHERE product_id = ?", variant.ProductID).Scan(&isBundle).Error; err != nil {
+		return err
+	}
+	if isBundle {
+		return nil
+	}
+	var total int
+	if err := tx.Raw(activeVariantStockSQL, variant.ProductID).Scan(&total).Error; err != nil {
+		return err
+	}
+	// Con sucursales el total es la suma de branch_stock: las variantes solo pueden repartirlo
+	if err := requireStockWithoutBranches(tx, variant.ProductID, total); err != nil {
+		return err
+	}
+	return withStockLedger(tx, variant.ProductID, models.StockReasonVariantEdit, func(tx *gorm.DB) error {
+		return tx.Model(&models.Product{}).
+			Where("product_id = ?", variant.ProductID).
+			Updates(map[string]interface{}{"stock_quantity": total, "updated_at": time.Now()}).Error
+	})
+}
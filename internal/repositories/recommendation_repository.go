@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecommendationRepository maneja las asociaciones entre productos comprados juntos
+type RecommendationRepository interface {
+	FindBasketLines(since time.Time) ([]models.OrderBasketLine, error)
+	ReplaceAssociations(associations []models.ProductAssociation) error
+	FindRelated(productID string, exclude []uuid.UUID, limit int) ([]models.ProductAssociation, error)
+	FindAssociationsFrom(productIDs []uuid.UUID) ([]models.ProductAssociation, error)
+	FindUserSignals(userID string) ([]models.UserProductSignal, error)
+	FindCandidates(productIDs, categoryIDs []uuid.UUID, limit int) ([]*models.Product, error)
+}
+
+// Productos con los que el usuario tiene relación: comprados en pedidos entregados, favoritos o calificados
+const userProductSignalsSQL = `SELECT p.product_id, p.category_id,
+		COALESCE(b.purchases, 0) AS purchases,
+		f.product_id IS NOT NULL AS is_favorite,
+		r.rating
+	FROM products p
+	LEFT JOIN (
+		SELECT oi.product_id, COUNT(DISTINCT o.order_id) AS purchases
+		FROM order_items oi
+		JOIN orders o ON o.order_id = oi.order_id
+		WHERE o.client_id = ? AND o.order_status = 'DELIVERED'
+		GROUP BY oi.product_id
+	) b ON b.product_id = p.product_id
+	LEFT JOIN user_favorites f ON f.product_id = p.product_id AND f.user_id = ?
+	LEFT JOIN product_ratings r ON r.product_id = p.product_id AND r.user_id = ?
+	WHERE p.deleted_at IS NULL
+	AND (b.product_id IS NOT NULL OR f.product_id IS NOT NULL OR r.product_id IS NOT NULL)`
+
+type recommendationRepository struct {
+	db *gorm.DB
+}
+
+// NewRecommendationRepository crea una nueva instancia del repositorio de recomendaciones
+func NewRecommendationRepository(db *gorm.DB) RecommendationRepository {
+	return &recommendationRepository{db: db}
+}
+
+// FindBasketLines obtiene los productos de cada pedido entregado desde since
+func (r *recommendationRepository) FindBasketLines(since time.Time) ([]models.OrderBasketLine, error) {
+	var lines []models.OrderBasketLine
+	err := r.db.Raw(`SELECT DISTINCT oi.order_id, oi.product_id
+		FROM order_items oi
+		JOIN orders o ON o.order_id = oi.order_id
+		WHERE o.order_status = 'DELIVERED' AND o.delivered_at >= ?`, since).
+		Scan(&lines).Error
+	return lines, err
+}
+
+// ReplaceAssociations reemplaza todas las asociaciones en una transacción: las consultas nunca
+// ven la tabla vacía ni a medio calcular
+func (r *recommendationRepository) ReplaceAssociations(associations []models.ProductAssociation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM product_associations").Error; err != nil {
+			return err
+		}
+		if len(associations) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(associations, 500).Error
+	})
+}
+
+// FindRelated obtiene los productos comprados junto con productID que están activos y con stock,
+// de mayor a menor confianza
+func (r *recommendationRepository) FindRelated(productID string, exclude []uuid.UUID, limit int) ([]models.ProductAssociation, error) {
+	var associations []models.ProductAssociation
+	query := r.db.Joins(`JOIN products p ON p.product_id = product_associations.related_product_id
+			AND p.is_active AND p.stock_quantity > 0 AND p.deleted_at IS NULL`).
+		Preload("RelatedProduct.Category").
+		Where("product_associations.product_id = ?", productID)
+	if len(exclude) > 0 {
+		query = query.Where("product_associations.related_product_id NOT IN ?", exclude)
+	}
+	err := query.Order("product_associations.confidence DESC, product_associations.lift DESC, product_associations.support_count DESC").
+		Limit(limit).
+		Find(&associations).Error
+	return associations, err
+}
+
+// FindAssociationsFrom obtiene las asociaciones que parten de cualquiera de los productos
+func (r *recommendationRepository) FindAssociationsFrom(productIDs []uuid.UUID) ([]models.ProductAssociation, error) {
+	var associations []models.ProductAssociation
+	if len(productIDs) == 0 {
+		return associations, nil
+	}
+	err := r.db.Where("product_id IN ?", productIDs).Find(&associations).Error
+	return associations, err
+}
+
+// FindUserSignals obtiene las compras, favoritos y calificaciones del usuario por producto
+func (r *recommendationRepository) FindUserSignals(userID string) ([]models.UserProductSignal, error) {
+	var signals []models.UserProductSignal
+	err := r.db.Raw(userProductSignalsSQL, userID, userID, userID).Scan(&signals).Error
+	return signals, err
+}
+
+// FindCandidates obtiene hasta limit productos que se pueden recomendar (activos y con stock): primero
+// los de productIDs, luego los de las categorías indicadas y el resto por popularidad
+func (r *recommendationRepository) FindCandidates(productIDs, categoryIDs []uuid.UUID, limit int) ([]*models.Product, error) {
+	var products []*models.Product
+	err := r.db.Preload("Category").
+		Where("is_active = ? AND stock_quantity > 0", true).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "product_id IN ? DESC, category_id IN ? DESC, popularity_score DESC, purchase_count DESC",
+			Vars:               []interface{}{productIDs, categoryIDs},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).
+		Find(&products).Error
+	return products, err
+}
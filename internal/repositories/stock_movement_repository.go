@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"fmt"
+
+	"backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// La variación se aplica solo si el stock no queda negativo; devuelve el stock resultante
+const applyProductStockDeltaSQL = `UPDATE products
+	SET stock_quantity = stock_quantity + ?, updated_at = NOW()
+	WHERE product_id = ? AND stock_quantity + ? >= 0
+	RETURNING stock_quantity`
+
+// Los combos no tienen libro propio: su stock se calcula a partir de sus componentes
+const reconciliationSQL = `SELECT p.product_id, p.name, p.stock_quantity,
+		COALESCE(SUM(m.quantity), 0) AS ledger_balance,
+		p.stock_quantity - COALESCE(SUM(m.quantity), 0) AS difference
+	FROM products p
+	LEFT JOIN stock_movements m ON m.product_id = p.product_id
+	WHERE NOT p.is_bundle %s
+	GROUP BY p.product_id, p.name, p.stock_quantity
+	HAVING p.stock_quantity <> COALESCE(SUM(m.quantity), 0)
+	ORDER BY p.name`
+
+// Condición de stock bajo: producto activo con umbral y stock por debajo de él
+const lowStockCondition = "is_active AND reorder_threshold > 0 AND stock_quantity < reorder_threshold"
+
+// StockMovementRepository maneja el libro de movimientos de stock y los avisos de stock bajo
+type StockMovementRepository interface {
+	Record(movement *models.StockMovement) error
+	FindByProduct(productID string, cursor *models.Cursor, limit int) ([]models.StockMovement, error)
+	FindByOrder(orderID string) ([]models.StockMovement, error)
+	FindReconciliation() ([]models.StockReconciliation, error)
+	Reconcile(productID uuid.UUID, createdBy *uuid.UUID) (*models.StockMovement, error)
+	FindLowStock() ([]models.LowStockProduct, error)
+	ClaimLowStockAlerts(productIDs []uuid.UUID) ([]models.LowStockProduct, error)
+}
+
+type stockMovementRepository struct {
+	db *gorm.DB
+}
+
+// NewStockMovementRepository crea una nueva instancia del repositorio
+func NewStockMovementRepository(db *gorm.DB) StockMovementRepository {
+	return &stockMovementRepository{db: db}
+}
+
+// Record aplica la variación al stock de la sucursal, del producto (y de la variante indicada, o de
+// la predeterminada) y registra el movimiento con el saldo resultante, todo en una transacción.
+// Devuelve models.ErrStockBelowZero si el stock quedaría negativo y models.ErrBranchStockRequired
+// si hay sucursales y el movimiento no indica una.
+func (r *stockMovementRepository) Record(movement *models.StockMovement) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := applyBranchStockDelta(tx, movement); err != nil {
+			return err
+		}
+
+		var balances []int
+		err := tx.Raw(applyProductStockDeltaSQL, movement.Quantity, movement.ProductID, movement.Quantity).
+			Scan(&balances).Error
+		if err != nil {
+			return err
+		}
+		if len(balances) == 0 {
+			return models.ErrStockBelowZero
+		}
+		movement.BalanceAfter = balances[0]
+
+		if movement.VariantID != nil {
+			result := tx.Model(&models.ProductVariant{}).
+				Where("variant_id = ? AND product_id = ? AND stock_quantity + ? >= 0", movement.VariantID, movement.ProductID, movement.Quantity).
+				Updates(map[string]interface{}{
+					"stock_quantity": gorm.Expr("stock_quantity + ?", movement.Quantity),
+					"updated_at":     gorm.Expr("NOW()"),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return models.ErrStockBelowZero
+			}
+		} else if err := syncDefaultVariantStock(tx, movement.ProductID); err != nil {
+			return err
+		}
+
+		return tx.Create(movement).Error
+	})
+}
+
+// applyBranchStockDelta aplica la variación al stock de la sucursal del movimiento. Con sucursales,
+// el total del producto es la suma de branch_stock y todo movimiento debe indicar la sucursal.
+func applyBranchStockDelta(tx *gorm.DB, movement *models.StockMovement) error {
+	if movement.BranchID == nil {
+		branches, err := hasBranches(tx)
+		if err != nil {
+			return err
+		}
+		if branches {
+			return models.ErrBranchStockRequired
+		}
+		return nil
+	}
+
+	err := tx.Exec(`INSERT INTO branch_stock (branch_id, product_id, quantity, updated_at)
+		VALUES (?, ?, ?, NOW())
+		ON CONFLICT (branch_id, product_id)
+		DO UPDATE SET quantity = branch_stock.quantity + EXCLUDED.quantity, updated_at = NOW()`,
+		movement.BranchID, movement.ProductID, movement.Quantity).Error
+	if isCheckViolation(err, "") {
+		return models.ErrStockBelowZero
+	}
+	return err
+}
+
+// FindByProduct obtiene hasta limit+1 movimientos de un producto, del más reciente al más antiguo
+func (r *stockMovementRepository) FindByProduct(productID string, cursor *models.Cursor, limit int) ([]models.StockMovement, error) {
+	var movements []models.StockMovement
+	query := r.db.Where("product_id = ?", productID)
+	err := applyCursor(query, cursor, "created_at", "movement_id", limit).Find(&movements).Error
+	return movements, err
+}
+
+// FindByOrder obtiene los movimientos generados por un pedido
+func (r *stockMovementRepository) FindByOrder(orderID string) ([]models.StockMovement, error) {
+	var movements []models.StockMovement
+	err := r.db.Where("order_id = ?", orderID).Order("created_at").Find(&movements).Error
+	return movements, err
+}
+
+// FindReconciliation obtiene los productos cuyo stock no coincide con la suma de su libro
+func (r *stockMovementRepository) FindReconciliation() ([]models.StockReconciliation, error) {
+	var rows []models.StockReconciliation
+	err := r.db.Raw(fmt.Sprintf(reconciliationSQL, "")).Scan(&rows).Error
+	return rows, err
+}
+
+// Reconcile registra un ajuste por la diferencia entre el stock y el libro, sin cambiar el stock.
+// Devuelve nil si el producto ya estaba conciliado.
+func (r *stockMovementRepository) Reconcile(productID uuid.UUID, createdBy *uuid.UUID) (*models.StockMovement, error) {
+	var movement *models.StockMovement
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Bloquear el producto para que no cambie su stock mientras se concilia
+		if err := tx.Exec("SELECT 1 FROM products WHERE product_id = ? FOR UPDATE", productID).Error; err != nil {
+			return err
+		}
+
+		var rows []models.StockReconciliation
+		err := tx.Raw(fmt.Sprintf(reconciliationSQL, "AND p.product_id = ?"), productID).Scan(&rows).Error
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+
+		movement = &models.StockMovement{
+			ProductID:    productID,
+			MovementType: models.StockMovementAdjustment,
+			Quantity:     rows[0].Difference,
+			BalanceAfter: rows[0].StockQuantity,
+			Reason:       models.StockReasonReconciliation,
+			CreatedBy:    createdBy,
+		}
+		return tx.Create(movement).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movement, nil
+}
+
+// FindLowStock obtiene los productos activos con stock por debajo de su umbral, los más críticos primero
+func (r *stockMovementRepository) FindLowStock() ([]models.LowStockProduct, error) {
+	var products []models.LowStockProduct
+	err := r.db.Model(&models.Product{}).
+		Select("product_id, name, stock_quantity, reorder_threshold, low_stock_alerted_at").
+		Where(lowStockCondition).
+		Order("stock_quantity::float / reorder_threshold, name").
+		Scan(&products).Error
+	return products, err
+}
+
+// ClaimLowStockAlerts marca como avisados los productos indicados que quedaron con stock bajo y
+// devuelve solo los que no tenían un aviso pendiente; a los que se repusieron les limpia el aviso.
+// La marca se toma en la misma sentencia, así dos procesos no avisan dos veces el mismo producto.
+func (r *stockMovementRepository) ClaimLowStockAlerts(productIDs []uuid.UUID) ([]models.LowStockProduct, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	var claimed []models.LowStockProduct
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`UPDATE products SET low_stock_alerted_at = NULL
+			WHERE product_id IN ? AND low_stock_alerted_at IS NOT NULL AND NOT (`+lowStockCondition+`)`, productIDs).Error
+		if err != nil {
+			return err
+		}
+		return tx.Raw(`UPDATE products SET low_stock_alerted_at = NOW()
+			WHERE product_id IN ? AND low_stock_alerted_at IS NULL AND `+lowStockCondition+`
+			RETURNING product_id, name, stock_quantity, reorder_threshold, low_stock_alerted_at`, productIDs).
+			Scan(&claimed).Error
+	})
+	return claimed, err
+}
+
+// withStockLedger ejecuta fn y registra como ajuste el cambio de stock que haya producido en el
+// producto, para que las ediciones que fijan el stock directamente también queden en el libro
+func withStockLedger(tx *gorm.DB, productID uuid.UUID, reason string, fn func(tx *gorm.DB) error) error {
+	var before []int
+	err := tx.Raw("SELECT stock_quantity FROM products WHERE product_id = ? FOR UPDATE", productID).Scan(&before).Error
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	var after []int
+	if err := tx.Raw("SELECT stock_quantity FROM products WHERE product_id = ?", productID).Scan(&after).Error; err != nil {
+		return err
+	}
+	previous := 0
+	if len(before) > 0 {
+		previous = before[0]
+	}
+	if len(after) == 0 || after[0] == previous {
+		return nil
+	}
+
+	return tx.Create(&models.StockMovement{
+		ProductID:    productID,
+		MovementType: models.StockMovementAdjustment,
+		Quantity:     after[0] - previous,
+		BalanceAfter: after[0],
+		Reason:       reason,
+	}).Error
+}
@@ -13,6 +13,7 @@ type UserRepository interface {
 	FindByRole(role models.UserRole) ([]*models.User, error)
 	FindAll() ([]*models.User, error)
 	FindAllWithPagination(offset, limit int, role *models.UserRole) ([]*models.User, int64, error)
+	FindAllWithCursor(cursor *models.Cursor, limit int, role *models.UserRole) ([]*models.User, error)
 	Update(user *models.User) error
 	Delete(id string) error
 	SoftDelete(id string) error
@@ -99,6 +100,22 @@ func (r *userRepository) FindAllWithPagination(offset, limit int, role *models.U
 	return users, total, nil
 }
 
+// FindAllWithCursor obtiene hasta limit+1 usuarios posteriores al cursor, ordenados por (created_at, user_id)
+func (r *userRepository) FindAllWithCursor(cursor *models.Cursor, limit int, role *models.UserRole) ([]*models.User, error) {
+	var users []*models.User
+
+	query := r.db.Model(&models.User{})
+	if role != nil {
+		query = query.Where("user_role = ?", *role)
+	}
+
+	if err := applyCursor(query, cursor, "created_at", "user_id", limit).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func (r *userRepository) Delete(id string) error {
 	return r.db.Delete(&models.User{}, "user_id = ?", id).Error
 }
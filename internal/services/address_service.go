@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAddressNotFound     = errors.New("dirección no encontrada")
+	ErrAddressLimitReached = errors.New("se alcanzó el máximo de direcciones guardadas")
+	ErrInvalidAddress      = errors.New("dirección inválida")
+)
+
+// AddressService maneja la libreta de direcciones de los clientes
+type AddressService struct {
+	addressRepo repositories.AddressRepository
+}
+
+// NewAddressService crea un nuevo servicio de direcciones
+func NewAddressService(addressRepo repositories.AddressRepository) *AddressService {
+	return &AddressService{
+		addressRepo: addressRepo,
+	}
+}
+
+// List obtiene las direcciones guardadas del usuario
+func (s *AddressService) List(userID string) ([]models.UserAddress, error) {
+	addresses, err := s.addressRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if addresses == nil {
+		addresses = []models.UserAddress{}
+	}
+	return addresses, nil
+}
+
+// Get obtiene una dirección del usuario
+func (s *AddressService) Get(addressID string, userID string) (*models.UserAddress, error) {
+	if _, err := uuid.Parse(addressID); err != nil {
+		return nil, ErrAddressNotFound
+	}
+
+	address, err := s.addressRepo.FindByID(addressID)
+	if err != nil || address.UserID.String() != userID {
+		// No revelar direcciones de otros usuarios
+		return nil, ErrAddressNotFound
+	}
+	return address, nil
+}
+
+// Create guarda una nueva dirección; la primera queda como predeterminada
+func (s *AddressService) Create(userID string, req *models.CreateAddressRequest) (*models.UserAddress, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	count, err := s.addressRepo.CountByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= models.MaxAddressesPerUser {
+		return nil, ErrAddressLimitReached
+	}
+
+	address := &models.UserAddress{
+		UserID:      userUUID,
+		Label:       req.Label,
+		AddressText: req.AddressText,
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+		Reference:   req.Reference,
+		IsDefault:   req.IsDefault || count == 0,
+	}
+	if err := address.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+
+	if err := s.addressRepo.Create(address); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// Update actualiza los campos enviados de una dirección del usuario
+func (s *AddressService) Update(addressID string, userID string, req *models.UpdateAddressRequest) (*models.UserAddress, error) {
+	address, err := s.Get(addressID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Label != nil {
+		address.Label = *req.Label
+	}
+	if req.AddressText != nil {
+		address.AddressText = *req.AddressText
+	}
+	if req.Latitude != nil {
+		address.Latitude = *req.Latitude
+	}
+	if req.Longitude != nil {
+		address.Longitude = *req.Longitude
+	}
+	if req.Reference != nil {
+		address.Reference = *req.Reference
+	}
+	// Solo se puede marcar como predeterminada; para cambiarla se marca otra
+	if req.IsDefault != nil && *req.IsDefault {
+		address.IsDefault = true
+	}
+
+	if err := address.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+
+	if err := s.addressRepo.Update(address); err != nil {
+		return nil, err
+	}
+	return s.addressRepo.FindByID(addressID)
+}
+
+// SetDefault marca una dirección como predeterminada
+func (s *AddressService) SetDefault(addressID string, userID string) (*models.UserAddress, error) {
+	isDefault := true
+	return s.Update(addressID, userID, &models.UpdateAddressRequest{IsDefault: &isDefault})
+}
+
+// Delete elimina una dirección del usuario (los pedidos conservan su copia)
+func (s *AddressService) Delete(addressID string, userID string) error {
+	address, err := s.Get(addressID, userID)
+	if err != nil {
+		return err
+	}
+	return s.addressRepo.Delete(address)
+}
+
+// ApplyToOrder copia una dirección guardada del cliente en el pedido
+func (s *AddressService) ApplyToOrder(addressID string, clientID string, order *models.Order) error {
+	address, err := s.Get(addressID, clientID)
+	if err != nil {
+		return err
+	}
+	address.ApplyToOrder(order)
+	return nil
+}
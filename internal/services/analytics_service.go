@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"backend/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+var ErrInvalidAnalyticsGroup = errors.New("agrupación de reporte inválida")
+
+// SalesAnalyticsQuery agrupa los parámetros del reporte de ventas (jornadas en formato YYYY-MM-DD)
+type SalesAnalyticsQuery struct {
+	GroupBy         string // Vacío = solo totales
+	From            string
+	To              string
+	CompareFrom     string
+	CompareTo       string
+	ComparePrevious bool // Compara con el período anterior de igual duración
+}
+
+// AnalyticsService genera los reportes de ventas para administradores
+type AnalyticsService struct {
+	analyticsRepo repositories.AnalyticsRepository
+	config        *config.Config
+}
+
+// NewAnalyticsService crea un nuevo servicio de analítica
+func NewAnalyticsService(analyticsRepo repositories.AnalyticsRepository, config *config.Config) *AnalyticsService {
+	return &AnalyticsService{
+		analyticsRepo: analyticsRepo,
+		config:        config,
+	}
+}
+
+// GetSalesAnalytics calcula los indicadores de ventas del período y, opcionalmente, del período de comparación
+func (s *AnalyticsService) GetSalesAnalytics(query SalesAnalyticsQuery) (*models.SalesAnalytics, error) {
+	if query.GroupBy != "" && !models.IsValidAnalyticsGroup(query.GroupBy) {
+		return nil, ErrInvalidAnalyticsGroup
+	}
+
+	start, end, err := s.resolveRange(query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := s.buildPeriod(start, end, query.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := &models.SalesAnalytics{
+		GroupBy:  query.GroupBy,
+		TimeZone: s.config.App.TimeZone,
+		Period:   *period,
+	}
+
+	var compareStart, compareEnd time.Time
+	switch {
+	case query.CompareFrom != "" || query.CompareTo != "":
+		compareStart, compareEnd, err = models.BusinessDateRange(query.CompareFrom, query.CompareTo, s.config.App.TimeZone, time.Now())
+		if err != nil {
+			return nil, ErrInvalidBusinessDate
+		}
+	case query.ComparePrevious:
+		compareStart, compareEnd = models.PreviousPeriod(start, end)
+	default:
+		return analytics, nil
+	}
+
+	comparison, err := s.buildPeriod(compareStart, compareEnd, query.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	analytics.Comparison = comparison
+	analytics.Change = models.CompareSalesMetrics(period.Totals, comparison.Totals)
+
+	return analytics, nil
+}
+
+// resolveRange convierte las jornadas recibidas en un rango [inicio, fin); sin fechas usa los últimos días
+func (s *AnalyticsService) resolveRange(from, to string) (time.Time, time.Time, error) {
+	if from == "" && to == "" {
+		_, end, err := models.BusinessDayBounds("", s.config.App.TimeZone, time.Now())
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidBusinessDate
+		}
+		return end.AddDate(0, 0, -models.AnalyticsDefaultDays), end, nil
+	}
+
+	start, end, err := models.BusinessDateRange(from, to, s.config.App.TimeZone, time.Now())
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidBusinessDate
+	}
+	return start, end, nil
+}
+
+// buildPeriod obtiene los totales y, si corresponde, las filas agrupadas de un rango
+func (s *AnalyticsService) buildPeriod(start, end time.Time, groupBy string) (*models.SalesPeriod, error) {
+	totals, err := s.analyticsRepo.GetSalesTotals(start, end)
+	if err != nil {
+		return nil, err
+	}
+	totals.Finalize()
+
+	period := &models.SalesPeriod{
+		From:   start.Format(models.BusinessDateLayout),
+		To:     end.AddDate(0, 0, -1).Format(models.BusinessDateLayout),
+		Totals: *totals,
+	}
+
+	if groupBy == "" {
+		return period, nil
+	}
+
+	rows, err := s.analyticsRepo.GetSalesByGroup(start, end, groupBy, s.config.App.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].Finalize()
+	}
+	period.Rows = rows
+
+	return period, nil
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBranchNotFound       = errors.New("sucursal no encontrada")
+	ErrInvalidBranch        = errors.New("sucursal inválida")
+	ErrBranchNameTaken      = errors.New("ya existe una sucursal con ese nombre")
+	ErrBranchAccessDenied   = errors.New("el administrador no tiene acceso a esta sucursal")
+	ErrBranchMismatch       = errors.New("el repartidor pertenece a otra sucursal")
+	ErrOutsideServiceZone   = errors.New("la dirección está fuera de la zona de reparto")
+	ErrNoBranchStock        = errors.New("ninguna sucursal cercana tiene stock suficiente")
+	ErrInvalidStockQuantity = errors.New("la cantidad de stock no puede ser negativa")
+)
+
+// BranchService maneja las sucursales, su stock y el enrutamiento de pedidos.
+// Mientras no haya sucursales activas los pedidos no se enrutan (un único depósito).
+type BranchService struct {
+	branchRepo  repositories.BranchRepository
+	userRepo    repositories.UserRepository
+	productRepo repositories.ProductRepository
+	config      *config.Config
+}
+
+// NewBranchService crea un nuevo servicio de sucursales
+func NewBranchService(
+	branchRepo repositories.BranchRepository,
+	userRepo repositories.UserRepository,
+	productRepo repositories.ProductRepository,
+	cfg *config.Config,
+) *BranchService {
+	return &BranchService{
+		branchRepo:  branchRepo,
+		userRepo:    userRepo,
+		productRepo: productRepo,
+		config:      cfg,
+	}
+}
+
+// ListBranches lista las sucursales; los clientes solo ven las activas
+func (s *BranchService) ListBranches(activeOnly bool) ([]models.Branch, error) {
+	branches, err := s.branchRepo.FindAll(activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	if branches == nil {
+		branches = []models.Branch{}
+	}
+	return branches, nil
+}
+
+// GetBranch obtiene una sucursal por su ID
+func (s *BranchService) GetBranch(branchID string) (*models.Branch, error) {
+	if _, err := uuid.Parse(branchID); err != nil {
+		return nil, ErrBranchNotFound
+	}
+	branch, err := s.branchRepo.FindByID(branchID)
+	if err != nil {
+		return nil, ErrBranchNotFound
+	}
+	return branch, nil
+}
+
+// CreateBranch crea una sucursal. La primera sucursal recibe el stock actual de los productos.
+// Solo los administradores sin sucursal asignada pueden crear sucursales.
+func (s *BranchService) CreateBranch(actorID string, req *models.CreateBranchRequest) (*models.Branch, error) {
+	if err := s.requireGlobalAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	branch := &models.Branch{
+		Name:            strings.TrimSpace(req.Name),
+		AddressText:     strings.TrimSpace(req.AddressText),
+		PhoneNumber:     req.PhoneNumber,
+		Latitude:        req.Latitude,
+		Longitude:       req.Longitude,
+		ServiceRadiusKm: models.DefaultServiceRadiusKm,
+		OpensAt:         req.OpensAt,
+		ClosesAt:        req.ClosesAt,
+		IsActive:        true,
+	}
+	if req.ServiceRadiusKm != nil {
+		branch.ServiceRadiusKm = *req.ServiceRadiusKm
+	}
+	if err := branch.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBranch, err)
+	}
+	if err := s.ensureNameAvailable(branch.Name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	count, err := s.branchRepo.Count()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.branchRepo.Create(branch); err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		// El stock existente pasa a la primera sucursal para que los totales no cambien
+		if err := s.branchRepo.InitializeStockFromProducts(branch.BranchID); err != nil {
+			return nil, err
+		}
+	}
+	return branch, nil
+}
+
+// UpdateBranch actualiza una sucursal; un administrador de sucursal solo puede editar la suya
+func (s *BranchService) UpdateBranch(actorID string, branchID string, req *models.UpdateBranchRequest) (*models.Branch, error) {
+	branch, err := s.GetBranch(branchID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireBranchAccess(actorID, branch.BranchID); err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		branch.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.AddressText != nil {
+		branch.AddressText = strings.TrimSpace(*req.AddressText)
+	}
+	if req.PhoneNumber != nil {
+		branch.PhoneNumber = *req.PhoneNumber
+	}
+	if req.Latitude != nil {
+		branch.Latitude = *req.Latitude
+	}
+	if req.Longitude != nil {
+		branch.Longitude = *req.Longitude
+	}
+	if req.ServiceRadiusKm != nil {
+		branch.ServiceRadiusKm = *req.ServiceRadiusKm
+	}
+	if req.OpensAt != nil {
+		branch.OpensAt = *req.OpensAt
+	}
+	if req.ClosesAt != nil {
+		branch.ClosesAt = *req.ClosesAt
+	}
+	if req.IsActive != nil {
+		branch.IsActive = *req.IsActive
+	}
+
+	if err := branch.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBranch, err)
+	}
+	if err := s.ensureNameAvailable(branch.Name, branch.BranchID); err != nil {
+		return nil, err
+	}
+	if err := s.branchRepo.Update(branch); err != nil {
+		return nil, err
+	}
+	return s.branchRepo.FindByID(branchID)
+}
+
+// GetBranchStock obtiene el stock por producto de una sucursal
+func (s *BranchService) GetBranchStock(actorID string, branchID string) ([]models.BranchStock, error) {
+	branch, err := s.GetBranch(branchID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireBranchAccess(actorID, branch.BranchID); err != nil {
+		return nil, err
+	}
+
+	stock, err := s.branchRepo.FindStockByBranch(branchID)
+	if err != nil {
+		return nil, err
+	}
+	if stock == nil {
+		stock = []models.BranchStock{}
+	}
+	return stock, nil
+}
+
+// SetBranchStock fija el stock de un producto en una sucursal; el total del producto se recalcula
+func (s *BranchService) SetBranchStock(actorID string, branchID string, productID string, quantity int) error {
+	if quantity < 0 {
+		return ErrInvalidStockQuantity
+	}
+	branch, err := s.GetBranch(branchID)
+	if err != nil {
+		return err
+	}
+	if err := s.requireBranchAccess(actorID, branch.BranchID); err != nil {
+		return err
+	}
+
+	if _, err := uuid.Parse(productID); err != nil {
+		return ErrProductNotFound
+	}
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return ErrProductNotFound
+	}
+
+	return s.branchRepo.SetStock(branch.BranchID, product.ProductID, quantity)
+}
+
+// AssignUserBranch asigna un repartidor o administrador a una sucursal (nil la quita)
+func (s *BranchService) AssignUserBranch(actorID string, userID string, branchID *string) (*models.User, error) {
+	if err := s.requireGlobalAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.UserRole != models.UserRoleRepartidor && user.UserRole != models.UserRoleAdmin {
+		return nil, ErrInvalidRole
+	}
+
+	user.BranchID = nil
+	if branchID != nil && *branchID != "" {
+		branch, err := s.GetBranch(*branchID)
+		if err != nil {
+			return nil, err
+		}
+		user.BranchID = &branch.BranchID
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RouteOrder elige la sucursal más cercana que cubre la dirección y tiene stock para los ítems.
+// Devuelve nil sin error cuando no hay sucursales activas.
+func (s *BranchService) RouteOrder(lat, lng float64, items []models.OrderItem) (*models.Branch, error) {
+	branches, err := s.branchRepo.FindAll(true)
+	if err != nil {
+		return nil, err
+	}
+	if len(branches) == 0 {
+		return nil, nil
+	}
+
+	// Los combos se despachan con el stock de sus componentes
+	demand := models.OrderStockDemand(items)
+	productIDs := make([]uuid.UUID, 0, len(demand))
+	for productID := range demand {
+		productIDs = append(productIDs, productID)
+	}
+
+	rows, err := s.branchRepo.FindStockByProducts(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	stock := make(map[uuid.UUID]map[uuid.UUID]int)
+	for _, row := range rows {
+		if stock[row.BranchID] == nil {
+			stock[row.BranchID] = make(map[uuid.UUID]int)
+		}
+		stock[row.BranchID][row.ProductID] = row.Quantity
+	}
+
+	routing := models.SelectBranch(branches, stock, demand, lat, lng)
+	if routing.Branch != nil {
+		return routing.Branch, nil
+	}
+	if !routing.InZone {
+		return nil, ErrOutsideServiceZone
+	}
+	return nil, ErrNoBranchStock
+}
+
+// IsBranchOpenAt verifica el horario propio de la sucursal en la zona horaria del negocio
+func (s *BranchService) IsBranchOpenAt(branch *models.Branch, t time.Time) bool {
+	loc, err := time.LoadLocation(s.config.App.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return branch.IsOpenAt(t, loc)
+}
+
+// requireGlobalAdmin verifica que el administrador no esté limitado a una sucursal
+func (s *BranchService) requireGlobalAdmin(actorID string) error {
+	actor, err := s.userRepo.FindByID(actorID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if actor.BranchID != nil {
+		return ErrBranchAccessDenied
+	}
+	return nil
+}
+
+// requireBranchAccess verifica que el administrador sea global o pertenezca a la sucursal
+func (s *BranchService) requireBranchAccess(actorID string, branchID uuid.UUID) error {
+	actor, err := s.userRepo.FindByID(actorID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if actor.BranchID != nil && *actor.BranchID != branchID {
+		return ErrBranchAccessDenied
+	}
+	return nil
+}
+
+// ensureNameAvailable evita dos sucursales con el mismo nombre
+func (s *BranchService) ensureNameAvailable(name string, exceptID uuid.UUID) error {
+	branches, err := s.branchRepo.FindAll(false)
+	if err != nil {
+		return err
+	}
+	for _, b := range branches {
+		if b.BranchID != exceptID && strings.EqualFold(b.Name, name) {
+			return ErrBranchNameTaken
+		}
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"backend/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidBusinessHours = errors.New("horario de atención inválido")
+	ErrInvalidSpecialDay    = errors.New("día especial inválido")
+	ErrSpecialDayNotFound   = errors.New("día especial no encontrado")
+	ErrSpecialDayExists     = errors.New("ya existe un día especial para esa fecha")
+)
+
+// businessCalendarCacheTTL limita cuánto tarda otra instancia en ver los cambios del calendario
+const businessCalendarCacheTTL = time.Minute
+
+// specialDaysWindow es el rango de días especiales que se mantiene en memoria alrededor de hoy
+const specialDaysWindow = 60
+
+// BusinessCalendarService resuelve el horario de atención a partir de la base de datos.
+// Los días sin horario configurado usan APP_BUSINESS_HOURS_START/END.
+type BusinessCalendarService struct {
+	calendarRepo repositories.BusinessCalendarRepository
+	config       *config.Config
+
+	mu       sync.RWMutex
+	cached   *models.BusinessCalendar
+	cachedAt time.Time
+}
+
+// NewBusinessCalendarService crea un nuevo servicio de calendario
+func NewBusinessCalendarService(calendarRepo repositories.BusinessCalendarRepository, cfg *config.Config) *BusinessCalendarService {
+	return &BusinessCalendarService{
+		calendarRepo: calendarRepo,
+		config:       cfg,
+	}
+}
+
+// Calendar devuelve el calendario vigente; si la base de datos falla usa el horario por defecto
+func (s *BusinessCalendarService) Calendar() *models.BusinessCalendar {
+	s.mu.RLock()
+	if s.cached != nil && time.Since(s.cachedAt) < businessCalendarCacheTTL {
+		calendar := s.cached
+		s.mu.RUnlock()
+		return calendar
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cached != nil && time.Since(s.cachedAt) < businessCalendarCacheTTL {
+		return s.cached
+	}
+
+	calendar, err := s.load()
+	if err != nil {
+		log.Printf("Error al cargar el calendario de atención, usando horario por defecto: %v", err)
+		calendar = s.defaultCalendar(nil, nil)
+	}
+	s.cached = calendar
+	s.cachedAt = time.Now()
+	return calendar
+}
+
+// IsOpenAt verifica si el negocio atiende en el instante t
+func (s *BusinessCalendarService) IsOpenAt(t time.Time) bool {
+	return s.Calendar().IsOpenAt(t)
+}
+
+// GetStoreHours devuelve el horario público: estado actual, hoy y los próximos 7 días
+func (s *BusinessCalendarService) GetStoreHours() *models.StoreHours {
+	calendar := s.Calendar()
+	now := time.Now()
+
+	hours := &models.StoreHours{
+		TimeZone:  calendar.Location.String(),
+		IsOpenNow: calendar.IsOpenAt(now),
+		Today:     calendar.ScheduleFor(now),
+		Week:      calendar.Week(now),
+	}
+	if !hours.IsOpenNow {
+		hours.NextOpening = calendar.NextOpening(now)
+	}
+	return hours
+}
+
+// GetWeeklyHours devuelve el horario de los 7 días de la semana, incluidos los que usan el horario por defecto
+func (s *BusinessCalendarService) GetWeeklyHours() ([]models.BusinessHours, error) {
+	weekly, err := s.calendarRepo.GetWeeklyHours()
+	if err != nil {
+		return nil, err
+	}
+	calendar := s.defaultCalendar(weekly, nil)
+	return calendar.Weekly[:], nil
+}
+
+// UpdateWeeklyHours reemplaza el horario de los días de la semana indicados
+func (s *BusinessCalendarService) UpdateWeeklyHours(hours []models.BusinessHours) ([]models.BusinessHours, error) {
+	if len(hours) == 0 {
+		return nil, fmt.Errorf("%w: indique al menos un día", ErrInvalidBusinessHours)
+	}
+
+	seen := make(map[int]bool, len(hours))
+	for i := range hours {
+		if err := hours[i].Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBusinessHours, err)
+		}
+		if seen[hours[i].Weekday] {
+			return nil, fmt.Errorf("%w: el día %d está repetido", ErrInvalidBusinessHours, hours[i].Weekday)
+		}
+		seen[hours[i].Weekday] = true
+	}
+
+	if err := s.calendarRepo.SaveWeeklyHours(hours); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+
+	return s.GetWeeklyHours()
+}
+
+// ListSpecialDays lista los días especiales entre dos fechas (por defecto, los próximos 60 días)
+func (s *BusinessCalendarService) ListSpecialDays(fromStr, toStr string) ([]models.SpecialDay, error) {
+	loc := s.location()
+	now := time.Now().In(loc)
+
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	to := from.AddDate(0, 0, specialDaysWindow)
+	var err error
+	if fromStr != "" {
+		if from, err = time.ParseInLocation(models.BusinessDateLayout, fromStr, loc); err != nil {
+			return nil, ErrInvalidBusinessDate
+		}
+	}
+	if toStr != "" {
+		if to, err = time.ParseInLocation(models.BusinessDateLayout, toStr, loc); err != nil {
+			return nil, ErrInvalidBusinessDate
+		}
+	}
+	if to.Before(from) {
+		return nil, ErrInvalidBusinessDate
+	}
+
+	days, err := s.calendarRepo.FindSpecialDays(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if days == nil {
+		days = []models.SpecialDay{}
+	}
+	return days, nil
+}
+
+// CreateSpecialDay registra un feriado, cierre u horario especial
+func (s *BusinessCalendarService) CreateSpecialDay(req *models.SpecialDayRequest) (*models.SpecialDay, error) {
+	day, err := req.ToSpecialDay()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSpecialDay, err)
+	}
+	if err := s.ensureDateAvailable(day.Date, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	if err := s.calendarRepo.CreateSpecialDay(day); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return day, nil
+}
+
+// UpdateSpecialDay modifica un día especial existente
+func (s *BusinessCalendarService) UpdateSpecialDay(specialDayID string, req *models.SpecialDayRequest) (*models.SpecialDay, error) {
+	existing, err := s.findSpecialDay(specialDayID)
+	if err != nil {
+		return nil, err
+	}
+
+	day, err := req.ToSpecialDay()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSpecialDay, err)
+	}
+	if err := s.ensureDateAvailable(day.Date, existing.SpecialDayID); err != nil {
+		return nil, err
+	}
+
+	day.SpecialDayID = existing.SpecialDayID
+	day.CreatedAt = existing.CreatedAt
+	if err := s.calendarRepo.UpdateSpecialDay(day); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return s.calendarRepo.FindSpecialDayByID(specialDayID)
+}
+
+// DeleteSpecialDay elimina un día especial; la fecha vuelve al horario semanal
+func (s *BusinessCalendarService) DeleteSpecialDay(specialDayID string) error {
+	day, err := s.findSpecialDay(specialDayID)
+	if err != nil {
+		return err
+	}
+	if err := s.calendarRepo.DeleteSpecialDay(day); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// load lee de la base de datos el horario semanal y los días especiales cercanos
+func (s *BusinessCalendarService) load() (*models.BusinessCalendar, error) {
+	weekly, err := s.calendarRepo.GetWeeklyHours()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(s.location())
+	specialDays, err := s.calendarRepo.FindSpecialDays(now.AddDate(0, 0, -1), now.AddDate(0, 0, specialDaysWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.defaultCalendar(weekly, specialDays), nil
+}
+
+// defaultCalendar completa con el horario de las variables de entorno los días sin configurar
+func (s *BusinessCalendarService) defaultCalendar(weekly []models.BusinessHours, specialDays []models.SpecialDay) *models.BusinessCalendar {
+	return models.NewBusinessCalendar(
+		weekly,
+		specialDays,
+		s.config.App.BusinessHoursStart,
+		s.config.App.BusinessHoursEnd,
+		s.config.App.TimeZone,
+	)
+}
+
+// invalidate descarta el calendario en memoria tras una edición
+func (s *BusinessCalendarService) invalidate() {
+	s.mu.Lock()
+	s.cached = nil
+	s.mu.Unlock()
+}
+
+func (s *BusinessCalendarService) location() *time.Location {
+	loc, err := time.LoadLocation(s.config.App.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func (s *BusinessCalendarService) findSpecialDay(specialDayID string) (*models.SpecialDay, error) {
+	if _, err := uuid.Parse(specialDayID); err != nil {
+		return nil, ErrSpecialDayNotFound
+	}
+	day, err := s.calendarRepo.FindSpecialDayByID(specialDayID)
+	if err != nil {
+		return nil, ErrSpecialDayNotFound
+	}
+	return day, nil
+}
+
+// ensureDateAvailable evita dos días especiales para la misma fecha
+func (s *BusinessCalendarService) ensureDateAvailable(date time.Time, exceptID uuid.UUID) error {
+	days, err := s.calendarRepo.FindSpecialDays(date, date)
+	if err != nil {
+		return err
+	}
+	for _, d := range days {
+		if d.SpecialDayID != exceptID {
+			return ErrSpecialDayExists
+		}
+	}
+	return nil
+}
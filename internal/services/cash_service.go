@@ -0,0 +1,192 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"backend/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidBusinessDate = errors.New("fecha de jornada inválida")
+	ErrInvalidHandInAmount = errors.New("monto entregado inválido")
+	ErrNotARepartidor      = errors.New("el usuario no es un repartidor")
+)
+
+// CashService maneja la caja de efectivo de los repartidores y su liquidación diaria
+type CashService struct {
+	orderRepo      repositories.OrderRepository
+	userRepo       repositories.UserRepository
+	settlementRepo repositories.CashSettlementRepository
+	config         *config.Config
+}
+
+// NewCashService crea un nuevo servicio de caja
+func NewCashService(
+	orderRepo repositories.OrderRepository,
+	userRepo repositories.UserRepository,
+	settlementRepo repositories.CashSettlementRepository,
+	config *config.Config,
+) *CashService {
+	return &CashService{
+		orderRepo:      orderRepo,
+		userRepo:       userRepo,
+		settlementRepo: settlementRepo,
+		config:         config,
+	}
+}
+
+// GetRepartidorLedger obtiene la caja de un repartidor para una jornada (YYYY-MM-DD, vacío = hoy)
+func (s *CashService) GetRepartidorLedger(repartidorID string, date string) (*models.CashLedger, error) {
+	repartidor, err := s.findRepartidor(repartidorID)
+	if err != nil {
+		return nil, err
+	}
+
+	dayStart, dayEnd, err := models.BusinessDayBounds(date, s.config.App.TimeZone, time.Now())
+	if err != nil {
+		return nil, ErrInvalidBusinessDate
+	}
+
+	return s.buildLedger(repartidor, dayStart, dayEnd)
+}
+
+// RecordHandIn registra el efectivo entregado por un repartidor y devuelve la caja actualizada
+func (s *CashService) RecordHandIn(repartidorID string, date string, amount float64, notes string, adminID string) (*models.CashLedger, error) {
+	if amount < 0 {
+		return nil, ErrInvalidHandInAmount
+	}
+
+	repartidor, err := s.findRepartidor(repartidorID)
+	if err != nil {
+		return nil, err
+	}
+
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	dayStart, dayEnd, err := models.BusinessDayBounds(date, s.config.App.TimeZone, time.Now())
+	if err != nil {
+		return nil, ErrInvalidBusinessDate
+	}
+
+	// Calcular lo esperado antes de registrar para guardar una foto del momento
+	current, err := s.buildLedger(repartidor, dayStart, dayEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	settlement := &models.CashSettlement{
+		RepartidorID:   repartidor.UserID,
+		BusinessDate:   dayStart,
+		ExpectedAmount: current.ExpectedCash,
+		HandedInAmount: models.RoundCurrency(amount),
+		Notes:          notes,
+		RecordedBy:     adminUUID,
+	}
+	if err := s.settlementRepo.Create(settlement); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Admin %s registró entrega de S/ %.2f del repartidor %s (jornada %s)",
+		adminID, settlement.HandedInAmount, repartidorID, current.BusinessDate)
+
+	return s.buildLedger(repartidor, dayStart, dayEnd)
+}
+
+// GetDailySettlementReport genera la liquidación diaria de todos los repartidores con movimiento
+func (s *CashService) GetDailySettlementReport(date string) (*models.DailySettlementReport, error) {
+	dayStart, dayEnd, err := models.BusinessDayBounds(date, s.config.App.TimeZone, time.Now())
+	if err != nil {
+		return nil, ErrInvalidBusinessDate
+	}
+
+	repartidores, err := s.userRepo.FindByRole(models.UserRoleRepartidor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Una consulta por tabla para toda la jornada, agrupada luego por repartidor
+	orders, err := s.orderRepo.FindDeliveredBetween(dayStart, dayEnd, "")
+	if err != nil {
+		return nil, err
+	}
+	settlements, err := s.settlementRepo.FindByDate(dayStart)
+	if err != nil {
+		return nil, err
+	}
+
+	ordersByRepartidor := make(map[uuid.UUID][]*models.Order)
+	for _, order := range orders {
+		ordersByRepartidor[*order.AssignedRepartidorID] = append(ordersByRepartidor[*order.AssignedRepartidorID], order)
+	}
+	settlementsByRepartidor := make(map[uuid.UUID][]models.CashSettlement)
+	for _, settlement := range settlements {
+		settlementsByRepartidor[settlement.RepartidorID] = append(settlementsByRepartidor[settlement.RepartidorID], settlement)
+	}
+
+	report := &models.DailySettlementReport{
+		BusinessDate: dayStart.Format(models.BusinessDateLayout),
+		Ledgers:      []models.CashLedger{},
+	}
+
+	for _, repartidor := range repartidores {
+		ledger := models.BuildCashLedger(repartidor.UserID, dayStart, dayEnd,
+			ordersByRepartidor[repartidor.UserID], settlementsByRepartidor[repartidor.UserID])
+		ledger.RepartidorName = repartidor.FullName
+
+		// Omitir repartidores sin entregas en efectivo ni registros en la jornada
+		if ledger.OrderCount == 0 && len(ledger.Settlements) == 0 {
+			continue
+		}
+
+		report.Ledgers = append(report.Ledgers, *ledger)
+		report.TotalExpected += ledger.ExpectedCash
+		report.TotalHandedIn += ledger.HandedIn
+	}
+
+	report.TotalExpected = models.RoundCurrency(report.TotalExpected)
+	report.TotalHandedIn = models.RoundCurrency(report.TotalHandedIn)
+	report.TotalDiscrepancy = models.RoundCurrency(report.TotalHandedIn - report.TotalExpected)
+
+	return report, nil
+}
+
+// buildLedger arma la caja de un repartidor usando los pedidos que entregó en la jornada
+func (s *CashService) buildLedger(repartidor *models.User, dayStart, dayEnd time.Time) (*models.CashLedger, error) {
+	orders, err := s.orderRepo.FindDeliveredBetween(dayStart, dayEnd, repartidor.UserID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	settlements, err := s.settlementRepo.FindByRepartidorAndDate(repartidor.UserID.String(), dayStart)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := models.BuildCashLedger(repartidor.UserID, dayStart, dayEnd, orders, settlements)
+	ledger.RepartidorName = repartidor.FullName
+
+	return ledger, nil
+}
+
+// findRepartidor verifica que el usuario exista y pueda entregar pedidos
+func (s *CashService) findRepartidor(repartidorID string) (*models.User, error) {
+	user, err := s.userRepo.FindByID(repartidorID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if user.UserRole != models.UserRoleRepartidor && user.UserRole != models.UserRoleAdmin {
+		return nil, ErrNotARepartidor
+	}
+
+	return user, nil
+}
@@ -72,7 +72,7 @@ func (s *CategoryService) Update(category *models.Category) error {
 	return nil
 }
 
-// Delete elimina una categoría por su ID
+// Delete elimina una categoría (borrado lógico); puede restaurarse
 func (s *CategoryService) Delete(id string) error {
 	// Obtener la categoría antes de eliminarla para la notificación
 	category, err := s.repo.FindByID(id)
@@ -90,6 +90,37 @@ func (s *CategoryService) Delete(id string) error {
 	return nil
 }
 
+// ListDeleted obtiene las categorías eliminadas
+func (s *CategoryService) ListDeleted() ([]*models.Category, error) {
+	return s.repo.FindDeleted()
+}
+
+// Restore devuelve una categoría eliminada, si su nombre no lo usa otra categoría
+func (s *CategoryService) Restore(id string) (*models.Category, error) {
+	deleted, err := s.repo.FindDeletedByID(id)
+	if err != nil {
+		return nil, ErrCategoryNotFoundService
+	}
+	taken, err := s.repo.NameTaken(deleted.Name, deleted.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrCategoryNameExists
+	}
+
+	if err := s.repo.Restore(id); err != nil {
+		return nil, err
+	}
+	category, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyCategoryUpdate(category, "created")
+	return category, nil
+}
+
 // notifyCategoryUpdate envía notificaciones WebSocket sobre cambios en categorías
 func (s *CategoryService) notifyCategoryUpdate(category *models.Category, action string) {
 	if s.wsHub == nil {
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/ws"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrChatClosed         = errors.New("el chat del pedido está cerrado")
+	ErrChatAccessDenied   = errors.New("no participas en el chat de este pedido")
+	ErrInvalidChatMessage = errors.New("mensaje de chat inválido")
+)
+
+// ChatService maneja el chat por pedido entre cliente, repartidor asignado y administradores
+type ChatService struct {
+	messageRepo repositories.OrderMessageRepository
+	orderRepo   repositories.OrderRepository
+	wsHub       ws.HubInterface
+}
+
+// NewChatService crea un nuevo servicio de chat
+func NewChatService(
+	messageRepo repositories.OrderMessageRepository,
+	orderRepo repositories.OrderRepository,
+	wsHub ws.HubInterface,
+) *ChatService {
+	return &ChatService{
+		messageRepo: messageRepo,
+		orderRepo:   orderRepo,
+		wsHub:       wsHub,
+	}
+}
+
+// SendMessage guarda un mensaje y lo reenvía por WebSocket a los participantes
+func (s *ChatService) SendMessage(orderID string, senderID string, senderRole models.UserRole, content string) (*models.OrderMessage, error) {
+	content = strings.TrimSpace(content)
+	if content == "" || len(content) > models.MaxChatMessageLength {
+		return nil, ErrInvalidChatMessage
+	}
+
+	order, err := s.findOrderForParticipant(orderID, senderID, senderRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if !order.IsChatOpen() {
+		return nil, ErrChatClosed
+	}
+
+	senderUUID, err := uuid.Parse(senderID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	message := &models.OrderMessage{
+		OrderID:    order.OrderID,
+		SenderID:   senderUUID,
+		SenderRole: senderRole,
+		Content:    content,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.messageRepo.Create(message); err != nil {
+		return nil, err
+	}
+
+	s.notifyParticipants(order, ws.Message{
+		Type:    ws.ChatMessage,
+		Payload: ws.MustMarshalPayload(message),
+	})
+
+	return message, nil
+}
+
+// GetMessages obtiene el historial del chat de un pedido (disponible aunque el chat esté cerrado)
+func (s *ChatService) GetMessages(orderID string, userID string, userRole models.UserRole) ([]models.OrderMessage, error) {
+	if _, err := s.findOrderForParticipant(orderID, userID, userRole); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.messageRepo.FindByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if messages == nil {
+		messages = []models.OrderMessage{}
+	}
+	return messages, nil
+}
+
+// MarkAsRead marca como leídos los mensajes recibidos y avisa a los demás participantes. La
+// lectura de un administrador no marca nada: los mensajes siguen pendientes para su destinatario.
+func (s *ChatService) MarkAsRead(orderID string, userID string, userRole models.UserRole) (int64, error) {
+	order, err := s.findOrderForParticipant(orderID, userID, userRole)
+	if err != nil {
+		return 0, err
+	}
+	if !order.IsChatRecipient(userID, userRole) {
+		return 0, nil
+	}
+
+	readAt := time.Now()
+	count, err := s.messageRepo.MarkAsRead(orderID, userID, readAt)
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		s.notifyParticipants(order, ws.Message{
+			Type: ws.ChatRead,
+			Payload: ws.MustMarshalPayload(ws.ChatReadPayload{
+				OrderID:  orderID,
+				ReaderID: userID,
+				ReadAt:   readAt.Format(time.RFC3339),
+				Count:    count,
+			}),
+		})
+	}
+
+	return count, nil
+}
+
+// HandleWebSocketMessage procesa los mensajes de chat que llegan por el WebSocket
+func (s *ChatService) HandleWebSocketMessage(userID, role string, msg ws.Message) {
+	switch msg.Type {
+	case ws.ChatMessage:
+		var payload ws.ChatMessagePayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			s.sendChatError(userID, "", ErrInvalidChatMessage)
+			return
+		}
+		if _, err := s.SendMessage(payload.OrderID, userID, models.UserRole(role), payload.Content); err != nil {
+			s.sendChatError(userID, payload.OrderID, err)
+		}
+
+	case ws.ChatRead:
+		var payload ws.ChatReadPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			s.sendChatError(userID, "", ErrInvalidChatMessage)
+			return
+		}
+		if _, err := s.MarkAsRead(payload.OrderID, userID, models.UserRole(role)); err != nil {
+			s.sendChatError(userID, payload.OrderID, err)
+		}
+
+	default:
+		log.Printf("[Chat] Tipo de mensaje no soportado desde userID=%s: %s", userID, msg.Type)
+	}
+}
+
+// findOrderForParticipant obtiene el pedido verificando que el usuario participe del chat
+func (s *ChatService) findOrderForParticipant(orderID string, userID string, userRole models.UserRole) (*models.Order, error) {
+	if _, err := uuid.Parse(orderID); err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	if !order.IsChatParticipant(userID, userRole) {
+		return nil, ErrChatAccessDenied
+	}
+
+	return order, nil
+}
+
+// notifyParticipants envía un mensaje al cliente, al repartidor asignado y a los administradores
+func (s *ChatService) notifyParticipants(order *models.Order, msg ws.Message) {
+	if s.wsHub == nil {
+		return
+	}
+
+	s.wsHub.SendToUser(order.ClientID.String(), msg)
+
+	// Si el repartidor asignado es administrador ya recibe el mensaje por su rol
+	if order.AssignedRepartidorID != nil &&
+		(order.AssignedRepartidor == nil || order.AssignedRepartidor.UserRole != models.UserRoleAdmin) {
+		s.wsHub.SendToUser(order.AssignedRepartidorID.String(), msg)
+	}
+
+	s.wsHub.SendToRole("ADMIN", msg)
+}
+
+// sendChatError informa al remitente que su mensaje no se pudo procesar
+func (s *ChatService) sendChatError(userID string, orderID string, err error) {
+	if s.wsHub == nil {
+		return
+	}
+
+	switch err {
+	case ErrChatClosed, ErrChatAccessDenied, ErrInvalidChatMessage, ErrOrderNotFound:
+	default:
+		log.Printf("[Chat] Error al procesar mensaje de userID=%s: %v", userID, err)
+		err = errors.New("error al procesar el mensaje")
+	}
+
+	s.wsHub.SendToUser(userID, ws.Message{
+		Type: ws.ChatError,
+		Payload: ws.MustMarshalPayload(ws.ChatErrorPayload{
+			OrderID: orderID,
+			Error:   err.Error(),
+		}),
+	})
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"time"
+
+	"backend/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+// CylinderService maneja la conciliación de balones retornables (clientes y repartidores)
+type CylinderService struct {
+	cylinderRepo repositories.CylinderRepository
+	config       *config.Config
+}
+
+// NewCylinderService crea un nuevo servicio de balones
+func NewCylinderService(cylinderRepo repositories.CylinderRepository, config *config.Config) *CylinderService {
+	return &CylinderService{
+		cylinderRepo: cylinderRepo,
+		config:       config,
+	}
+}
+
+// GetHoldings obtiene los balones en poder de los clientes (clientID vacío = todos)
+func (s *CylinderService) GetHoldings(clientID string) ([]models.CylinderHolding, error) {
+	holdings, err := s.cylinderRepo.GetHoldings(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if holdings == nil {
+		holdings = []models.CylinderHolding{}
+	}
+	return holdings, nil
+}
+
+// GetDailyReport genera el movimiento de balones por repartidor de una jornada (YYYY-MM-DD, vacío = hoy)
+func (s *CylinderService) GetDailyReport(date string) (*models.CylinderDailyReport, error) {
+	dayStart, dayEnd, err := models.BusinessDayBounds(date, s.config.App.TimeZone, time.Now())
+	if err != nil {
+		return nil, ErrInvalidBusinessDate
+	}
+
+	collections, err := s.cylinderRepo.GetCollections(dayStart, dayEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.CylinderDailyReport{
+		BusinessDate: dayStart.Format(models.BusinessDateLayout),
+		Collections:  collections,
+	}
+	if report.Collections == nil {
+		report.Collections = []models.CylinderCollection{}
+	}
+
+	for _, c := range report.Collections {
+		report.TotalFullDelivered += c.FullDelivered
+		report.TotalEmptiesCollected += c.EmptiesCollected
+		report.TotalDepositsCharged += c.DepositsCharged
+	}
+	report.TotalDepositsCharged = models.RoundCurrency(report.TotalDepositsCharged)
+
+	return report, nil
+}
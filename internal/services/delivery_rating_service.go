@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sort"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDeliveryRatingNotFound = errors.New("calificación de entrega no encontrada")
+	ErrDeliveryRatingExists   = models.ErrDeliveryRatingExists
+	ErrOrderNotDelivered      = errors.New("el pedido aún no ha sido entregado")
+	ErrInvalidDeliveryRating  = errors.New("calificación de entrega inválida")
+	ErrOrderAccessDenied      = errors.New("no tienes permiso para ver este pedido")
+)
+
+// DeliveryRatingService maneja las calificaciones de la experiencia de entrega
+type DeliveryRatingService struct {
+	ratingRepo repositories.DeliveryRatingRepository
+	orderRepo  repositories.OrderRepository
+	userRepo   repositories.UserRepository
+}
+
+// NewDeliveryRatingService crea un nuevo servicio de calificaciones de entrega
+func NewDeliveryRatingService(
+	ratingRepo repositories.DeliveryRatingRepository,
+	orderRepo repositories.OrderRepository,
+	userRepo repositories.UserRepository,
+) *DeliveryRatingService {
+	return &DeliveryRatingService{
+		ratingRepo: ratingRepo,
+		orderRepo:  orderRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// Create registra la calificación de la entrega de un pedido (una sola vez por pedido)
+func (s *DeliveryRatingService) Create(orderID string, clientID string, req *models.CreateDeliveryRatingRequest) (*models.DeliveryRating, error) {
+	if err := req.Validate(); err != nil {
+		return nil, ErrInvalidDeliveryRating
+	}
+
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	if order.ClientID.String() != clientID {
+		return nil, ErrNotOrderOwner
+	}
+
+	if order.OrderStatus != models.OrderStatusDelivered || order.AssignedRepartidorID == nil {
+		return nil, ErrOrderNotDelivered
+	}
+
+	existing, err := s.ratingRepo.FindByOrderID(orderID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrDeliveryRatingExists
+	}
+
+	rating := &models.DeliveryRating{
+		OrderID:      order.OrderID,
+		RepartidorID: *order.AssignedRepartidorID,
+		ClientID:     order.ClientID,
+		Rating:       req.Rating,
+		Tags:         models.DeliveryTags(req.Tags),
+		Comment:      req.Comment,
+	}
+
+	if err := s.ratingRepo.Create(rating); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Cliente %s calificó con %d estrellas la entrega del pedido %s", clientID, rating.Rating, orderID)
+
+	return rating, nil
+}
+
+// GetByOrderID obtiene la calificación de entrega de un pedido según los permisos del usuario
+func (s *DeliveryRatingService) GetByOrderID(orderID string, userID string, userRole models.UserRole) (*models.DeliveryRating, error) {
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	switch userRole {
+	case models.UserRoleClient:
+		if order.ClientID.String() != userID {
+			return nil, ErrOrderAccessDenied
+		}
+	case models.UserRoleRepartidor:
+		if order.AssignedRepartidorID == nil || order.AssignedRepartidorID.String() != userID {
+			return nil, ErrOrderAccessDenied
+		}
+	}
+
+	rating, err := s.ratingRepo.FindByOrderID(orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeliveryRatingNotFound
+		}
+		return nil, err
+	}
+
+	return rating, nil
+}
+
+// GetRepartidorRatings obtiene el puntaje de todos los repartidores calificados, de mayor a menor
+func (s *DeliveryRatingService) GetRepartidorRatings() ([]models.RepartidorRatingSummary, error) {
+	summaries, err := s.ratingRepo.GetRepartidorSummaries("")
+	if err != nil {
+		return nil, err
+	}
+
+	globalAverage, err := s.ratingRepo.GetGlobalAverage()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range summaries {
+		summaries[i].Score = models.WeightedDeliveryScore(summaries[i].AverageRating, summaries[i].RatingCount, globalAverage)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Score > summaries[j].Score
+	})
+
+	if summaries == nil {
+		summaries = []models.RepartidorRatingSummary{}
+	}
+	return summaries, nil
+}
+
+// GetRepartidorRating obtiene el puntaje de un repartidor con el detalle de etiquetas
+func (s *DeliveryRatingService) GetRepartidorRating(repartidorID string) (*models.RepartidorRatingSummary, error) {
+	repartidor, err := s.userRepo.FindByID(repartidorID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	summaries, err := s.ratingRepo.GetRepartidorSummaries(repartidorID)
+	if err != nil {
+		return nil, err
+	}
+
+	globalAverage, err := s.ratingRepo.GetGlobalAverage()
+	if err != nil {
+		return nil, err
+	}
+
+	summary := models.RepartidorRatingSummary{
+		RepartidorID:   repartidor.UserID,
+		RepartidorName: repartidor.FullName,
+	}
+	if len(summaries) > 0 {
+		summary = summaries[0]
+	}
+	summary.Score = models.WeightedDeliveryScore(summary.AverageRating, summary.RatingCount, globalAverage)
+
+	summary.TagCounts, err = s.ratingRepo.GetTagCounts(repartidorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+// RepartidorScores obtiene el puntaje ponderado de entrega de cada repartidor calificado y el
+// puntaje que corresponde a quien aún no tiene calificaciones
+func (s *DeliveryRatingService) RepartidorScores() (map[uuid.UUID]float64, float64, error) {
+	summaries, err := s.ratingRepo.GetRepartidorSummaries("")
+	if err != nil {
+		return nil, 0, err
+	}
+	globalAverage, err := s.ratingRepo.GetGlobalAverage()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	scores := make(map[uuid.UUID]float64, len(summaries))
+	for _, summary := range summaries {
+		scores[summary.RepartidorID] = models.WeightedDeliveryScore(summary.AverageRating, summary.RatingCount, globalAverage)
+	}
+	return scores, models.WeightedDeliveryScore(0, 0, globalAverage), nil
+}
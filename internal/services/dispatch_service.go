@@ -0,0 +1,85 @@
+package services
+
+import (
+	"errors"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+var ErrNoRepartidorAvailable = errors.New("no hay repartidores disponibles para el pedido")
+
+// DispatchService elige a qué repartidor despachar un pedido. El puntaje de entrega es solo uno de
+// los criterios: la carga y la sucursal se deciden aquí y no en el servicio de calificaciones
+type DispatchService struct {
+	orderRepo     repositories.OrderRepository
+	userRepo      repositories.UserRepository
+	ratingService *DeliveryRatingService
+}
+
+// NewDispatchService crea un nuevo servicio de despacho
+func NewDispatchService(
+	orderRepo repositories.OrderRepository,
+	userRepo repositories.UserRepository,
+	ratingService *DeliveryRatingService,
+) *DispatchService {
+	return &DispatchService{
+		orderRepo:     orderRepo,
+		userRepo:      userRepo,
+		ratingService: ratingService,
+	}
+}
+
+// SuggestRepartidores ordena los repartidores activos que pueden recibir el pedido (los de su
+// sucursal y los que no pertenecen a ninguna): menos pedidos en curso primero y, a igual carga,
+// mejor puntaje de entrega
+func (s *DispatchService) SuggestRepartidores(orderID string) ([]models.DispatchCandidate, error) {
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	repartidores, err := s.userRepo.FindByRole(models.UserRoleRepartidor)
+	if err != nil {
+		return nil, err
+	}
+	activeOrders, err := s.orderRepo.CountActiveByRepartidor()
+	if err != nil {
+		return nil, err
+	}
+
+	// Sin servicio de calificaciones todos empatan en puntaje y decide la carga
+	var scores map[uuid.UUID]float64
+	var unratedScore float64
+	if s.ratingService != nil {
+		scores, unratedScore, err = s.ratingService.RepartidorScores()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	candidates := []models.DispatchCandidate{}
+	for _, repartidor := range repartidores {
+		if !repartidor.IsActive {
+			continue
+		}
+		if order.BranchID != nil && repartidor.BranchID != nil && *order.BranchID != *repartidor.BranchID {
+			continue
+		}
+		score, rated := scores[repartidor.UserID]
+		if !rated {
+			score = unratedScore
+		}
+		candidates = append(candidates, models.DispatchCandidate{
+			RepartidorID:   repartidor.UserID,
+			RepartidorName: repartidor.FullName,
+			BranchID:       repartidor.BranchID,
+			ActiveOrders:   activeOrders[repartidor.UserID],
+			Score:          score,
+		})
+	}
+
+	return models.RankDispatchCandidates(candidates), nil
+}
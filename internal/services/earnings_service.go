@@ -0,0 +1,83 @@
+package services
+
+import (
+	"time"
+
+	"backend/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+// EarningsService calcula las propinas y entregas de los repartidores
+type EarningsService struct {
+	orderRepo repositories.OrderRepository
+	userRepo  repositories.UserRepository
+	config    *config.Config
+}
+
+// NewEarningsService crea un nuevo servicio de ganancias
+func NewEarningsService(orderRepo repositories.OrderRepository, userRepo repositories.UserRepository, config *config.Config) *EarningsService {
+	return &EarningsService{
+		orderRepo: orderRepo,
+		userRepo:  userRepo,
+		config:    config,
+	}
+}
+
+// GetRepartidorEarnings obtiene las entregas y propinas de un repartidor entre dos jornadas (YYYY-MM-DD)
+func (s *EarningsService) GetRepartidorEarnings(repartidorID string, from, to string) (*models.RepartidorEarnings, error) {
+	repartidor, err := s.userRepo.FindByID(repartidorID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	start, end, err := models.BusinessDateRange(from, to, s.config.App.TimeZone, time.Now())
+	if err != nil {
+		return nil, ErrInvalidBusinessDate
+	}
+
+	earnings, err := s.orderRepo.GetRepartidorEarnings(start, end, repartidorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sin entregas en el período: devolver el resumen en cero
+	if len(earnings) == 0 {
+		return &models.RepartidorEarnings{
+			RepartidorID:   repartidor.UserID,
+			RepartidorName: repartidor.FullName,
+		}, nil
+	}
+
+	return &earnings[0], nil
+}
+
+// GetTipsReport genera el reporte de propinas por repartidor entre dos jornadas (YYYY-MM-DD)
+func (s *EarningsService) GetTipsReport(from, to string) (*models.TipsReport, error) {
+	start, end, err := models.BusinessDateRange(from, to, s.config.App.TimeZone, time.Now())
+	if err != nil {
+		return nil, ErrInvalidBusinessDate
+	}
+
+	earnings, err := s.orderRepo.GetRepartidorEarnings(start, end, "")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.TipsReport{
+		From:         start.Format(models.BusinessDateLayout),
+		To:           end.AddDate(0, 0, -1).Format(models.BusinessDateLayout),
+		Repartidores: earnings,
+	}
+	if report.Repartidores == nil {
+		report.Repartidores = []models.RepartidorEarnings{}
+	}
+
+	for _, e := range report.Repartidores {
+		report.TotalTips += e.TipsTotal
+		report.TotalTippedOrders += e.TippedOrders
+	}
+	report.TotalTips = models.RoundCurrency(report.TotalTips)
+
+	return report, nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"backend/internal/models"
 	"backend/internal/repositories"
@@ -174,6 +175,24 @@ func (s *FavoriteService) GetUserFavorites(userID uuid.UUID, page, limit int) (*
 	return response, nil
 }
 
+// GetUserFavoritesWithCursor obtiene los favoritos del usuario paginados por cursor
+func (s *FavoriteService) GetUserFavoritesWithCursor(userID uuid.UUID, cursorValue string, limit int) (*models.CursorPage[models.FavoriteResponse], error) {
+	cursor, err := models.DecodeCursor(cursorValue)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	limit = models.NormalizeCursorLimit(limit)
+
+	favorites, err := s.favoriteRepo.GetUserFavoritesWithCursor(userID, cursor, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener favoritos: %v", err)
+	}
+
+	return models.NewCursorPage(favorites, limit, func(f models.FavoriteResponse) (time.Time, string) {
+		return f.AddedAt, f.ProductID.String()
+	}), nil
+}
+
 // BulkCheckFavorites verifica el estado de favorito para múltiples productos
 func (s *FavoriteService) BulkCheckFavorites(userID uuid.UUID, productIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
 	return s.favoriteRepo.BulkCheckFavorites(userID, productIDs)
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"backend/config"
+	"backend/internal/forecast"
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidForecastHorizon = errors.New("horizonte de pronóstico inválido")
+
+// ForecastService genera pronósticos de demanda y sugerencias de stock para los productos activos
+type ForecastService struct {
+	forecastRepo repositories.ForecastRepository
+	productRepo  repositories.ProductRepository
+	config       *config.Config
+}
+
+// NewForecastService crea un nuevo servicio de pronósticos
+func NewForecastService(forecastRepo repositories.ForecastRepository, productRepo repositories.ProductRepository, config *config.Config) *ForecastService {
+	return &ForecastService{
+		forecastRepo: forecastRepo,
+		productRepo:  productRepo,
+		config:       config,
+	}
+}
+
+// fittedProduct es un producto activo con su modelo de demanda ajustado
+type fittedProduct struct {
+	product *models.Product
+	model   *forecast.Model
+}
+
+// forecastWindow son las jornadas de historial y de pronóstico
+type forecastWindow struct {
+	historyStart time.Time
+	historyEnd   time.Time // También es el inicio del pronóstico (hoy)
+	horizon      []time.Time
+}
+
+// GetDemandForecast pronostica la demanda diaria de las próximas days jornadas (productID vacío = todos).
+// includeHours agrega el detalle por hora de cada producto; los totales siempre lo incluyen.
+func (s *ForecastService) GetDemandForecast(productID string, days int, includeHours bool) (*models.DemandForecastReport, error) {
+	window, err := s.window(days)
+	if err != nil {
+		return nil, err
+	}
+
+	fitted, err := s.fitProducts(window, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.DemandForecastReport{
+		From:        window.horizon[0].Format(models.BusinessDateLayout),
+		To:          window.horizon[len(window.horizon)-1].Format(models.BusinessDateLayout),
+		HistoryFrom: window.historyStart.Format(models.BusinessDateLayout),
+		HistoryTo:   window.historyEnd.AddDate(0, 0, -1).Format(models.BusinessDateLayout),
+		Products:    make([]models.ProductDemandForecast, 0, len(fitted)),
+		Totals:      make([]models.DailyForecast, len(window.horizon)),
+	}
+
+	totalHours := make([][24]float64, len(window.horizon))
+	for _, fp := range fitted {
+		productForecast := models.ProductDemandForecast{
+			ProductID:   fp.product.ProductID,
+			ProductName: fp.product.Name,
+			Days:        make([]models.DailyForecast, 0, len(window.horizon)),
+		}
+
+		for i, date := range window.horizon {
+			units := fp.model.PredictDay(date)
+			hours := fp.model.PredictHours(date)
+
+			day := models.DailyForecast{
+				Date:  date.Format(models.BusinessDateLayout),
+				Units: models.RoundCurrency(units),
+			}
+			if includeHours {
+				day.Hourly = roundHours(hours)
+			}
+			productForecast.Days = append(productForecast.Days, day)
+			productForecast.TotalUnits += units
+
+			report.Totals[i].Units += units
+			for h := range hours {
+				totalHours[i][h] += hours[h]
+			}
+		}
+
+		productForecast.TotalUnits = models.RoundCurrency(productForecast.TotalUnits)
+		report.Products = append(report.Products, productForecast)
+	}
+
+	for i, date := range window.horizon {
+		report.Totals[i].Date = date.Format(models.BusinessDateLayout)
+		report.Totals[i].Units = models.RoundCurrency(report.Totals[i].Units)
+		report.Totals[i].Hourly = roundHours(totalHours[i])
+	}
+
+	return report, nil
+}
+
+// GetStockSuggestions sugiere el stock necesario para cubrir las próximas days jornadas con stock de seguridad
+func (s *ForecastService) GetStockSuggestions(days int) (*models.StockSuggestionReport, error) {
+	window, err := s.window(days)
+	if err != nil {
+		return nil, err
+	}
+
+	fitted, err := s.fitProducts(window, "")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.StockSuggestionReport{
+		From:        window.horizon[0].Format(models.BusinessDateLayout),
+		To:          window.horizon[len(window.horizon)-1].Format(models.BusinessDateLayout),
+		Suggestions: make([]models.StockSuggestion, 0, len(fitted)),
+	}
+
+	for _, fp := range fitted {
+		var forecastUnits float64
+		for _, date := range window.horizon {
+			forecastUnits += fp.model.PredictDay(date)
+		}
+		safetyStock := fp.model.SafetyStock(len(window.horizon), models.ForecastServiceLevelZ)
+		suggested := int(math.Ceil(forecastUnits + safetyStock))
+
+		reorder := suggested - fp.product.StockQuantity
+		if reorder < 0 {
+			reorder = 0
+		}
+
+		report.Suggestions = append(report.Suggestions, models.StockSuggestion{
+			ProductID:       fp.product.ProductID,
+			ProductName:     fp.product.Name,
+			CurrentStock:    fp.product.StockQuantity,
+			ForecastUnits:   models.RoundCurrency(forecastUnits),
+			SafetyStock:     models.RoundCurrency(safetyStock),
+			SuggestedStock:  suggested,
+			ReorderQuantity: reorder,
+		})
+	}
+
+	return report, nil
+}
+
+// window calcula el historial (últimas semanas completas hasta ayer) y las jornadas a pronosticar desde hoy
+func (s *ForecastService) window(days int) (*forecastWindow, error) {
+	if days == 0 {
+		days = models.ForecastDefaultHorizonDays
+	}
+	if days < 1 || days > models.ForecastMaxHorizonDays {
+		return nil, ErrInvalidForecastHorizon
+	}
+
+	today, _, err := models.BusinessDayBounds("", s.config.App.TimeZone, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	window := &forecastWindow{
+		historyStart: today.AddDate(0, 0, -models.ForecastHistoryDays),
+		historyEnd:   today,
+	}
+	for i := 0; i < days; i++ {
+		window.horizon = append(window.horizon, today.AddDate(0, 0, i))
+	}
+	return window, nil
+}
+
+// fitProducts ajusta un modelo por producto activo con el historial de la ventana
+func (s *ForecastService) fitProducts(window *forecastWindow, productID string) ([]fittedProduct, error) {
+	products, err := s.productRepo.FindActive()
+	if err != nil {
+		return nil, err
+	}
+
+	if productID != "" {
+		var selected []*models.Product
+		for _, p := range products {
+			if p.ProductID.String() == productID {
+				selected = append(selected, p)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, ErrProductNotFound
+		}
+		products = selected
+	}
+
+	history, err := s.forecastRepo.GetDemandHistory(window.historyStart, window.historyEnd, s.config.App.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := window.historyStart.Location()
+	observations := make(map[uuid.UUID][]forecast.Observation)
+	for _, h := range history {
+		date, err := time.ParseInLocation(models.BusinessDateLayout, h.Date, loc)
+		if err != nil {
+			continue
+		}
+		observations[h.ProductID] = append(observations[h.ProductID], forecast.Observation{
+			Date:  date,
+			Hour:  h.Hour,
+			Units: h.Units,
+		})
+	}
+
+	fitted := make([]fittedProduct, 0, len(products))
+	for _, p := range products {
+		fitted = append(fitted, fittedProduct{
+			product: p,
+			model:   forecast.Fit(observations[p.ProductID], window.historyStart, window.historyEnd, forecast.DefaultConfig),
+		})
+	}
+	return fitted, nil
+}
+
+func roundHours(hours [24]float64) []float64 {
+	rounded := make([]float64, len(hours))
+	for h, units := range hours {
+		rounded[h] = models.RoundCurrency(units)
+	}
+	return rounded
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/ws"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidStockMovement = errors.New("movimiento de stock inválido")
+	ErrStockBelowZero       = models.ErrStockBelowZero
+)
+
+// InventoryService maneja el libro de movimientos de stock y los avisos de stock bajo
+type InventoryService struct {
+	movementRepo repositories.StockMovementRepository
+	productRepo  repositories.ProductRepository
+	wsHub        ws.HubInterface
+}
+
+// NewInventoryService crea un nuevo servicio de inventario
+func NewInventoryService(movementRepo repositories.StockMovementRepository, productRepo repositories.ProductRepository, wsHub ws.HubInterface) *InventoryService {
+	return &InventoryService{
+		movementRepo: movementRepo,
+		productRepo:  productRepo,
+		wsHub:        wsHub,
+	}
+}
+
+// RecordMovement registra un ingreso por compra, un ajuste o una baja por daño y actualiza el stock
+// de la sucursal indicada, del producto y de la variante
+func (s *InventoryService) RecordMovement(productID, userID string, req *models.CreateStockMovementRequest) (*models.StockMovement, error) {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, ErrProductNotFoundService
+	}
+
+	if product.IsBundle {
+		return nil, fmt.Errorf("%w: el stock de un combo se calcula a partir de sus componentes", ErrInvalidStockMovement)
+	}
+
+	delta, err := req.Delta()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStockMovement, err)
+	}
+
+	if req.VariantID != nil {
+		variant, err := s.productRepo.FindVariantByID(req.VariantID.String())
+		if err != nil || variant.ProductID != product.ProductID {
+			return nil, ErrVariantNotFound
+		}
+	}
+	if req.BranchID != nil {
+		exists, err := s.productRepo.BranchExists(*req.BranchID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrBranchNotFound
+		}
+	}
+
+	movement := &models.StockMovement{
+		ProductID:    product.ProductID,
+		VariantID:    req.VariantID,
+		BranchID:     req.BranchID,
+		MovementType: req.MovementType,
+		Quantity:     delta,
+		Reason:       req.Reason,
+	}
+	if createdBy, err := uuid.Parse(userID); err == nil {
+		movement.CreatedBy = &createdBy
+	}
+
+	if err := s.movementRepo.Record(movement); err != nil {
+		return nil, err
+	}
+
+	s.CheckLowStock(product.ProductID)
+	return movement, nil
+}
+
+// ListMovements obtiene el libro de movimientos de un producto, del más reciente al más antiguo
+func (s *InventoryService) ListMovements(productID, cursorValue string, limit int) (*models.CursorPage[models.StockMovement], error) {
+	if _, err := s.productRepo.FindByID(productID); err != nil {
+		return nil, ErrProductNotFoundService
+	}
+
+	cursor, err := models.DecodeCursor(cursorValue)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	limit = models.NormalizeCursorLimit(limit)
+
+	movements, err := s.movementRepo.FindByProduct(productID, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewCursorPage(movements, limit, func(m models.StockMovement) (time.Time, string) {
+		return m.CreatedAt, m.MovementID.String()
+	}), nil
+}
+
+// GetLowStock obtiene los productos con stock por debajo de su umbral de reposición
+func (s *InventoryService) GetLowStock() ([]models.LowStockProduct, error) {
+	products, err := s.movementRepo.FindLowStock()
+	if err != nil {
+		return nil, err
+	}
+	if products == nil {
+		products = []models.LowStockProduct{}
+	}
+	return products, nil
+}
+
+// GetReconciliation obtiene los productos cuyo stock no coincide con su libro de movimientos
+func (s *InventoryService) GetReconciliation() ([]models.StockReconciliation, error) {
+	rows, err := s.movementRepo.FindReconciliation()
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []models.StockReconciliation{}
+	}
+	return rows, nil
+}
+
+// Reconcile registra un ajuste por la diferencia entre el stock y el libro. Devuelve nil si el
+// producto ya estaba conciliado.
+func (s *InventoryService) Reconcile(productID, userID string) (*models.StockMovement, error) {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, ErrProductNotFoundService
+	}
+
+	var createdBy *uuid.UUID
+	if id, err := uuid.Parse(userID); err == nil {
+		createdBy = &id
+	}
+	return s.movementRepo.Reconcile(product.ProductID, createdBy)
+}
+
+// CheckOrderStock revisa el stock de los productos de un pedido confirmado (los combos, por sus componentes)
+func (s *InventoryService) CheckOrderStock(order *models.Order) {
+	demand := models.OrderStockDemand(order.OrderItems)
+	productIDs := make([]uuid.UUID, 0, len(demand))
+	for productID := range demand {
+		productIDs = append(productIDs, productID)
+	}
+	s.CheckLowStock(productIDs...)
+}
+
+// CheckLowStock avisa a los administradores de los productos que quedaron por debajo de su umbral.
+// Cada producto se avisa una sola vez hasta que se reponga. Los errores solo se registran: el
+// aviso nunca hace fallar la operación que cambió el stock.
+func (s *InventoryService) CheckLowStock(productIDs ...uuid.UUID) {
+	products, err := s.movementRepo.ClaimLowStockAlerts(productIDs)
+	if err != nil {
+		log.Printf("Error al revisar stock bajo: %v", err)
+		return
+	}
+	if s.wsHub == nil {
+		return
+	}
+
+	for _, product := range products {
+		msg := ws.Message{
+			Type: ws.LowStockAlert,
+			Payload: ws.MustMarshalPayload(ws.LowStockAlertPayload{
+				ProductID:        product.ProductID.String(),
+				Name:             product.Name,
+				StockQuantity:    product.StockQuantity,
+				ReorderThreshold: product.ReorderThreshold,
+			}),
+		}
+		log.Printf("[WebSocket] Stock bajo: %s (%d de %d)", product.Name, product.StockQuantity, product.ReorderThreshold)
+		s.wsHub.SendToRole("ADMIN", msg)
+	}
+}
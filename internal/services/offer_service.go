@@ -61,8 +61,16 @@ func (s *offerService) CreateOffer(userID string, offer *models.ProductOffer) er
 		return errors.New("no se pueden crear ofertas para productos inactivos")
 	}
 
-	// Desactivar cualquier oferta existente para este producto
-	if err := s.offerRepo.DeactivateByProductID(offer.ProductID.String()); err != nil {
+	// Desactivar la oferta existente del mismo alcance: la variante indicada o el producto completo
+	if offer.VariantID != nil {
+		variant, err := s.productRepo.FindVariantByID(offer.VariantID.String())
+		if err != nil || variant.ProductID != offer.ProductID {
+			return errors.New("la variante no pertenece al producto")
+		}
+		if err := s.offerRepo.DeactivateByVariantID(offer.VariantID.String()); err != nil {
+			return errors.New("error al desactivar ofertas existentes")
+		}
+	} else if err := s.offerRepo.DeactivateByProductID(offer.ProductID.String()); err != nil {
 		return errors.New("error al desactivar ofertas existentes")
 	}
 
@@ -3,14 +3,19 @@ package services
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"math"
+	"slices"
 	"time"
 
 	"backend/config"
+	"backend/internal/export"
 	"backend/internal/models"
 	"backend/internal/repositories"
 	"backend/internal/ws"
+
+	"github.com/google/uuid"
 )
 
 var (
@@ -19,11 +24,18 @@ var (
 	ErrOutsideBusinessHours = errors.New("fuera del horario de atención")
 	ErrInvalidTransition    = errors.New("transición de estado inválida")
 	ErrInvalidUnitPrice     = errors.New("precio unitario inválido")
-	ErrOrderAlreadyAssigned = errors.New("pedido ya asignado")
+	ErrOrderAlreadyAssigned = models.ErrOrderAlreadyAssigned
 	ErrUserNotFound         = errors.New("usuario no encontrado")
 	ErrInvalidRole          = errors.New("rol de usuario inválido")
 	ErrProductNotFound      = errors.New("producto no encontrado")
 	ErrProductInactive      = errors.New("producto no está activo")
+	ErrInvalidTipAmount     = errors.New("monto de propina inválido")
+	ErrTipNotAllowed        = errors.New("ya no se puede dejar propina en este pedido")
+	ErrNotOrderOwner        = errors.New("el pedido no pertenece al cliente")
+	ErrInsufficientStock    = errors.New("stock insuficiente para la variante")
+
+	ErrInvalidCylindersReturned = models.ErrInvalidCylindersReturned
+	ErrInvalidCursor            = models.ErrInvalidCursor
 )
 
 // PaginatedOrdersResponse estructura para respuestas paginadas de órdenes
@@ -42,6 +54,10 @@ type OrderService struct {
 	notificationService *NotificationService
 	config              *config.Config
 	wsHub               ws.HubInterface
+	businessCalendar    *BusinessCalendarService
+	branchService       *BranchService
+	inventoryService    *InventoryService
+	dispatchService     *DispatchService
 }
 
 func NewOrderService(
@@ -62,6 +78,94 @@ func NewOrderService(
 	}
 }
 
+// SetBusinessCalendar hace que el horario de atención se lea del calendario administrable
+// en lugar de APP_BUSINESS_HOURS_START/END
+func (s *OrderService) SetBusinessCalendar(calendar *BusinessCalendarService) {
+	s.businessCalendar = calendar
+}
+
+// SetBranchService activa el enrutamiento de pedidos a la sucursal más cercana con stock
+func (s *OrderService) SetBranchService(branchService *BranchService) {
+	s.branchService = branchService
+}
+
+// SetInventoryService activa los avisos de stock bajo al confirmar pedidos
+func (s *OrderService) SetInventoryService(inventoryService *InventoryService) {
+	s.inventoryService = inventoryService
+}
+
+// SetDispatchService hace que la asignación sin repartidor elija al mejor candidato según su carga
+// y su puntaje de entrega
+func (s *OrderService) SetDispatchService(dispatchService *DispatchService) {
+	s.dispatchService = dispatchService
+}
+
+// SuggestRepartidores obtiene los repartidores a los que se puede despachar el pedido, del mejor al
+// peor candidato. Sin servicio de despacho no hay sugerencias
+func (s *OrderService) SuggestRepartidores(orderID string) ([]models.DispatchCandidate, error) {
+	if s.dispatchService == nil {
+		if _, err := s.orderRepo.FindByID(orderID); err != nil {
+			return nil, ErrOrderNotFound
+		}
+		return []models.DispatchCandidate{}, nil
+	}
+	return s.dispatchService.SuggestRepartidores(orderID)
+}
+
+// resolveOrderVariant obtiene la variante indicada en el ítem, que debe pertenecer al producto y estar
+// activa, o la predeterminada del producto. Devuelve nil para productos sin variantes registradas
+func (s *OrderService) resolveOrderVariant(product *models.Product, variantID *uuid.UUID) (*models.ProductVariant, error) {
+	if variantID == nil {
+		variant, err := s.productRepo.FindDefaultVariant(product.ProductID.String())
+		if err != nil {
+			return nil, nil // Producto sin variantes: se vende con su precio y stock propios
+		}
+		return variant, nil
+	}
+
+	variant, err := s.productRepo.FindVariantByID(variantID.String())
+	if err != nil || variant.ProductID != product.ProductID || !variant.IsActive {
+		return nil, ErrVariantNotFound
+	}
+	return variant, nil
+}
+
+// bundleBreakdown desglosa la cantidad pedida de un combo en las unidades de cada componente, con la
+// variante predeterminada del componente, y verifica que haya stock de todos
+func (s *OrderService) bundleBreakdown(bundle *models.Product, quantity int) ([]models.OrderItemComponent, error) {
+	components := make([]models.OrderItemComponent, 0, len(bundle.BundleItems))
+	for _, item := range bundle.BundleItems {
+		if item.Component == nil || !item.Component.IsActive {
+			log.Printf("[ERROR] Componente inactivo en el combo %s: %s", bundle.ProductID, item.ComponentID)
+			return nil, ErrProductInactive
+		}
+
+		line := models.OrderItemComponent{
+			ProductID:      item.ComponentID,
+			Name:           item.Component.Name,
+			UnitsPerBundle: item.Quantity,
+			Quantity:       item.Quantity * quantity,
+		}
+		available := item.Component.StockQuantity
+		variant, err := s.resolveOrderVariant(item.Component, nil)
+		if err != nil {
+			return nil, err
+		}
+		if variant != nil {
+			line.VariantID = &variant.VariantID
+			line.SKU = variant.SKU
+			available = variant.StockQuantity
+		}
+		if available < line.Quantity {
+			log.Printf("[ERROR] Stock insuficiente del componente %s del combo %s: pedido=%d, disponible=%d",
+				item.Component.Name, bundle.Name, line.Quantity, available)
+			return nil, ErrInsufficientStock
+		}
+		components = append(components, line)
+	}
+	return components, nil
+}
+
 // CreateOrder crea un nuevo pedido verificando horario de atención
 func (s *OrderService) CreateOrder(order *models.Order, items []models.OrderItem) (*models.Order, error) {
 	// Verificar que el cliente existe
@@ -75,8 +179,14 @@ func (s *OrderService) CreateOrder(order *models.Order, items []models.OrderItem
 		return nil, ErrInvalidRole
 	}
 
+	// La propina es opcional y se registra aparte del total
+	if !models.IsValidTipAmount(order.TipAmount) {
+		return nil, ErrInvalidTipAmount
+	}
+
 	// Verificar productos y calcular total
 	var totalAmount float64 = 0
+	var depositTotal float64 = 0
 	for i := range items {
 		log.Printf("[DEBUG] Procesando item %d: ProductID=%s, Quantity=%d, UnitPrice=%.2f", 
 			i, items[i].ProductID.String(), items[i].Quantity, items[i].UnitPrice)
@@ -109,9 +219,25 @@ func (s *OrderService) CreateOrder(order *models.Order, items []models.OrderItem
 			return nil, ErrInvalidUnitPrice
 		}
 		
+		// Resolver la variante pedida (o la predeterminada): define precio, SKU y stock
+		variant, err := s.resolveOrderVariant(product, items[i].VariantID)
+		if err != nil {
+			return nil, err
+		}
+
 		// Calcular el precio correcto considerando ofertas activas
 		expectedPrice := product.Price
-		if product.CurrentOffer != nil && product.CurrentOffer.IsCurrentlyActive() {
+		if variant != nil {
+			expectedPrice = variant.ApplyFinalPrice(product.CurrentOffer)
+			items[i].VariantID = &variant.VariantID
+			items[i].SKU = variant.SKU
+
+			if variant.StockQuantity < items[i].Quantity {
+				log.Printf("[ERROR] Stock insuficiente para la variante %s: pedido=%d, disponible=%d",
+					variant.SKU, items[i].Quantity, variant.StockQuantity)
+				return nil, ErrInsufficientStock
+			}
+		} else if product.CurrentOffer != nil && product.CurrentOffer.IsCurrentlyActive() {
 			expectedPrice = product.CurrentOffer.CalculateFinalPrice(product.Price)
 			log.Printf("[DEBUG] Oferta activa encontrada: OfferID=%s, Tipo=%s, Valor=%.2f, Precio original=%.2f, Precio con oferta=%.2f", 
 				product.CurrentOffer.OfferID.String(), product.CurrentOffer.DiscountType, product.CurrentOffer.DiscountValue, product.Price, expectedPrice)
@@ -119,6 +245,15 @@ func (s *OrderService) CreateOrder(order *models.Order, items []models.OrderItem
 			log.Printf("[DEBUG] No hay oferta activa para el producto %s", product.ProductID)
 		}
 		
+		// Un combo se desglosa en sus componentes: son los que se verifican y descuentan al confirmar
+		if product.IsBundle {
+			components, err := s.bundleBreakdown(product, items[i].Quantity)
+			if err != nil {
+				return nil, err
+			}
+			items[i].Components = components
+		}
+
 		// Validar que el precio del frontend coincida con el precio esperado
 		// Permitir una pequeña tolerancia para diferencias de redondeo (0.01)
 		tolerance := 0.01
@@ -136,20 +271,43 @@ func (s *OrderService) CreateOrder(order *models.Order, items []models.OrderItem
 		// Usar el precio que envía el frontend (ya incluye descuentos)
 		items[i].Subtotal = float64(items[i].Quantity) * items[i].UnitPrice
 		totalAmount += items[i].Subtotal
+
+		// Intercambio de balones: se cobra garantía por cada vacío que no se entrega, con la
+		// garantía vigente al comprar
+		items[i].SetUnitDeposit(product)
+		if err := items[i].ApplyCylinderExchange(product, items[i].CylindersReturned); err != nil {
+			return nil, ErrInvalidCylindersReturned
+		}
+		depositTotal += items[i].DepositCharge
 		
 		log.Printf("[DEBUG] Subtotal calculado: %.2f, Total acumulado: %.2f", items[i].Subtotal, totalAmount)
 	}
 
-	order.TotalAmount = totalAmount
+	order.DepositTotal = models.RoundCurrency(depositTotal)
+	order.TotalAmount = totalAmount + order.DepositTotal
 	order.OrderTime = time.Now()
+	if order.TipAmount > 0 {
+		order.TipAmount = models.RoundCurrency(order.TipAmount)
+		order.TipUpdatedAt = &order.OrderTime
+	}
 
-	// Verificar horario de atención
-	isWithinHours := models.IsWithinBusinessHours(
-		order.OrderTime,
-		s.config.App.BusinessHoursStart,
-		s.config.App.BusinessHoursEnd,
-		s.config.App.TimeZone,
-	)
+	// Enrutar el pedido a la sucursal más cercana que cubra la dirección y tenga stock
+	var branch *models.Branch
+	if s.branchService != nil {
+		branch, err = s.branchService.RouteOrder(order.Latitude, order.Longitude, items)
+		if err != nil {
+			return nil, err
+		}
+		if branch != nil {
+			order.BranchID = &branch.BranchID
+		}
+	}
+
+	// Verificar horario de atención (general y, si tiene, el de la sucursal)
+	isWithinHours := s.isWithinBusinessHours(order.OrderTime)
+	if branch != nil && !s.branchService.IsBranchOpenAt(branch, order.OrderTime) {
+		isWithinHours = false
+	}
 
 	if isWithinHours {
 		order.OrderStatus = models.OrderStatusPending
@@ -187,6 +345,36 @@ func (s *OrderService) GetOrderByID(orderID string) (*models.Order, error) {
 	return s.orderRepo.FindByID(orderID)
 }
 
+// GetOrderForUser obtiene un pedido visible para el usuario; un administrador de sucursal no ve pedidos de otra sucursal
+func (s *OrderService) GetOrderForUser(orderID string, userID string, userRole models.UserRole) (*models.Order, error) {
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+	if err := s.requireOrderBranch(order, userID, userRole); err != nil {
+		if err == ErrBranchAccessDenied {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return order, nil
+}
+
+// requireOrderBranch verifica que un administrador de sucursal solo opere pedidos de su sucursal
+func (s *OrderService) requireOrderBranch(order *models.Order, userID string, userRole models.UserRole) error {
+	if userRole != models.UserRoleAdmin {
+		return nil
+	}
+	actor, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if actor.BranchID != nil && (order.BranchID == nil || *order.BranchID != *actor.BranchID) {
+		return ErrBranchAccessDenied
+	}
+	return nil
+}
+
 // GetOrdersByClientID obtiene todos los pedidos de un cliente
 func (s *OrderService) GetOrdersByClientID(clientID string) ([]*models.Order, error) {
 	return s.orderRepo.FindByClientID(clientID)
@@ -202,6 +390,28 @@ func (s *OrderService) GetPendingOrders() ([]*models.Order, error) {
 	return s.orderRepo.FindPendingOrders()
 }
 
+// GetPendingOrdersForRepartidor obtiene los pedidos pendientes visibles para un repartidor:
+// los de su sucursal y los que no tienen sucursal asignada
+func (s *OrderService) GetPendingOrdersForRepartidor(repartidorID string) ([]*models.Order, error) {
+	orders, err := s.orderRepo.FindPendingOrders()
+	if err != nil {
+		return nil, err
+	}
+
+	repartidor, err := s.userRepo.FindByID(repartidorID)
+	if err != nil || repartidor.BranchID == nil {
+		return orders, nil
+	}
+
+	visible := make([]*models.Order, 0, len(orders))
+	for _, order := range orders {
+		if order.BranchID == nil || *order.BranchID == *repartidor.BranchID {
+			visible = append(visible, order)
+		}
+	}
+	return visible, nil
+}
+
 // GetOrdersByStatus obtiene todos los pedidos con un estado específico
 func (s *OrderService) GetOrdersByStatus(status models.OrderStatus) ([]*models.Order, error) {
 	return s.orderRepo.FindByStatus(status)
@@ -246,6 +456,75 @@ func (s *OrderService) GetOrdersWithPagination(page, pageSize int, status *model
 	}, nil
 }
 
+// GetOrdersWithCursor obtiene órdenes paginadas por cursor (estable ante pedidos nuevos durante el scroll)
+func (s *OrderService) GetOrdersWithCursor(cursorValue string, limit int, status *models.OrderStatus, searchQuery string, userRole models.UserRole, userID string) (*models.CursorPage[*models.Order], error) {
+	cursor, err := models.DecodeCursor(cursorValue)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	limit = models.NormalizeCursorLimit(limit)
+
+	orders, err := s.orderRepo.FindAllWithCursor(cursor, limit, status, searchQuery, userRole, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewCursorPage(orders, limit, func(o *models.Order) (time.Time, string) {
+		return o.OrderTime, o.OrderID.String()
+	}), nil
+}
+
+// NewOrderExportFilter arma los filtros de exportación; from/to son jornadas YYYY-MM-DD (por defecto hoy).
+// userID es el admin que exporta: si tiene sucursal, solo se exportan los pedidos de esa sucursal.
+func (s *OrderService) NewOrderExportFilter(status *models.OrderStatus, searchQuery string, from, to string, userID string) (*models.OrderExportFilter, error) {
+	start, end, err := models.BusinessDateRange(from, to, s.config.App.TimeZone, time.Now())
+	if err != nil {
+		return nil, ErrInvalidBusinessDate
+	}
+
+	return &models.OrderExportFilter{
+		Status: status,
+		Search: searchQuery,
+		From:   start,
+		To:     end,
+		UserID: userID,
+	}, nil
+}
+
+// ExportOrders escribe en w los pedidos filtrados con sus ítems (una fila por ítem).
+// Los pedidos se leen por lotes y cada fila se escribe apenas se arma.
+func (s *OrderService) ExportOrders(w io.Writer, format export.Format, filter *models.OrderExportFilter) error {
+	loc, err := time.LoadLocation(s.config.App.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	writer, err := export.NewRowWriter(format, w, "Pedidos")
+	if err != nil {
+		return err
+	}
+
+	if err := writer.WriteRow(models.OrderExportColumns); err != nil {
+		return err
+	}
+
+	err = s.orderRepo.FindForExport(*filter, models.OrderExportBatchSize, func(orders []*models.Order) error {
+		for _, order := range orders {
+			for _, row := range models.OrderExportRows(order, loc) {
+				if err := writer.WriteRow(row); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
 // UpdateOrderStatus actualiza el estado de un pedido
 func (s *OrderService) UpdateOrderStatus(orderID string, newStatus models.OrderStatus, userID string, userRole models.UserRole) (*models.Order, error) {
 	order, err := s.orderRepo.FindByID(orderID)
@@ -253,11 +532,25 @@ func (s *OrderService) UpdateOrderStatus(orderID string, newStatus models.OrderS
 		return nil, ErrOrderNotFound
 	}
 
+	if err := s.requireOrderBranch(order, userID, userRole); err != nil {
+		return nil, err
+	}
+
 	// Validar la transición de estado según el rol
 	if !s.canUpdateStatus(order, newStatus, userID, userRole) {
 		return nil, ErrInvalidTransition
 	}
 
+	// Si se está cambiando a IN_TRANSIT pero no hay repartidor asignado, es un error
+	if newStatus == models.OrderStatusInTransit && order.AssignedRepartidorID == nil {
+		return nil, errors.New("no se puede cambiar a 'EN CAMINO' sin asignar un repartidor primero")
+	}
+
+	// Actualizar el estado (antes de asignar: la confirmación puede fallar por falta de stock)
+	if err := s.orderRepo.UpdateStatus(orderID, newStatus); err != nil {
+		return nil, err
+	}
+
 	// Si un repartidor está cambiando el estado a CONFIRMED, asignarlo automáticamente
 	if userRole == models.UserRoleRepartidor && newStatus == models.OrderStatusConfirmed {
 		// Solo asignar si no hay repartidor asignado aún
@@ -268,22 +561,17 @@ func (s *OrderService) UpdateOrderStatus(orderID string, newStatus models.OrderS
 		}
 	}
 
-	// Si se está cambiando a IN_TRANSIT pero no hay repartidor asignado, es un error
-	if newStatus == models.OrderStatusInTransit && order.AssignedRepartidorID == nil {
-		return nil, errors.New("no se puede cambiar a 'EN CAMINO' sin asignar un repartidor primero")
-	}
-
-	// Actualizar el estado
-	if err := s.orderRepo.UpdateStatus(orderID, newStatus); err != nil {
-		return nil, err
-	}
-
 	// Recargar el pedido con los datos actualizados
 	updatedOrder, err := s.orderRepo.FindByID(orderID)
 	if err != nil {
 		return nil, err
 	}
 
+	// La confirmación descuenta el stock (trigger de la base de datos)
+	if models.OrderStatusHoldsStock(newStatus) && !models.OrderStatusHoldsStock(order.OrderStatus) && s.inventoryService != nil {
+		s.inventoryService.CheckOrderStock(updatedOrder)
+	}
+
 	// Enviar notificación al cliente sobre el cambio de estado
 	s.notifyStatusChange(updatedOrder)
 
@@ -291,16 +579,18 @@ func (s *OrderService) UpdateOrderStatus(orderID string, newStatus models.OrderS
 }
 
 // AssignRepartidor asigna un repartidor a un pedido
-func (s *OrderService) AssignRepartidor(orderID string, repartidorID string) (*models.Order, error) {
+func (s *OrderService) AssignRepartidor(orderID string, repartidorID string, userID string, userRole models.UserRole) (*models.Order, error) {
 	order, err := s.orderRepo.FindByID(orderID)
 	if err != nil {
 		return nil, ErrOrderNotFound
 	}
 
+	if err := s.requireOrderBranch(order, userID, userRole); err != nil {
+		return nil, err
+	}
+
 	// Verificar que el pedido esté en estado pendiente o confirmado
-	if order.OrderStatus != models.OrderStatusPending &&
-		order.OrderStatus != models.OrderStatusPendingOutOfHours &&
-		order.OrderStatus != models.OrderStatusConfirmed {
+	if !slices.Contains(models.AssignableOrderStatuses, order.OrderStatus) {
 		return nil, ErrInvalidOrderStatus
 	}
 
@@ -309,6 +599,18 @@ func (s *OrderService) AssignRepartidor(orderID string, repartidorID string) (*m
 		return nil, ErrOrderAlreadyAssigned
 	}
 
+	// Sin repartidor indicado se despacha al mejor candidato: menos pedidos en curso y mejor puntaje
+	if repartidorID == "" && s.dispatchService != nil {
+		candidates, err := s.dispatchService.SuggestRepartidores(orderID)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) == 0 {
+			return nil, ErrNoRepartidorAvailable
+		}
+		repartidorID = candidates[0].RepartidorID.String()
+	}
+
 	// Verificar que el repartidor existe y tiene el rol correcto
 	repartidor, err := s.userRepo.FindByID(repartidorID)
 	if err != nil {
@@ -319,13 +621,14 @@ func (s *OrderService) AssignRepartidor(orderID string, repartidorID string) (*m
 		return nil, ErrInvalidRole
 	}
 
-	// Asignar el repartidor y cambiar estado a asignado
-	if err := s.orderRepo.AssignRepartidor(orderID, repartidorID); err != nil {
-		return nil, err
+	// Un repartidor de sucursal solo puede tomar pedidos de su sucursal
+	if order.BranchID != nil && repartidor.BranchID != nil && *order.BranchID != *repartidor.BranchID {
+		return nil, ErrBranchMismatch
 	}
 
-	// Cambiar estado a ASSIGNED después de asignar repartidor
-	if err := s.orderRepo.UpdateStatus(orderID, models.OrderStatusAssigned); err != nil {
+	// Asignar el repartidor y pasar a ASSIGNED en una sola sentencia: si otra asignación se adelantó
+	// no se actualiza nada, y si el stock no alcanza (un pedido pendiente lo descuenta aquí) tampoco
+	if err := s.orderRepo.MarkAssigned(orderID, repartidorID); err != nil {
 		return nil, err
 	}
 
@@ -335,6 +638,10 @@ func (s *OrderService) AssignRepartidor(orderID string, repartidorID string) (*m
 		return nil, err
 	}
 
+	if !models.OrderStatusHoldsStock(order.OrderStatus) && s.inventoryService != nil {
+		s.inventoryService.CheckOrderStock(updatedOrder)
+	}
+
 	// Notificar al cliente que su pedido ha sido asignado
 	s.notifyOrderAssigned(updatedOrder)
 
@@ -342,12 +649,16 @@ func (s *OrderService) AssignRepartidor(orderID string, repartidorID string) (*m
 }
 
 // SetEstimatedArrivalTime establece el tiempo estimado de llegada para un pedido
-func (s *OrderService) SetEstimatedArrivalTime(orderID string, eta time.Time) (*models.Order, error) {
+func (s *OrderService) SetEstimatedArrivalTime(orderID string, eta time.Time, userID string, userRole models.UserRole) (*models.Order, error) {
 	order, err := s.orderRepo.FindByID(orderID)
 	if err != nil {
 		return nil, ErrOrderNotFound
 	}
 
+	if err := s.requireOrderBranch(order, userID, userRole); err != nil {
+		return nil, err
+	}
+
 	// Verificar que el pedido esté confirmado, asignado o en tránsito
 	if order.OrderStatus != models.OrderStatusConfirmed &&
 		order.OrderStatus != models.OrderStatusAssigned &&
@@ -372,6 +683,92 @@ func (s *OrderService) SetEstimatedArrivalTime(orderID string, eta time.Time) (*
 	return updatedOrder, nil
 }
 
+// SetTip registra o cambia la propina de un pedido del cliente
+func (s *OrderService) SetTip(orderID string, clientID string, amount float64) (*models.Order, error) {
+	if !models.IsValidTipAmount(amount) {
+		return nil, ErrInvalidTipAmount
+	}
+
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	if order.ClientID.String() != clientID {
+		return nil, ErrNotOrderOwner
+	}
+
+	if !order.CanSetTip(time.Now()) {
+		return nil, ErrTipNotAllowed
+	}
+
+	if err := s.orderRepo.SetTip(orderID, models.RoundCurrency(amount)); err != nil {
+		return nil, err
+	}
+
+	updatedOrder, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyTip(updatedOrder)
+
+	return updatedOrder, nil
+}
+
+// ConfirmCylinderReturns registra los balones vacíos que el repartidor recibió al entregar
+// y recalcula las garantías y el total del pedido con la garantía fijada en cada ítem al comprar
+func (s *OrderService) ConfirmCylinderReturns(orderID string, userID string, userRole models.UserRole, returns []models.CylinderReturnRequest) (*models.Order, error) {
+	order, err := s.orderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	if userRole != models.UserRoleAdmin &&
+		(order.AssignedRepartidorID == nil || order.AssignedRepartidorID.String() != userID) {
+		return nil, ErrOrderAccessDenied
+	}
+
+	// Se confirma al momento de la entrega, antes de marcar el pedido como entregado
+	if order.OrderStatus != models.OrderStatusAssigned && order.OrderStatus != models.OrderStatusInTransit {
+		return nil, ErrInvalidOrderStatus
+	}
+
+	returnedByItem := make(map[string]int, len(returns))
+	for _, r := range returns {
+		returnedByItem[r.OrderItemID.String()] = r.CylindersReturned
+	}
+
+	previousDeposit := order.DepositTotal
+	var depositTotal float64
+	for i := range order.OrderItems {
+		item := &order.OrderItems[i]
+		if returned, ok := returnedByItem[item.OrderItemID.String()]; ok {
+			if err := item.ApplyCylinderExchange(&item.Product, returned); err != nil {
+				return nil, ErrInvalidCylindersReturned
+			}
+			delete(returnedByItem, item.OrderItemID.String())
+		}
+		depositTotal += item.DepositCharge
+	}
+
+	// Ítems que no pertenecen al pedido
+	if len(returnedByItem) > 0 {
+		return nil, ErrInvalidCylindersReturned
+	}
+
+	now := time.Now()
+	order.DepositTotal = models.RoundCurrency(depositTotal)
+	order.TotalAmount = models.RoundCurrency(order.TotalAmount - previousDeposit + order.DepositTotal)
+	order.CylindersConfirmedAt = &now
+
+	if err := s.orderRepo.UpdateCylinderReturns(order); err != nil {
+		return nil, err
+	}
+
+	return s.orderRepo.FindByID(orderID)
+}
+
 // FindNearbyOrders encuentra pedidos cercanos a una ubicación
 func (s *OrderService) FindNearbyOrders(lat, lng float64, radiusKm float64) ([]*models.Order, error) {
 	return s.orderRepo.FindNearbyOrders(lat, lng, radiusKm)
@@ -550,6 +947,28 @@ func (s *OrderService) notifyStatusChange(order *models.Order) {
 	}
 }
 
+func (s *OrderService) notifyTip(order *models.Order) {
+	// Solo se avisa al repartidor asignado cuando hay propina
+	if s.wsHub == nil || order.AssignedRepartidorID == nil || order.TipAmount <= 0 {
+		return
+	}
+
+	type TipPayload struct {
+		OrderID   string  `json:"order_id"`
+		TipAmount float64 `json:"tip_amount"`
+		Message   string  `json:"message"`
+	}
+	msg := ws.Message{
+		Type: ws.TipReceived,
+		Payload: ws.MustMarshalPayload(TipPayload{
+			OrderID:   order.OrderID.String(),
+			TipAmount: order.TipAmount,
+			Message:   fmt.Sprintf("Recibiste una propina de S/ %.2f", order.TipAmount),
+		}),
+	}
+	s.wsHub.SendToUser(order.AssignedRepartidorID.String(), msg)
+}
+
 func (s *OrderService) notifyOrderConfirmed(order *models.Order) {
 	message := "Tu pedido ha sido confirmado y será preparado para entrega."
 	s.notificationService.SendToClient(order.ClientID.String(), message, order.OrderID.String())
@@ -654,3 +1073,16 @@ func (s *OrderService) notifyETA(order *models.Order) {
 	message := fmt.Sprintf("Tu pedido llegará aproximadamente a las %s", formattedTime)
 	s.notificationService.SendToClient(order.ClientID.String(), message, order.OrderID.String())
 }
+
+// isWithinBusinessHours verifica el horario de atención con el calendario o, si no está configurado, con el horario fijo
+func (s *OrderService) isWithinBusinessHours(t time.Time) bool {
+	if s.businessCalendar != nil {
+		return s.businessCalendar.IsOpenAt(t)
+	}
+	return models.IsWithinBusinessHours(
+		t,
+		s.config.App.BusinessHoursStart,
+		s.config.App.BusinessHoursEnd,
+		s.config.App.TimeZone,
+	)
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"time"
+
+	"backend/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// PerformanceService calcula los indicadores de desempeño de los repartidores
+type PerformanceService struct {
+	orderRepo  repositories.OrderRepository
+	ratingRepo repositories.DeliveryRatingRepository
+	userRepo   repositories.UserRepository
+	config     *config.Config
+}
+
+// NewPerformanceService crea un nuevo servicio de desempeño
+func NewPerformanceService(
+	orderRepo repositories.OrderRepository,
+	ratingRepo repositories.DeliveryRatingRepository,
+	userRepo repositories.UserRepository,
+	config *config.Config,
+) *PerformanceService {
+	return &PerformanceService{
+		orderRepo:  orderRepo,
+		ratingRepo: ratingRepo,
+		userRepo:   userRepo,
+		config:     config,
+	}
+}
+
+// GetLeaderboard obtiene el ranking de repartidores entre dos jornadas (por defecto, los últimos 30 días)
+func (s *PerformanceService) GetLeaderboard(from, to string) (*models.PerformanceReport, error) {
+	return s.buildReport(from, to, "")
+}
+
+// GetRepartidorPerformance obtiene el desempeño de un solo repartidor; el reporte siempre trae una fila
+func (s *PerformanceService) GetRepartidorPerformance(repartidorID string, from, to string) (*models.PerformanceReport, error) {
+	repartidor, err := s.userRepo.FindByID(repartidorID)
+	if err != nil || repartidor.UserRole != models.UserRoleRepartidor {
+		return nil, ErrNotARepartidor
+	}
+
+	report, err := s.buildReport(from, to, repartidorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sin pedidos asignados en el período: devolver los indicadores en cero
+	if len(report.Repartidores) == 0 {
+		report.Repartidores = []models.RepartidorPerformance{{
+			RepartidorID:   repartidor.UserID,
+			RepartidorName: repartidor.FullName,
+		}}
+		if err := s.applyRatings(report.Repartidores, repartidorID); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// buildReport arma el reporte de desempeño (repartidorID vacío = todos)
+func (s *PerformanceService) buildReport(from, to string, repartidorID string) (*models.PerformanceReport, error) {
+	start, end, err := models.RecentBusinessDateRange(from, to, models.PerformanceDefaultDays, s.config.App.TimeZone, time.Now())
+	if err != nil {
+		return nil, ErrInvalidBusinessDate
+	}
+	days := models.BusinessDaysBetween(start, end)
+
+	performance, err := s.orderRepo.GetRepartidorPerformance(start, end, repartidorID)
+	if err != nil {
+		return nil, err
+	}
+	if performance == nil {
+		performance = []models.RepartidorPerformance{}
+	}
+
+	for i := range performance {
+		performance[i].Finalize(days)
+	}
+
+	if err := s.applyRatings(performance, repartidorID); err != nil {
+		return nil, err
+	}
+
+	models.SortPerformanceLeaderboard(performance)
+
+	return &models.PerformanceReport{
+		From:         start.Format(models.BusinessDateLayout),
+		To:           end.AddDate(0, 0, -1).Format(models.BusinessDateLayout),
+		Days:         days,
+		Repartidores: performance,
+	}, nil
+}
+
+// applyRatings agrega el puntaje ponderado de calificaciones de entrega (histórico) a cada repartidor
+func (s *PerformanceService) applyRatings(performance []models.RepartidorPerformance, repartidorID string) error {
+	summaries, err := s.ratingRepo.GetRepartidorSummaries(repartidorID)
+	if err != nil {
+		return err
+	}
+
+	globalAverage, err := s.ratingRepo.GetGlobalAverage()
+	if err != nil {
+		return err
+	}
+
+	byRepartidor := make(map[uuid.UUID]models.RepartidorRatingSummary, len(summaries))
+	for _, summary := range summaries {
+		byRepartidor[summary.RepartidorID] = summary
+	}
+
+	for i := range performance {
+		summary := byRepartidor[performance[i].RepartidorID]
+		performance[i].RatingCount = summary.RatingCount
+		performance[i].RatingScore = models.WeightedDeliveryScore(summary.AverageRating, summary.RatingCount, globalAverage)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// PopularityService recalcula la popularidad de los productos con decaimiento en el tiempo: las
+// compras y vistas recientes pesan más. La puntuación guardada ordena GetPopular y la búsqueda.
+type PopularityService struct {
+	popularityRepo repositories.PopularityRepository
+	config         *config.Config
+}
+
+// NewPopularityService crea un nuevo servicio de popularidad
+func NewPopularityService(popularityRepo repositories.PopularityRepository, config *config.Config) *PopularityService {
+	return &PopularityService{
+		popularityRepo: popularityRepo,
+		config:         config,
+	}
+}
+
+// Weights son los pesos configurados de la puntuación de popularidad
+func (s *PopularityService) Weights() models.PopularityWeights {
+	cfg := s.config.Popularity
+	return models.PopularityWeights{
+		Purchase:      cfg.PurchaseWeight,
+		View:          cfg.ViewWeight,
+		RatingAverage: cfg.RatingAverageWeight,
+		RatingCount:   cfg.RatingCountWeight,
+		HalfLife:      cfg.HalfLife,
+	}
+}
+
+// Preview calcula la puntuación de cada producto con su desglose, sin guardarla
+func (s *PopularityService) Preview() (*models.PopularityPreview, error) {
+	now := time.Now()
+	weights := s.Weights()
+	since := now.Add(-weights.Window())
+
+	products, err := s.popularityRepo.FindProducts()
+	if err != nil {
+		return nil, err
+	}
+	activity, err := s.popularityRepo.FindActivity(since)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PopularityPreview{
+		Weights:       weights,
+		HalfLifeHours: weights.HalfLife.Hours(),
+		Since:         since,
+		ComputedAt:    now,
+		Products:      models.ComputePopularity(products, activity, weights, now),
+	}, nil
+}
+
+// RecomputeScores recalcula la popularidad y guarda las puntuaciones que cambiaron. Es la tarea
+// periódica que reemplaza a la fórmula fija del trigger de la base de datos.
+func (s *PopularityService) RecomputeScores(ctx context.Context) error {
+	preview, err := s.Preview()
+	if err != nil {
+		return fmt.Errorf("error al calcular la popularidad: %w", err)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	changed := make(map[uuid.UUID]float64)
+	for _, p := range preview.Products {
+		if p.Score != p.CurrentScore {
+			changed[p.ProductID] = p.Score
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if err := s.popularityRepo.UpdateScores(changed); err != nil {
+		return fmt.Errorf("error al guardar la popularidad: %w", err)
+	}
+	log.Printf("Popularidad recalculada: %d de %d productos actualizados", len(changed), len(preview.Products))
+	return nil
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"backend/internal/imaging"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrImageNotFound     = errors.New("imagen no encontrada")
+	ErrInvalidImage      = errors.New("imagen inválida")
+	ErrImageTooLarge     = errors.New("la imagen supera el tamaño máximo permitido")
+	ErrImageLimitReached = errors.New("se alcanzó el máximo de imágenes del producto")
+	ErrInvalidImageOrder = errors.New("el orden debe incluir todas las imágenes del producto una sola vez")
+	ErrTooManyImageFiles = errors.New("demasiados archivos en una sola subida")
+	ErrNoImageFiles      = errors.New("no se envió ninguna imagen")
+)
+
+// ProductImageService maneja la subida, el orden y la eliminación de imágenes de productos
+type ProductImageService struct {
+	imageRepo      repositories.ProductImageRepository
+	productService *ProductService
+	store          storage.Storage
+	maxUploadBytes int64
+}
+
+// NewProductImageService crea un nuevo servicio de imágenes de productos
+func NewProductImageService(imageRepo repositories.ProductImageRepository, productService *ProductService, store storage.Storage, maxUploadBytes int64) *ProductImageService {
+	return &ProductImageService{
+		imageRepo:      imageRepo,
+		productService: productService,
+		store:          store,
+		maxUploadBytes: maxUploadBytes,
+	}
+}
+
+// MaxUploadBytes es el tamaño máximo de cada archivo subido
+func (s *ProductImageService) MaxUploadBytes() int64 {
+	return s.maxUploadBytes
+}
+
+// UploadBodyLimit es el tamaño máximo de la petición de subida: todos los archivos permitidos más
+// 1 MB para el resto del formulario multipart
+func (s *ProductImageService) UploadBodyLimit() int {
+	return int(s.maxUploadBytes)*models.MaxImagesPerRequest + 1<<20
+}
+
+// List obtiene las imágenes de un producto en orden
+func (s *ProductImageService) List(productID string) ([]models.ProductImage, error) {
+	if _, err := s.productService.GetByID(productID); err != nil {
+		return nil, ErrProductNotFoundService
+	}
+
+	images, err := s.imageRepo.FindByProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if images == nil {
+		images = []models.ProductImage{}
+	}
+	return images, nil
+}
+
+// Upload valida y procesa todos los archivos antes de guardar cualquiera, de modo que un archivo
+// inválido no deja la subida a medias. Las imágenes se agregan al final del orden.
+func (s *ProductImageService) Upload(productID string, files [][]byte) ([]models.ProductImage, error) {
+	product, err := s.productService.GetByID(productID)
+	if err != nil {
+		return nil, ErrProductNotFoundService
+	}
+	if len(files) == 0 {
+		return nil, ErrNoImageFiles
+	}
+	if len(files) > models.MaxImagesPerRequest {
+		return nil, ErrTooManyImageFiles
+	}
+
+	count, err := s.imageRepo.CountByProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if count+int64(len(files)) > models.MaxProductImages {
+		return nil, ErrImageLimitReached
+	}
+
+	processed := make([]*imaging.Result, len(files))
+	for i, data := range files {
+		if int64(len(data)) > s.maxUploadBytes {
+			return nil, ErrImageTooLarge
+		}
+		result, err := imaging.Process(data, imaging.DefaultSizes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: archivo %d: %v", ErrInvalidImage, i+1, err)
+		}
+		processed[i] = result
+	}
+
+	images := make([]models.ProductImage, 0, len(files))
+	for i, result := range processed {
+		image, err := s.storeImage(product.ProductID, files[i], result)
+		if err != nil {
+			if len(images) > 0 {
+				s.notifyImagesChanged(productID)
+			}
+			return images, err
+		}
+		images = append(images, *image)
+	}
+
+	s.notifyImagesChanged(productID)
+	return images, nil
+}
+
+// Reorder cambia el orden de las imágenes; la primera pasa a ser la principal
+func (s *ProductImageService) Reorder(productID string, imageIDs []string) ([]models.ProductImage, error) {
+	current, err := s.List(productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(current) == 0 {
+		return nil, ErrInvalidImageOrder
+	}
+
+	// El nuevo orden debe ser una permutación de las imágenes actuales
+	pending := make(map[uuid.UUID]bool, len(current))
+	for _, image := range current {
+		pending[image.ImageID] = true
+	}
+	ordered := make([]uuid.UUID, 0, len(imageIDs))
+	for _, id := range imageIDs {
+		imageID, err := uuid.Parse(id)
+		if err != nil || !pending[imageID] {
+			return nil, ErrInvalidImageOrder
+		}
+		delete(pending, imageID)
+		ordered = append(ordered, imageID)
+	}
+	if len(pending) > 0 {
+		return nil, ErrInvalidImageOrder
+	}
+
+	if err := s.imageRepo.Reorder(current[0].ProductID, ordered); err != nil {
+		return nil, err
+	}
+
+	s.notifyImagesChanged(productID)
+	return s.List(productID)
+}
+
+// Delete elimina una imagen del producto y sus archivos
+func (s *ProductImageService) Delete(productID, imageID string) error {
+	if _, err := uuid.Parse(imageID); err != nil {
+		return ErrImageNotFound
+	}
+	image, err := s.imageRepo.FindByID(imageID)
+	if err != nil || image.ProductID.String() != productID {
+		return ErrImageNotFound
+	}
+
+	if err := s.imageRepo.Delete(image); err != nil {
+		return err
+	}
+
+	// Los archivos huérfanos no afectan a los clientes; solo se registra el error
+	for _, key := range image.Keys() {
+		if err := s.store.Delete(key); err != nil {
+			log.Printf("Error al eliminar archivo %s de la imagen %s: %v", key, image.ImageID, err)
+		}
+	}
+
+	s.notifyImagesChanged(productID)
+	return nil
+}
+
+// storeImage guarda el original y sus versiones y registra la imagen. Si algo falla, elimina los
+// archivos ya subidos.
+func (s *ProductImageService) storeImage(productID uuid.UUID, original []byte, result *imaging.Result) (*models.ProductImage, error) {
+	image := &models.ProductImage{
+		ImageID:     uuid.New(),
+		ProductID:   productID,
+		Width:       result.Width,
+		Height:      result.Height,
+		ContentType: result.ContentType,
+		SizeBytes:   int64(len(original)),
+	}
+
+	put := func(size, ext, contentType string, data []byte) error {
+		key := models.ProductImageKey(productID, image.ImageID, size, ext)
+		url, err := s.store.Put(key, data, contentType)
+		if err != nil {
+			return fmt.Errorf("error al guardar %s: %w", key, err)
+		}
+		image.AddFile(size, ext, key, url)
+		return nil
+	}
+
+	err := put("original", result.Ext, result.ContentType, original)
+	for _, r := range result.Renditions {
+		if err != nil {
+			break
+		}
+		err = put(r.Size, r.Ext, r.ContentType, r.Data)
+	}
+	if err == nil {
+		err = s.imageRepo.Create(image)
+	}
+
+	if err != nil {
+		for _, key := range image.Keys() {
+			if delErr := s.store.Delete(key); delErr != nil {
+				log.Printf("Error al limpiar archivo %s: %v", key, delErr)
+			}
+		}
+		return nil, err
+	}
+	return image, nil
+}
+
+// notifyImagesChanged avisa a los clientes que cambiaron las imágenes (y la imagen principal)
+func (s *ProductImageService) notifyImagesChanged(productID string) {
+	product, err := s.productService.GetByID(productID)
+	if err != nil {
+		log.Printf("Error al recargar producto %s tras cambiar sus imágenes: %v", productID, err)
+		return
+	}
+	s.productService.notifyProductUpdate(product, "updated")
+}
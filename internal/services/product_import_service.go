@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"backend/internal/export"
+	"backend/internal/models"
+	"backend/internal/ws"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidProductImport = errors.New("archivo de importación inválido")
+
+// ImportCSV valida todas las filas del archivo y, si ninguna tiene errores y no es una prueba
+// (dryRun), aplica las altas y modificaciones en una sola transacción. Con sucursales, el stock
+// del archivo es el de branchID; sin branchID las filas no pueden cambiar el stock.
+func (s *ProductService) ImportCSV(r io.Reader, dryRun bool, branchID *uuid.UUID) (*models.ProductImportResult, error) {
+	if err := s.CheckStockBranch(branchID); err != nil {
+		return nil, err
+	}
+	rows, err := models.ParseProductCSV(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProductImport, err)
+	}
+
+	index, err := s.repo.FindImportIndex(branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, result := models.PlanProductImport(rows, index)
+	result.DryRun = dryRun
+	if dryRun || result.Failed > 0 {
+		return result, nil
+	}
+
+	if len(changes) > 0 {
+		if err := s.repo.ApplyImport(changes, branchID); err != nil {
+			return nil, fmt.Errorf("error al aplicar la importación: %w", err)
+		}
+	}
+	result.Applied = true
+
+	productIDs := make([]uuid.UUID, 0, len(changes))
+	for _, change := range changes {
+		productIDs = append(productIDs, change.Product.ProductID)
+	}
+	s.checkLowStock(productIDs...)
+	s.notifyProductImport(changes, result)
+	return result, nil
+}
+
+// ExportProducts escribe en w todos los productos con las mismas columnas que acepta la importación.
+// Con branchID, el stock es el de esa sucursal.
+func (s *ProductService) ExportProducts(w io.Writer, format export.Format, branchID *uuid.UUID) error {
+	writer, err := export.NewRowWriter(format, w, "Productos")
+	if err != nil {
+		return err
+	}
+
+	if err := writer.WriteRow(models.ProductCSVColumns); err != nil {
+		return err
+	}
+
+	rows, err := s.repo.FindForExport(branchID)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writer.WriteRow(row.Values()); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// CheckStockBranch verifica que exista la sucursal cuyo stock se importa o exporta (nil = total)
+func (s *ProductService) CheckStockBranch(branchID *uuid.UUID) error {
+	if branchID == nil {
+		return nil
+	}
+	exists, err := s.repo.BranchExists(*branchID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrBranchNotFound
+	}
+	return nil
+}
+
+// notifyProductImport envía una sola notificación con todos los productos importados
+func (s *ProductService) notifyProductImport(changes []models.ProductImportChange, result *models.ProductImportResult) {
+	if s.wsHub == nil || len(changes) == 0 {
+		return
+	}
+
+	products := make([]*models.Product, 0, len(changes))
+	for _, change := range changes {
+		products = append(products, change.Product)
+	}
+
+	msg := ws.Message{
+		Type: ws.ProductUpdate,
+		Payload: ws.MustMarshalPayload(map[string]interface{}{
+			"action":   "bulk_import",
+			"created":  result.Created,
+			"updated":  result.Updated,
+			"products": products,
+		}),
+	}
+
+	log.Printf("[WebSocket] Enviando notificación de importación: %d creados, %d actualizados", result.Created, result.Updated)
+	s.wsHub.SendToRole("ADMIN", msg)
+	s.wsHub.SendToRole("CLIENT", msg)
+	s.wsHub.SendToRole("REPARTIDOR", msg)
+}
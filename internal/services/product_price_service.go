@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidScheduledPrice    = errors.New("cambio de precio programado inválido")
+	ErrScheduledPriceNotFound   = errors.New("cambio de precio programado no encontrado")
+	ErrScheduledPriceConflict   = models.ErrScheduledPriceConflict
+	ErrScheduledPriceNotPending = errors.New("el cambio de precio ya se aplicó o fue cancelado")
+)
+
+// ProductPriceService maneja el historial de precios y los cambios de precio programados
+type ProductPriceService struct {
+	priceRepo      repositories.ProductPriceRepository
+	productService *ProductService
+}
+
+// NewProductPriceService crea un nuevo servicio de precios
+func NewProductPriceService(priceRepo repositories.ProductPriceRepository, productService *ProductService) *ProductPriceService {
+	return &ProductPriceService{
+		priceRepo:      priceRepo,
+		productService: productService,
+	}
+}
+
+// GetHistory obtiene los cambios de precio de un producto, del más reciente al más antiguo
+func (s *ProductPriceService) GetHistory(productID, cursorValue string, limit int) (*models.CursorPage[models.ProductPriceHistory], error) {
+	if _, err := s.productService.GetByID(productID); err != nil {
+		return nil, ErrProductNotFoundService
+	}
+
+	cursor, err := models.DecodeCursor(cursorValue)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	limit = models.NormalizeCursorLimit(limit)
+
+	history, err := s.priceRepo.FindHistory(productID, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewCursorPage(history, limit, func(h models.ProductPriceHistory) (time.Time, string) {
+		return h.ChangedAt, h.HistoryID.String()
+	}), nil
+}
+
+// ListScheduled obtiene los cambios programados de un producto; status vacío = todos
+func (s *ProductPriceService) ListScheduled(productID, status string) ([]models.ScheduledPriceChange, error) {
+	if _, err := s.productService.GetByID(productID); err != nil {
+		return nil, ErrProductNotFoundService
+	}
+
+	var filter *models.ScheduledPriceStatus
+	if status != "" {
+		st := models.ScheduledPriceStatus(status)
+		switch st {
+		case models.ScheduledPricePending, models.ScheduledPriceApplied, models.ScheduledPriceCancelled:
+			filter = &st
+		default:
+			return nil, fmt.Errorf("%w: estado desconocido %q", ErrInvalidScheduledPrice, status)
+		}
+	}
+
+	schedules, err := s.priceRepo.FindSchedules(productID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if schedules == nil {
+		schedules = []models.ScheduledPriceChange{}
+	}
+	return schedules, nil
+}
+
+// SchedulePriceChange programa un nuevo precio para el producto a partir de una fecha futura
+func (s *ProductPriceService) SchedulePriceChange(productID, userID string, req *models.SchedulePriceChangeRequest) (*models.ScheduledPriceChange, error) {
+	product, err := s.productService.GetByID(productID)
+	if err != nil {
+		return nil, ErrProductNotFoundService
+	}
+	if err := req.Validate(time.Now()); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScheduledPrice, err)
+	}
+
+	pending := models.ScheduledPricePending
+	existing, err := s.priceRepo.FindSchedules(productID, &pending)
+	if err != nil {
+		return nil, err
+	}
+	for _, schedule := range existing {
+		if schedule.EffectiveAt.Equal(req.EffectiveAt) {
+			return nil, ErrScheduledPriceConflict
+		}
+	}
+
+	schedule := &models.ScheduledPriceChange{
+		ProductID:   product.ProductID,
+		NewPrice:    req.NewPrice,
+		EffectiveAt: req.EffectiveAt,
+		Status:      models.ScheduledPricePending,
+		Note:        req.Note,
+	}
+	if createdBy, err := uuid.Parse(userID); err == nil {
+		schedule.CreatedBy = &createdBy
+	}
+	if err := s.priceRepo.CreateSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// CancelScheduled cancela un cambio programado que aún no se aplicó
+func (s *ProductPriceService) CancelScheduled(productID, scheduleID string) (*models.ScheduledPriceChange, error) {
+	if _, err := uuid.Parse(scheduleID); err != nil {
+		return nil, ErrScheduledPriceNotFound
+	}
+	schedule, err := s.priceRepo.FindScheduleByID(scheduleID)
+	if err != nil || schedule.ProductID.String() != productID {
+		return nil, ErrScheduledPriceNotFound
+	}
+
+	cancelled, err := s.priceRepo.CancelSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, ErrScheduledPriceNotPending
+	}
+	return schedule, nil
+}
+
+// ApplyDuePriceChanges aplica, en orden de vigencia, los cambios programados cuya fecha ya llegó y
+// envía la notificación habitual de producto actualizado por cada uno. Es la tarea en segundo plano.
+func (s *ProductPriceService) ApplyDuePriceChanges(ctx context.Context) error {
+	for ctx.Err() == nil {
+		schedule, err := s.priceRepo.ApplyNextDueSchedule(time.Now())
+		if err != nil {
+			return fmt.Errorf("error al aplicar cambios de precio programados: %w", err)
+		}
+		if schedule == nil {
+			return nil
+		}
+
+		log.Printf("Precio programado aplicado: producto %s a %.2f (vigente desde %s)",
+			schedule.ProductID, schedule.NewPrice, schedule.EffectiveAt.Format(time.RFC3339))
+
+		product, err := s.productService.GetByID(schedule.ProductID.String())
+		if err != nil {
+			log.Printf("Error al recargar producto %s tras aplicar su precio programado: %v", schedule.ProductID, err)
+			continue
+		}
+		s.productService.notifyProductUpdate(product, "updated")
+	}
+	return ctx.Err()
+}
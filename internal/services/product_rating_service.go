@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"time"
 
 	"backend/internal/models"
 	"backend/internal/repositories"
@@ -94,7 +95,34 @@ func (s *ProductRatingService) GetByProduct(productID string) ([]*models.RatingR
 		return nil, err
 	}
 
-	// Convertir a response format
+	return toRatingResponses(ratings), nil
+}
+
+// GetByProductWithCursor obtiene las calificaciones de un producto paginadas por cursor
+func (s *ProductRatingService) GetByProductWithCursor(productID string, cursorValue string, limit int) (*models.CursorPage[*models.RatingResponse], error) {
+	// Verificar que el producto existe
+	if _, err := s.productRepo.FindByID(productID); err != nil {
+		return nil, ErrProductNotFoundService
+	}
+
+	cursor, err := models.DecodeCursor(cursorValue)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	limit = models.NormalizeCursorLimit(limit)
+
+	ratings, err := s.ratingRepo.FindByProductWithCursor(productID, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewCursorPage(toRatingResponses(ratings), limit, func(r *models.RatingResponse) (time.Time, string) {
+		return r.CreatedAt, r.RatingID.String()
+	}), nil
+}
+
+// toRatingResponses convierte las calificaciones al formato de respuesta
+func toRatingResponses(ratings []*models.ProductRating) []*models.RatingResponse {
 	responses := make([]*models.RatingResponse, len(ratings))
 	for i, rating := range ratings {
 		responses[i] = &models.RatingResponse{
@@ -111,8 +139,7 @@ func (s *ProductRatingService) GetByProduct(productID string) ([]*models.RatingR
 			responses[i].UserName = rating.User.FullName
 		}
 	}
-
-	return responses, nil
+	return responses
 }
 
 // GetUserRatingForProduct obtiene la calificación de un usuario para un producto específico
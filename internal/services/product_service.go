@@ -2,22 +2,39 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"time"
 
 	"backend/internal/models"
 	"backend/internal/repositories"
 	"backend/internal/ws"
+
+	"github.com/google/uuid"
 )
 
 var (
 	ErrProductNotFoundService = errors.New("producto no encontrado")
 	ErrProductNameExists      = errors.New("ya existe un producto con ese nombre")
+	ErrInvalidProductSearch   = errors.New("parámetros de búsqueda inválidos")
+	ErrVariantNotFound        = errors.New("variante no encontrada")
+	ErrInvalidVariant         = errors.New("variante inválida")
+	ErrVariantSKUExists       = errors.New("ya existe una variante con ese SKU")
+	ErrDefaultVariantRequired = errors.New("la variante predeterminada no puede desactivarse")
+	ErrInvalidBundle          = errors.New("combo inválido")
+	ErrNotBundle              = errors.New("el producto no es un combo")
+	ErrProductInBundle        = errors.New("el producto forma parte de un combo; quítelo del combo antes de eliminarlo")
+	ErrProductNotDeleted      = errors.New("el producto debe eliminarse antes de borrarlo definitivamente")
+	ErrProductHasOrders       = errors.New("el producto figura en pedidos y no puede borrarse definitivamente")
+
+	ErrBranchStockRequired = models.ErrBranchStockRequired
 )
 
 // ProductService maneja la lógica de negocio relacionada con productos
 type ProductService struct {
-	repo  repositories.ProductRepository
-	wsHub ws.HubInterface
+	repo      repositories.ProductRepository
+	wsHub     ws.HubInterface
+	inventory *InventoryService
 }
 
 // NewProductService crea un nuevo servicio de productos
@@ -30,17 +47,101 @@ func NewProductService(repo repositories.ProductRepository, wsHub ws.HubInterfac
 
 // Create crea un nuevo producto
 func (s *ProductService) Create(product *models.Product) error {
+	// El producto y su variante predeterminada se crean en la misma transacción
 	err := s.repo.Create(product)
 	if err != nil {
 		return err
 	}
 
+	s.checkLowStock(product.ProductID)
+
 	// Enviar notificación WebSocket
 	s.notifyProductUpdate(product, "created")
 	
 	return nil
 }
 
+// CreateBundle crea un combo con sus componentes. El stock del combo no se indica: es el número de
+// combos que alcanza a armarse con el stock de los componentes.
+func (s *ProductService) CreateBundle(product *models.Product, components []models.BundleItemRequest) error {
+	if product.ProductID == uuid.Nil {
+		product.ProductID = uuid.New()
+	}
+	items, err := s.buildBundleItems(product.ProductID, components)
+	if err != nil {
+		return err
+	}
+
+	product.IsBundle = true
+	product.StockQuantity = 0
+	product.BundleItems = items
+	if err := s.Create(product); err != nil {
+		return err
+	}
+
+	// Devolver el combo con sus componentes cargados
+	if created, err := s.repo.FindByID(product.ProductID.String()); err == nil {
+		*product = *created
+	}
+	return nil
+}
+
+// SetBundleItems reemplaza los componentes de un combo y recalcula su stock
+func (s *ProductService) SetBundleItems(productID string, components []models.BundleItemRequest) (*models.Product, error) {
+	product, err := s.repo.FindByID(productID)
+	if err != nil {
+		return nil, ErrProductNotFoundService
+	}
+	if !product.IsBundle {
+		return nil, ErrNotBundle
+	}
+
+	items, err := s.buildBundleItems(product.ProductID, components)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceBundleItems(product.ProductID, items); err != nil {
+		return nil, err
+	}
+
+	product, err = s.repo.FindByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyProductUpdate(product, "updated")
+	return product, nil
+}
+
+// buildBundleItems valida los componentes: deben existir y no ser combos
+func (s *ProductService) buildBundleItems(bundleID uuid.UUID, components []models.BundleItemRequest) ([]models.ProductBundleItem, error) {
+	items, err := models.NewBundleItems(bundleID, components)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	for i := range items {
+		component, err := s.repo.FindByID(items[i].ComponentID.String())
+		if err != nil {
+			return nil, fmt.Errorf("%w: el componente %s no existe", ErrInvalidBundle, items[i].ComponentID)
+		}
+		if component.IsBundle {
+			return nil, fmt.Errorf("%w: %q es un combo y no puede incluirse en otro", ErrInvalidBundle, component.Name)
+		}
+	}
+	return items, nil
+}
+
+// SetInventoryService activa los avisos de stock bajo al editar el stock de los productos
+func (s *ProductService) SetInventoryService(inventory *InventoryService) {
+	s.inventory = inventory
+}
+
+// checkLowStock revisa el umbral de reposición de los productos cuyo stock cambió
+func (s *ProductService) checkLowStock(productIDs ...uuid.UUID) {
+	if s.inventory != nil {
+		s.inventory.CheckLowStock(productIDs...)
+	}
+}
+
 // GetByID obtiene un producto por su ID
 func (s *ProductService) GetByID(id string) (*models.Product, error) {
 	return s.repo.FindByID(id)
@@ -56,12 +157,34 @@ func (s *ProductService) GetActive() ([]*models.Product, error) {
 	return s.repo.FindActive()
 }
 
+// GetWithCursor obtiene productos paginados por cursor, del más reciente al más antiguo
+func (s *ProductService) GetWithCursor(cursorValue string, limit int, onlyActive bool) (*models.CursorPage[*models.Product], error) {
+	cursor, err := models.DecodeCursor(cursorValue)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	limit = models.NormalizeCursorLimit(limit)
+
+	products, err := s.repo.FindWithCursor(cursor, limit, onlyActive)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewCursorPage(products, limit, func(p *models.Product) (time.Time, string) {
+		return p.CreatedAt, p.ProductID.String()
+	}), nil
+}
+
 // Update actualiza un producto existente
 func (s *ProductService) Update(product *models.Product) error {
 	err := s.repo.Update(product)
 	if err != nil {
 		return err
 	}
+	if err := s.repo.SyncDefaultVariant(product); err != nil {
+		return err
+	}
+	s.checkLowStock(product.ProductID)
 
 	// Enviar notificación WebSocket
 	s.notifyProductUpdate(product, "updated")
@@ -69,7 +192,7 @@ func (s *ProductService) Update(product *models.Product) error {
 	return nil
 }
 
-// Delete elimina un producto por su ID
+// Delete elimina un producto del catálogo (borrado lógico); puede restaurarse
 func (s *ProductService) Delete(id string) error {
 	// Primero obtener el producto para la notificación
 	product, err := s.repo.FindByID(id)
@@ -77,6 +200,15 @@ func (s *ProductService) Delete(id string) error {
 		return err
 	}
 
+	// Los combos guardan referencias a sus componentes
+	inBundle, err := s.repo.IsBundleComponent(id)
+	if err != nil {
+		return err
+	}
+	if inBundle {
+		return ErrProductInBundle
+	}
+
 	err = s.repo.Delete(id)
 	if err != nil {
 		return err
@@ -88,6 +220,66 @@ func (s *ProductService) Delete(id string) error {
 	return nil
 }
 
+// ListDeleted obtiene los productos eliminados
+func (s *ProductService) ListDeleted() ([]*models.Product, error) {
+	return s.repo.FindDeleted()
+}
+
+// Restore devuelve al catálogo un producto eliminado, si su nombre no lo usa otro producto
+func (s *ProductService) Restore(id string) (*models.Product, error) {
+	deleted, err := s.repo.FindDeletedByID(id)
+	if err != nil {
+		return nil, ErrProductNotFoundService
+	}
+	taken, err := s.repo.NameTaken(deleted.Name, deleted.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrProductNameExists
+	}
+
+	if err := s.repo.Restore(id); err != nil {
+		return nil, err
+	}
+	product, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Para los clientes el producto vuelve a aparecer en el catálogo
+	s.notifyProductUpdate(product, "created")
+	return product, nil
+}
+
+// Purge borra definitivamente un producto ya eliminado. Solo se permite si ningún pedido lo
+// incluye ni forma parte de un combo: el historial de pedidos debe conservar sus productos.
+func (s *ProductService) Purge(id string) error {
+	if _, err := s.repo.FindDeletedByID(id); err != nil {
+		if _, err := s.repo.FindByID(id); err == nil {
+			return ErrProductNotDeleted
+		}
+		return ErrProductNotFoundService
+	}
+
+	hasOrders, err := s.repo.HasOrderItems(id)
+	if err != nil {
+		return err
+	}
+	if hasOrders {
+		return ErrProductHasOrders
+	}
+	inBundle, err := s.repo.IsBundleComponent(id)
+	if err != nil {
+		return err
+	}
+	if inBundle {
+		return ErrProductInBundle
+	}
+
+	return s.repo.HardDelete(id)
+}
+
 // GetPopular obtiene productos populares
 func (s *ProductService) GetPopular(limit int) ([]*models.Product, error) {
 	if limit <= 0 {
@@ -112,15 +304,165 @@ func (s *ProductService) GetRecent(limit int) ([]*models.Product, error) {
 	return s.repo.FindRecent(limit)
 }
 
-// IncrementViewCount incrementa el contador de vistas
-func (s *ProductService) IncrementViewCount(id string) error {
-	// Verificar que el producto existe
-	_, err := s.repo.FindByID(id)
+// Search busca productos activos por texto completo con filtros, orden, paginación y facetas
+func (s *ProductService) Search(params models.ProductSearchParams) (*models.ProductSearchResult, error) {
+	if err := params.Normalize(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProductSearch, err)
+	}
+
+	products, total, err := s.repo.Search(params)
 	if err != nil {
-		return ErrProductNotFoundService
+		return nil, err
+	}
+	facets, err := s.repo.SearchFacets(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ProductSearchResult{
+		Products:   products,
+		TotalCount: total,
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		TotalPages: int((total + int64(params.PageSize) - 1) / int64(params.PageSize)),
+		Sort:       params.Sort,
+		Facets:     *facets,
+	}, nil
+}
+
+// ListVariants obtiene las variantes de un producto con su precio final
+func (s *ProductService) ListVariants(productID string, activeOnly bool) ([]models.ProductVariant, error) {
+	product, err := s.repo.FindByID(productID)
+	if err != nil {
+		return nil, ErrProductNotFoundService
+	}
+	if err := s.repo.LoadCurrentOffer(product); err != nil {
+		return nil, err
+	}
+
+	variants, err := s.repo.FindVariants(productID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	for i := range variants {
+		variants[i].ApplyFinalPrice(product.CurrentOffer)
+	}
+	return variants, nil
+}
+
+// CreateVariant agrega una variante a un producto
+func (s *ProductService) CreateVariant(productID string, req *models.CreateVariantRequest) (*models.ProductVariant, error) {
+	product, err := s.repo.FindByID(productID)
+	if err != nil {
+		return nil, ErrProductNotFoundService
+	}
+	if product.IsBundle {
+		return nil, fmt.Errorf("%w: los combos se venden en una sola presentación", ErrInvalidVariant)
+	}
+
+	variant := &models.ProductVariant{
+		ProductID:     product.ProductID,
+		SKU:           req.SKU,
+		Name:          req.Name,
+		Size:          req.Size,
+		ValveType:     req.ValveType,
+		Brand:         req.Brand,
+		Price:         req.Price,
+		StockQuantity: req.StockQuantity,
+		SortOrder:     req.SortOrder,
+		IsDefault:     req.IsDefault,
+		IsActive:      true,
+	}
+	if err := s.saveVariant(variant, true); err != nil {
+		return nil, err
 	}
 
-	return s.repo.IncrementViewCount(id)
+	s.notifyVariantChange(productID)
+	return variant, nil
+}
+
+// UpdateVariant actualiza los datos de una variante de un producto
+func (s *ProductService) UpdateVariant(productID, variantID string, req *models.UpdateVariantRequest) (*models.ProductVariant, error) {
+	variant, err := s.findProductVariant(productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	wasDefault := variant.IsDefault
+	stock := variant.StockQuantity
+
+	req.Apply(variant)
+	if variant.StockQuantity != stock {
+		if product, err := s.repo.FindByID(productID); err == nil && product.IsBundle {
+			return nil, fmt.Errorf("%w: el stock de un combo se calcula a partir de sus componentes", ErrInvalidVariant)
+		}
+	}
+	// La predeterminada solo cambia marcando otra variante como predeterminada
+	if wasDefault && (!variant.IsDefault || !variant.IsActive) {
+		return nil, ErrDefaultVariantRequired
+	}
+	if variant.IsDefault && !variant.IsActive {
+		return nil, fmt.Errorf("%w: una variante inactiva no puede ser la predeterminada", ErrInvalidVariant)
+	}
+	if err := s.saveVariant(variant, false); err != nil {
+		return nil, err
+	}
+
+	s.notifyVariantChange(productID)
+	return variant, nil
+}
+
+// DeleteVariant desactiva una variante; se conserva para el historial de pedidos
+func (s *ProductService) DeleteVariant(productID, variantID string) error {
+	variant, err := s.findProductVariant(productID, variantID)
+	if err != nil {
+		return err
+	}
+	if variant.IsDefault {
+		return ErrDefaultVariantRequired
+	}
+
+	variant.IsActive = false
+	if err := s.repo.UpdateVariant(variant); err != nil {
+		return err
+	}
+
+	s.notifyVariantChange(productID)
+	return nil
+}
+
+// findProductVariant obtiene una variante verificando que pertenezca al producto
+func (s *ProductService) findProductVariant(productID, variantID string) (*models.ProductVariant, error) {
+	variant, err := s.repo.FindVariantByID(variantID)
+	if err != nil || variant.ProductID.String() != productID {
+		return nil, ErrVariantNotFound
+	}
+	return variant, nil
+}
+
+// saveVariant valida la variante, verifica que el SKU no esté en uso y la guarda
+func (s *ProductService) saveVariant(variant *models.ProductVariant, isNew bool) error {
+	if err := variant.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidVariant, err)
+	}
+	if existing, err := s.repo.FindVariantBySKU(variant.SKU); err == nil && existing.VariantID != variant.VariantID {
+		return ErrVariantSKUExists
+	}
+
+	if isNew {
+		return s.repo.CreateVariant(variant)
+	}
+	return s.repo.UpdateVariant(variant)
+}
+
+// notifyVariantChange avisa que cambió un producto a partir de sus variantes (precio o stock)
+func (s *ProductService) notifyVariantChange(productID string) {
+	product, err := s.repo.FindByID(productID)
+	if err != nil {
+		log.Printf("Error al recargar producto %s tras cambiar una variante: %v", productID, err)
+		return
+	}
+	s.checkLowStock(product.ProductID)
+	s.notifyProductUpdate(product, "updated")
 }
 
 // IncrementPurchaseCount incrementa el contador de compras
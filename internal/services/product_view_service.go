@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/config"
+	"backend/internal/repositories"
+	"backend/internal/views"
+
+	"github.com/google/uuid"
+)
+
+// ProductViewService cuenta las vistas de productos: una por visitante y producto dentro de la
+// ventana configurada, acumuladas en memoria y guardadas por lotes por una tarea periódica
+type ProductViewService struct {
+	productRepo repositories.ProductRepository
+	buffer      *views.Buffer
+	config      *config.Config
+}
+
+// NewProductViewService crea un nuevo servicio de vistas de productos
+func NewProductViewService(productRepo repositories.ProductRepository, config *config.Config) *ProductViewService {
+	return &ProductViewService{
+		productRepo: productRepo,
+		buffer:      views.NewBuffer(config.Views.DedupWindow),
+		config:      config,
+	}
+}
+
+// RateLimit es la cantidad máxima de vistas que acepta cada IP por minuto (0 = sin límite)
+func (s *ProductViewService) RateLimit() int {
+	return s.config.Views.RateLimit
+}
+
+// RecordView registra la vista del producto por el visitante (usuario o dispositivo anónimo) e
+// indica si se contó; las vistas repetidas dentro de la ventana no se cuentan
+func (s *ProductViewService) RecordView(productID uuid.UUID, viewer string) (bool, error) {
+	if _, err := s.productRepo.FindByID(productID.String()); err != nil {
+		return false, ErrProductNotFoundService
+	}
+	return s.buffer.Record(productID, viewer, time.Now()), nil
+}
+
+// Flush guarda en un lote las vistas acumuladas. Si falla, las vistas vuelven al buffer para el
+// siguiente intento. Se ejecuta periódicamente y al apagar el servidor.
+func (s *ProductViewService) Flush(ctx context.Context) error {
+	counts := s.buffer.Drain(time.Now())
+	if len(counts) == 0 {
+		return nil
+	}
+
+	if err := s.productRepo.AddViewCounts(ctx, counts); err != nil {
+		s.buffer.Restore(counts)
+		return fmt.Errorf("error al guardar las vistas de %d productos: %w", len(counts), err)
+	}
+	log.Printf("Vistas de productos guardadas: %d productos", len(counts))
+	return nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/google/uuid"
+)
+
+// RecommendationService recomienda productos a partir de los pedidos: productos que se compran
+// juntos con frecuencia, calculados periódicamente en una tabla de asociaciones, y recomendaciones
+// personalizadas según la actividad de cada usuario
+type RecommendationService struct {
+	recommendationRepo repositories.RecommendationRepository
+	productRepo        repositories.ProductRepository
+}
+
+// NewRecommendationService crea un nuevo servicio de recomendaciones
+func NewRecommendationService(recommendationRepo repositories.RecommendationRepository, productRepo repositories.ProductRepository) *RecommendationService {
+	return &RecommendationService{
+		recommendationRepo: recommendationRepo,
+		productRepo:        productRepo,
+	}
+}
+
+// GetRelated obtiene los productos activos y con stock que se compran junto con el producto.
+// exclude omite productos, por ejemplo los que ya están en el carrito.
+func (s *RecommendationService) GetRelated(productID string, exclude []uuid.UUID, limit int) ([]models.RelatedProduct, error) {
+	if _, err := s.productRepo.FindByID(productID); err != nil {
+		return nil, ErrProductNotFoundService
+	}
+
+	associations, err := s.recommendationRepo.FindRelated(productID, exclude, models.NormalizeRelatedLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	related := make([]models.RelatedProduct, 0, len(associations))
+	for _, a := range associations {
+		related = append(related, models.RelatedProduct{
+			Product:      a.RelatedProduct,
+			SupportCount: a.SupportCount,
+			Confidence:   a.Confidence,
+			Lift:         a.Lift,
+		})
+	}
+	return related, nil
+}
+
+// GetRecommended obtiene los productos recomendados para el usuario en la pantalla de inicio. Sin
+// compras, favoritos ni calificaciones se recomiendan los productos populares.
+func (s *RecommendationService) GetRecommended(userID string, limit int) (*models.ProductRecommendations, error) {
+	limit = models.NormalizeRecommendedLimit(limit)
+
+	signals, err := s.recommendationRepo.FindUserSignals(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(signals) == 0 {
+		return s.popularRecommendations(limit)
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(signals))
+	for _, signal := range signals {
+		productIDs = append(productIDs, signal.ProductID)
+	}
+	associations, err := s.recommendationRepo.FindAssociationsFrom(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	candidateIDs, categoryIDs := models.RecommendationCandidateKeys(signals, associations)
+	candidates, err := s.recommendationRepo.FindCandidates(candidateIDs, categoryIDs, models.MaxRecommendationCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ProductRecommendations{
+		Personalized: true,
+		Products:     models.RecommendProducts(candidates, signals, associations, limit),
+	}, nil
+}
+
+// popularRecommendations son los productos populares, como en GetPopular, para usuarios sin actividad.
+// FindPopular ya excluye los productos inactivos, sin stock o eliminados.
+func (s *RecommendationService) popularRecommendations(limit int) (*models.ProductRecommendations, error) {
+	products, err := s.productRepo.FindPopular(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	recommended := make([]models.RecommendedProduct, 0, len(products))
+	for _, p := range products {
+		recommended = append(recommended, models.RecommendedProduct{
+			Product: p,
+			Score:   p.PopularityScore,
+			Reasons: []string{models.RecommendationReasonPopular},
+		})
+	}
+	return &models.ProductRecommendations{Personalized: false, Products: recommended}, nil
+}
+
+// RefreshAssociations recalcula las asociaciones con los pedidos entregados del periodo analizado
+func (s *RecommendationService) RefreshAssociations(ctx context.Context) error {
+	now := time.Now()
+	lines, err := s.recommendationRepo.FindBasketLines(now.Add(-models.AssociationWindow))
+	if err != nil {
+		return fmt.Errorf("error al obtener los pedidos para las asociaciones: %w", err)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	associations := models.ComputeProductAssociations(lines, models.AssociationMinSupport, now)
+	if err := s.recommendationRepo.ReplaceAssociations(associations); err != nil {
+		return fmt.Errorf("error al guardar las asociaciones: %w", err)
+	}
+	log.Printf("Asociaciones de productos recalculadas: %d", len(associations))
+	return nil
+}
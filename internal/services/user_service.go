@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"log"
+	"time"
 
 	"backend/internal/models"
 	"backend/internal/repositories"
@@ -106,6 +107,30 @@ func (s *UserService) GetUsersWithPagination(page, pageSize int, roleFilter stri
 	}, nil
 }
 
+// GetUsersWithCursor obtiene usuarios paginados por cursor, del más reciente al más antiguo
+func (s *UserService) GetUsersWithCursor(cursorValue string, limit int, roleFilter string) (*models.CursorPage[*models.User], error) {
+	cursor, err := models.DecodeCursor(cursorValue)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	limit = models.NormalizeCursorLimit(limit)
+
+	var role *models.UserRole
+	if roleFilter != "" {
+		roleValue := models.UserRole(roleFilter)
+		role = &roleValue
+	}
+
+	users, err := s.repo.FindAllWithCursor(cursor, limit, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewCursorPage(users, limit, func(u *models.User) (time.Time, string) {
+		return u.CreatedAt, u.UserID.String()
+	}), nil
+}
+
 // CreateUserAdmin crea un nuevo usuario (solo administradores)
 func (s *UserService) CreateUserAdmin(user *models.User, adminID string) error {
 	log.Printf("Admin %s creando nuevo usuario: %s (%s)", adminID, user.Email, user.UserRole)
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage guarda los archivos en un directorio servido por la propia API
+type LocalStorage struct {
+	dir       string
+	publicURL string
+}
+
+// NewLocalStorage crea un almacenamiento en disco; publicURL es la ruta o URL desde la que se sirve dir
+func NewLocalStorage(dir, publicURL string) *LocalStorage {
+	return &LocalStorage{dir: dir, publicURL: publicURL}
+}
+
+// Put escribe el archivo de forma atómica (archivo temporal y renombrado)
+func (s *LocalStorage) Put(key string, data []byte, contentType string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	return joinURL(s.publicURL, key), nil
+}
+
+// Delete elimina el archivo
+func (s *LocalStorage) Delete(key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Package storage guarda archivos públicos (como las imágenes de productos) en disco local o en un
+// bucket compatible con S3, y devuelve la URL pública de cada archivo.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"backend/config"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+var ErrInvalidKey = errors.New("clave de archivo inválida")
+
+// Storage es el almacenamiento de archivos públicos
+type Storage interface {
+	// Put guarda el archivo bajo la clave dada (p. ej. "products/<id>/thumb.webp") y devuelve su URL pública
+	Put(key string, data []byte, contentType string) (string, error)
+	// Delete elimina el archivo; no falla si no existe
+	Delete(key string) error
+}
+
+// New crea el almacenamiento configurado
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "", DriverLocal:
+		return NewLocalStorage(cfg.LocalDir, cfg.PublicURL), nil
+	case DriverS3:
+		return NewS3Storage(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PublicURL: cfg.PublicURL,
+			PathStyle: cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("driver de almacenamiento desconocido: %s", cfg.Driver)
+	}
+}
+
+// validateKey rechaza claves vacías, absolutas o que intenten salir del directorio base
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// joinURL une la URL base y la clave con una sola barra
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
@@ -0,0 +1,85 @@
+// Package views acumula en memoria las vistas de productos: descarta las repetidas de un mismo
+// visitante dentro de una ventana de tiempo y las entrega por lotes para guardarlas.
+package views
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Buffer cuenta las vistas pendientes de guardar por producto. Es seguro para uso concurrente.
+type Buffer struct {
+	mu       sync.Mutex
+	window   time.Duration
+	lastSeen map[string]time.Time // Última vista contada por visitante y producto
+	pending  map[uuid.UUID]int
+}
+
+// NewBuffer crea un buffer que cuenta una sola vista por visitante y producto cada window.
+// Una ventana menor o igual a 0 cuenta todas las vistas.
+func NewBuffer(window time.Duration) *Buffer {
+	return &Buffer{
+		window:   window,
+		lastSeen: make(map[string]time.Time),
+		pending:  make(map[uuid.UUID]int),
+	}
+}
+
+// Record registra la vista del producto por el visitante (usuario o dispositivo) e indica si se
+// contó; no se cuenta si el mismo visitante ya vio el producto dentro de la ventana.
+func (b *Buffer) Record(productID uuid.UUID, viewer string, now time.Time) bool {
+	key := viewer + "|" + productID.String()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if seen, ok := b.lastSeen[key]; ok && now.Sub(seen) < b.window {
+		return false
+	}
+	if b.window > 0 {
+		b.lastSeen[key] = now
+	}
+	b.pending[productID]++
+	return true
+}
+
+// Drain entrega las vistas pendientes y las quita del buffer. También olvida a los visitantes
+// cuya ventana ya venció, para que la memoria no crezca indefinidamente.
+func (b *Buffer) Drain(now time.Time) map[uuid.UUID]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for key, seen := range b.lastSeen {
+		if now.Sub(seen) >= b.window {
+			delete(b.lastSeen, key)
+		}
+	}
+
+	pending := b.pending
+	b.pending = make(map[uuid.UUID]int)
+	return pending
+}
+
+// Restore devuelve al buffer vistas que no se pudieron guardar, para reintentarlas en el siguiente lote
+func (b *Buffer) Restore(counts map[uuid.UUID]int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for productID, views := range counts {
+		b.pending[productID] += views
+	}
+}
+
+// Pending es la cantidad de vistas pendientes de guardar
+func (b *Buffer) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	total := 0
+	for _, views := range b.pending {
+		total += views
+	}
+	return total
+}
@@ -1,6 +1,7 @@
 package ws
 
 import (
+	"encoding/json"
 	"log"
 	"time"
 
@@ -11,21 +12,26 @@ const (
 	writeWait      = 10 * time.Second
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 512
+	maxMessageSize = 4096
+	// Mensajes recibidos que pueden esperar a ser procesados; si el cliente envía más, se descartan
+	incomingQueueSize = 32
 )
 
 // Client representa una conexión WebSocket activa.
 type Client struct {
-	conn   *websocket.Conn
-	userID string
-	role   string
-	send   chan Message
-	hub    *Hub
+	conn     *websocket.Conn
+	userID   string
+	role     string
+	send     chan Message
+	incoming chan Message // Mensajes leídos que HandlePump entrega al hub
+	hub      *Hub
 }
 
-// ReadPump lee mensajes entrantes (no usados en MVP, pero útil para chat futuro).
+// ReadPump lee mensajes entrantes y los deja en la cola de HandlePump. No los procesa aquí: un
+// manejador lento (consultas a la base) frenaría la lectura de pongs y la conexión se cerraría.
 func (c *Client) ReadPump() {
 	defer func() {
+		close(c.incoming)
 		c.hub.unregister <- c
 		c.conn.Close()
 	}()
@@ -36,14 +42,32 @@ func (c *Client) ReadPump() {
 		return nil
 	})
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, data, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("error: %v", err)
 			}
 			break
 		}
-		// Para MVP, no procesamos mensajes entrantes.
+
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Printf("[WebSocket] Mensaje inválido de userID=%s: %v", c.userID, err)
+			continue
+		}
+		select {
+		case c.incoming <- msg:
+		default:
+			log.Printf("[WebSocket] Cola de mensajes llena para userID=%s, mensaje tipo '%s' descartado", c.userID, msg.Type)
+		}
+	}
+}
+
+// HandlePump entrega al manejador registrado en el hub (chat) los mensajes que leyó ReadPump, en
+// el orden en que llegaron. Termina cuando ReadPump cierra la cola.
+func (c *Client) HandlePump() {
+	for msg := range c.incoming {
+		c.hub.handleIncoming(c, msg)
 	}
 }
 
@@ -23,16 +23,18 @@ func WebSocketHandler(hub *Hub, cfg *config.Config) fiber.Handler {
 		log.Printf("[WebSocket] Conexión aceptada para userID=%s, role=%s", userID, role)
 
 		client := &Client{
-			conn:   c,
-			userID: userID,
-			role:   role,
-			send:   make(chan Message, 256),
-			hub:    hub,
+			conn:     c,
+			userID:   userID,
+			role:     role,
+			send:     make(chan Message, 256),
+			incoming: make(chan Message, incomingQueueSize),
+			hub:      hub,
 		}
 		hub.register <- client
 
-		// 3. Iniciar goroutines de lectura y escritura
+		// 3. Iniciar goroutines de escritura y de procesamiento, y leer en esta
 		go client.WritePump()
+		go client.HandlePump()
 		client.ReadPump()
 		log.Printf("[WebSocket] Conexión cerrada para userID=%s, role=%s", userID, role)
 	})
@@ -5,6 +5,9 @@ import (
 	"sync"
 )
 
+// MessageHandler procesa los mensajes que envían los clientes conectados.
+type MessageHandler func(userID, role string, msg Message)
+
 // Hub gestiona todas las conexiones activas y el broadcast de mensajes.
 type Hub struct {
 	clients    map[string]*Client            // key: userID
@@ -12,6 +15,7 @@ type Hub struct {
 	register   chan *Client
 	unregister chan *Client
 	broadcast  chan Message
+	onMessage  MessageHandler
 	mu         sync.RWMutex
 }
 
@@ -120,3 +124,22 @@ func (h *Hub) SendToRole(role string, msg Message) {
 func (h *Hub) Broadcast(msg Message) {
 	h.broadcast <- msg
 }
+
+// SetMessageHandler registra el manejador de mensajes entrantes (por ejemplo, el chat de pedidos).
+func (h *Hub) SetMessageHandler(handler MessageHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onMessage = handler
+}
+
+// handleIncoming entrega un mensaje entrante al manejador registrado, si existe.
+func (h *Hub) handleIncoming(client *Client, msg Message) {
+	h.mu.RLock()
+	handler := h.onMessage
+	h.mu.RUnlock()
+
+	if handler == nil {
+		return
+	}
+	handler(client.userID, client.role, msg)
+}
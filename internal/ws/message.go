@@ -12,7 +12,11 @@ const (
 	NewOrderAvailable MessageType = "new_order_available"
 	CategoryUpdate    MessageType = "category_update"
 	ProductUpdate     MessageType = "product_update"
-	ChatMessage       MessageType = "chat_message" // Futuro
+	TipReceived       MessageType = "tip_received"
+	ChatMessage       MessageType = "chat_message"
+	ChatRead          MessageType = "chat_read"
+	ChatError         MessageType = "chat_error"
+	LowStockAlert     MessageType = "low_stock_alert"
 )
 
 type Message struct {
@@ -46,6 +50,34 @@ type ProductUpdatePayload struct {
 	Product   string `json:"product,omitempty"`
 }
 
+// LowStockAlertPayload avisa a los administradores que un producto bajó de su umbral de reposición
+type LowStockAlertPayload struct {
+	ProductID        string `json:"product_id"`
+	Name             string `json:"name"`
+	StockQuantity    int    `json:"stock_quantity"`
+	ReorderThreshold int    `json:"reorder_threshold"`
+}
+
+// ChatMessagePayload es el mensaje de chat que envía el cliente por el WebSocket
+type ChatMessagePayload struct {
+	OrderID string `json:"order_id"`
+	Content string `json:"content"`
+}
+
+// ChatReadPayload indica que un usuario leyó los mensajes de un pedido
+type ChatReadPayload struct {
+	OrderID  string `json:"order_id"`
+	ReaderID string `json:"reader_id,omitempty"`
+	ReadAt   string `json:"read_at,omitempty"`
+	Count    int64  `json:"count,omitempty"`
+}
+
+// ChatErrorPayload informa al remitente que su mensaje no pudo procesarse
+type ChatErrorPayload struct {
+	OrderID string `json:"order_id,omitempty"`
+	Error   string `json:"error"`
+}
+
 // MustMarshalPayload serializa un struct a json.RawMessage y hace log si falla.
 func MustMarshalPayload(v interface{}) json.RawMessage {
 	b, err := json.Marshal(v)
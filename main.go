@@ -1,20 +1,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 
 	v1 "backend/api/v1"
+	"backend/api/v1/middlewares"
 	"backend/config"
 	"backend/database"
 	"backend/docs"
 	"backend/internal/auth"
+	"backend/internal/jobs"
 	"backend/internal/repositories"
 	"backend/internal/services"
+	"backend/internal/storage"
 	"backend/internal/ws"
 
 	"github.com/gofiber/fiber/v2"
@@ -62,6 +67,26 @@ func main() {
 	productRatingRepo := repositories.NewProductRatingRepository(db)
 	favoriteRepo := repositories.NewFavoriteRepository(db)
 	offerRepo := repositories.NewOfferRepository(db)
+	cashSettlementRepo := repositories.NewCashSettlementRepository(db)
+	deliveryRatingRepo := repositories.NewDeliveryRatingRepository(db)
+	orderMessageRepo := repositories.NewOrderMessageRepository(db)
+	addressRepo := repositories.NewAddressRepository(db)
+	cylinderRepo := repositories.NewCylinderRepository(db)
+	analyticsRepo := repositories.NewAnalyticsRepository(db)
+	forecastRepo := repositories.NewForecastRepository(db)
+	businessCalendarRepo := repositories.NewBusinessCalendarRepository(db)
+	branchRepo := repositories.NewBranchRepository(db)
+	productImageRepo := repositories.NewProductImageRepository(db)
+	stockMovementRepo := repositories.NewStockMovementRepository(db)
+	productPriceRepo := repositories.NewProductPriceRepository(db)
+	popularityRepo := repositories.NewPopularityRepository(db)
+	recommendationRepo := repositories.NewRecommendationRepository(db)
+
+	// Almacenamiento de imágenes (disco local o bucket S3)
+	fileStorage, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Error al configurar el almacenamiento de archivos: %v", err)
+	}
 
 	// Inicializar servicios básicos
 	authService := auth.NewService(db, cfg)
@@ -76,6 +101,10 @@ func main() {
 	//     log.Println("Las notificaciones estarán desactivadas")
 	// }
 
+	// El horario de atención se administra desde la base de datos
+	businessCalendarService := services.NewBusinessCalendarService(businessCalendarRepo, cfg)
+	branchService := services.NewBranchService(branchRepo, userRepo, productRepo, cfg)
+
 	// Inicializar WebSocket hub
 	hub := ws.NewHub()
 	go hub.Run()
@@ -83,21 +112,70 @@ func main() {
 	// Servicios que requieren WebSocket hub
 	categoryService := services.NewCategoryService(categoryRepo, hub)
 	productService := services.NewProductService(productRepo, hub)
+	inventoryService := services.NewInventoryService(stockMovementRepo, productRepo, hub)
+	productService.SetInventoryService(inventoryService)
+	productPriceService := services.NewProductPriceService(productPriceRepo, productService)
+	productImageService := services.NewProductImageService(productImageRepo, productService, fileStorage, cfg.Storage.MaxUploadBytes)
 	orderService := services.NewOrderService(orderRepo, userRepo, productRepo, notificationService, cfg, hub)
+	orderService.SetBusinessCalendar(businessCalendarService)
+	orderService.SetBranchService(branchService)
+	orderService.SetInventoryService(inventoryService)
 	favoriteService := services.NewFavoriteService(favoriteRepo, productRepo, userRepo, hub)
 	offerService := services.NewOfferService(offerRepo, userRepo, productRepo)
+	cashService := services.NewCashService(orderRepo, userRepo, cashSettlementRepo, cfg)
+	earningsService := services.NewEarningsService(orderRepo, userRepo, cfg)
+	deliveryRatingService := services.NewDeliveryRatingService(deliveryRatingRepo, orderRepo, userRepo)
+	dispatchService := services.NewDispatchService(orderRepo, userRepo, deliveryRatingService)
+	orderService.SetDispatchService(dispatchService)
+	chatService := services.NewChatService(orderMessageRepo, orderRepo, hub)
+	addressService := services.NewAddressService(addressRepo)
+	cylinderService := services.NewCylinderService(cylinderRepo, cfg)
+	analyticsService := services.NewAnalyticsService(analyticsRepo, cfg)
+	performanceService := services.NewPerformanceService(orderRepo, deliveryRatingRepo, userRepo, cfg)
+	forecastService := services.NewForecastService(forecastRepo, productRepo, cfg)
+	popularityService := services.NewPopularityService(popularityRepo, cfg)
+	recommendationService := services.NewRecommendationService(recommendationRepo, productRepo)
+	productViewService := services.NewProductViewService(productRepo, cfg)
+
+	// Los mensajes de chat entrantes por WebSocket se procesan en el servicio de chat
+	hub.SetMessageHandler(chatService.HandleWebSocketMessage)
+
+	// Tareas en segundo plano
+	jobRunner := jobs.NewRunner()
+	jobRunner.Every("precios programados", cfg.Jobs.ScheduledPriceInterval, productPriceService.ApplyDuePriceChanges)
+	jobRunner.Every("popularidad de productos", cfg.Jobs.PopularityInterval, popularityService.RecomputeScores)
+	jobRunner.Every("productos comprados juntos", cfg.Jobs.AssociationsInterval, recommendationService.RefreshAssociations)
+	jobRunner.Every("vistas de productos", cfg.Jobs.ViewFlushInterval, productViewService.Flush)
 
 	// Crear la aplicación Fiber
 	app := fiber.New(fiber.Config{
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
+		// Se guardan en memoria como máximo BodyLimit bytes; el resto del cuerpo queda en la conexión y
+		// cada ruta lo lee con su límite (middlewares.BodyLimit, o la subida de imágenes tras autenticar)
+		BodyLimit:                    fiber.DefaultBodyLimit,
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+		// Detrás de un proxy, c.IP() toma la IP del cliente de esta cabecera (límite de vistas por IP),
+		// solo si la petición llega desde un proxy de confianza: cualquier otro cliente podría falsificarla
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		EnableIPValidation:      true,
 	})
 
 	// Registrar middlewares globales
 	app.Use(logger.New())
 	app.Use(recover.New())
 
+	// Límite de Fiber para todas las rutas salvo la subida de imágenes, que lee su cuerpo con un
+	// límite mayor recién después de verificar que la hace un administrador
+	app.Use(middlewares.BodyLimit(fiber.DefaultBodyLimit, func(c *fiber.Ctx) bool {
+		return c.Method() == fiber.MethodPost &&
+			strings.HasPrefix(c.Path(), "/api/v1/products/") && strings.HasSuffix(c.Path(), "/images")
+	}))
+
 	// Configurar CORS para permitir conexiones desde aplicaciones móviles
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "*",
@@ -108,7 +186,7 @@ func main() {
 	}))
 
 	// Configurar rutas de la API
-	v1.SetupRoutes(app, authService, userService, productService, categoryService, orderService, productRatingService, favoriteService, offerService)
+	v1.SetupRoutes(app, authService, userService, productService, categoryService, orderService, productRatingService, favoriteService, offerService, cashService, earningsService, deliveryRatingService, chatService, addressService, cylinderService, analyticsService, performanceService, forecastService, businessCalendarService, branchService, productImageService, inventoryService, productPriceService, popularityService, recommendationService, productViewService)
 
 	// Endpoint de salud para verificar que el servidor está funcionando
 	app.Get("/api/v1/health", func(c *fiber.Ctx) error {
@@ -118,6 +196,13 @@ func main() {
 		})
 	})
 
+	// Imágenes subidas cuando se guardan en disco local
+	if cfg.Storage.Driver == storage.DriverLocal {
+		app.Static(cfg.Storage.LocalPublicPath(), cfg.Storage.LocalDir, fiber.Static{
+			MaxAge: 31536000, // Las claves incluyen el ID de la imagen: el contenido nunca cambia
+		})
+	}
+
 	// --- INICIO WEBSOCKET ---
 	app.Get("/ws/notifications", ws.WebSocketHandler(hub, cfg))
 	// --- FIN WEBSOCKET ---
@@ -162,12 +247,23 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 
+	// Guardar las vistas que quedaron en memoria; se llama cuando el servidor ya no atiende peticiones
+	flushViews := func() {
+		if err := productViewService.Flush(context.Background()); err != nil {
+			log.Printf("Error al guardar las vistas pendientes: %v", err)
+		}
+	}
+
+	shutdownDone := make(chan struct{})
 	go func() {
 		<-quit
 		log.Println("Apagando servidor...")
-		if err := app.Shutdown(); err != nil {
-			log.Fatalf("Error al apagar servidor: %v", err)
+		jobRunner.Stop()
+		// Shutdown espera a que terminen las peticiones en curso, que todavía pueden registrar vistas
+		if err := app.ShutdownWithTimeout(cfg.Server.ShutdownTimeout); err != nil {
+			log.Printf("Error al apagar servidor: %v", err)
 		}
+		close(shutdownDone)
 	}()
 
 	// Iniciar servidor
@@ -175,6 +271,12 @@ func main() {
 	log.Printf("Servidor iniciado en http://localhost%s", port)
 	log.Printf("Documentación Swagger disponible en http://localhost%s/swagger", port)
 	if err := app.Listen(port); err != nil {
-		log.Fatalf("Error al iniciar servidor: %v", err)
+		log.Printf("Error al iniciar servidor: %v", err)
+		flushViews()
+		os.Exit(1)
 	}
+
+	// Listen vuelve al cerrar el listener, antes de que terminen las peticiones en curso
+	<-shutdownDone
+	flushViews()
 }
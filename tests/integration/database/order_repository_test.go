@@ -253,6 +253,38 @@ func (suite *OrderRepositoryTestSuite) TestAssignRepartidor() {
 	assert.False(suite.T(), updatedOrder.AssignedAt.IsZero())
 }
 
+func (suite *OrderRepositoryTestSuite) TestMarkAssigned_OnlyOnce() {
+	order := &models.Order{
+		OrderID:             uuid.New(),
+		ClientID:            suite.testClient.UserID,
+		OrderTime:           time.Now(),
+		OrderStatus:         models.OrderStatusConfirmed,
+		TotalAmount:         25.50,
+		DeliveryAddressText: "Test Address 123",
+		Latitude:            -12.0464,
+		Longitude:           -77.0428,
+	}
+	require.NoError(suite.T(), suite.orderRepo.Create(order))
+
+	// La asignación cambia el repartidor y el estado a la vez
+	err := suite.orderRepo.MarkAssigned(order.OrderID.String(), suite.testRepartidor.UserID.String())
+	require.NoError(suite.T(), err)
+
+	updatedOrder, err := suite.orderRepo.FindByID(order.OrderID.String())
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.OrderStatusAssigned, updatedOrder.OrderStatus)
+	assert.Equal(suite.T(), suite.testRepartidor.UserID, *updatedOrder.AssignedRepartidorID)
+	assert.NotNil(suite.T(), updatedOrder.AssignedAt)
+
+	// Una segunda asignación (por ejemplo, simultánea) no actualiza el pedido
+	err = suite.orderRepo.MarkAssigned(order.OrderID.String(), suite.testClient.UserID.String())
+	assert.ErrorIs(suite.T(), err, models.ErrOrderAlreadyAssigned)
+
+	updatedOrder, err = suite.orderRepo.FindByID(order.OrderID.String())
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.testRepartidor.UserID, *updatedOrder.AssignedRepartidorID)
+}
+
 func (suite *OrderRepositoryTestSuite) TestFindByRepartidorID() {
 	// Create order and assign repartidor
 	order := &models.Order{
@@ -489,6 +521,80 @@ func (suite *OrderRepositoryTestSuite) TestOrderConstraints() {
 	suite.orderRepo.Delete(invalidOrder.OrderID.String())
 }
 
+func (suite *OrderRepositoryTestSuite) TestFindForExport_BranchAdminSeesOnlyOwnBranch() {
+	branchA, branchB := uuid.New(), uuid.New()
+	branchAdmin := &models.User{
+		UserID:       uuid.New(),
+		Email:        "branch-admin@test.com",
+		PasswordHash: "hashedpassword",
+		FullName:     "Branch Admin",
+		PhoneNumber:  "+51999999003",
+		UserRole:     models.UserRoleAdmin,
+		BranchID:     &branchA,
+	}
+	require.NoError(suite.T(), suite.userRepo.Create(branchAdmin))
+	defer suite.db.Exec("DELETE FROM users WHERE user_id = ?", branchAdmin.UserID)
+
+	now := time.Now()
+	orderA := &models.Order{
+		OrderID:             uuid.New(),
+		ClientID:            suite.testClient.UserID,
+		BranchID:            &branchA,
+		OrderTime:           now,
+		OrderStatus:         models.OrderStatusPending,
+		TotalAmount:         25.50,
+		DeliveryAddressText: "Branch A Address",
+		Latitude:            -12.0464,
+		Longitude:           -77.0428,
+	}
+	orderB := &models.Order{
+		OrderID:             uuid.New(),
+		ClientID:            suite.testClient.UserID,
+		BranchID:            &branchB,
+		OrderTime:           now,
+		OrderStatus:         models.OrderStatusPending,
+		TotalAmount:         30.00,
+		DeliveryAddressText: "Branch B Address",
+		Latitude:            -12.0464,
+		Longitude:           -77.0428,
+	}
+	require.NoError(suite.T(), suite.orderRepo.Create(orderA))
+	require.NoError(suite.T(), suite.orderRepo.Create(orderB))
+
+	exported := func(userID string) []uuid.UUID {
+		filter := models.OrderExportFilter{
+			From:   now.Add(-time.Hour),
+			To:     now.Add(time.Hour),
+			UserID: userID,
+		}
+		var ids []uuid.UUID
+		err := suite.orderRepo.FindForExport(filter, 10, func(orders []*models.Order) error {
+			for _, order := range orders {
+				ids = append(ids, order.OrderID)
+			}
+			return nil
+		})
+		require.NoError(suite.T(), err)
+		return ids
+	}
+
+	// El admin de la sucursal A solo exporta los pedidos de su sucursal
+	assert.Equal(suite.T(), []uuid.UUID{orderA.OrderID}, exported(branchAdmin.UserID.String()))
+
+	// Un admin sin sucursal exporta los de todas
+	globalAdmin := &models.User{
+		UserID:       uuid.New(),
+		Email:        "global-admin@test.com",
+		PasswordHash: "hashedpassword",
+		FullName:     "Global Admin",
+		PhoneNumber:  "+51999999004",
+		UserRole:     models.UserRoleAdmin,
+	}
+	require.NoError(suite.T(), suite.userRepo.Create(globalAdmin))
+	defer suite.db.Exec("DELETE FROM users WHERE user_id = ?", globalAdmin.UserID)
+	assert.ElementsMatch(suite.T(), []uuid.UUID{orderA.OrderID, orderB.OrderID}, exported(globalAdmin.UserID.String()))
+}
+
 // TestOrderRepositoryTestSuite runs the test suite
 func TestOrderRepositoryTestSuite(t *testing.T) {
 	suite.Run(t, new(OrderRepositoryTestSuite))
@@ -0,0 +1,191 @@
+package database
+
+import (
+	"backend/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// StockLedgerTestSuite verifica el trigger de stock de los pedidos; requiere las migraciones SQL aplicadas
+type StockLedgerTestSuite struct {
+	suite.Suite
+	db          *gorm.DB
+	orderRepo   repositories.OrderRepository
+	productRepo repositories.ProductRepository
+	userRepo    repositories.UserRepository
+	testClient  *models.User
+}
+
+// SetupSuite runs once before the test suite
+func (suite *StockLedgerTestSuite) SetupSuite() {
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{
+			Host:     "localhost",
+			Port:     "5432",
+			User:     "postgres",
+			Password: "postgres",
+			DBName:   "exactogas_test",
+			SSLMode:  "disable",
+		},
+	}
+
+	var err error
+	suite.db, err = gorm.Open(postgres.Open(cfg.Database.GetDSN()), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		suite.T().Skip("PostgreSQL no disponible para pruebas")
+		return
+	}
+
+	var migrated bool
+	suite.db.Raw("SELECT to_regproc('order_stock_lines') IS NOT NULL").Scan(&migrated)
+	if !migrated {
+		suite.T().Skip("Migraciones SQL no aplicadas en la base de pruebas")
+		return
+	}
+
+	suite.orderRepo = repositories.NewOrderRepository(suite.db)
+	suite.productRepo = repositories.NewProductRepository(suite.db)
+	suite.userRepo = repositories.NewUserRepository(suite.db)
+
+	suite.testClient = &models.User{
+		UserID:       uuid.New(),
+		Email:        "ledger-client@test.com",
+		PasswordHash: "hashedpassword",
+		FullName:     "Test Client",
+		PhoneNumber:  "+51999999101",
+		UserRole:     models.UserRoleClient,
+	}
+	require.NoError(suite.T(), suite.userRepo.Create(suite.testClient))
+}
+
+// TearDownSuite runs once after the test suite
+func (suite *StockLedgerTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		suite.db.Exec("TRUNCATE TABLE stock_movements, order_items, orders RESTART IDENTITY CASCADE")
+		if suite.testClient != nil {
+			suite.db.Exec("DELETE FROM users WHERE user_id = ?", suite.testClient.UserID)
+		}
+		sqlDB, _ := suite.db.DB()
+		sqlDB.Close()
+	}
+}
+
+func (suite *StockLedgerTestSuite) createOrder(product *models.Product, quantity int) *models.Order {
+	order := &models.Order{
+		OrderID:             uuid.New(),
+		ClientID:            suite.testClient.UserID,
+		OrderTime:           time.Now(),
+		OrderStatus:         models.OrderStatusPending,
+		TotalAmount:         product.Price * float64(quantity),
+		DeliveryAddressText: "Test Address 123",
+		Latitude:            -12.0464,
+		Longitude:           -77.0428,
+	}
+	require.NoError(suite.T(), suite.orderRepo.Create(order))
+
+	item := &models.OrderItem{
+		OrderID:   order.OrderID,
+		ProductID: product.ProductID,
+		Quantity:  quantity,
+		UnitPrice: product.Price,
+		Subtotal:  product.Price * float64(quantity),
+	}
+	require.NoError(suite.T(), suite.db.Omit("Product", "Variant").Create(item).Error)
+	return order
+}
+
+func (suite *StockLedgerTestSuite) orderMovements(orderID uuid.UUID) []models.StockMovement {
+	var movements []models.StockMovement
+	require.NoError(suite.T(), suite.db.Where("order_id = ?", orderID).Order("created_at").Find(&movements).Error)
+	return movements
+}
+
+func (suite *StockLedgerTestSuite) productStock(productID uuid.UUID) int {
+	product, err := suite.productRepo.FindByID(productID.String())
+	require.NoError(suite.T(), err)
+	return product.StockQuantity
+}
+
+func (suite *StockLedgerTestSuite) TestCancelConfirmedOrder_ReturnsStock() {
+	product := &models.Product{
+		ProductID:     uuid.New(),
+		Name:          "Balón ledger 10kg",
+		Price:         40,
+		StockQuantity: 10,
+		IsActive:      true,
+	}
+	require.NoError(suite.T(), suite.productRepo.Create(product))
+	order := suite.createOrder(product, 3)
+
+	require.NoError(suite.T(), suite.orderRepo.UpdateStatus(order.OrderID.String(), models.OrderStatusConfirmed))
+	assert.Equal(suite.T(), 7, suite.productStock(product.ProductID), "La confirmación descuenta el stock")
+
+	require.NoError(suite.T(), suite.orderRepo.UpdateStatus(order.OrderID.String(), models.OrderStatusCancelled))
+	assert.Equal(suite.T(), 10, suite.productStock(product.ProductID), "La cancelación repone el stock")
+
+	movements := suite.orderMovements(order.OrderID)
+	require.Len(suite.T(), movements, 2)
+	assert.Equal(suite.T(), models.StockMovementSale, movements[0].MovementType)
+	assert.Equal(suite.T(), -3, movements[0].Quantity)
+	assert.Equal(suite.T(), models.StockMovementCancellationReturn, movements[1].MovementType)
+	assert.Equal(suite.T(), 3, movements[1].Quantity)
+	assert.Equal(suite.T(), 10, movements[1].BalanceAfter)
+}
+
+func (suite *StockLedgerTestSuite) TestOrderLifecycle_TakesStockOnce() {
+	product := &models.Product{
+		ProductID:     uuid.New(),
+		Name:          "Balón ledger 5kg",
+		Price:         25,
+		StockQuantity: 5,
+		IsActive:      true,
+	}
+	require.NoError(suite.T(), suite.productRepo.Create(product))
+	order := suite.createOrder(product, 2)
+
+	for _, status := range []models.OrderStatus{
+		models.OrderStatusConfirmed,
+		models.OrderStatusAssigned,
+		models.OrderStatusInTransit,
+		models.OrderStatusDelivered,
+	} {
+		require.NoError(suite.T(), suite.orderRepo.UpdateStatus(order.OrderID.String(), status))
+	}
+
+	assert.Equal(suite.T(), 3, suite.productStock(product.ProductID))
+	movements := suite.orderMovements(order.OrderID)
+	require.Len(suite.T(), movements, 1)
+	assert.Equal(suite.T(), models.StockMovementSale, movements[0].MovementType)
+}
+
+func (suite *StockLedgerTestSuite) TestCancelPendingOrder_WritesNoMovement() {
+	product := &models.Product{
+		ProductID:     uuid.New(),
+		Name:          "Balón ledger 15kg",
+		Price:         60,
+		StockQuantity: 4,
+		IsActive:      true,
+	}
+	require.NoError(suite.T(), suite.productRepo.Create(product))
+	order := suite.createOrder(product, 1)
+
+	require.NoError(suite.T(), suite.orderRepo.UpdateStatus(order.OrderID.String(), models.OrderStatusCancelled))
+	assert.Equal(suite.T(), 4, suite.productStock(product.ProductID))
+	assert.Empty(suite.T(), suite.orderMovements(order.OrderID))
+}
+
+func TestStockLedgerTestSuite(t *testing.T) {
+	suite.Run(t, new(StockLedgerTestSuite))
+}
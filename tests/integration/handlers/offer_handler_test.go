@@ -91,7 +91,7 @@ func (suite *OfferHandlerTestSuite) SetupSuite() {
 
 	// Setup routes
 	categoryService := services.NewCategoryService(categoryRepo, nil)
-	v1.SetupRoutes(suite.app, suite.authService, suite.userService, suite.productService, categoryService, nil, nil, nil, suite.offerService)
+	v1.SetupRoutes(suite.app, suite.authService, suite.userService, suite.productService, categoryService, nil, nil, nil, suite.offerService, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 }
 
 // SetupTest runs before each test
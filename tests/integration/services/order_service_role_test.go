@@ -311,7 +311,7 @@ func (suite *OrderServiceRoleTestSuite) TestOrderStatusTransitions_ByRole() {
 	assert.NotNil(suite.T(), confirmedOrder.ConfirmedAt)
 
 	// Test 4: ADMIN can assign repartidor
-	assignedOrder, err := suite.orderService.AssignRepartidor(orderID, suite.repartidorUser.UserID.String())
+	assignedOrder, err := suite.orderService.AssignRepartidor(orderID, suite.repartidorUser.UserID.String(), suite.adminUser.UserID.String(), suite.adminUser.UserRole)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), models.OrderStatusAssigned, assignedOrder.OrderStatus)
 	assert.Equal(suite.T(), suite.repartidorUser.UserID, *assignedOrder.AssignedRepartidorID)
@@ -449,12 +449,12 @@ func (suite *OrderServiceRoleTestSuite) TestSetEstimatedArrivalTime_Permissions(
 	)
 	require.NoError(suite.T(), err)
 
-	assignedOrder, err := suite.orderService.AssignRepartidor(order.OrderID.String(), suite.repartidorUser.UserID.String())
+	assignedOrder, err := suite.orderService.AssignRepartidor(order.OrderID.String(), suite.repartidorUser.UserID.String(), suite.adminUser.UserID.String(), suite.adminUser.UserRole)
 	require.NoError(suite.T(), err)
 
 	// Test ETA setting by assigned repartidor
 	eta := time.Now().Add(30 * time.Minute)
-	updatedOrder, err := suite.orderService.SetEstimatedArrivalTime(assignedOrder.OrderID.String(), eta)
+	updatedOrder, err := suite.orderService.SetEstimatedArrivalTime(assignedOrder.OrderID.String(), eta, suite.repartidorUser.UserID.String(), suite.repartidorUser.UserRole)
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), updatedOrder.EstimatedArrivalTime)
 	assert.True(suite.T(), updatedOrder.EstimatedArrivalTime.Equal(eta))
@@ -606,7 +606,7 @@ func (suite *OrderServiceRoleTestSuite) TestCompleteOrderWorkflow() {
 	assert.Equal(suite.T(), models.OrderStatusConfirmed, order.OrderStatus)
 
 	// Step 3: Admin assigns repartidor
-	order, err = suite.orderService.AssignRepartidor(orderID, suite.repartidorUser.UserID.String())
+	order, err = suite.orderService.AssignRepartidor(orderID, suite.repartidorUser.UserID.String(), suite.adminUser.UserID.String(), suite.adminUser.UserRole)
 	require.NoError(suite.T(), err)
 	assert.Equal(suite.T(), models.OrderStatusAssigned, order.OrderStatus)
 	assert.Equal(suite.T(), suite.repartidorUser.UserID, *order.AssignedRepartidorID)
@@ -623,7 +623,7 @@ func (suite *OrderServiceRoleTestSuite) TestCompleteOrderWorkflow() {
 
 	// Step 5: Repartidor sets ETA
 	eta := time.Now().Add(25 * time.Minute)
-	order, err = suite.orderService.SetEstimatedArrivalTime(orderID, eta)
+	order, err = suite.orderService.SetEstimatedArrivalTime(orderID, eta, suite.repartidorUser.UserID.String(), suite.repartidorUser.UserRole)
 	require.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), order.EstimatedArrivalTime)
 
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"backend/internal/export"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	format, err := export.ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, export.FormatCSV, format)
+
+	format, err = export.ParseFormat("XLSX")
+	require.NoError(t, err)
+	assert.Equal(t, export.FormatXLSX, format)
+
+	_, err = export.ParseFormat("pdf")
+	assert.ErrorIs(t, err, export.ErrUnsupportedFormat)
+}
+
+func TestCSVRowWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := export.NewRowWriter(export.FormatCSV, &buf, "")
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRow([]interface{}{"producto", "cantidad", "precio"}))
+	require.NoError(t, writer.WriteRow([]interface{}{"Balón, 10kg", 2, 50.5}))
+	require.NoError(t, writer.Close())
+
+	content := strings.TrimPrefix(buf.String(), "\xEF\xBB\xBF")
+	assert.Equal(t, "producto,cantidad,precio\n\"Balón, 10kg\",2,50.50\n", content)
+}
+
+func TestCSVRowWriter_EscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := export.NewRowWriter(export.FormatCSV, &buf, "")
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRow([]interface{}{"=HYPERLINK(\"http://x\")", "+51 999", "-2", "@SUM(A1)", "Av. Perú 123", -3.5}))
+	require.NoError(t, writer.Close())
+
+	content := strings.TrimPrefix(buf.String(), "\xEF\xBB\xBF")
+	assert.Equal(t, "\"'=HYPERLINK(\"\"http://x\"\")\",'+51 999,'-2,'@SUM(A1),Av. Perú 123,-3.50\n", content)
+}
+
+func TestUnescapeFormula(t *testing.T) {
+	assert.Equal(t, "=SUM(A1)", export.UnescapeFormula("'=SUM(A1)"))
+	assert.Equal(t, "-2", export.UnescapeFormula("'-2"))
+	assert.Equal(t, "'hola", export.UnescapeFormula("'hola"), "solo quita el escape de una fórmula")
+	assert.Equal(t, "'", export.UnescapeFormula("'"))
+}
+
+func TestXLSXRowWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := export.NewRowWriter(export.FormatXLSX, &buf, "Pedidos")
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRow([]interface{}{"producto", "cantidad"}))
+	row := make([]interface{}, 28)
+	row[0] = "Gas <especial> & más"
+	row[27] = 3.5
+	require.NoError(t, writer.WriteRow(row))
+	require.NoError(t, writer.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	require.Contains(t, files, "[Content_Types].xml")
+	require.Contains(t, files, "xl/workbook.xml")
+	assert.Contains(t, files["xl/workbook.xml"], `name="Pedidos"`)
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="A1" t="inlineStr"><is><t>producto</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="B1" t="inlineStr"><is><t>cantidad</t></is></c>`)
+	assert.Contains(t, sheet, `Gas &lt;especial&gt; &amp; más`)
+	assert.Contains(t, sheet, `<c r="AB2"><v>3.5</v></c>`)
+	assert.True(t, strings.HasSuffix(sheet, "</sheetData></worksheet>"))
+}
@@ -0,0 +1,83 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/forecast"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// history genera ventas diarias: 10 unidades entre semana y 20 los sábados, repartidas entre las 8 y las 18 h
+func history(start time.Time, days int) []forecast.Observation {
+	var observations []forecast.Observation
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i)
+		units := 10.0
+		if date.Weekday() == time.Saturday {
+			units = 20
+		}
+		observations = append(observations,
+			forecast.Observation{Date: date, Hour: 8, Units: units * 0.75},
+			forecast.Observation{Date: date, Hour: 18, Units: units * 0.25},
+		)
+	}
+	return observations
+}
+
+func TestFit_WeekdaySeasonality(t *testing.T) {
+	cfg := forecast.Config{Alpha: 0.3, MonthStartDays: 0}
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC) // Lunes
+	end := start.AddDate(0, 0, 56)
+
+	model := forecast.Fit(history(start, 56), start, end, cfg)
+	require.Equal(t, 56, model.HistoryDays)
+
+	saturday := time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC)
+	monday := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	assert.InDelta(t, 20, model.PredictDay(saturday), 0.5)
+	assert.InDelta(t, 10, model.PredictDay(monday), 0.5)
+	assert.InDelta(t, 0, model.ResidualStdDev, 0.5)
+
+	hours := model.PredictHours(monday)
+	assert.InDelta(t, 7.5, hours[8], 0.5)
+	assert.InDelta(t, 2.5, hours[18], 0.5)
+	assert.Zero(t, hours[3])
+}
+
+func TestFit_MonthStartEffect(t *testing.T) {
+	cfg := forecast.Config{Alpha: 0.3, MonthStartDays: 3}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 90)
+
+	var observations []forecast.Observation
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		units := 10.0
+		if d.Day() <= 3 {
+			units = 30
+		}
+		observations = append(observations, forecast.Observation{Date: d, Hour: 12, Units: units})
+	}
+
+	model := forecast.Fit(observations, start, end, cfg)
+	assert.Greater(t, model.MonthStartFactor, 2.0)
+	assert.Greater(t, model.PredictDay(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)),
+		model.PredictDay(time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestFit_NoHistory(t *testing.T) {
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	model := forecast.Fit(nil, start, start.AddDate(0, 0, 14), forecast.DefaultConfig)
+
+	assert.Zero(t, model.PredictDay(start.AddDate(0, 0, 20)))
+	assert.Zero(t, model.SafetyStock(7, 1.65))
+}
+
+func TestSafetyStock(t *testing.T) {
+	model := &forecast.Model{ResidualStdDev: 2}
+	assert.InDelta(t, 1.65*2*2, model.SafetyStock(4, 1.65), 0.0001)
+	assert.Zero(t, model.SafetyStock(0, 1.65))
+}
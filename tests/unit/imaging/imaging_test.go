@@ -0,0 +1,143 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"backend/internal/imaging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+func gradient(w, h int, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: uint8(x + y), A: alpha})
+		}
+	}
+	return img
+}
+
+func TestFitSize(t *testing.T) {
+	w, h := imaging.FitSize(1600, 1200, 800)
+	assert.Equal(t, []int{800, 600}, []int{w, h})
+
+	w, h = imaging.FitSize(300, 900, 200)
+	assert.Equal(t, []int{66, 200}, []int{w, h})
+
+	w, h = imaging.FitSize(150, 100, 800)
+	assert.Equal(t, []int{150, 100}, []int{w, h}, "nunca agranda")
+}
+
+func TestResize(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		src.SetNRGBA(x, 0, color.NRGBA{R: 200, A: 255})
+		src.SetNRGBA(x, 1, color.NRGBA{B: 100, A: 255})
+	}
+
+	out := imaging.Resize(src, 2)
+	require.Equal(t, image.Rect(0, 0, 2, 1), out.Bounds())
+	assert.Equal(t, color.NRGBA{R: 100, B: 50, A: 255}, out.NRGBAAt(0, 0))
+
+	t.Run("transparent pixels do not darken the average", func(t *testing.T) {
+		src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+		src.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+		src.SetNRGBA(1, 0, color.NRGBA{})
+		out := imaging.Resize(src, 1)
+		assert.Equal(t, color.NRGBA{R: 255, A: 128}, out.NRGBAAt(0, 0))
+	})
+}
+
+func TestEncodeWebP(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, imaging.EncodeWebP(&buf, gradient(300, 200, 255)))
+
+	data := buf.Bytes()
+	require.Greater(t, len(data), 25)
+	assert.Equal(t, "RIFF", string(data[0:4]))
+	assert.Equal(t, uint32(len(data)-8), binary.LittleEndian.Uint32(data[4:8]))
+	assert.Equal(t, "WEBP", string(data[8:12]))
+	assert.Equal(t, "VP8L", string(data[12:16]))
+	assert.Equal(t, byte(0x2f), data[20])
+
+	// Ancho y alto menos uno en 14 bits cada uno, desde el bit menos significativo
+	bits := binary.LittleEndian.Uint32(data[21:25])
+	assert.Equal(t, uint32(299), bits&0x3fff)
+	assert.Equal(t, uint32(199), (bits>>14)&0x3fff)
+	assert.Equal(t, uint32(0), (bits>>28)&1, "imagen opaca")
+	assert.Less(t, len(data), 300*200*4, "la predicción debe comprimir un degradado")
+
+	// Sin pérdida: un decodificador WebP independiente devuelve los mismos píxeles
+	src := gradient(300, 200, 255)
+	decoded, err := webp.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, src.Bounds(), decoded.Bounds())
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 300; x++ {
+			require.Equal(t, src.NRGBAAt(x, y), color.NRGBAModel.Convert(decoded.At(x, y)), "píxel (%d, %d)", x, y)
+		}
+	}
+}
+
+func TestEncodeWebP_Transparency(t *testing.T) {
+	src := gradient(40, 30, 100)
+	var buf bytes.Buffer
+	require.NoError(t, imaging.EncodeWebP(&buf, src))
+
+	decoded, err := webp.Decode(&buf)
+	require.NoError(t, err)
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 40; x++ {
+			require.Equal(t, src.NRGBAAt(x, y), color.NRGBAModel.Convert(decoded.At(x, y)), "píxel (%d, %d)", x, y)
+		}
+	}
+}
+
+func TestProcess(t *testing.T) {
+	t.Run("rejects non images", func(t *testing.T) {
+		_, err := imaging.Process([]byte("hola, esto no es una imagen"), imaging.DefaultSizes)
+		assert.ErrorIs(t, err, imaging.ErrUnsupportedFormat)
+	})
+
+	t.Run("rejects corrupt images", func(t *testing.T) {
+		data := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+		_, err := imaging.Process(data, imaging.DefaultSizes)
+		assert.ErrorIs(t, err, imaging.ErrInvalidImage)
+	})
+
+	t.Run("generates classic and webp renditions", func(t *testing.T) {
+		var src bytes.Buffer
+		require.NoError(t, png.Encode(&src, gradient(1000, 500, 255)))
+
+		result, err := imaging.Process(src.Bytes(), imaging.DefaultSizes)
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", result.ContentType)
+		assert.Equal(t, 1000, result.Width)
+		require.Len(t, result.Renditions, 4)
+
+		thumb, thumbWebP := result.Renditions[0], result.Renditions[1]
+		assert.Equal(t, "thumb", thumb.Size)
+		assert.Equal(t, []int{200, 100}, []int{thumb.Width, thumb.Height})
+		assert.Equal(t, "image/jpeg", thumb.ContentType, "una imagen opaca se guarda como JPEG")
+		assert.Equal(t, "webp", thumbWebP.Ext)
+		assert.Equal(t, 800, result.Renditions[2].Width)
+	})
+
+	t.Run("keeps transparency as png", func(t *testing.T) {
+		var src bytes.Buffer
+		require.NoError(t, png.Encode(&src, gradient(50, 50, 100)))
+
+		result, err := imaging.Process(src.Bytes(), imaging.DefaultSizes)
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", result.Renditions[0].ContentType)
+		assert.Equal(t, 50, result.Renditions[0].Width)
+	})
+}
@@ -0,0 +1,71 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/jobs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunner_RunsImmediatelyAndRepeats(t *testing.T) {
+	runner := jobs.NewRunner()
+	var runs int32
+	runner.Every("test", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return errors.New("los errores no detienen la tarea")
+	})
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 3 }, time.Second, 5*time.Millisecond)
+	runner.Stop()
+
+	stopped := atomic.LoadInt32(&runs)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&runs), "no debe ejecutarse después de Stop")
+}
+
+func TestRunner_StopWaitsForRunningTask(t *testing.T) {
+	runner := jobs.NewRunner()
+	started := make(chan struct{})
+	var finished int32
+	runner.Every("lenta", time.Hour, func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		atomic.StoreInt32(&finished, 1)
+		return ctx.Err()
+	})
+
+	<-started
+	runner.Stop()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&finished))
+}
+
+func TestRunner_RecoversFromPanic(t *testing.T) {
+	runner := jobs.NewRunner()
+	var runs int32
+	runner.Every("panic", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		panic("falla")
+	})
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 2 }, time.Second, 5*time.Millisecond)
+	runner.Stop()
+}
+
+func TestRunner_NonPositiveIntervalDisablesTask(t *testing.T) {
+	runner := jobs.NewRunner()
+	var runs int32
+	runner.Every("desactivada", 0, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+
+	time.Sleep(20 * time.Millisecond)
+	runner.Stop()
+	assert.Zero(t, atomic.LoadInt32(&runs))
+}
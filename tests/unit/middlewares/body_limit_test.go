@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"backend/api/v1/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStreamingApp arma una aplicación como la de main.go: Fiber guarda en memoria hasta bufferLimit
+// y el resto del cuerpo se lee en BodyLimit o, en /upload, en el handler con un límite mayor
+func newStreamingApp(bufferLimit, limit, uploadLimit int) *fiber.App {
+	app := fiber.New(fiber.Config{
+		BodyLimit:                    bufferLimit,
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+	})
+	app.Use(middlewares.BodyLimit(limit, func(c *fiber.Ctx) bool {
+		return c.Path() == "/upload"
+	}))
+	echoLength := func(c *fiber.Ctx) error {
+		return c.SendString(strconv.Itoa(len(c.Body())))
+	}
+	app.Post("/echo", echoLength)
+	app.Post("/upload", func(c *fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) == "" {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+		if err := middlewares.ReadBody(c, uploadLimit); err != nil {
+			return err
+		}
+		return echoLength(c)
+	})
+	return app
+}
+
+func send(t *testing.T, app *fiber.App, path string, size int) (int, string) {
+	req := httptest.NewRequest(fiber.MethodPost, path, bytes.NewReader(make([]byte, size)))
+	if path == "/upload" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer token")
+	}
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestBodyLimit(t *testing.T) {
+	app := newStreamingApp(1024, 4096, 16384)
+
+	t.Run("bodies within the limit reach the handler", func(t *testing.T) {
+		status, body := send(t, app, "/echo", 3000)
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "3000", body, "the streamed part is read into the body")
+	})
+
+	t.Run("bodies over the limit are rejected", func(t *testing.T) {
+		status, _ := send(t, app, "/echo", 5000)
+		assert.Equal(t, fiber.StatusRequestEntityTooLarge, status)
+	})
+
+	t.Run("skipped routes read with their own limit", func(t *testing.T) {
+		status, body := send(t, app, "/upload", 10000)
+		assert.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "10000", body)
+
+		status, _ = send(t, app, "/upload", 20000)
+		assert.Equal(t, fiber.StatusRequestEntityTooLarge, status)
+	})
+
+	t.Run("unread bodies close the connection", func(t *testing.T) {
+		req := httptest.NewRequest(fiber.MethodPost, "/upload", bytes.NewReader(make([]byte, 10000)))
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err, "the rest of the body is not parsed as another request")
+		assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+		assert.True(t, resp.Close, "Connection: close")
+	})
+}
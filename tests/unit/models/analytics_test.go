@@ -0,0 +1,54 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSalesMetrics_Finalize(t *testing.T) {
+	metrics := models.SalesMetrics{OrderCount: 8, DeliveredCount: 3, CancelledCount: 2, Revenue: 200.004}
+	metrics.Finalize()
+
+	assert.Equal(t, 200.0, metrics.Revenue)
+	assert.Equal(t, 66.67, metrics.AverageTicket)
+	assert.Equal(t, 25.0, metrics.CancellationRate)
+
+	empty := models.SalesMetrics{}
+	empty.Finalize()
+	assert.Zero(t, empty.AverageTicket)
+	assert.Zero(t, empty.CancellationRate)
+}
+
+func TestCompareSalesMetrics(t *testing.T) {
+	current := models.SalesMetrics{OrderCount: 12, Revenue: 150, AverageTicket: 50, CancellationRate: 10}
+	previous := models.SalesMetrics{OrderCount: 10, Revenue: 0, AverageTicket: 40, CancellationRate: 15}
+
+	change := models.CompareSalesMetrics(current, previous)
+	require.NotNil(t, change.OrderCount)
+	assert.Equal(t, 20.0, *change.OrderCount)
+	assert.Nil(t, change.Revenue, "sin base de comparación no hay variación")
+	assert.Equal(t, 25.0, *change.AverageTicket)
+	assert.Equal(t, -5.0, *change.CancellationRate)
+}
+
+func TestPreviousPeriod(t *testing.T) {
+	loc, err := time.LoadLocation("America/Lima")
+	require.NoError(t, err)
+
+	start := time.Date(2025, 3, 8, 0, 0, 0, 0, loc)
+	end := time.Date(2025, 3, 15, 0, 0, 0, 0, loc)
+
+	prevStart, prevEnd := models.PreviousPeriod(start, end)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, loc), prevStart)
+	assert.Equal(t, start, prevEnd)
+}
+
+func TestIsValidAnalyticsGroup(t *testing.T) {
+	assert.True(t, models.IsValidAnalyticsGroup(models.AnalyticsGroupWeek))
+	assert.True(t, models.IsValidAnalyticsGroup(models.AnalyticsGroupHour))
+	assert.False(t, models.IsValidAnalyticsGroup("year"))
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectBranch(t *testing.T) {
+	balon := uuid.New()
+	valvula := uuid.New()
+
+	// Miraflores y San Isidro están a ~3 km; Callao a más de 10 km
+	miraflores := models.Branch{BranchID: uuid.New(), Name: "Miraflores", Latitude: -12.1211, Longitude: -77.0297, ServiceRadiusKm: 5, IsActive: true}
+	sanIsidro := models.Branch{BranchID: uuid.New(), Name: "San Isidro", Latitude: -12.0977, Longitude: -77.0365, ServiceRadiusKm: 5, IsActive: true}
+	callao := models.Branch{BranchID: uuid.New(), Name: "Callao", Latitude: -12.0566, Longitude: -77.1181, ServiceRadiusKm: 3, IsActive: true}
+	branches := []models.Branch{sanIsidro, miraflores, callao}
+
+	clientLat, clientLng := -12.1190, -77.0330 // Cerca de Miraflores
+	demand := map[uuid.UUID]int{balon: 2, valvula: 1}
+
+	t.Run("nearest branch with stock", func(t *testing.T) {
+		stock := map[uuid.UUID]map[uuid.UUID]int{
+			miraflores.BranchID: {balon: 5, valvula: 3},
+			sanIsidro.BranchID:  {balon: 5, valvula: 3},
+		}
+		routing := models.SelectBranch(branches, stock, demand, clientLat, clientLng)
+		require.NotNil(t, routing.Branch)
+		assert.Equal(t, "Miraflores", routing.Branch.Name)
+		assert.True(t, routing.InZone)
+	})
+
+	t.Run("falls back to the next branch when the nearest lacks stock", func(t *testing.T) {
+		stock := map[uuid.UUID]map[uuid.UUID]int{
+			miraflores.BranchID: {balon: 1, valvula: 3},
+			sanIsidro.BranchID:  {balon: 2, valvula: 1},
+		}
+		routing := models.SelectBranch(branches, stock, demand, clientLat, clientLng)
+		require.NotNil(t, routing.Branch)
+		assert.Equal(t, "San Isidro", routing.Branch.Name)
+	})
+
+	t.Run("in zone but without stock", func(t *testing.T) {
+		routing := models.SelectBranch(branches, nil, demand, clientLat, clientLng)
+		assert.Nil(t, routing.Branch)
+		assert.True(t, routing.InZone)
+	})
+
+	t.Run("outside every service zone", func(t *testing.T) {
+		routing := models.SelectBranch(branches, nil, demand, -12.5, -76.8)
+		assert.Nil(t, routing.Branch)
+		assert.False(t, routing.InZone)
+	})
+
+	t.Run("inactive branches are ignored", func(t *testing.T) {
+		closed := miraflores
+		closed.IsActive = false
+		stock := map[uuid.UUID]map[uuid.UUID]int{closed.BranchID: {balon: 5, valvula: 5}}
+		routing := models.SelectBranch([]models.Branch{closed}, stock, demand, clientLat, clientLng)
+		assert.Nil(t, routing.Branch)
+		assert.False(t, routing.InZone)
+	})
+}
+
+func TestBranch_HoursAndValidation(t *testing.T) {
+	loc, err := time.LoadLocation("America/Lima")
+	require.NoError(t, err)
+
+	branch := models.Branch{Name: "Surco", AddressText: "Av. Primavera 123", Latitude: -12.1, Longitude: -77.0, ServiceRadiusKm: 4}
+	require.NoError(t, branch.Validate())
+	assert.True(t, branch.IsOpenAt(time.Date(2025, 7, 30, 3, 0, 0, 0, loc), loc), "without own hours the business hours apply")
+
+	branch.OpensAt, branch.ClosesAt = "08:00", "18:00"
+	require.NoError(t, branch.Validate())
+	assert.True(t, branch.IsOpenAt(time.Date(2025, 7, 30, 8, 0, 0, 0, loc), loc))
+	assert.False(t, branch.IsOpenAt(time.Date(2025, 7, 30, 18, 0, 0, 0, loc), loc))
+
+	branch.ClosesAt = ""
+	assert.Error(t, branch.Validate())
+
+	branch.ClosesAt = "18:00"
+	branch.ServiceRadiusKm = 0
+	assert.Error(t, branch.Validate())
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusinessCalendar_Resolution(t *testing.T) {
+	loc, err := time.LoadLocation("America/Lima")
+	require.NoError(t, err)
+
+	holiday := time.Date(2025, 7, 28, 0, 0, 0, 0, time.UTC)  // lunes
+	shortDay := time.Date(2025, 7, 29, 0, 0, 0, 0, time.UTC) // martes
+	calendar := models.NewBusinessCalendar(
+		[]models.BusinessHours{
+			{Weekday: int(time.Sunday), IsOpen: false},
+			{Weekday: int(time.Saturday), IsOpen: true, OpensAt: "08:00", ClosesAt: "14:00"},
+		},
+		[]models.SpecialDay{
+			{Date: holiday, Name: "Fiestas Patrias", IsHoliday: true},
+			{Date: shortDay, Name: "Inventario", IsOpen: true, OpensAt: "12:00", ClosesAt: "16:00"},
+		},
+		6*time.Hour, 20*time.Hour, "America/Lima",
+	)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 7, day, hour, minute, 0, 0, loc)
+	}
+
+	t.Run("unconfigured weekdays use the default hours", func(t *testing.T) {
+		assert.True(t, calendar.IsOpenAt(at(30, 6, 0)))
+		assert.True(t, calendar.IsOpenAt(at(30, 19, 59)))
+		assert.False(t, calendar.IsOpenAt(at(30, 20, 0)))
+		assert.False(t, calendar.IsOpenAt(at(30, 5, 59)))
+	})
+
+	t.Run("weekly hours per weekday", func(t *testing.T) {
+		assert.True(t, calendar.IsOpenAt(at(26, 13, 0)))  // sábado
+		assert.False(t, calendar.IsOpenAt(at(26, 15, 0))) // sábado por la tarde
+		assert.False(t, calendar.IsOpenAt(at(27, 10, 0))) // domingo cerrado
+	})
+
+	t.Run("special days override the weekly hours", func(t *testing.T) {
+		assert.False(t, calendar.IsOpenAt(at(28, 10, 0)))
+		schedule := calendar.ScheduleFor(at(28, 10, 0))
+		assert.True(t, schedule.IsHoliday)
+		assert.True(t, schedule.IsSpecial)
+		assert.Equal(t, "Fiestas Patrias", schedule.Name)
+
+		assert.False(t, calendar.IsOpenAt(at(29, 10, 0)))
+		assert.True(t, calendar.IsOpenAt(at(29, 12, 30)))
+	})
+
+	t.Run("next opening skips closed days", func(t *testing.T) {
+		next := calendar.NextOpening(at(27, 10, 0)) // domingo, luego feriado
+		require.NotNil(t, next)
+		assert.True(t, next.Equal(at(29, 12, 0)))
+	})
+
+	t.Run("week starts today", func(t *testing.T) {
+		week := calendar.Week(at(26, 9, 0))
+		require.Len(t, week, 7)
+		assert.Equal(t, "2025-07-26", week[0].Date)
+		assert.Equal(t, "14:00", week[0].ClosesAt)
+		assert.False(t, week[1].IsOpen)
+	})
+}
+
+func TestBusinessCalendar_Validation(t *testing.T) {
+	valid := models.BusinessHours{Weekday: 1, IsOpen: true, OpensAt: "08:00", ClosesAt: "24:00"}
+	assert.NoError(t, valid.Validate())
+
+	closed := models.BusinessHours{Weekday: 0, IsOpen: false}
+	assert.NoError(t, closed.Validate())
+
+	assert.Error(t, (&models.BusinessHours{Weekday: 7}).Validate())
+	assert.Error(t, (&models.BusinessHours{Weekday: 1, IsOpen: true, OpensAt: "18:00", ClosesAt: "09:00"}).Validate())
+	assert.Error(t, (&models.BusinessHours{Weekday: 1, IsOpen: true, OpensAt: "8am", ClosesAt: "18:00"}).Validate())
+
+	req := models.SpecialDayRequest{Date: "2025-12-25", Name: "Navidad", IsHoliday: true, OpensAt: "99:99"}
+	day, err := req.ToSpecialDay()
+	require.NoError(t, err)
+	assert.Empty(t, day.OpensAt, "closed days ignore opening times")
+
+	req = models.SpecialDayRequest{Date: "25/12/2025", Name: "Navidad"}
+	_, err = req.ToSpecialDay()
+	assert.Error(t, err)
+
+	req = models.SpecialDayRequest{Date: "2025-12-24", Name: "Nochebuena", IsOpen: true}
+	_, err = req.ToSpecialDay()
+	assert.Error(t, err)
+}
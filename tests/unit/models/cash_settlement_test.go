@@ -0,0 +1,111 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusinessDayBounds(t *testing.T) {
+	t.Run("explicit date in business timezone", func(t *testing.T) {
+		start, end, err := models.BusinessDayBounds("2025-03-10", "America/Lima", time.Now())
+		require.NoError(t, err)
+
+		assert.Equal(t, "2025-03-10T00:00:00-05:00", start.Format(time.RFC3339))
+		assert.Equal(t, 24*time.Hour, end.Sub(start))
+	})
+
+	t.Run("empty date uses today in business timezone", func(t *testing.T) {
+		// 02:00 UTC del 11 de marzo sigue siendo 10 de marzo en Lima
+		now := time.Date(2025, 3, 11, 2, 0, 0, 0, time.UTC)
+		start, _, err := models.BusinessDayBounds("", "America/Lima", now)
+		require.NoError(t, err)
+
+		assert.Equal(t, "2025-03-10", start.Format(models.BusinessDateLayout))
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		_, _, err := models.BusinessDayBounds("10/03/2025", "America/Lima", time.Now())
+		assert.Error(t, err)
+	})
+}
+
+func TestBuildCashLedger(t *testing.T) {
+	repartidorID := uuid.New()
+	dayStart, dayEnd, err := models.BusinessDayBounds("2025-03-10", "UTC", time.Now())
+	require.NoError(t, err)
+
+	at := func(hour int) *time.Time {
+		ts := dayStart.Add(time.Duration(hour) * time.Hour)
+		return &ts
+	}
+
+	orders := []*models.Order{
+		{OrderID: uuid.New(), OrderStatus: models.OrderStatusDelivered, PaymentMethod: models.PaymentMethodCash, TotalAmount: 50.00, DeliveredAt: at(10)},
+		{OrderID: uuid.New(), OrderStatus: models.OrderStatusDelivered, PaymentMethod: models.PaymentMethodCash, TotalAmount: 35.50, DeliveredAt: at(15)},
+		// Pagado con Yape: no entra a la caja de efectivo
+		{OrderID: uuid.New(), OrderStatus: models.OrderStatusDelivered, PaymentMethod: models.PaymentMethodYape, TotalAmount: 40.00, DeliveredAt: at(11)},
+		// Aún en camino
+		{OrderID: uuid.New(), OrderStatus: models.OrderStatusInTransit, PaymentMethod: models.PaymentMethodCash, TotalAmount: 20.00},
+		// Entregado el día siguiente
+		{OrderID: uuid.New(), OrderStatus: models.OrderStatusDelivered, PaymentMethod: models.PaymentMethodCash, TotalAmount: 60.00, DeliveredAt: at(25)},
+	}
+
+	t.Run("pending when nothing handed in", func(t *testing.T) {
+		ledger := models.BuildCashLedger(repartidorID, dayStart, dayEnd, orders, nil)
+
+		assert.Equal(t, "2025-03-10", ledger.BusinessDate)
+		assert.Equal(t, 2, ledger.OrderCount)
+		assert.Equal(t, 85.50, ledger.ExpectedCash)
+		assert.Equal(t, 0.0, ledger.HandedIn)
+		assert.Equal(t, -85.50, ledger.Discrepancy)
+		assert.Equal(t, models.CashStatusPending, ledger.Status)
+		assert.NotNil(t, ledger.Settlements)
+	})
+
+	t.Run("balanced", func(t *testing.T) {
+		settlements := []models.CashSettlement{{HandedInAmount: 50.00}, {HandedInAmount: 35.50}}
+		ledger := models.BuildCashLedger(repartidorID, dayStart, dayEnd, orders, settlements)
+
+		assert.Equal(t, 85.50, ledger.HandedIn)
+		assert.Equal(t, 0.0, ledger.Discrepancy)
+		assert.Equal(t, models.CashStatusBalanced, ledger.Status)
+	})
+
+	t.Run("short", func(t *testing.T) {
+		settlements := []models.CashSettlement{{HandedInAmount: 80.00}}
+		ledger := models.BuildCashLedger(repartidorID, dayStart, dayEnd, orders, settlements)
+
+		assert.Equal(t, -5.50, ledger.Discrepancy)
+		assert.Equal(t, models.CashStatusShort, ledger.Status)
+	})
+
+	t.Run("over", func(t *testing.T) {
+		settlements := []models.CashSettlement{{HandedInAmount: 90.00}}
+		ledger := models.BuildCashLedger(repartidorID, dayStart, dayEnd, orders, settlements)
+
+		assert.Equal(t, 4.50, ledger.Discrepancy)
+		assert.Equal(t, models.CashStatusOver, ledger.Status)
+	})
+
+	t.Run("no cash orders", func(t *testing.T) {
+		ledger := models.BuildCashLedger(repartidorID, dayStart, dayEnd, nil, nil)
+
+		assert.Equal(t, 0, ledger.OrderCount)
+		assert.Empty(t, ledger.Entries)
+		assert.Equal(t, models.CashStatusBalanced, ledger.Status)
+	})
+}
+
+func TestIsValidPaymentMethod(t *testing.T) {
+	assert.True(t, models.IsValidPaymentMethod(models.PaymentMethodCash))
+	assert.True(t, models.IsValidPaymentMethod(models.PaymentMethodYape))
+	assert.True(t, models.IsValidPaymentMethod(models.PaymentMethodPlin))
+	assert.True(t, models.IsValidPaymentMethod(models.PaymentMethodCard))
+	assert.False(t, models.IsValidPaymentMethod("BITCOIN"))
+	assert.False(t, models.IsValidPaymentMethod(""))
+}
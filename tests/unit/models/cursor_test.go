@@ -0,0 +1,72 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	ts := time.Date(2025, 3, 10, 15, 30, 45, 123456000, time.UTC)
+	id := uuid.New().String()
+
+	cursor, err := models.DecodeCursor(models.EncodeCursor(ts, id))
+	require.NoError(t, err)
+	require.NotNil(t, cursor)
+	assert.True(t, ts.Equal(cursor.Time))
+	assert.Equal(t, id, cursor.ID)
+
+	t.Run("empty cursor is the first page", func(t *testing.T) {
+		cursor, err := models.DecodeCursor("")
+		assert.NoError(t, err)
+		assert.Nil(t, cursor)
+	})
+
+	t.Run("invalid cursors are rejected", func(t *testing.T) {
+		for _, value := range []string{"%%%", "bm90LWpzb24", models.EncodeCursor(ts, "no-es-uuid"), models.EncodeCursor(time.Time{}, id)} {
+			_, err := models.DecodeCursor(value)
+			assert.ErrorIs(t, err, models.ErrInvalidCursor, value)
+		}
+	})
+}
+
+func TestNewCursorPage(t *testing.T) {
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	rows := make([]models.Cursor, 4)
+	for i := range rows {
+		rows[i] = models.Cursor{Time: base.Add(-time.Duration(i) * time.Minute), ID: uuid.New().String()}
+	}
+	key := func(c models.Cursor) (time.Time, string) { return c.Time, c.ID }
+
+	t.Run("extra row means more results", func(t *testing.T) {
+		page := models.NewCursorPage(rows, 3, key)
+		assert.Len(t, page.Items, 3)
+		assert.True(t, page.HasMore)
+
+		next, err := models.DecodeCursor(page.NextCursor)
+		require.NoError(t, err)
+		assert.Equal(t, rows[2].ID, next.ID)
+	})
+
+	t.Run("last page has no cursor", func(t *testing.T) {
+		page := models.NewCursorPage(rows, 10, key)
+		assert.Len(t, page.Items, 4)
+		assert.False(t, page.HasMore)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("empty page returns empty items", func(t *testing.T) {
+		page := models.NewCursorPage([]models.Cursor(nil), 10, key)
+		assert.NotNil(t, page.Items)
+	})
+}
+
+func TestNormalizeCursorLimit(t *testing.T) {
+	assert.Equal(t, models.DefaultCursorLimit, models.NormalizeCursorLimit(0))
+	assert.Equal(t, 15, models.NormalizeCursorLimit(15))
+	assert.Equal(t, models.MaxCursorLimit, models.NormalizeCursorLimit(500))
+}
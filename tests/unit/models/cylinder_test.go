@@ -0,0 +1,56 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderItem_ApplyCylinderExchange(t *testing.T) {
+	returnable := &models.Product{IsReturnable: true, DepositAmount: 80}
+
+	t.Run("full exchange has no deposit", func(t *testing.T) {
+		item := models.OrderItem{Quantity: 2}
+		item.SetUnitDeposit(returnable)
+		assert.NoError(t, item.ApplyCylinderExchange(returnable, 2))
+		assert.Equal(t, 2, item.CylindersReturned)
+		assert.Equal(t, 0.0, item.DepositCharge)
+	})
+
+	t.Run("missing empties are charged", func(t *testing.T) {
+		item := models.OrderItem{Quantity: 3}
+		item.SetUnitDeposit(returnable)
+		assert.NoError(t, item.ApplyCylinderExchange(returnable, 1))
+		assert.Equal(t, 1, item.CylindersReturned)
+		assert.Equal(t, 160.0, item.DepositCharge)
+	})
+
+	t.Run("deposit is the one charged at purchase", func(t *testing.T) {
+		product := &models.Product{IsReturnable: true, DepositAmount: 80}
+		item := models.OrderItem{Quantity: 2}
+		item.SetUnitDeposit(product)
+
+		// La garantía del producto cambia antes de la entrega
+		product.DepositAmount = 120
+		assert.NoError(t, item.ApplyCylinderExchange(product, 0))
+		assert.Equal(t, 80.0, item.UnitDeposit)
+		assert.Equal(t, 160.0, item.DepositCharge)
+	})
+
+	t.Run("invalid quantities are rejected", func(t *testing.T) {
+		item := models.OrderItem{Quantity: 2}
+		assert.ErrorIs(t, item.ApplyCylinderExchange(returnable, 3), models.ErrInvalidCylindersReturned)
+		assert.ErrorIs(t, item.ApplyCylinderExchange(returnable, -1), models.ErrInvalidCylindersReturned)
+	})
+
+	t.Run("non returnable products ignore exchange", func(t *testing.T) {
+		item := models.OrderItem{Quantity: 2}
+		product := &models.Product{IsReturnable: false, DepositAmount: 50}
+		item.SetUnitDeposit(product)
+		assert.NoError(t, item.ApplyCylinderExchange(product, 5))
+		assert.Equal(t, 0, item.CylindersReturned)
+		assert.Equal(t, 0.0, item.UnitDeposit)
+		assert.Equal(t, 0.0, item.DepositCharge)
+	})
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDeliveryRatingRequest_Validate(t *testing.T) {
+	t.Run("valid request deduplicates tags", func(t *testing.T) {
+		req := models.CreateDeliveryRatingRequest{
+			Rating: 4,
+			Tags:   []string{models.DeliveryTagFriendly, models.DeliveryTagLate, models.DeliveryTagFriendly},
+		}
+		require.NoError(t, req.Validate())
+		assert.Equal(t, []string{models.DeliveryTagFriendly, models.DeliveryTagLate}, req.Tags)
+	})
+
+	t.Run("rating out of range", func(t *testing.T) {
+		assert.Error(t, (&models.CreateDeliveryRatingRequest{Rating: 0}).Validate())
+		assert.Error(t, (&models.CreateDeliveryRatingRequest{Rating: 6}).Validate())
+	})
+
+	t.Run("unknown tag", func(t *testing.T) {
+		req := models.CreateDeliveryRatingRequest{Rating: 5, Tags: []string{"muy_rapido"}}
+		assert.Error(t, req.Validate())
+	})
+}
+
+func TestWeightedDeliveryScore(t *testing.T) {
+	// Sin calificaciones: se usa el promedio global
+	assert.Equal(t, 4.2, models.WeightedDeliveryScore(0, 0, 4.2))
+
+	// Una sola calificación de 5 no supera a quien tiene muchas de 4.8
+	few := models.WeightedDeliveryScore(5, 1, 4.0)
+	many := models.WeightedDeliveryScore(4.8, 100, 4.0)
+	assert.Less(t, few, many)
+
+	// Con muchas calificaciones el puntaje se acerca al promedio propio
+	assert.InDelta(t, 4.8, many, 0.05)
+}
+
+func TestDeliveryTags_ValueAndScan(t *testing.T) {
+	tags := models.DeliveryTags{models.DeliveryTagOnTime, models.DeliveryTagCarefulHandling}
+
+	value, err := tags.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `["on_time","careful_handling"]`, value)
+
+	var scanned models.DeliveryTags
+	require.NoError(t, scanned.Scan([]byte(`["on_time","careful_handling"]`)))
+	assert.Equal(t, tags, scanned)
+
+	require.NoError(t, scanned.Scan(nil))
+	assert.Empty(t, scanned)
+}
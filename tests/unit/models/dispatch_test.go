@@ -0,0 +1,24 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRankDispatchCandidates(t *testing.T) {
+	busy := models.DispatchCandidate{RepartidorID: uuid.New(), RepartidorName: "Ocupado", ActiveOrders: 2, Score: 4.9}
+	good := models.DispatchCandidate{RepartidorID: uuid.New(), RepartidorName: "Bueno", ActiveOrders: 0, Score: 4.7}
+	fair := models.DispatchCandidate{RepartidorID: uuid.New(), RepartidorName: "Regular", ActiveOrders: 0, Score: 3.9}
+
+	ranked := models.RankDispatchCandidates([]models.DispatchCandidate{busy, fair, good})
+
+	// A igual carga gana el mejor puntaje; la carga pesa más que el puntaje
+	require.Len(t, ranked, 3)
+	assert.Equal(t, good.RepartidorID, ranked[0].RepartidorID)
+	assert.Equal(t, fair.RepartidorID, ranked[1].RepartidorID)
+	assert.Equal(t, busy.RepartidorID, ranked[2].RepartidorID)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderExportRows(t *testing.T) {
+	loc, err := time.LoadLocation("America/Lima")
+	require.NoError(t, err)
+
+	order := &models.Order{
+		OrderID:     uuid.New(),
+		OrderTime:   time.Date(2025, 3, 10, 15, 30, 0, 0, time.UTC),
+		OrderStatus: models.OrderStatusPending,
+		Client:      models.User{FullName: "Ana Torres"},
+		TotalAmount: 130,
+	}
+
+	t.Run("order without items yields a single row", func(t *testing.T) {
+		rows := models.OrderExportRows(order, loc)
+		require.Len(t, rows, 1)
+		assert.Len(t, rows[0], len(models.OrderExportColumns))
+		assert.Equal(t, "2025-03-10 10:30:00", rows[0][1])
+		assert.Equal(t, "", rows[0][6]) // Sin repartidor asignado
+	})
+
+	t.Run("one row per item", func(t *testing.T) {
+		withItems := *order
+		withItems.OrderItems = []models.OrderItem{
+			{Product: models.Product{Name: "Balón de Gas 10kg"}, Quantity: 2, UnitPrice: 50, Subtotal: 100},
+			{Product: models.Product{Name: "Balón de Gas 5kg"}, Quantity: 1, UnitPrice: 30, Subtotal: 30},
+		}
+
+		rows := models.OrderExportRows(&withItems, loc)
+		require.Len(t, rows, 2)
+		for _, row := range rows {
+			assert.Len(t, row, len(models.OrderExportColumns))
+			assert.Equal(t, order.OrderID.String(), row[0])
+		}
+		assert.Equal(t, "Balón de Gas 5kg", rows[1][14])
+		assert.Equal(t, 1, rows[1][15])
+	})
+}
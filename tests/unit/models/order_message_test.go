@@ -0,0 +1,59 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrder_IsChatOpen(t *testing.T) {
+	tests := []struct {
+		status   models.OrderStatus
+		expected bool
+	}{
+		{models.OrderStatusPending, false},
+		{models.OrderStatusConfirmed, false},
+		{models.OrderStatusAssigned, true},
+		{models.OrderStatusInTransit, true},
+		{models.OrderStatusDelivered, false},
+		{models.OrderStatusCancelled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			order := &models.Order{OrderStatus: tt.status}
+			assert.Equal(t, tt.expected, order.IsChatOpen())
+		})
+	}
+}
+
+func TestOrder_IsChatParticipant(t *testing.T) {
+	clientID := uuid.New()
+	repartidorID := uuid.New()
+	order := &models.Order{ClientID: clientID, AssignedRepartidorID: &repartidorID}
+
+	assert.True(t, order.IsChatParticipant(clientID.String(), models.UserRoleClient))
+	assert.True(t, order.IsChatParticipant(repartidorID.String(), models.UserRoleRepartidor))
+	assert.True(t, order.IsChatParticipant(uuid.New().String(), models.UserRoleAdmin))
+
+	assert.False(t, order.IsChatParticipant(uuid.New().String(), models.UserRoleClient), "otro cliente")
+	assert.False(t, order.IsChatParticipant(uuid.New().String(), models.UserRoleRepartidor), "otro repartidor")
+
+	unassigned := &models.Order{ClientID: clientID}
+	assert.False(t, unassigned.IsChatParticipant(repartidorID.String(), models.UserRoleRepartidor))
+}
+
+func TestOrder_IsChatRecipient(t *testing.T) {
+	clientID := uuid.New()
+	repartidorID := uuid.New()
+	order := &models.Order{ClientID: clientID, AssignedRepartidorID: &repartidorID}
+
+	assert.True(t, order.IsChatRecipient(clientID.String(), models.UserRoleClient))
+	assert.True(t, order.IsChatRecipient(repartidorID.String(), models.UserRoleRepartidor))
+
+	// El administrador lee el chat sin ser destinatario de los mensajes
+	assert.False(t, order.IsChatRecipient(uuid.New().String(), models.UserRoleAdmin))
+	assert.False(t, order.IsChatRecipient(uuid.New().String(), models.UserRoleClient), "otro cliente")
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestOrder_CanTransitionTo(t *testing.T) {
@@ -294,3 +295,70 @@ func TestOrder_StatusWorkflow(t *testing.T) {
 	assert.False(t, order.CanTransitionTo(models.OrderStatusPending),
 		"No debe permitir la transición desde el estado final")
 }
+
+func TestOrder_CanSetTip(t *testing.T) {
+	now := time.Now()
+	deliveredAt := func(ago time.Duration) *time.Time {
+		ts := now.Add(-ago)
+		return &ts
+	}
+
+	tests := []struct {
+		name     string
+		order    models.Order
+		expected bool
+	}{
+		{"pending order (checkout)", models.Order{OrderStatus: models.OrderStatusPending}, true},
+		{"in transit", models.Order{OrderStatus: models.OrderStatusInTransit}, true},
+		{"delivered 1h ago", models.Order{OrderStatus: models.OrderStatusDelivered, DeliveredAt: deliveredAt(time.Hour)}, true},
+		{"delivered 23h ago", models.Order{OrderStatus: models.OrderStatusDelivered, DeliveredAt: deliveredAt(23 * time.Hour)}, true},
+		{"delivered 25h ago", models.Order{OrderStatus: models.OrderStatusDelivered, DeliveredAt: deliveredAt(25 * time.Hour)}, false},
+		{"delivered without timestamp", models.Order{OrderStatus: models.OrderStatusDelivered}, false},
+		{"cancelled", models.Order{OrderStatus: models.OrderStatusCancelled}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.order.CanSetTip(now))
+		})
+	}
+}
+
+func TestIsValidTipAmount(t *testing.T) {
+	assert.True(t, models.IsValidTipAmount(0))
+	assert.True(t, models.IsValidTipAmount(5.50))
+	assert.True(t, models.IsValidTipAmount(models.MaxTipAmount))
+	assert.False(t, models.IsValidTipAmount(-1))
+	assert.False(t, models.IsValidTipAmount(models.MaxTipAmount+0.01))
+}
+
+func TestBusinessDateRange(t *testing.T) {
+	start, end, err := models.BusinessDateRange("2025-03-01", "2025-03-31", "America/Lima", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-01", start.Format(models.BusinessDateLayout))
+	assert.Equal(t, "2025-04-01", end.Format(models.BusinessDateLayout))
+
+	// Solo una fecha: cubre esa jornada
+	start, end, err = models.BusinessDateRange("2025-03-10", "", "America/Lima", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, end.Sub(start))
+
+	// Rango invertido
+	_, _, err = models.BusinessDateRange("2025-03-31", "2025-03-01", "America/Lima", time.Now())
+	assert.Error(t, err)
+}
+
+func TestOrderStatusHoldsStock(t *testing.T) {
+	held := []models.OrderStatus{models.OrderStatusConfirmed, models.OrderStatusAssigned, models.OrderStatusInTransit, models.OrderStatusDelivered}
+	for _, status := range held {
+		assert.True(t, models.OrderStatusHoldsStock(status), status)
+	}
+	free := []models.OrderStatus{models.OrderStatusPending, models.OrderStatusPendingOutOfHours, models.OrderStatusCancelled}
+	for _, status := range free {
+		assert.False(t, models.OrderStatusHoldsStock(status), status)
+	}
+
+	// Un pedido confirmado puede cancelarse: la cancelación tiene stock que reponer
+	confirmed := &models.Order{OrderStatus: models.OrderStatusConfirmed}
+	assert.True(t, confirmed.CanTransitionTo(models.OrderStatusCancelled))
+}
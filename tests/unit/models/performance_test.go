@@ -0,0 +1,54 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepartidorPerformance_Finalize(t *testing.T) {
+	transit := 12.3456
+	performance := models.RepartidorPerformance{
+		AssignedOrders:              20,
+		DeliveredOrders:             15,
+		CancelledWhileAssigned:      2,
+		EtaDeliveries:               8,
+		OnTimeDeliveries:            6,
+		AvgAssignedToTransitMinutes: &transit,
+	}
+	performance.Finalize(7)
+
+	assert.Equal(t, 2.14, performance.DeliveriesPerDay)
+	assert.Equal(t, 10.0, performance.CancellationRate)
+	require.NotNil(t, performance.OnTimeRate)
+	assert.Equal(t, 75.0, *performance.OnTimeRate)
+	assert.Equal(t, 12.35, *performance.AvgAssignedToTransitMinutes)
+	assert.Nil(t, performance.AvgTransitToDeliveredMinutes)
+
+	t.Run("without ETA there is no on-time rate", func(t *testing.T) {
+		p := models.RepartidorPerformance{DeliveredOrders: 3}
+		p.Finalize(0)
+		assert.Nil(t, p.OnTimeRate)
+		assert.Zero(t, p.DeliveriesPerDay)
+	})
+}
+
+func TestSortPerformanceLeaderboard(t *testing.T) {
+	high, low := 90.0, 60.0
+	performance := []models.RepartidorPerformance{
+		{RepartidorName: "C", DeliveredOrders: 10, OnTimeRate: &low},
+		{RepartidorName: "A", DeliveredOrders: 12},
+		{RepartidorName: "B", DeliveredOrders: 10, OnTimeRate: &high},
+		{RepartidorName: "D", DeliveredOrders: 10, OnTimeRate: &high, RatingScore: 4.8},
+	}
+
+	models.SortPerformanceLeaderboard(performance)
+
+	names := make([]string, len(performance))
+	for i, p := range performance {
+		names[i] = p.RepartidorName
+	}
+	assert.Equal(t, []string{"A", "D", "B", "C"}, names)
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPopularityWeights_Decay(t *testing.T) {
+	w := models.PopularityWeights{HalfLife: 7 * 24 * time.Hour}
+
+	assert.Equal(t, 1.0, w.Decay(0))
+	assert.Equal(t, 1.0, w.Decay(-time.Hour), "future activity counts fully")
+	assert.InDelta(t, 0.5, w.Decay(7*24*time.Hour), 1e-9)
+	assert.InDelta(t, 0.25, w.Decay(14*24*time.Hour), 1e-9)
+	assert.Equal(t, 56*24*time.Hour, w.Window())
+}
+
+func TestComputePopularity(t *testing.T) {
+	now := time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	weights := models.PopularityWeights{Purchase: 3, View: 0.1, RatingAverage: 2, RatingCount: 1, HalfLife: 7 * 24 * time.Hour}
+
+	recent := models.PopularityProduct{ProductID: uuid.New(), Name: "Agua 20L"}
+	old := models.PopularityProduct{ProductID: uuid.New(), Name: "Balón de gas 10kg", RatingAverage: 4.5, RatingCount: 2, PopularityScore: 40}
+	activity := []models.PopularityActivity{
+		{ProductID: recent.ProductID, Day: today, Views: 10, Purchases: 2},
+		{ProductID: old.ProductID, Day: today.AddDate(0, 0, -7), Views: 20, Purchases: 4},
+		{ProductID: uuid.New(), Day: today, Views: 100}, // producto eliminado
+	}
+
+	breakdowns := models.ComputePopularity([]models.PopularityProduct{old, recent}, activity, weights, now)
+	require.Len(t, breakdowns, 2)
+
+	top := breakdowns[0]
+	assert.Equal(t, old.ProductID, top.ProductID)
+	assert.Equal(t, 4, top.Purchases)
+	assert.Equal(t, 2.0, top.DecayedPurchases, "a week-old purchase counts half")
+	assert.Equal(t, 10.0, top.DecayedViews)
+	assert.Equal(t, 6.0, top.PurchasePoints)
+	assert.Equal(t, 1.0, top.ViewPoints)
+	assert.Equal(t, 11.0, top.RatingPoints, "ratings do not decay")
+	assert.Equal(t, 18.0, top.Score)
+	assert.Equal(t, 40.0, top.CurrentScore)
+
+	second := breakdowns[1]
+	assert.Equal(t, recent.ProductID, second.ProductID)
+	assert.Equal(t, 7.0, second.Score)
+	assert.Equal(t, 2, second.Purchases)
+}
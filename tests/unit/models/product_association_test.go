@@ -0,0 +1,59 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeProductAssociations(t *testing.T) {
+	now := time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+	gas, water, coal := uuid.New(), uuid.New(), uuid.New()
+
+	var lines []models.OrderBasketLine
+	basket := func(products ...uuid.UUID) {
+		orderID := uuid.New()
+		for _, p := range products {
+			lines = append(lines, models.OrderBasketLine{OrderID: orderID, ProductID: p})
+		}
+	}
+	basket(gas, water)
+	basket(gas, water, gas) // producto repetido en el pedido: cuenta una vez
+	basket(gas, coal)
+	basket(gas)
+	basket(water)
+
+	associations := models.ComputeProductAssociations(lines, 2, now)
+	require.Len(t, associations, 2, "gas-coal appears in a single order")
+
+	byProduct := map[uuid.UUID]models.ProductAssociation{}
+	for _, a := range associations {
+		byProduct[a.ProductID] = a
+		assert.Equal(t, now, a.ComputedAt)
+		assert.Equal(t, 2, a.SupportCount)
+	}
+
+	gasToWater := byProduct[gas]
+	assert.Equal(t, water, gasToWater.RelatedProductID)
+	assert.Equal(t, 0.5, gasToWater.Confidence) // 2 de 4 pedidos con gas
+	assert.Equal(t, 0.8333, gasToWater.Lift)    // 0.5 / (3 de 5 pedidos con agua)
+
+	waterToGas := byProduct[water]
+	assert.Equal(t, gas, waterToGas.RelatedProductID)
+	assert.Equal(t, 0.6667, waterToGas.Confidence)
+	assert.Equal(t, 0.8333, waterToGas.Lift)
+}
+
+func TestComputeProductAssociations_Empty(t *testing.T) {
+	assert.Empty(t, models.ComputeProductAssociations(nil, 1, time.Now()))
+}
+
+func TestNormalizeRelatedLimit(t *testing.T) {
+	assert.Equal(t, models.DefaultRelatedLimit, models.NormalizeRelatedLimit(0))
+	assert.Equal(t, 3, models.NormalizeRelatedLimit(3))
+	assert.Equal(t, models.MaxRelatedLimit, models.NormalizeRelatedLimit(500))
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBundleItems(t *testing.T) {
+	bundleID := uuid.New()
+	balon := uuid.New()
+	valvula := uuid.New()
+
+	t.Run("keeps order and quantities", func(t *testing.T) {
+		items, err := models.NewBundleItems(bundleID, []models.BundleItemRequest{
+			{ComponentID: balon, Quantity: 1},
+			{ComponentID: valvula, Quantity: 2},
+		})
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Equal(t, bundleID, items[0].BundleID)
+		assert.Equal(t, balon, items[0].ComponentID)
+		assert.Equal(t, 0, items[0].SortOrder)
+		assert.Equal(t, valvula, items[1].ComponentID)
+		assert.Equal(t, 2, items[1].Quantity)
+		assert.Equal(t, 1, items[1].SortOrder)
+	})
+
+	t.Run("invalid components", func(t *testing.T) {
+		tooMany := make([]models.BundleItemRequest, models.MaxBundleComponents+1)
+		for i := range tooMany {
+			tooMany[i] = models.BundleItemRequest{ComponentID: uuid.New(), Quantity: 1}
+		}
+
+		cases := map[string][]models.BundleItemRequest{
+			"empty":          nil,
+			"too many":       tooMany,
+			"missing id":     {{Quantity: 1}},
+			"self reference": {{ComponentID: bundleID, Quantity: 1}},
+			"duplicate":      {{ComponentID: balon, Quantity: 1}, {ComponentID: balon, Quantity: 1}},
+			"zero quantity":  {{ComponentID: balon, Quantity: 0}},
+		}
+		for name, reqs := range cases {
+			_, err := models.NewBundleItems(bundleID, reqs)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestOrderStockDemand(t *testing.T) {
+	combo := uuid.New()
+	balon := uuid.New()
+	manguera := uuid.New()
+
+	items := []models.OrderItem{
+		{ProductID: balon, Quantity: 1},
+		{ProductID: combo, Quantity: 2, Components: []models.OrderItemComponent{
+			{ProductID: balon, UnitsPerBundle: 1, Quantity: 2},
+			{ProductID: manguera, UnitsPerBundle: 1, Quantity: 2},
+		}},
+		{ProductID: manguera, Quantity: 3},
+	}
+
+	demand := models.OrderStockDemand(items)
+	assert.Equal(t, map[uuid.UUID]int{balon: 3, manguera: 5}, demand)
+	assert.NotContains(t, demand, combo, "el combo se descuenta por sus componentes")
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProductImage_AddFile(t *testing.T) {
+	productID := uuid.MustParse("11111111-1111-4111-8111-111111111111")
+	imageID := uuid.MustParse("22222222-2222-4222-8222-222222222222")
+	image := models.ProductImage{ProductID: productID, ImageID: imageID}
+
+	files := []struct{ size, ext string }{{"original", "png"}, {"thumb", "jpg"}, {"thumb", "webp"}, {"medium", "jpg"}, {"medium", "webp"}}
+	for _, f := range files {
+		key := models.ProductImageKey(productID, imageID, f.size, f.ext)
+		image.AddFile(f.size, f.ext, key, "https://cdn.example.com/"+key)
+	}
+
+	prefix := "products/11111111-1111-4111-8111-111111111111/22222222-2222-4222-8222-222222222222/"
+	assert.Equal(t, "https://cdn.example.com/"+prefix+"original.png", image.URL)
+	assert.Equal(t, "https://cdn.example.com/"+prefix+"thumb.jpg", image.ThumbnailURL)
+	assert.Equal(t, "https://cdn.example.com/"+prefix+"thumb.webp", image.ThumbnailWebPURL)
+	assert.Equal(t, "https://cdn.example.com/"+prefix+"medium.jpg", image.MediumURL)
+	assert.Equal(t, "https://cdn.example.com/"+prefix+"medium.webp", image.MediumWebPURL)
+	assert.Len(t, image.Keys(), 5)
+	assert.Equal(t, prefix+"original.png", image.Keys()[0])
+
+	assert.Nil(t, (&models.ProductImage{}).Keys())
+}
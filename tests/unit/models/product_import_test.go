@@ -0,0 +1,202 @@
+package models
+
+import (
+	"backend/internal/export"
+	"backend/internal/models"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func importIndex(t *testing.T) (*models.ProductImportIndex, *models.Product, uuid.UUID) {
+	t.Helper()
+	categoryID := uuid.New()
+	product := &models.Product{
+		ProductID:     uuid.New(),
+		Name:          "Balón de gas 10kg",
+		Description:   "Recarga",
+		Price:         45,
+		StockQuantity: 20,
+		UnitOfMeasure: "unidad",
+		PackageSize:   "10kg",
+		IsActive:      true,
+	}
+	other := &models.Product{ProductID: uuid.New(), Name: "Agua 20L", Price: 12, UnitOfMeasure: "bidón", IsActive: true}
+	variants := []models.ProductVariant{
+		{ProductID: product.ProductID, SKU: "GAS-10", IsDefault: true},
+		{ProductID: product.ProductID, SKU: "GAS-10-PREM"},
+		{ProductID: other.ProductID, SKU: "AGUA-20", IsDefault: true},
+	}
+	categories := []models.Category{{CategoryID: categoryID, Name: "Gas"}}
+	return models.NewProductImportIndex([]*models.Product{product, other}, variants, categories), product, categoryID
+}
+
+func parseCSV(t *testing.T, content string) []models.ProductImportRow {
+	t.Helper()
+	rows, err := models.ParseProductCSV(strings.NewReader(content))
+	require.NoError(t, err)
+	return rows
+}
+
+func TestParseProductCSV(t *testing.T) {
+	t.Run("semicolon, BOM and decimal comma", func(t *testing.T) {
+		rows := parseCSV(t, "\xEF\xBB\xBFName;Price;Stock_Quantity;is_active;sku\nBalón 5kg;25,50;10;no; gas-5 \n;;;;\n")
+		require.Len(t, rows, 1)
+		row := rows[0]
+		assert.Equal(t, 2, row.Line)
+		assert.Equal(t, "Balón 5kg", row.Name)
+		assert.Equal(t, "GAS-5", row.SKU)
+		require.NotNil(t, row.Price)
+		assert.Equal(t, 25.5, *row.Price)
+		require.NotNil(t, row.StockQuantity)
+		assert.Equal(t, 10, *row.StockQuantity)
+		require.NotNil(t, row.IsActive)
+		assert.False(t, *row.IsActive)
+		assert.Empty(t, row.Errors)
+	})
+
+	t.Run("invalid values stay as row errors", func(t *testing.T) {
+		rows := parseCSV(t, "name,price,stock_quantity,is_active\nX,-1,abc,quizás\n")
+		require.Len(t, rows, 1)
+		fields := []string{}
+		for _, e := range rows[0].Errors {
+			fields = append(fields, e.Field)
+		}
+		assert.ElementsMatch(t, []string{"price", "stock_quantity", "is_active"}, fields)
+	})
+
+	t.Run("exported formulas round-trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := export.NewRowWriter(export.FormatCSV, &buf, "")
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteRow(models.ProductCSVColumns))
+		row := models.ProductExportRow{SKU: "GAS-10", Name: "=Balón", Description: "+promo", Category: "@Gas", Price: 45, StockQuantity: 3, UnitOfMeasure: "unidad", PackageSize: "-10kg", IsActive: true}
+		require.NoError(t, writer.WriteRow(row.Values()))
+		require.NoError(t, writer.Close())
+
+		rows := parseCSV(t, buf.String())
+		require.Len(t, rows, 1)
+		assert.Equal(t, "=Balón", rows[0].Name)
+		require.NotNil(t, rows[0].Description)
+		assert.Equal(t, "+promo", *rows[0].Description)
+		assert.Equal(t, "@Gas", rows[0].Category)
+		require.NotNil(t, rows[0].PackageSize)
+		assert.Equal(t, "-10kg", *rows[0].PackageSize)
+		assert.Empty(t, rows[0].Errors)
+	})
+
+	t.Run("structural errors", func(t *testing.T) {
+		_, err := models.ParseProductCSV(strings.NewReader(""))
+		assert.ErrorIs(t, err, models.ErrEmptyProductCSV)
+		_, err = models.ParseProductCSV(strings.NewReader("price,stock_quantity\n10,1\n"))
+		assert.ErrorIs(t, err, models.ErrProductCSVHeader)
+		_, err = models.ParseProductCSV(strings.NewReader("name,price\n"))
+		assert.ErrorIs(t, err, models.ErrEmptyProductCSV)
+	})
+}
+
+func TestPlanProductImport(t *testing.T) {
+	t.Run("updates by SKU keeping empty cells and creates new products", func(t *testing.T) {
+		idx, product, categoryID := importIndex(t)
+		rows := parseCSV(t, "sku,name,category,price,stock_quantity,unit_of_measure\n"+
+			"gas-10,,gas,47.5,,\n"+
+			"GAS-5,Balón de gas 5kg,Gas,25,8,\n")
+
+		changes, result := models.PlanProductImport(rows, idx)
+		require.Equal(t, 0, result.Failed, result.Rows)
+		assert.Equal(t, 1, result.Updated)
+		assert.Equal(t, 1, result.Created)
+		require.Len(t, changes, 2)
+
+		updated := changes[0]
+		assert.False(t, updated.IsNew)
+		assert.Equal(t, product.ProductID, updated.Product.ProductID)
+		assert.Equal(t, "Balón de gas 10kg", updated.Product.Name)
+		assert.Equal(t, 47.5, updated.Product.Price)
+		assert.Equal(t, 20, updated.Product.StockQuantity)
+		assert.Equal(t, categoryID, *updated.Product.CategoryID)
+		assert.Empty(t, updated.SKU)
+		assert.Equal(t, 45.0, product.Price, "the indexed product must not be modified")
+
+		created := changes[1]
+		assert.True(t, created.IsNew)
+		assert.Equal(t, "GAS-5", created.SKU)
+		assert.Equal(t, "unidad", created.Product.UnitOfMeasure)
+		assert.Equal(t, 8, created.Product.StockQuantity)
+		assert.True(t, created.Product.IsActive)
+		assert.Equal(t, created.Product.ProductID, *result.Rows[1].ProductID)
+	})
+
+	t.Run("matches by name case-insensitively and sets a new SKU", func(t *testing.T) {
+		idx, product, _ := importIndex(t)
+		rows := parseCSV(t, "name,sku,price\nbalón de GAS 10kg,GAS-10-STD,45\n")
+
+		changes, result := models.PlanProductImport(rows, idx)
+		require.Equal(t, 0, result.Failed, result.Rows)
+		require.Len(t, changes, 1)
+		assert.Equal(t, product.ProductID, changes[0].Product.ProductID)
+		assert.Equal(t, "GAS-10-STD", changes[0].SKU)
+		assert.Equal(t, models.ProductImportUpdate, result.Rows[0].Action)
+	})
+
+	t.Run("rows without changes are skipped", func(t *testing.T) {
+		idx, _, _ := importIndex(t)
+		rows := parseCSV(t, "sku,price,package_size\nGAS-10,45.00,10kg\n")
+
+		changes, result := models.PlanProductImport(rows, idx)
+		assert.Empty(t, changes)
+		assert.Equal(t, 1, result.Unchanged)
+		assert.Equal(t, models.ProductImportUnchanged, result.Rows[0].Action)
+	})
+
+	t.Run("row errors", func(t *testing.T) {
+		idx, _, _ := importIndex(t)
+		rows := parseCSV(t, "sku,name,category,price\n"+
+			"GAS-10-PREM,,,50\n"+ // SKU de una variante no predeterminada
+			"NUEVO-1,,,10\n"+ // nuevo sin nombre
+			",Carbón 5kg,,\n"+ // nuevo sin precio
+			",Leña,Leña,8\n"+ // categoría inexistente
+			"GAS-10,Agua 20L,,\n"+ // nombre de otro producto
+			"AGUA-20,,,13\n"+
+			",agua 20l,,14\n"+ // producto repetido en el archivo
+			"X-1,Kerosene,,9\n"+
+			"X-1,Kerosene 2,,9\n") // SKU repetido en el archivo
+
+		changes, result := models.PlanProductImport(rows, idx)
+		assert.Equal(t, 7, result.Failed)
+		assert.Len(t, changes, 2)
+
+		errorsByLine := map[int]string{}
+		for _, r := range result.Rows {
+			if len(r.Errors) > 0 {
+				errorsByLine[r.Line] = r.Errors[0].Field
+			}
+		}
+		assert.Equal(t, map[int]string{2: "sku", 3: "name", 4: "price", 5: "category", 6: "name", 8: "", 10: "sku"}, errorsByLine)
+	})
+
+	t.Run("SKU of a deleted product is rejected", func(t *testing.T) {
+		product := &models.Product{ProductID: uuid.New(), Name: "Agua 20L", Price: 12, IsActive: true}
+		// Los productos eliminados no se cargan en el índice, pero sus variantes sí
+		variants := []models.ProductVariant{{ProductID: uuid.New(), SKU: "GAS-OLD", IsDefault: true}}
+		idx := models.NewProductImportIndex([]*models.Product{product}, variants, nil)
+		rows := parseCSV(t, "sku,name,price\nGAS-OLD,Balón de gas antiguo,40\n")
+
+		changes, result := models.PlanProductImport(rows, idx)
+		assert.Empty(t, changes)
+		require.Equal(t, 1, result.Failed)
+		assert.Equal(t, "sku", result.Rows[0].Errors[0].Field)
+	})
+}
+
+func TestProductExportRow_Values(t *testing.T) {
+	row := models.ProductExportRow{SKU: "GAS-10", Name: "Balón", Price: 45, StockQuantity: 3, IsActive: true}
+	values := row.Values()
+	require.Len(t, values, len(models.ProductCSVColumns))
+	assert.Equal(t, "GAS-10", values[0])
+	assert.Equal(t, "true", values[8])
+}
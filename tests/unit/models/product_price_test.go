@@ -0,0 +1,35 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulePriceChangeRequest_Validate(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("valid request is normalized", func(t *testing.T) {
+		req := models.SchedulePriceChangeRequest{NewPrice: 49.999, EffectiveAt: now.Add(time.Hour), Note: "  Aumento de proveedor "}
+		require.NoError(t, req.Validate(now))
+		assert.Equal(t, 50.0, req.NewPrice)
+		assert.Equal(t, "Aumento de proveedor", req.Note)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		cases := map[string]models.SchedulePriceChangeRequest{
+			"zero price":       {NewPrice: 0, EffectiveAt: now.Add(time.Hour)},
+			"negative price":   {NewPrice: -5, EffectiveAt: now.Add(time.Hour)},
+			"missing date":     {NewPrice: 10},
+			"date in the past": {NewPrice: 10, EffectiveAt: now.Add(-time.Minute)},
+			"date is now":      {NewPrice: 10, EffectiveAt: now},
+		}
+		for name, req := range cases {
+			req := req
+			assert.Error(t, req.Validate(now), name)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildPrefixTSQuery(t *testing.T) {
+	assert.Equal(t, "balón:* & 10kg:*", models.BuildPrefixTSQuery("  Balón 10kg "))
+	assert.Equal(t, "gas:* & premium:*", models.BuildPrefixTSQuery("gas & (premium)!:*"))
+	assert.Equal(t, "", models.BuildPrefixTSQuery(" ¿? "))
+}
+
+func TestProductSearchParams_Normalize(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		params := models.ProductSearchParams{}
+		assert.NoError(t, params.Normalize())
+		assert.Equal(t, models.ProductSortPopularity, params.Sort)
+		assert.Equal(t, 1, params.Page)
+		assert.Equal(t, models.ProductSearchDefaultPageSize, params.PageSize)
+
+		params = models.ProductSearchParams{Query: "balón", Page: 3, PageSize: 500}
+		assert.NoError(t, params.Normalize())
+		assert.Equal(t, models.ProductSortRelevance, params.Sort)
+		assert.Equal(t, models.ProductSearchMaxPageSize, params.PageSize)
+		assert.Equal(t, 200, params.Offset())
+	})
+
+	t.Run("relevance without text falls back to popularity", func(t *testing.T) {
+		params := models.ProductSearchParams{Sort: models.ProductSortRelevance}
+		assert.NoError(t, params.Normalize())
+		assert.Equal(t, models.ProductSortPopularity, params.Sort)
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		low, high, negative, tooHigh := 50.0, 20.0, -1.0, 6.0
+		assert.Error(t, (&models.ProductSearchParams{MinPrice: &low, MaxPrice: &high}).Normalize())
+		assert.Error(t, (&models.ProductSearchParams{MinPrice: &negative}).Normalize())
+		assert.Error(t, (&models.ProductSearchParams{MinRating: &tooHigh}).Normalize())
+		assert.Error(t, (&models.ProductSearchParams{Sort: "cheapest"}).Normalize())
+	})
+}
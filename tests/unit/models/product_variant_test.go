@@ -0,0 +1,93 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func activeOffer(discountType models.OfferDiscountType, value float64) *models.ProductOffer {
+	return &models.ProductOffer{
+		DiscountType:  discountType,
+		DiscountValue: value,
+		StartDate:     time.Now().Add(-time.Hour),
+		EndDate:       time.Now().Add(time.Hour),
+		IsActive:      true,
+	}
+}
+
+func TestProductVariant_Validate(t *testing.T) {
+	t.Run("normalizes SKU and builds name from attributes", func(t *testing.T) {
+		v := models.ProductVariant{SKU: "  gas-10kg-prem ", Size: "10kg", ValveType: "Premium", Brand: "Solgas", Price: 48}
+		require.NoError(t, v.Validate())
+		assert.Equal(t, "GAS-10KG-PREM", v.SKU)
+		assert.Equal(t, "10kg · Premium · Solgas", v.Name)
+	})
+
+	t.Run("invalid variants", func(t *testing.T) {
+		cases := map[string]models.ProductVariant{
+			"sku with spaces":   {SKU: "GAS 10", Name: "10kg", Price: 48},
+			"empty sku":         {SKU: " ", Name: "10kg", Price: 48},
+			"no name":           {SKU: "GAS-10", Price: 48},
+			"zero price":        {SKU: "GAS-10", Name: "10kg"},
+			"negative stock":    {SKU: "GAS-10", Name: "10kg", Price: 48, StockQuantity: -1},
+			"sku starts with -": {SKU: "-GAS", Name: "10kg", Price: 48},
+		}
+		for name, v := range cases {
+			assert.Error(t, v.Validate(), name)
+		}
+	})
+}
+
+func TestProductVariant_ApplyFinalPrice(t *testing.T) {
+	productOffer := activeOffer(models.DiscountTypePercentage, 10)
+
+	t.Run("product offer applies to the variant price", func(t *testing.T) {
+		v := models.ProductVariant{Price: 50}
+		assert.InDelta(t, 45.0, v.ApplyFinalPrice(productOffer), 0.001)
+	})
+
+	t.Run("variant offer takes priority", func(t *testing.T) {
+		v := models.ProductVariant{Price: 50, CurrentOffer: activeOffer(models.DiscountTypeFixedPrice, 40)}
+		assert.InDelta(t, 40.0, v.ApplyFinalPrice(productOffer), 0.001)
+	})
+
+	t.Run("expired or variant-scoped product offer is ignored", func(t *testing.T) {
+		expired := activeOffer(models.DiscountTypePercentage, 10)
+		expired.EndDate = time.Now().Add(-time.Minute)
+		v := models.ProductVariant{Price: 50}
+		assert.Equal(t, 50.0, v.ApplyFinalPrice(expired))
+
+		otherVariant := uuid.New()
+		scoped := activeOffer(models.DiscountTypePercentage, 10)
+		scoped.VariantID = &otherVariant
+		assert.Equal(t, 50.0, v.ApplyFinalPrice(scoped))
+		assert.Equal(t, 50.0, v.FinalPrice)
+	})
+}
+
+func TestNewDefaultVariant(t *testing.T) {
+	product := &models.Product{
+		ProductID:     uuid.MustParse("3f2a9c1e-0000-4000-8000-000000000000"),
+		Name:          "Balón de gas",
+		PackageSize:   "10kg",
+		Price:         48,
+		StockQuantity: 12,
+	}
+
+	v := models.NewDefaultVariant(product)
+	assert.Equal(t, "SKU-3F2A9C1E", v.SKU)
+	assert.Equal(t, "10kg", v.Name)
+	assert.Equal(t, 48.0, v.Price)
+	assert.Equal(t, 12, v.StockQuantity)
+	assert.True(t, v.IsDefault)
+	assert.True(t, v.IsActive)
+	require.NoError(t, v.Validate())
+
+	product.PackageSize = ""
+	assert.Equal(t, "Balón de gas", models.NewDefaultVariant(product).Name)
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecommendProducts(t *testing.T) {
+	gasCategory, waterCategory := uuid.New(), uuid.New()
+	product := func(name string, category uuid.UUID, popularity float64) *models.Product {
+		return &models.Product{ProductID: uuid.New(), Name: name, CategoryID: &category, PopularityScore: popularity}
+	}
+	gas10 := product("Balón de gas 10kg", gasCategory, 50)
+	gas5 := product("Balón de gas 5kg", gasCategory, 10)
+	valve := product("Válvula", gasCategory, 5)
+	water := product("Agua 20L", waterCategory, 100)
+	disliked := product("Agua 5L", waterCategory, 80)
+	candidates := []*models.Product{gas10, gas5, valve, water, disliked}
+
+	bad := 1
+	signals := []models.UserProductSignal{
+		{ProductID: gas10.ProductID, CategoryID: &gasCategory, Purchases: 3},
+		{ProductID: disliked.ProductID, CategoryID: &waterCategory, Rating: &bad},
+	}
+	associations := []models.ProductAssociation{
+		{ProductID: gas10.ProductID, RelatedProductID: valve.ProductID, Confidence: 0.5},
+		{ProductID: water.ProductID, RelatedProductID: gas5.ProductID, Confidence: 0.9}, // no parte de un producto del usuario
+	}
+
+	recommended := models.RecommendProducts(candidates, signals, associations, 10)
+	require.Len(t, recommended, 4, "products rated badly by the user are excluded")
+
+	names := make([]string, len(recommended))
+	for i, r := range recommended {
+		names[i] = r.Product.Name
+	}
+	assert.Equal(t, []string{"Balón de gas 10kg", "Válvula", "Balón de gas 5kg", "Agua 20L"}, names)
+
+	assert.Equal(t, []string{models.RecommendationReasonPurchased, models.RecommendationReasonCategory}, recommended[0].Reasons)
+	assert.Equal(t, 4.5, recommended[0].Score) // compra 2 + categoría 2 + popularidad 0.5
+	assert.Equal(t, []string{models.RecommendationReasonBoughtTogether, models.RecommendationReasonCategory}, recommended[1].Reasons)
+	assert.Equal(t, []string{models.RecommendationReasonPopular}, recommended[3].Reasons)
+	assert.Equal(t, 1.0, recommended[3].Score)
+
+	assert.Len(t, models.RecommendProducts(candidates, signals, associations, 2), 2)
+}
+
+func TestNormalizeRecommendedLimit(t *testing.T) {
+	assert.Equal(t, models.DefaultRecommendedLimit, models.NormalizeRecommendedLimit(-1))
+	assert.Equal(t, 5, models.NormalizeRecommendedLimit(5))
+	assert.Equal(t, models.MaxRecommendedLimit, models.NormalizeRecommendedLimit(100))
+}
+
+func TestRecommendationCandidateKeys(t *testing.T) {
+	gas, water, valve := uuid.New(), uuid.New(), uuid.New()
+	category := uuid.New()
+	signals := []models.UserProductSignal{
+		{ProductID: gas, CategoryID: &category, Purchases: 1},
+		{ProductID: water, CategoryID: &category, IsFavorite: true},
+	}
+	associations := []models.ProductAssociation{
+		{ProductID: gas, RelatedProductID: valve, Confidence: 0.6},
+		{ProductID: water, RelatedProductID: gas, Confidence: 0.3},
+	}
+
+	productIDs, categoryIDs := models.RecommendationCandidateKeys(signals, associations)
+	assert.Equal(t, []uuid.UUID{gas, water, valve}, productIDs)
+	assert.Equal(t, []uuid.UUID{category}, categoryIDs)
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateStockMovementRequest_Delta(t *testing.T) {
+	t.Run("valid movements", func(t *testing.T) {
+		cases := []struct {
+			req   models.CreateStockMovementRequest
+			delta int
+		}{
+			{models.CreateStockMovementRequest{MovementType: models.StockMovementPurchaseReceipt, Quantity: 30}, 30},
+			{models.CreateStockMovementRequest{MovementType: models.StockMovementDamage, Quantity: 2}, -2},
+			{models.CreateStockMovementRequest{MovementType: models.StockMovementAdjustment, Quantity: -5, Reason: " conteo físico "}, -5},
+			{models.CreateStockMovementRequest{MovementType: models.StockMovementAdjustment, Quantity: 4, Reason: "Devolución de proveedor"}, 4},
+		}
+		for _, tc := range cases {
+			delta, err := tc.req.Delta()
+			require.NoError(t, err, tc.req.MovementType)
+			assert.Equal(t, tc.delta, delta, tc.req.MovementType)
+		}
+	})
+
+	t.Run("adjustment reason is trimmed", func(t *testing.T) {
+		req := models.CreateStockMovementRequest{MovementType: models.StockMovementAdjustment, Quantity: 1, Reason: "  conteo  "}
+		_, err := req.Delta()
+		require.NoError(t, err)
+		assert.Equal(t, "conteo", req.Reason)
+	})
+
+	t.Run("invalid movements", func(t *testing.T) {
+		cases := map[string]models.CreateStockMovementRequest{
+			"receipt without quantity":   {MovementType: models.StockMovementPurchaseReceipt},
+			"negative receipt":           {MovementType: models.StockMovementPurchaseReceipt, Quantity: -3},
+			"negative damage":            {MovementType: models.StockMovementDamage, Quantity: -1},
+			"adjustment without reason":  {MovementType: models.StockMovementAdjustment, Quantity: 3, Reason: "  "},
+			"zero adjustment":            {MovementType: models.StockMovementAdjustment, Reason: "conteo"},
+			"sale is recorded by orders": {MovementType: models.StockMovementSale, Quantity: 1},
+			"cancellation return":        {MovementType: models.StockMovementCancellationReturn, Quantity: 1},
+			"unknown type":               {MovementType: "GIFT", Quantity: 1},
+		}
+		for name, req := range cases {
+			_, err := req.Delta()
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestProduct_IsLowStock(t *testing.T) {
+	assert.False(t, (&models.Product{StockQuantity: 0}).IsLowStock(), "no threshold means no alert")
+	assert.True(t, (&models.Product{StockQuantity: 4, ReorderThreshold: 5}).IsLowStock())
+	assert.False(t, (&models.Product{StockQuantity: 5, ReorderThreshold: 5}).IsLowStock())
+}
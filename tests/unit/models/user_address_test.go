@@ -0,0 +1,67 @@
+package models
+
+import (
+	"backend/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validAddress() models.UserAddress {
+	return models.UserAddress{
+		UserID:      uuid.New(),
+		Label:       " Casa ",
+		AddressText: "Av. Los Próceres 123, Lima",
+		Latitude:    -12.046374,
+		Longitude:   -77.042793,
+		Reference:   "Portón verde",
+	}
+}
+
+func TestUserAddress_Validate(t *testing.T) {
+	t.Run("valid address is trimmed", func(t *testing.T) {
+		address := validAddress()
+		require.NoError(t, address.Validate())
+		assert.Equal(t, "Casa", address.Label)
+	})
+
+	tests := []struct {
+		name   string
+		modify func(a *models.UserAddress)
+	}{
+		{"empty label", func(a *models.UserAddress) { a.Label = "  " }},
+		{"empty address text", func(a *models.UserAddress) { a.AddressText = "" }},
+		{"latitude out of range", func(a *models.UserAddress) { a.Latitude = 91 }},
+		{"longitude out of range", func(a *models.UserAddress) { a.Longitude = -181 }},
+		{"missing coordinates", func(a *models.UserAddress) { a.Latitude, a.Longitude = 0, 0 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := validAddress()
+			tt.modify(&address)
+			assert.Error(t, address.Validate())
+		})
+	}
+}
+
+func TestUserAddress_ApplyToOrder(t *testing.T) {
+	address := validAddress()
+	address.AddressID = uuid.New()
+
+	order := &models.Order{DeliveryAddressText: "dirección anterior"}
+	address.ApplyToOrder(order)
+
+	require.NotNil(t, order.AddressID)
+	assert.Equal(t, address.AddressID, *order.AddressID)
+	assert.Equal(t, address.Latitude, order.Latitude)
+	assert.Equal(t, address.Longitude, order.Longitude)
+	assert.Equal(t, address.AddressText, order.DeliveryAddressText)
+	assert.Equal(t, address.Reference, order.DeliveryReference)
+
+	// La copia no depende de cambios posteriores en la libreta
+	address.AddressText = "Nueva dirección"
+	assert.Equal(t, "Av. Los Próceres 123, Lima", order.DeliveryAddressText)
+}
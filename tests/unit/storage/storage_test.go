@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	store := storage.NewLocalStorage(dir, "/uploads/")
+
+	url, err := store.Put("products/abc/thumb.webp", []byte("data"), "image/webp")
+	require.NoError(t, err)
+	assert.Equal(t, "/uploads/products/abc/thumb.webp", url)
+
+	content, err := os.ReadFile(filepath.Join(dir, "products", "abc", "thumb.webp"))
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(content))
+
+	require.NoError(t, store.Delete("products/abc/thumb.webp"))
+	_, err = os.Stat(filepath.Join(dir, "products", "abc", "thumb.webp"))
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, store.Delete("products/abc/thumb.webp"), "borrar un archivo inexistente no falla")
+
+	for _, key := range []string{"", "/etc/passwd", "../secreto", "products/../../x", "a//b", `a\b`} {
+		_, err := store.Put(key, []byte("x"), "text/plain")
+		assert.ErrorIs(t, err, storage.ErrInvalidKey, key)
+	}
+}
+
+func TestNewS3Storage(t *testing.T) {
+	_, err := storage.NewS3Storage(storage.S3Config{Endpoint: "https://s3.example.com"})
+	assert.Error(t, err, "requiere bucket y credenciales")
+
+	_, err = storage.NewS3Storage(storage.S3Config{Endpoint: "sin-esquema", Bucket: "b", AccessKey: "a", SecretKey: "s"})
+	assert.Error(t, err)
+}
+
+// Ejemplo de la documentación de AWS Signature Version 4 (IAM ListUsers)
+func TestSignV4(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	storage.SignV4(req,
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		"us-east-1", "iam",
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC),
+	)
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	authorization := req.Header.Get("Authorization")
+	assert.True(t, strings.HasPrefix(authorization,
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, "))
+	assert.True(t, strings.HasSuffix(authorization,
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"), authorization)
+}
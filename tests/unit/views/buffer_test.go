@@ -0,0 +1,80 @@
+package views
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/views"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuffer_DeduplicatesWithinWindow(t *testing.T) {
+	now := time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+	buffer := views.NewBuffer(30 * time.Minute)
+	gas, water := uuid.New(), uuid.New()
+
+	assert.True(t, buffer.Record(gas, "user:1", now))
+	assert.False(t, buffer.Record(gas, "user:1", now.Add(10*time.Minute)), "same viewer within the window")
+	assert.True(t, buffer.Record(gas, "device:abc", now), "another viewer")
+	assert.True(t, buffer.Record(water, "user:1", now), "another product")
+	assert.True(t, buffer.Record(gas, "user:1", now.Add(30*time.Minute)), "window expired")
+
+	assert.Equal(t, 4, buffer.Pending())
+	assert.Equal(t, map[uuid.UUID]int{gas: 3, water: 1}, buffer.Drain(now.Add(30*time.Minute)))
+	assert.Equal(t, 0, buffer.Pending())
+	assert.False(t, buffer.Record(gas, "user:1", now.Add(40*time.Minute)), "draining keeps viewers still within the window")
+}
+
+func TestBuffer_DrainForgetsExpiredViewers(t *testing.T) {
+	now := time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+	buffer := views.NewBuffer(time.Minute)
+	gas := uuid.New()
+
+	buffer.Record(gas, "user:1", now)
+	buffer.Drain(now.Add(2 * time.Minute))
+	assert.True(t, buffer.Record(gas, "user:1", now.Add(2*time.Minute)))
+}
+
+func TestBuffer_ZeroWindowCountsEveryView(t *testing.T) {
+	now := time.Now()
+	buffer := views.NewBuffer(0)
+	gas := uuid.New()
+
+	assert.True(t, buffer.Record(gas, "user:1", now))
+	assert.True(t, buffer.Record(gas, "user:1", now))
+	assert.Equal(t, map[uuid.UUID]int{gas: 2}, buffer.Drain(now))
+}
+
+func TestBuffer_RestoreRequeuesFailedBatch(t *testing.T) {
+	now := time.Now()
+	buffer := views.NewBuffer(time.Minute)
+	gas := uuid.New()
+
+	buffer.Record(gas, "user:1", now)
+	batch := buffer.Drain(now)
+	buffer.Record(gas, "user:2", now)
+	buffer.Restore(batch)
+
+	assert.Equal(t, map[uuid.UUID]int{gas: 2}, buffer.Drain(now))
+}
+
+func TestBuffer_ConcurrentRecords(t *testing.T) {
+	now := time.Now()
+	buffer := views.NewBuffer(time.Hour)
+	gas := uuid.New()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			buffer.Record(gas, "device:"+string(rune('a'+i%10)), now)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, buffer.Pending(), "one view per distinct viewer")
+}